@@ -0,0 +1,44 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// Backend degrade modes
+const (
+	// DegradeModeNone serves a backend normally while its health check fails
+	DegradeModeNone = "none"
+	// DegradeModeReadOnly rejects writes to a backend while its health check fails
+	DegradeModeReadOnly = "readonly"
+)
+
+// HealthCheckFailing reports whether the last connectivity test of the backend failed
+func (b *MinioBackend) HealthCheckFailing() bool {
+	return meta.IsStatusConditionFalse(b.Status.Conditions, "Ready")
+}
+
+// HealthCheckFailing reports whether the last connectivity test of the backend failed
+func (b *WebDavBackend) HealthCheckFailing() bool {
+	return meta.IsStatusConditionFalse(b.Status.Conditions, "Ready")
+}
+
+// HealthCheckFailing reports whether the backend was checked and found not ready
+func (b *FilesystemBackend) HealthCheckFailing() bool {
+	return b.Status.LastChecked != nil && !b.Status.Ready
+}
@@ -0,0 +1,32 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidateMinioEndpoint checks a MinioBackend endpoint against the HTTPS
+// policy. When requireHTTPS is set, plain http:// endpoints are rejected so
+// credentials and data never cross the network unencrypted.
+func ValidateMinioEndpoint(endpoint string, requireHTTPS bool) error {
+	if requireHTTPS && !strings.HasPrefix(strings.ToLower(endpoint), "https://") {
+		return fmt.Errorf("endpoint %q must use https:// while HTTPS backends are required", endpoint)
+	}
+	return nil
+}
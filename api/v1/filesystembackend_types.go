@@ -47,11 +47,66 @@ type FilesystemBackendSpec struct {
 	// +kubebuilder:default:=0
 	MaxFileSize int64 `json:"maxFileSize,omitempty"`
 
+	// CompressAtRest stores files gzip-compressed on disk with a ".gz" suffix.
+	// Clients still see the original names, sizes and content. Other ".gz"
+	// files on disk are served as they are.
+	// +kubebuilder:default:=false
+	// +optional
+	CompressAtRest bool `json:"compressAtRest,omitempty"`
+
+	// FollowSymlinks reports symbolic links as the files or directories they
+	// point to. When false, links are listed as links along with their targets.
+	// +kubebuilder:default:=false
+	// +optional
+	FollowSymlinks bool `json:"followSymlinks,omitempty"`
+
+	// DatePartition stores each upload under a YYYY/MM/DD directory of the
+	// upload date, taken from the server's clock, inside the directory the
+	// client uploaded to. Listings show the date directories.
+	// +kubebuilder:default:=false
+	// +optional
+	DatePartition bool `json:"datePartition,omitempty"`
+
+	// AutoCreateParents creates missing parent directories on upload, so a
+	// STOR of a/b/c.txt also creates a/b. When false, uploads into a missing
+	// directory are refused with 550.
+	// +kubebuilder:default:=true
+	// +optional
+	AutoCreateParents *bool `json:"autoCreateParents,omitempty"`
+
+	// MaxConcurrentOperations caps the storage operations running against this
+	// backend at once across all sessions. Transfers over the limit are refused
+	// with a temporary error so clients retry. Zero means unlimited.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxConcurrentOperations int32 `json:"maxConcurrentOperations,omitempty"`
+
+	// DegradeMode controls sessions while the backend's health check is failing:
+	// "none" keeps serving it normally, "readonly" serves reads and rejects writes
+	// until the backend is ready again
+	// +kubebuilder:default="none"
+	// +kubebuilder:validation:Enum=none;readonly
+	// +optional
+	DegradeMode string `json:"degradeMode,omitempty"`
+
+	// MinFreeBytes is the free space the base path's filesystem must keep.
+	// Below it the backend is marked not ready and writes are rejected.
+	// Zero disables the check.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinFreeBytes int64 `json:"minFreeBytes,omitempty"`
+
 	// VolumeClaimRef references the PersistentVolumeClaim to use for storage
 	// +optional
 	VolumeClaimRef *VolumeClaimReference `json:"volumeClaimRef,omitempty"`
 }
 
+// CreatesParents reports whether uploads create missing parent directories,
+// which is the default when AutoCreateParents is unset
+func (spec *FilesystemBackendSpec) CreatesParents() bool {
+	return spec.AutoCreateParents == nil || *spec.AutoCreateParents
+}
+
 // VolumeClaimReference references a PersistentVolumeClaim
 type VolumeClaimReference struct {
 	// Name is the name of the PersistentVolumeClaim
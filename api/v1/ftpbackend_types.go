@@ -0,0 +1,165 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// FtpBackendSpec defines the desired state of FtpBackend
+type FtpBackendSpec struct {
+	// Host is the hostname or IP address of the remote FTP server
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Host string `json:"host"`
+
+	// Port is the control port of the remote FTP server
+	// +kubebuilder:default=21
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// BasePath is the directory on the remote server that user home
+	// directories are resolved under
+	// +optional
+	BasePath string `json:"basePath,omitempty"`
+
+	// Credentials specify how to log in to the remote server
+	// +kubebuilder:validation:Required
+	Credentials FtpCredentials `json:"credentials"`
+
+	// TLS configures FTPS to the remote server
+	// +optional
+	TLS *FtpTLSConfig `json:"tls,omitempty"`
+
+	// TimeoutSeconds bounds connecting to the remote server and each command sent to it
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=30
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+
+	// MaxConcurrentOperations caps the storage operations running against this
+	// backend at once across all sessions. Transfers over the limit are refused
+	// with a temporary error so clients retry. Zero means unlimited.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxConcurrentOperations int32 `json:"maxConcurrentOperations,omitempty"`
+}
+
+// FtpCredentials define authentication for the remote FTP server
+type FtpCredentials struct {
+	// UseSecret references the Secret holding the username and password
+	// +kubebuilder:validation:Required
+	UseSecret FtpSecretRef `json:"useSecret"`
+}
+
+// FtpSecretRef references a Kubernetes Secret for remote FTP credentials
+type FtpSecretRef struct {
+	// Name of the secret
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace of the secret (defaults to same namespace)
+	// +optional
+	Namespace *string `json:"namespace,omitempty"`
+
+	// UsernameKey is the key in the secret containing the username
+	// +kubebuilder:default="username"
+	UsernameKey string `json:"usernameKey,omitempty"`
+
+	// PasswordKey is the key in the secret containing the password
+	// +kubebuilder:default="password"
+	PasswordKey string `json:"passwordKey,omitempty"`
+}
+
+// FtpTLSConfig defines FTPS settings for the remote connection
+type FtpTLSConfig struct {
+	// Mode selects plain FTP ("none"), explicit FTPS upgraded with AUTH TLS
+	// ("explicit") or implicit FTPS where the connection starts in TLS ("implicit")
+	// +kubebuilder:default="explicit"
+	// +kubebuilder:validation:Enum=none;explicit;implicit
+	// +optional
+	Mode string `json:"mode,omitempty"`
+
+	// InsecureSkipVerify controls whether to skip certificate verification
+	// +kubebuilder:default=false
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+
+	// CACert is an inline PEM-encoded CA certificate bundle for verifying the remote server.
+	// Use CASecretRef instead when the CA needs to be rotated or kept out of the CRD spec.
+	// +optional
+	CACert string `json:"caCert,omitempty"`
+
+	// CASecretRef references a Kubernetes Secret containing the PEM-encoded CA bundle.
+	// Takes precedence over CACert when both are set.
+	// +optional
+	CASecretRef *TLSCASecretRef `json:"caSecretRef,omitempty"`
+}
+
+// FtpBackendStatus defines the observed state of FtpBackend.
+type FtpBackendStatus struct {
+	// Ready indicates if the backend is accessible and ready for use
+	// +optional
+	Ready bool `json:"ready,omitempty"`
+
+	// Conditions represent the latest available observations of the backend's state
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Message provides additional status information
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// FtpBackend is the Schema for the ftpbackends API. It proxies storage
+// operations to another FTP or FTPS server, e.g. to migrate or federate.
+type FtpBackend struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of FtpBackend
+	// +required
+	Spec FtpBackendSpec `json:"spec"`
+
+	// status defines the observed state of FtpBackend
+	// +optional
+	Status FtpBackendStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// FtpBackendList contains a list of FtpBackend
+type FtpBackendList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FtpBackend `json:"items"`
+}
+
+func init() {
+	SchemeBuilder = append(SchemeBuilder, func(s *runtime.Scheme) error {
+		s.AddKnownTypes(GroupVersion, &FtpBackend{}, &FtpBackendList{})
+		return nil
+	})
+}
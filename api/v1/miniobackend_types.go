@@ -17,6 +17,8 @@ limitations under the License.
 package v1
 
 import (
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -44,6 +46,15 @@ type MinioBackendSpec struct {
 	// +optional
 	PathPrefix string `json:"pathPrefix,omitempty"`
 
+	// KeyNormalization controls how client-supplied paths are mapped to object keys.
+	// "strict" collapses duplicate slashes, strips trailing dots from path segments
+	// and rejects names containing control characters, which makes existing objects
+	// whose keys end in a dot unreachable; "none" passes paths through unchanged.
+	// +kubebuilder:default="none"
+	// +kubebuilder:validation:Enum=strict;none
+	// +optional
+	KeyNormalization string `json:"keyNormalization,omitempty"`
+
 	// Credentials specify how to authenticate with MinIO
 	// +kubebuilder:validation:Required
 	Credentials MinioCredentials `json:"credentials"`
@@ -51,6 +62,162 @@ type MinioBackendSpec struct {
 	// TLS configuration for MinIO connection
 	// +optional
 	TLS *MinioTLSConfig `json:"tls,omitempty"`
+
+	// ServerSideEncryption requests server-side encryption of uploaded objects
+	// +optional
+	ServerSideEncryption *MinioSSEConfig `json:"serverSideEncryption,omitempty"`
+
+	// StorageClass is the S3 storage class requested for uploaded objects, e.g.
+	// STANDARD_IA or GLACIER. Empty leaves the bucket's default class.
+	// +kubebuilder:validation:Pattern="^[A-Z0-9_]*$"
+	// +optional
+	StorageClass string `json:"storageClass,omitempty"`
+
+	// StorageClassRules override StorageClass for uploads under a path prefix.
+	// Rules are checked in order and the first whose pathPrefix contains the
+	// uploaded path wins.
+	// +optional
+	StorageClassRules []MinioStorageClassRule `json:"storageClassRules,omitempty"`
+
+	// MultipartThreshold is the size in bytes from which uploads of a known
+	// size are sent as multipart uploads. Smaller uploads use a single PUT.
+	// Zero leaves the choice to the MinIO client. Streamed uploads of unknown
+	// size are always multipart.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=5368709120
+	// +optional
+	MultipartThreshold int64 `json:"multipartThreshold,omitempty"`
+
+	// PartSize is the size in bytes of each part of a multipart upload.
+	// Zero uses the MinIO client default of 16MiB.
+	// +kubebuilder:validation:Minimum=5242880
+	// +kubebuilder:validation:Maximum=5368709120
+	// +optional
+	PartSize int64 `json:"partSize,omitempty"`
+
+	// ResumableUploads sends uploads as multipart uploads that are kept when a
+	// transfer is interrupted, so clients can resume them with REST+STOR.
+	// Interrupted uploads are tracked in the memory of the replica that received
+	// them, so a client must resume on the same replica, and are lost if it
+	// restarts. Uploads not resumed within 24 hours are aborted.
+	// +kubebuilder:default=false
+	// +optional
+	ResumableUploads bool `json:"resumableUploads,omitempty"`
+
+	// CaseInsensitiveLookup makes file lookups and downloads fall
+	// back to an object in the same directory whose name differs only in case
+	// when no exact key exists, e.g. "File.TXT" finds "file.txt". Each miss
+	// costs a listing of the directory.
+	// +kubebuilder:default=false
+	// +optional
+	CaseInsensitiveLookup bool `json:"caseInsensitiveLookup,omitempty"`
+
+	// StripLeadingSlash drops the leading "/" from object keys, so the path
+	// /home/alice/file.txt is stored as "home/alice/file.txt". Paths seen by
+	// FTP clients are unchanged.
+	// +kubebuilder:default=false
+	// +optional
+	StripLeadingSlash bool `json:"stripLeadingSlash,omitempty"`
+
+	// RequiredObjectTag restricts users of the backend to objects carrying
+	// this tag, given as "key=value", e.g. "tenant=acme". Other objects are
+	// left out of listings and cannot be downloaded, deleted or renamed, and
+	// uploads are tagged with it. Listing tags is a MinIO extension to S3.
+	// +kubebuilder:validation:Pattern="^[^=]+=.*$"
+	// +optional
+	RequiredObjectTag string `json:"requiredObjectTag,omitempty"`
+
+	// DatePartition stores each upload under a YYYY/MM/DD directory of the
+	// upload date, taken from the server's clock, inside the directory the
+	// client uploaded to. Listings show the date directories.
+	// +kubebuilder:default=false
+	// +optional
+	DatePartition bool `json:"datePartition,omitempty"`
+
+	// PresignedDownloads fetches objects through presigned GET URLs, which are
+	// cached per object and reused for repeated downloads instead of signing
+	// every request
+	// +kubebuilder:default=false
+	// +optional
+	PresignedDownloads bool `json:"presignedDownloads,omitempty"`
+
+	// PresignExpirySeconds is how long a presigned download URL stays valid.
+	// Cached URLs are renewed once less than half of this remains.
+	// +kubebuilder:validation:Minimum=60
+	// +kubebuilder:validation:Maximum=604800
+	// +kubebuilder:default=900
+	// +optional
+	PresignExpirySeconds int32 `json:"presignExpirySeconds,omitempty"`
+
+	// MaxConcurrentOperations caps the storage operations running against this
+	// backend at once across all sessions. Transfers over the limit are refused
+	// with a temporary error so clients retry. Zero means unlimited.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxConcurrentOperations int32 `json:"maxConcurrentOperations,omitempty"`
+
+	// ConnectTimeoutSeconds bounds dialing the endpoint and the bucket check
+	// run when the backend is connected, so an unreachable endpoint fails
+	// promptly and the backend is marked not ready. Zero keeps the MinIO
+	// client defaults.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=300
+	// +optional
+	ConnectTimeoutSeconds int32 `json:"connectTimeoutSeconds,omitempty"`
+
+	// UploadVerifyRetries is how many times an upload is sent again when the
+	// stored object's size does not match what was uploaded. Retried uploads
+	// are spooled to a temporary file so they can be resent. Only uploads up
+	// to 256 MiB are spooled; larger ones are sent once without retries.
+	// Zero fails the upload on the first mismatch.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	UploadVerifyRetries int32 `json:"uploadVerifyRetries,omitempty"`
+
+	// DegradeMode controls sessions while the backend's health check is failing:
+	// "none" keeps serving it normally, "readonly" serves reads and rejects writes
+	// until the backend is ready again
+	// +kubebuilder:default="none"
+	// +kubebuilder:validation:Enum=none;readonly
+	// +optional
+	DegradeMode string `json:"degradeMode,omitempty"`
+}
+
+// ObjectTag splits RequiredObjectTag into its key and value. ok is false when
+// no tag is required.
+func (spec *MinioBackendSpec) ObjectTag() (key, value string, ok bool) {
+	if spec.RequiredObjectTag == "" {
+		return "", "", false
+	}
+	key, value, _ = strings.Cut(spec.RequiredObjectTag, "=")
+	return key, value, true
+}
+
+// MinioSSEConfig configures server-side encryption for uploaded objects
+type MinioSSEConfig struct {
+	// Type selects SSE-S3 (server-managed keys) or SSE-KMS
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=SSE-S3;SSE-KMS
+	Type string `json:"type"`
+
+	// KMSKeyID is the KMS key used when Type is SSE-KMS (defaults to the bucket's key)
+	// +optional
+	KMSKeyID string `json:"kmsKeyID,omitempty"`
+}
+
+// MinioStorageClassRule selects the storage class for uploads under a path
+type MinioStorageClassRule struct {
+	// PathPrefix is matched against the uploaded file's path as seen by the
+	// server, before the backend's pathPrefix is added, e.g. "/archive/"
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	PathPrefix string `json:"pathPrefix"`
+
+	// StorageClass is requested for uploads under PathPrefix
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern="^[A-Z0-9_]+$"
+	StorageClass string `json:"storageClass"`
 }
 
 // MinioCredentials define authentication for MinIO
@@ -102,6 +269,27 @@ type TLSCASecretRef struct {
 	Key string `json:"key,omitempty"`
 }
 
+// TLSCABundleRef references a Secret or ConfigMap containing a CA certificate bundle
+type TLSCABundleRef struct {
+	// Kind of the referenced object
+	// +kubebuilder:validation:Enum=Secret;ConfigMap
+	// +kubebuilder:default="ConfigMap"
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Name of the Secret or ConfigMap
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace of the Secret or ConfigMap (defaults to same namespace as the backend resource)
+	// +optional
+	Namespace *string `json:"namespace,omitempty"`
+
+	// Key is the key containing the PEM-encoded CA bundle
+	// +kubebuilder:default="ca.crt"
+	Key string `json:"key,omitempty"`
+}
+
 // MinioTLSConfig defines TLS settings for MinIO connection
 type MinioTLSConfig struct {
 	// Enabled controls whether to use TLS
@@ -121,6 +309,12 @@ type MinioTLSConfig struct {
 	// Takes precedence over CACert when both are set.
 	// +optional
 	CASecretRef *TLSCASecretRef `json:"caSecretRef,omitempty"`
+
+	// CABundleRef references a Secret or ConfigMap containing the PEM-encoded CA
+	// bundle, such as one published by trust-manager. Takes precedence over
+	// CASecretRef and CACert.
+	// +optional
+	CABundleRef *TLSCABundleRef `json:"caBundleRef,omitempty"`
 }
 
 // MinioBackendStatus defines the observed state of MinioBackend.
@@ -0,0 +1,69 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// PermissionTemplateSpec defines a reusable set of user permissions
+type PermissionTemplateSpec struct {
+	// Description explains what the template is intended for
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Permissions granted to users referencing this template
+	// +kubebuilder:validation:Required
+	Permissions UserPermissions `json:"permissions"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Read",type=boolean,JSONPath=`.spec.permissions.read`
+// +kubebuilder:printcolumn:name="Write",type=boolean,JSONPath=`.spec.permissions.write`
+// +kubebuilder:printcolumn:name="Delete",type=boolean,JSONPath=`.spec.permissions.delete`
+// +kubebuilder:printcolumn:name="List",type=boolean,JSONPath=`.spec.permissions.list`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// PermissionTemplate is the Schema for the permissiontemplates API
+type PermissionTemplate struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the permissions provided by the template
+	// +required
+	Spec PermissionTemplateSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// PermissionTemplateList contains a list of PermissionTemplate
+type PermissionTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PermissionTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder = append(SchemeBuilder, func(s *runtime.Scheme) error {
+		s.AddKnownTypes(GroupVersion, &PermissionTemplate{}, &PermissionTemplateList{})
+		return nil
+	})
+}
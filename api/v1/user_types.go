@@ -31,6 +31,12 @@ type UserSpec struct {
 	// +kubebuilder:validation:Pattern="^[a-zA-Z0-9_-]+$"
 	Username string `json:"username"`
 
+	// LoginAliases are additional login names that authenticate as this user
+	// with the same password, e.g. device names of scanners sharing one identity
+	// +kubebuilder:validation:items:Pattern="^[a-zA-Z0-9_-]+$"
+	// +optional
+	LoginAliases []string `json:"loginAliases,omitempty"`
+
 	// Type indicates the type of user (regular, anonymous, admin)
 	// +kubebuilder:default="regular"
 	// +kubebuilder:validation:Enum=regular;anonymous;admin
@@ -45,10 +51,24 @@ type UserSpec struct {
 	// +optional
 	PasswordSecret *UserSecretRef `json:"passwordSecret,omitempty"`
 
+	// AllowPasswordChange lets the user change their own password with SITE PASSWD.
+	// Only passwords stored in PasswordSecret can be changed.
+	// +kubebuilder:default=false
+	// +optional
+	AllowPasswordChange bool `json:"allowPasswordChange,omitempty"`
+
 	// Backend specifies which backend storage to use
 	// +kubebuilder:validation:Required
 	Backend BackendReference `json:"backend"`
 
+	// MirrorBackend receives a copy of each completed upload in the background,
+	// e.g. for backup. Mirror failures are retried and logged but never fail
+	// the upload to Backend. Uploads wait for the copy in the server's temporary
+	// directory, which holds at most 1 GiB across all users; uploads that would
+	// exceed it are not mirrored.
+	// +optional
+	MirrorBackend *BackendReference `json:"mirrorBackend,omitempty"`
+
 	// HomeDirectory is the virtual home directory path for the user
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:Pattern="^/.*"
@@ -67,24 +87,188 @@ type UserSpec struct {
 	// Permissions define what the user can do
 	// +optional
 	Permissions UserPermissions `json:"permissions,omitempty"`
+
+	// PermissionTemplateRef references a PermissionTemplate that supplies the user's
+	// permissions in place of Permissions. PermissionOverrides adjust individual
+	// permissions of the template.
+	// +optional
+	PermissionTemplateRef *PermissionTemplateReference `json:"permissionTemplateRef,omitempty"`
+
+	// PermissionOverrides replace individual permissions of the PermissionTemplateRef
+	// template. Unset fields keep the template's value, and a field set to false
+	// denies that permission even when the template grants it.
+	// +optional
+	PermissionOverrides *PermissionOverrides `json:"permissionOverrides,omitempty"`
+
+	// OverwritePolicy controls uploads to a path that already exists: allow replaces
+	// the file, deny rejects the upload, and rename stores it under a numbered name
+	// +kubebuilder:default="allow"
+	// +kubebuilder:validation:Enum=allow;deny;rename
+	// +optional
+	OverwritePolicy string `json:"overwritePolicy,omitempty"`
+
+	// DefaultTransferType is the transfer type of the user's sessions until the
+	// client sends TYPE. With ascii, line endings are translated between CRLF on
+	// the wire and LF in storage.
+	// +kubebuilder:default="binary"
+	// +kubebuilder:validation:Enum=ascii;binary
+	// +optional
+	DefaultTransferType string `json:"defaultTransferType,omitempty"`
+
+	// MaxSessionDuration closes the user's sessions this long after login,
+	// even while they are active, e.g. "8h". Zero never closes them.
+	// +optional
+	MaxSessionDuration metav1.Duration `json:"maxSessionDuration,omitempty"`
+
+	// SingleSession allows the user one logged-in session at a time: reject
+	// refuses a new login while another session is active, replace closes the
+	// existing session and lets the new login in. Unset allows any number.
+	// +kubebuilder:validation:Enum=reject;replace
+	// +optional
+	SingleSession string `json:"singleSession,omitempty"`
+
+	// IdleTimeout closes the user's sessions after waiting this long for the
+	// next command, e.g. "30m", instead of the server's --ftp-idle-timeout.
+	// Zero uses the server's setting.
+	// +optional
+	IdleTimeout metav1.Duration `json:"idleTimeout,omitempty"`
+
+	// Timezone is the IANA time zone, e.g. "Europe/Berlin", in which LIST shows
+	// file times to this user. MDTM and MLSD always report UTC.
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+
+	// QuotaBytes limits the total size of the user's files. Uploads are refused
+	// once usage reaches the limit. Zero means unlimited.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	QuotaBytes int64 `json:"quotaBytes,omitempty"`
+
+	// MaxFiles limits the number of files and directories the user can store.
+	// Uploads and new directories are refused once the count reaches the limit.
+	// Zero means unlimited.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxFiles int64 `json:"maxFiles,omitempty"`
+
+	// ShowQuotaFile adds a read-only ".quota" file to the home directory that
+	// reports the user's used and available bytes
+	// +optional
+	ShowQuotaFile bool `json:"showQuotaFile,omitempty"`
+
+	// AllowedCIDRs restricts logins to client addresses within these CIDR ranges.
+	// When empty, any address not matched by DeniedCIDRs may log in.
+	// +optional
+	AllowedCIDRs []string `json:"allowedCIDRs,omitempty"`
+
+	// DeniedCIDRs rejects logins from client addresses within these CIDR ranges
+	// +optional
+	DeniedCIDRs []string `json:"deniedCIDRs,omitempty"`
+
+	// CIDRConfigMapRef references a shared ConfigMap whose allowedCIDRs and deniedCIDRs
+	// keys are merged with the lists set inline. Edits to the ConfigMap apply to new logins.
+	// +optional
+	CIDRConfigMapRef *ConfigMapReference `json:"cidrConfigMapRef,omitempty"`
+
+	// HomeByCIDR selects the home directory from the client's address at login.
+	// The first rule whose CIDR contains the address replaces HomeDirectory for
+	// the session, including as the chroot; other clients use HomeDirectory.
+	// +optional
+	HomeByCIDR []HomeCIDRRule `json:"homeByCIDR,omitempty"`
+
+	// LogDestination routes this user's file operation logs away from the
+	// server log: an absolute file path, "syslog" for the local syslog daemon,
+	// or "syslog://host:port" for a remote syslog server over UDP
+	// +kubebuilder:validation:Pattern="^(/.+|syslog(://.+)?)$"
+	// +optional
+	LogDestination string `json:"logDestination,omitempty"`
+
+	// Tags are free-form metadata, such as department or device, attached to
+	// this user's operation logs. Tag keys safelisted on the server are also
+	// exported in the kubeftpd_user_tag_info metric.
+	// +optional
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// HomeCIDRRule maps a client subnet to a home directory
+type HomeCIDRRule struct {
+	// CIDR is the client address range, e.g. 10.1.0.0/16
+	// +kubebuilder:validation:Required
+	CIDR string `json:"cidr"`
+
+	// HomeDirectory is the home directory for clients within CIDR
+	// +kubebuilder:validation:Required
+	HomeDirectory string `json:"homeDirectory"`
+}
+
+// ConfigMapReference refers to a Kubernetes ConfigMap
+type ConfigMapReference struct {
+	// Name of the ConfigMap
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace of the ConfigMap (defaults to same namespace as User)
+	// +optional
+	Namespace *string `json:"namespace,omitempty"`
+}
+
+// PermissionTemplateReference refers to a PermissionTemplate resource
+type PermissionTemplateReference struct {
+	// Name of the PermissionTemplate
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace of the PermissionTemplate (defaults to same namespace as User)
+	// +optional
+	Namespace *string `json:"namespace,omitempty"`
+}
+
+// PermissionOverrides set individual permissions on top of a PermissionTemplate
+type PermissionOverrides struct {
+	// Read permission for downloading files
+	// +optional
+	Read *bool `json:"read,omitempty"`
+
+	// Write permission for uploading files
+	// +optional
+	Write *bool `json:"write,omitempty"`
+
+	// Delete permission for removing files
+	// +optional
+	Delete *bool `json:"delete,omitempty"`
+
+	// List permission for listing directories
+	// +optional
+	List *bool `json:"list,omitempty"`
 }
 
 // BackendReference refers to a backend storage resource
 type BackendReference struct {
-	// Kind specifies the backend type (MinioBackend, WebDavBackend, FilesystemBackend)
+	// Kind specifies the backend type (MinioBackend, WebDavBackend, FilesystemBackend, FtpBackend)
 	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:Enum=MinioBackend;WebDavBackend;FilesystemBackend
+	// +kubebuilder:validation:Enum=MinioBackend;WebDavBackend;FilesystemBackend;FtpBackend
 	Kind string `json:"kind"`
 
 	// Name of the backend resource
 	// +kubebuilder:validation:Required
 	Name string `json:"name"`
 
-	// Namespace of the backend resource (defaults to same namespace)
+	// Namespace of the backend resource (defaults to same namespace).
+	// Referencing a shared backend in another namespace requires the operator
+	// to be allowed to read it there.
 	// +optional
 	Namespace *string `json:"namespace,omitempty"`
 }
 
+// ResolveNamespace returns the namespace the referenced backend lives in,
+// falling back to the referencing User's namespace
+func (r BackendReference) ResolveNamespace(userNamespace string) string {
+	if r.Namespace != nil && *r.Namespace != "" {
+		return *r.Namespace
+	}
+	return userNamespace
+}
+
 // UserSecretRef references a Kubernetes Secret for user password
 type UserSecretRef struct {
 	// Name of the secret
@@ -140,6 +324,10 @@ type UserStatus struct {
 	// Message provides additional status information
 	// +optional
 	Message string `json:"message,omitempty"`
+
+	// EffectivePermissions are the permissions in force after resolving PermissionTemplateRef
+	// +optional
+	EffectivePermissions *UserPermissions `json:"effectivePermissions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
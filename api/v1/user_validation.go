@@ -0,0 +1,199 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ValidateHomeDirectory checks that a user's home directory is an absolute,
+// normalized path without ".." segments. Messy homes such as "/home//bob/" or
+// "/srv/../etc" resolve unpredictably under chroot.
+func ValidateHomeDirectory(homeDirectory string) error {
+	if homeDirectory == "" {
+		return fmt.Errorf("homeDirectory is required")
+	}
+	if !strings.HasPrefix(homeDirectory, "/") {
+		return fmt.Errorf("homeDirectory %q must be an absolute path", homeDirectory)
+	}
+	for _, segment := range strings.Split(homeDirectory, "/") {
+		if segment == ".." {
+			return fmt.Errorf("homeDirectory %q must not contain '..'", homeDirectory)
+		}
+	}
+	if cleaned := path.Clean(homeDirectory); cleaned != homeDirectory {
+		return fmt.Errorf("homeDirectory %q is not normalized (use %q)", homeDirectory, cleaned)
+	}
+	return nil
+}
+
+// ValidateHomeByCIDR checks that each HomeByCIDR rule has a valid CIDR and a
+// home directory that passes ValidateHomeDirectory
+func ValidateHomeByCIDR(rules []HomeCIDRRule) error {
+	for i, rule := range rules {
+		if _, _, err := net.ParseCIDR(rule.CIDR); err != nil {
+			return fmt.Errorf("homeByCIDR[%d]: invalid CIDR %q: %w", i, rule.CIDR, err)
+		}
+		if err := ValidateHomeDirectory(rule.HomeDirectory); err != nil {
+			return fmt.Errorf("homeByCIDR[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ValidateTimezone checks that a user's timezone, if set, names a time zone
+// in the IANA database
+func ValidateTimezone(timezone string) error {
+	if timezone == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(timezone); err != nil || timezone == "Local" {
+		return fmt.Errorf("timezone %q is not a known IANA time zone", timezone)
+	}
+	return nil
+}
+
+// Character classes a PasswordPolicy can require
+const (
+	PasswordClassUpper   = "upper"
+	PasswordClassLower   = "lower"
+	PasswordClassDigit   = "digit"
+	PasswordClassSpecial = "special"
+)
+
+// PasswordClasses lists the supported character classes
+var PasswordClasses = []string{PasswordClassUpper, PasswordClassLower, PasswordClassDigit, PasswordClassSpecial}
+
+// passwordClassNames maps character classes to their names in error messages
+var passwordClassNames = map[string]string{
+	PasswordClassUpper:   "uppercase letter",
+	PasswordClassLower:   "lowercase letter",
+	PasswordClassDigit:   "digit",
+	PasswordClassSpecial: "special character",
+}
+
+// PasswordPolicy describes the password strength rules enforced by the
+// admission webhook and SITE PASSWD.
+// +kubebuilder:object:generate=false
+type PasswordPolicy struct {
+	// MinLength is the minimum password length in bytes
+	MinLength int
+	// RequiredClasses lists the character classes a password must contain
+	RequiredClasses []string
+	// BannedSubstrings are rejected anywhere in the password, case-insensitively
+	BannedSubstrings []string
+	// DisallowSequential rejects runs such as "123" or "abc"
+	DisallowSequential bool
+}
+
+// DefaultPasswordPolicy returns the built-in password rules
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:       8,
+		RequiredClasses: []string{PasswordClassUpper, PasswordClassLower, PasswordClassDigit, PasswordClassSpecial},
+		BannedSubstrings: []string{
+			"password", "123456", "qwerty", "admin", "test", "user",
+			"welcome", "login", "pass", "secret", "default",
+		},
+		DisallowSequential: true,
+	}
+}
+
+// passwordPolicy is the policy applied by ValidatePasswordStrength
+var (
+	passwordPolicyMu sync.RWMutex
+	passwordPolicy   = DefaultPasswordPolicy()
+)
+
+// SetPasswordPolicy replaces the policy applied by ValidatePasswordStrength
+func SetPasswordPolicy(policy PasswordPolicy) {
+	passwordPolicyMu.Lock()
+	defer passwordPolicyMu.Unlock()
+	passwordPolicy = policy
+}
+
+// CurrentPasswordPolicy returns the policy applied by ValidatePasswordStrength
+func CurrentPasswordPolicy() PasswordPolicy {
+	passwordPolicyMu.RLock()
+	defer passwordPolicyMu.RUnlock()
+	return passwordPolicy
+}
+
+// sequentialPattern matches three ascending digits or letters in a row
+var sequentialPattern = regexp.MustCompile(`(012|123|234|345|456|567|678|789|890|abc|bcd|cde|def|efg|fgh|ghi|hij|ijk|jkl|klm|lmn|mno|nop|opq|pqr|qrs|rst|stu|tuv|uvw|vwx|wxy|xyz)`)
+
+// ValidatePasswordStrength checks a plaintext password against the configured
+// PasswordPolicy, which defaults to DefaultPasswordPolicy.
+func ValidatePasswordStrength(password string) error {
+	return CurrentPasswordPolicy().Validate(password)
+}
+
+// Validate checks that a password is long and complex enough and avoids
+// banned substrings and, if configured, sequential characters.
+func (p PasswordPolicy) Validate(password string) error {
+	// Minimum length check
+	if len(password) < p.MinLength {
+		return fmt.Errorf("password must be at least %d characters long", p.MinLength)
+	}
+
+	// Check for banned substrings
+	lowercasePassword := strings.ToLower(password)
+	for _, weak := range p.BannedSubstrings {
+		if weak != "" && strings.Contains(lowercasePassword, strings.ToLower(weak)) {
+			return fmt.Errorf("password contains weak pattern: %s", weak)
+		}
+	}
+
+	// Complexity requirements
+	present := map[string]bool{}
+	for _, char := range password {
+		switch {
+		case char >= 'A' && char <= 'Z':
+			present[PasswordClassUpper] = true
+		case char >= 'a' && char <= 'z':
+			present[PasswordClassLower] = true
+		case char >= '0' && char <= '9':
+			present[PasswordClassDigit] = true
+		case strings.ContainsRune("!@#$%^&*()_+-=[]{}|;:,.<>?", char):
+			present[PasswordClassSpecial] = true
+		}
+	}
+
+	missing := []string{}
+	for _, class := range p.RequiredClasses {
+		if !present[class] {
+			missing = append(missing, passwordClassNames[class])
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("password must contain at least one: %s", strings.Join(missing, ", "))
+	}
+
+	// Check for sequential characters
+	if p.DisallowSequential && sequentialPattern.MatchString(lowercasePassword) {
+		return fmt.Errorf("password cannot contain sequential characters")
+	}
+
+	return nil
+}
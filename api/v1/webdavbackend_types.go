@@ -42,6 +42,57 @@ type WebDavBackendSpec struct {
 	// TLS configuration for WebDAV connection
 	// +optional
 	TLS *WebDavTLSConfig `json:"tls,omitempty"`
+
+	// ConnectionPool tunes the HTTP client shared by all sessions using this backend
+	// +optional
+	ConnectionPool *WebDavConnectionPool `json:"connectionPool,omitempty"`
+
+	// MaxConcurrentOperations caps the storage operations running against this
+	// backend at once across all sessions. Transfers over the limit are refused
+	// with a temporary error so clients retry. Zero means unlimited.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxConcurrentOperations int32 `json:"maxConcurrentOperations,omitempty"`
+
+	// DegradeMode controls sessions while the backend's health check is failing:
+	// "none" keeps serving it normally, "readonly" serves reads and rejects writes
+	// until the backend is ready again
+	// +kubebuilder:default="none"
+	// +kubebuilder:validation:Enum=none;readonly
+	// +optional
+	DegradeMode string `json:"degradeMode,omitempty"`
+}
+
+// WebDavConnectionPool defines limits for the shared WebDAV HTTP client
+type WebDavConnectionPool struct {
+	// MaxIdleConns is the maximum number of idle keep-alive connections kept open
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=100
+	// +optional
+	MaxIdleConns int32 `json:"maxIdleConns,omitempty"`
+
+	// MaxIdleConnsPerHost is the maximum number of idle connections kept per host
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=10
+	// +optional
+	MaxIdleConnsPerHost int32 `json:"maxIdleConnsPerHost,omitempty"`
+
+	// MaxConnsPerHost caps concurrent connections per host (0 means unlimited)
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxConnsPerHost int32 `json:"maxConnsPerHost,omitempty"`
+
+	// IdleConnTimeoutSeconds is how long an idle connection stays in the pool
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=90
+	// +optional
+	IdleConnTimeoutSeconds int32 `json:"idleConnTimeoutSeconds,omitempty"`
+
+	// RequestTimeoutSeconds bounds each WebDAV request, including reading the body
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=30
+	// +optional
+	RequestTimeoutSeconds int32 `json:"requestTimeoutSeconds,omitempty"`
 }
 
 // WebDavCredentials define authentication for WebDAV
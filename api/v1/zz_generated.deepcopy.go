@@ -45,6 +45,26 @@ func (in *BackendReference) DeepCopy() *BackendReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapReference) DeepCopyInto(out *ConfigMapReference) {
+	*out = *in
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapReference.
+func (in *ConfigMapReference) DeepCopy() *ConfigMapReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FilesystemBackend) DeepCopyInto(out *FilesystemBackend) {
 	*out = *in
@@ -107,6 +127,11 @@ func (in *FilesystemBackendList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FilesystemBackendSpec) DeepCopyInto(out *FilesystemBackendSpec) {
 	*out = *in
+	if in.AutoCreateParents != nil {
+		in, out := &in.AutoCreateParents, &out.AutoCreateParents
+		*out = new(bool)
+		**out = **in
+	}
 	if in.VolumeClaimRef != nil {
 		in, out := &in.VolumeClaimRef, &out.VolumeClaimRef
 		*out = new(VolumeClaimReference)
@@ -153,6 +178,179 @@ func (in *FilesystemBackendStatus) DeepCopy() *FilesystemBackendStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FtpBackend) DeepCopyInto(out *FtpBackend) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FtpBackend.
+func (in *FtpBackend) DeepCopy() *FtpBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(FtpBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FtpBackend) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FtpBackendList) DeepCopyInto(out *FtpBackendList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FtpBackend, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FtpBackendList.
+func (in *FtpBackendList) DeepCopy() *FtpBackendList {
+	if in == nil {
+		return nil
+	}
+	out := new(FtpBackendList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FtpBackendList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FtpBackendSpec) DeepCopyInto(out *FtpBackendSpec) {
+	*out = *in
+	in.Credentials.DeepCopyInto(&out.Credentials)
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(FtpTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FtpBackendSpec.
+func (in *FtpBackendSpec) DeepCopy() *FtpBackendSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FtpBackendSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FtpBackendStatus) DeepCopyInto(out *FtpBackendStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FtpBackendStatus.
+func (in *FtpBackendStatus) DeepCopy() *FtpBackendStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FtpBackendStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FtpCredentials) DeepCopyInto(out *FtpCredentials) {
+	*out = *in
+	in.UseSecret.DeepCopyInto(&out.UseSecret)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FtpCredentials.
+func (in *FtpCredentials) DeepCopy() *FtpCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(FtpCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FtpSecretRef) DeepCopyInto(out *FtpSecretRef) {
+	*out = *in
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FtpSecretRef.
+func (in *FtpSecretRef) DeepCopy() *FtpSecretRef {
+	if in == nil {
+		return nil
+	}
+	out := new(FtpSecretRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FtpTLSConfig) DeepCopyInto(out *FtpTLSConfig) {
+	*out = *in
+	if in.CASecretRef != nil {
+		in, out := &in.CASecretRef, &out.CASecretRef
+		*out = new(TLSCASecretRef)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FtpTLSConfig.
+func (in *FtpTLSConfig) DeepCopy() *FtpTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(FtpTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HomeCIDRRule) DeepCopyInto(out *HomeCIDRRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HomeCIDRRule.
+func (in *HomeCIDRRule) DeepCopy() *HomeCIDRRule {
+	if in == nil {
+		return nil
+	}
+	out := new(HomeCIDRRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MinioBackend) DeepCopyInto(out *MinioBackend) {
 	*out = *in
@@ -221,6 +419,16 @@ func (in *MinioBackendSpec) DeepCopyInto(out *MinioBackendSpec) {
 		*out = new(MinioTLSConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ServerSideEncryption != nil {
+		in, out := &in.ServerSideEncryption, &out.ServerSideEncryption
+		*out = new(MinioSSEConfig)
+		**out = **in
+	}
+	if in.StorageClassRules != nil {
+		in, out := &in.StorageClassRules, &out.StorageClassRules
+		*out = make([]MinioStorageClassRule, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinioBackendSpec.
@@ -280,6 +488,21 @@ func (in *MinioCredentials) DeepCopy() *MinioCredentials {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MinioSSEConfig) DeepCopyInto(out *MinioSSEConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinioSSEConfig.
+func (in *MinioSSEConfig) DeepCopy() *MinioSSEConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MinioSSEConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MinioSecretRef) DeepCopyInto(out *MinioSecretRef) {
 	*out = *in
@@ -300,6 +523,21 @@ func (in *MinioSecretRef) DeepCopy() *MinioSecretRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MinioStorageClassRule) DeepCopyInto(out *MinioStorageClassRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinioStorageClassRule.
+func (in *MinioStorageClassRule) DeepCopy() *MinioStorageClassRule {
+	if in == nil {
+		return nil
+	}
+	out := new(MinioStorageClassRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MinioTLSConfig) DeepCopyInto(out *MinioTLSConfig) {
 	*out = *in
@@ -308,6 +546,11 @@ func (in *MinioTLSConfig) DeepCopyInto(out *MinioTLSConfig) {
 		*out = new(TLSCASecretRef)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CABundleRef != nil {
+		in, out := &in.CABundleRef, &out.CABundleRef
+		*out = new(TLSCABundleRef)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinioTLSConfig.
@@ -320,6 +563,155 @@ func (in *MinioTLSConfig) DeepCopy() *MinioTLSConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PermissionOverrides) DeepCopyInto(out *PermissionOverrides) {
+	*out = *in
+	if in.Read != nil {
+		in, out := &in.Read, &out.Read
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Write != nil {
+		in, out := &in.Write, &out.Write
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Delete != nil {
+		in, out := &in.Delete, &out.Delete
+		*out = new(bool)
+		**out = **in
+	}
+	if in.List != nil {
+		in, out := &in.List, &out.List
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PermissionOverrides.
+func (in *PermissionOverrides) DeepCopy() *PermissionOverrides {
+	if in == nil {
+		return nil
+	}
+	out := new(PermissionOverrides)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PermissionTemplate) DeepCopyInto(out *PermissionTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PermissionTemplate.
+func (in *PermissionTemplate) DeepCopy() *PermissionTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(PermissionTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PermissionTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PermissionTemplateList) DeepCopyInto(out *PermissionTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PermissionTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PermissionTemplateList.
+func (in *PermissionTemplateList) DeepCopy() *PermissionTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(PermissionTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PermissionTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PermissionTemplateReference) DeepCopyInto(out *PermissionTemplateReference) {
+	*out = *in
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PermissionTemplateReference.
+func (in *PermissionTemplateReference) DeepCopy() *PermissionTemplateReference {
+	if in == nil {
+		return nil
+	}
+	out := new(PermissionTemplateReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PermissionTemplateSpec) DeepCopyInto(out *PermissionTemplateSpec) {
+	*out = *in
+	out.Permissions = in.Permissions
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PermissionTemplateSpec.
+func (in *PermissionTemplateSpec) DeepCopy() *PermissionTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PermissionTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSCABundleRef) DeepCopyInto(out *TLSCABundleRef) {
+	*out = *in
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSCABundleRef.
+func (in *TLSCABundleRef) DeepCopy() *TLSCABundleRef {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSCABundleRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLSCASecretRef) DeepCopyInto(out *TLSCASecretRef) {
 	*out = *in
@@ -437,13 +829,60 @@ func (in *UserSecretRef) DeepCopy() *UserSecretRef {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UserSpec) DeepCopyInto(out *UserSpec) {
 	*out = *in
+	if in.LoginAliases != nil {
+		in, out := &in.LoginAliases, &out.LoginAliases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.PasswordSecret != nil {
 		in, out := &in.PasswordSecret, &out.PasswordSecret
 		*out = new(UserSecretRef)
 		(*in).DeepCopyInto(*out)
 	}
 	in.Backend.DeepCopyInto(&out.Backend)
+	if in.MirrorBackend != nil {
+		in, out := &in.MirrorBackend, &out.MirrorBackend
+		*out = new(BackendReference)
+		(*in).DeepCopyInto(*out)
+	}
 	out.Permissions = in.Permissions
+	if in.PermissionTemplateRef != nil {
+		in, out := &in.PermissionTemplateRef, &out.PermissionTemplateRef
+		*out = new(PermissionTemplateReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PermissionOverrides != nil {
+		in, out := &in.PermissionOverrides, &out.PermissionOverrides
+		*out = new(PermissionOverrides)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedCIDRs != nil {
+		in, out := &in.AllowedCIDRs, &out.AllowedCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeniedCIDRs != nil {
+		in, out := &in.DeniedCIDRs, &out.DeniedCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CIDRConfigMapRef != nil {
+		in, out := &in.CIDRConfigMapRef, &out.CIDRConfigMapRef
+		*out = new(ConfigMapReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HomeByCIDR != nil {
+		in, out := &in.HomeByCIDR, &out.HomeByCIDR
+		*out = make([]HomeCIDRRule, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserSpec.
@@ -470,6 +909,11 @@ func (in *UserStatus) DeepCopyInto(out *UserStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.EffectivePermissions != nil {
+		in, out := &in.EffectivePermissions, &out.EffectivePermissions
+		*out = new(UserPermissions)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserStatus.
@@ -570,6 +1014,11 @@ func (in *WebDavBackendSpec) DeepCopyInto(out *WebDavBackendSpec) {
 		*out = new(WebDavTLSConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ConnectionPool != nil {
+		in, out := &in.ConnectionPool, &out.ConnectionPool
+		*out = new(WebDavConnectionPool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebDavBackendSpec.
@@ -609,6 +1058,21 @@ func (in *WebDavBackendStatus) DeepCopy() *WebDavBackendStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebDavConnectionPool) DeepCopyInto(out *WebDavConnectionPool) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebDavConnectionPool.
+func (in *WebDavConnectionPool) DeepCopy() *WebDavConnectionPool {
+	if in == nil {
+		return nil
+	}
+	out := new(WebDavConnectionPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WebDavCredentials) DeepCopyInto(out *WebDavCredentials) {
 	*out = *in
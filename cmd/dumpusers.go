@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+// Exit codes returned by the dump-users subcommand
+const (
+	dumpUsersExitOK     = 0
+	dumpUsersExitFailed = 1
+	dumpUsersExitUsage  = 2
+)
+
+// dumpedUser is one User as reported by dump-users. Passwords and secret
+// contents are never included.
+type dumpedUser struct {
+	Namespace     string                `json:"namespace"`
+	Name          string                `json:"name"`
+	Username      string                `json:"username"`
+	Type          string                `json:"type"`
+	Enabled       bool                  `json:"enabled"`
+	Ready         bool                  `json:"ready"`
+	HomeDirectory string                `json:"homeDirectory"`
+	Chroot        bool                  `json:"chroot"`
+	Backend       dumpedBackend         `json:"backend"`
+	Permissions   ftpv1.UserPermissions `json:"permissions"`
+}
+
+// dumpedBackend describes the backend a User resolves to
+type dumpedBackend struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Found     bool   `json:"found"`
+	Ready     bool   `json:"ready"`
+	// Target is where files are stored, e.g. an endpoint and bucket or a path
+	Target string `json:"target,omitempty"`
+}
+
+// runDumpUsers implements "kubeftpd dump-users". It lists the Users in the
+// cluster with their resolved backends and effective permissions.
+func runDumpUsers(args []string, out io.Writer) int {
+	fs := flag.NewFlagSet("dump-users", flag.ContinueOnError)
+	fs.SetOutput(out)
+	namespace := fs.String("namespace", "", "Only list Users in this namespace (default all namespaces)")
+	output := fs.String("output", "table", "Output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return dumpUsersExitUsage
+	}
+	if *output != "table" && *output != "json" {
+		_, _ = fmt.Fprintf(out, "error: unsupported output format %q (expected table or json)\n", *output)
+		return dumpUsersExitUsage
+	}
+
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		_, _ = fmt.Fprintf(out, "error: failed to load Kubernetes config: %v\n", err)
+		return dumpUsersExitFailed
+	}
+	kubeClient, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		_, _ = fmt.Fprintf(out, "error: failed to create Kubernetes client: %v\n", err)
+		return dumpUsersExitFailed
+	}
+
+	if err := dumpUsers(context.Background(), kubeClient, *namespace, *output, out); err != nil {
+		_, _ = fmt.Fprintf(out, "error: %v\n", err)
+		return dumpUsersExitFailed
+	}
+	return dumpUsersExitOK
+}
+
+// dumpUsers writes the Users in namespace (all if empty) to out as a table or JSON
+func dumpUsers(ctx context.Context, kubeClient client.Client, namespace, output string, out io.Writer) error {
+	users, err := collectUsers(ctx, kubeClient, namespace)
+	if err != nil {
+		return err
+	}
+
+	if output == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(users)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAMESPACE\tNAME\tUSERNAME\tTYPE\tENABLED\tHOME\tBACKEND\tTARGET\tPERMISSIONS")
+	for _, u := range users {
+		backend := u.Backend.Kind + "/" + u.Backend.Namespace + "/" + u.Backend.Name
+		target := u.Backend.Target
+		switch {
+		case !u.Backend.Found:
+			target = "<missing>"
+		case !u.Backend.Ready:
+			target += " (not ready)"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%s\t%s\t%s\t%s\n", u.Namespace, u.Name, u.Username, u.Type,
+			u.Enabled, u.HomeDirectory, backend, target, permissionString(u.Permissions))
+	}
+	return w.Flush()
+}
+
+// collectUsers lists Users sorted by namespace and name and resolves their backends
+func collectUsers(ctx context.Context, kubeClient client.Client, namespace string) ([]dumpedUser, error) {
+	userList := &ftpv1.UserList{}
+	if err := kubeClient.List(ctx, userList, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	users := make([]dumpedUser, 0, len(userList.Items))
+	for i := range userList.Items {
+		user := &userList.Items[i]
+		userType := user.Spec.Type
+		if userType == "" {
+			userType = "regular"
+		}
+		permissions := user.Spec.Permissions
+		if user.Spec.PermissionTemplateRef != nil && user.Status.EffectivePermissions != nil {
+			permissions = *user.Status.EffectivePermissions
+		}
+
+		backend, err := resolveDumpedBackend(ctx, kubeClient, user)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, dumpedUser{
+			Namespace:     user.Namespace,
+			Name:          user.Name,
+			Username:      user.Spec.Username,
+			Type:          userType,
+			Enabled:       user.Spec.Enabled,
+			Ready:         user.Status.Ready,
+			HomeDirectory: user.Spec.HomeDirectory,
+			Chroot:        user.Spec.Chroot,
+			Backend:       backend,
+			Permissions:   permissions,
+		})
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].Namespace != users[j].Namespace {
+			return users[i].Namespace < users[j].Namespace
+		}
+		return users[i].Name < users[j].Name
+	})
+	return users, nil
+}
+
+// resolveDumpedBackend looks up the backend a User references. A missing
+// backend is reported rather than treated as an error.
+func resolveDumpedBackend(ctx context.Context, kubeClient client.Client, user *ftpv1.User) (dumpedBackend, error) {
+	ref := user.Spec.Backend
+	result := dumpedBackend{
+		Kind:      ref.Kind,
+		Namespace: ref.ResolveNamespace(user.Namespace),
+		Name:      ref.Name,
+	}
+	key := client.ObjectKey{Namespace: result.Namespace, Name: ref.Name}
+
+	var obj client.Object
+	switch ref.Kind {
+	case "MinioBackend":
+		obj = &ftpv1.MinioBackend{}
+	case "WebDavBackend":
+		obj = &ftpv1.WebDavBackend{}
+	case "FilesystemBackend":
+		obj = &ftpv1.FilesystemBackend{}
+	case "FtpBackend":
+		obj = &ftpv1.FtpBackend{}
+	default:
+		return result, nil
+	}
+
+	if err := kubeClient.Get(ctx, key, obj); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			return result, nil
+		}
+		return result, fmt.Errorf("failed to get %s %s: %w", ref.Kind, key, err)
+	}
+	result.Found = true
+
+	switch backend := obj.(type) {
+	case *ftpv1.MinioBackend:
+		result.Ready = backend.Status.Ready
+		result.Target = strings.TrimSuffix(backend.Spec.Endpoint, "/") + "/" + backend.Spec.Bucket
+		if backend.Spec.PathPrefix != "" {
+			result.Target += "/" + strings.TrimPrefix(backend.Spec.PathPrefix, "/")
+		}
+	case *ftpv1.WebDavBackend:
+		result.Ready = backend.Status.Ready
+		result.Target = strings.TrimSuffix(backend.Spec.Endpoint, "/") + backend.Spec.BasePath
+	case *ftpv1.FilesystemBackend:
+		result.Ready = backend.Status.Ready
+		result.Target = backend.Spec.BasePath
+	case *ftpv1.FtpBackend:
+		result.Ready = backend.Status.Ready
+		result.Target = "ftp://" + backend.Spec.Host + path.Join("/", backend.Spec.BasePath)
+	}
+	return result, nil
+}
+
+// permissionString renders permissions as "rwdl", with "-" for each one denied
+func permissionString(p ftpv1.UserPermissions) string {
+	flags := []byte("----")
+	if p.Read {
+		flags[0] = 'r'
+	}
+	if p.Write {
+		flags[1] = 'w'
+	}
+	if p.Delete {
+		flags[2] = 'd'
+	}
+	if p.List {
+		flags[3] = 'l'
+	}
+	return string(flags)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+func newDumpUsersClient() client.Client {
+	shared := "shared"
+	objects := []client.Object{
+		&ftpv1.MinioBackend{
+			ObjectMeta: metav1.ObjectMeta{Name: "minio", Namespace: "team-a"},
+			Spec: ftpv1.MinioBackendSpec{
+				Endpoint:   "https://minio.example.com",
+				Bucket:     "uploads",
+				PathPrefix: "ftp",
+			},
+			Status: ftpv1.MinioBackendStatus{Ready: true},
+		},
+		&ftpv1.FilesystemBackend{
+			ObjectMeta: metav1.ObjectMeta{Name: "disk", Namespace: "shared"},
+			Spec:       ftpv1.FilesystemBackendSpec{BasePath: "/data/ftp"},
+		},
+		&ftpv1.User{
+			ObjectMeta: metav1.ObjectMeta{Name: "bob", Namespace: "team-a"},
+			Spec: ftpv1.UserSpec{
+				Username:      "bob",
+				Password:      "s3cret-Passw0rd!",
+				Enabled:       true,
+				HomeDirectory: "/home/bob",
+				Backend:       ftpv1.BackendReference{Kind: "FilesystemBackend", Name: "disk", Namespace: &shared},
+				Permissions:   ftpv1.UserPermissions{Read: true, List: true},
+			},
+		},
+		&ftpv1.User{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice", Namespace: "team-a"},
+			Spec: ftpv1.UserSpec{
+				Username:              "alice",
+				Enabled:               true,
+				HomeDirectory:         "/home/alice",
+				Chroot:                true,
+				Backend:               ftpv1.BackendReference{Kind: "MinioBackend", Name: "minio"},
+				Permissions:           ftpv1.UserPermissions{Read: true},
+				PermissionTemplateRef: &ftpv1.PermissionTemplateReference{Name: "uploader"},
+			},
+			Status: ftpv1.UserStatus{
+				Ready:                true,
+				EffectivePermissions: &ftpv1.UserPermissions{Read: true, Write: true, List: true},
+			},
+		},
+		&ftpv1.User{
+			ObjectMeta: metav1.ObjectMeta{Name: "carol", Namespace: "team-b"},
+			Spec: ftpv1.UserSpec{
+				Username:      "carol",
+				HomeDirectory: "/",
+				Backend:       ftpv1.BackendReference{Kind: "WebDavBackend", Name: "gone"},
+			},
+		},
+	}
+
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithStatusSubresource(&ftpv1.User{}, &ftpv1.MinioBackend{}).
+		Build()
+}
+
+func TestDumpUsers_JSON(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, dumpUsers(context.Background(), newDumpUsersClient(), "", "json", &out))
+	assert.NotContains(t, out.String(), "s3cret", "passwords must never be dumped")
+
+	var users []dumpedUser
+	require.NoError(t, json.Unmarshal(out.Bytes(), &users))
+	require.Len(t, users, 3)
+
+	assert.Equal(t, dumpedUser{
+		Namespace:     "team-a",
+		Name:          "alice",
+		Username:      "alice",
+		Type:          "regular",
+		Enabled:       true,
+		Ready:         true,
+		HomeDirectory: "/home/alice",
+		Chroot:        true,
+		Backend: dumpedBackend{
+			Kind:      "MinioBackend",
+			Namespace: "team-a",
+			Name:      "minio",
+			Found:     true,
+			Ready:     true,
+			Target:    "https://minio.example.com/uploads/ftp",
+		},
+		Permissions: ftpv1.UserPermissions{Read: true, Write: true, List: true},
+	}, users[0], "template permissions are reported as resolved")
+
+	assert.Equal(t, "bob", users[1].Name)
+	assert.Equal(t, dumpedBackend{Kind: "FilesystemBackend", Namespace: "shared", Name: "disk", Found: true, Target: "/data/ftp"}, users[1].Backend)
+	assert.Equal(t, ftpv1.UserPermissions{Read: true, List: true}, users[1].Permissions)
+
+	assert.Equal(t, "carol", users[2].Name)
+	assert.False(t, users[2].Backend.Found)
+}
+
+func TestDumpUsers_Table(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, dumpUsers(context.Background(), newDumpUsersClient(), "team-a", "table", &out))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3, "header plus the two team-a users")
+	assert.Equal(t, []string{"NAMESPACE", "NAME", "USERNAME", "TYPE", "ENABLED", "HOME", "BACKEND", "TARGET", "PERMISSIONS"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"team-a", "alice", "alice", "regular", "true", "/home/alice",
+		"MinioBackend/team-a/minio", "https://minio.example.com/uploads/ftp", "rw-l"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"team-a", "bob", "bob", "regular", "true", "/home/bob",
+		"FilesystemBackend/shared/disk", "/data/ftp", "(not", "ready)", "r--l"}, strings.Fields(lines[2]))
+}
+
+func TestRunDumpUsers_InvalidOutput(t *testing.T) {
+	var out bytes.Buffer
+	assert.Equal(t, dumpUsersExitUsage, runDumpUsers([]string{"--output", "yaml"}, &out))
+	assert.Contains(t, out.String(), "unsupported output format")
+}
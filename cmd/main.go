@@ -19,13 +19,16 @@ package main
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"net/http"
 	"net/http/pprof"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -33,6 +36,7 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -43,10 +47,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
 	"github.com/rossigee/kubeftpd/internal/controller"
 	"github.com/rossigee/kubeftpd/internal/ftp"
+	"github.com/rossigee/kubeftpd/internal/metrics"
+	"github.com/rossigee/kubeftpd/internal/storage"
+	ftpwebhook "github.com/rossigee/kubeftpd/internal/webhook"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -86,6 +94,30 @@ type appConfig struct {
 	ftpTLSCertName    string
 	ftpTLSCertKey     string
 	ftpForceTLS       bool
+	ftpIdleTimeout    int
+	ftpMaxCmdLength   int
+	ftpDropLongLines  bool
+	ftpDataIdle       time.Duration
+	ftpMaxDataConns   int
+	ftpRetryHint      string
+	ftpSystemType     string
+	ftpHideIdentity   bool
+	ftpAllowUTF8Off   bool
+	ftpNameCharset    string
+	ftpICAPServer     string
+	ftpErrorLogLimit  time.Duration
+	ftpShowHomePath   bool
+	ftpGreetingDelay  time.Duration
+	ftpRequireSize    bool
+	ftpRejectSizeDiff bool
+	ftpFailTruncated  bool
+	ftpIdempotentMkd  bool
+	ftpSlowOpLimit    time.Duration
+	quotaUsageRefresh time.Duration
+	ftpDisableFeats   string
+	ftpRedactCommands string
+	metricsUserTags   string
+	ftpNormalizeSlash bool
 	// Built-in anonymous user settings
 	enableAnonymous      bool
 	anonymousHomeDir     string
@@ -97,11 +129,60 @@ type appConfig struct {
 	adminHomeDir        string
 	adminBackendKind    string
 	adminBackendName    string
+	// Startup reconciliation retry for built-in users
+	builtInRetryAttempts int
+	builtInRetryBackoff  time.Duration
 	// Profiling settings
 	enableProfiling bool
 	profilingAddr   string
+	// HTTP status settings
+	statusIncludeStats bool
+	// Path the status JSON is served on
+	statusPath string
+	// Recent backend error rate reported as degraded (0 ignores it)
+	statusDegradedErrorRate float64
+	// HTTP response code while the status is not running
+	statusDegradedHTTPCode int
+	// Backend kinds served by this instance (empty = all)
+	enabledBackendKinds string
+	// Reject MinioBackends with plain http:// endpoints
+	requireHTTPSBackends bool
+	// Write-read-delete self-test of each backend at startup
+	backendSelfTest bool
+	// Refuse usernames defined by more than one enabled User across namespaces
+	requireUniqueUsernames bool
+	// Mark Users not ready when their password Secret or key is missing
+	requirePasswordSecretKeys bool
+	// Confine every user to their home directory regardless of spec.chroot
+	forceChroot bool
+	// Disable a User after this many chroot violations (0 never disables)
+	autoDisableOnViolations int
+	// User cache settings
+	userCacheMaxStaleness time.Duration
+	// Serve the User manifests in this YAML file instead of the cluster's Users
+	usersFile string
+	// Record a Kubernetes Event on the User for each completed transfer
+	emitTransferEvents bool
+	// Virtual host profiles selectable with the FTP HOST command
+	virtualHosts string
+	// Reply template overrides for common storage errors
+	ftpErrorMessages string
+	// Welcome banner lines shown during daily time windows
+	ftpScheduledBanners string
+	// Maintenance mode settings
+	maintenanceMessage   string
+	maintenanceConfigMap string
+	globalReadOnly       bool
+	// Password strength policy for the webhook and SITE PASSWD
+	passwordMinLength          int
+	passwordRequiredClasses    string
+	passwordBannedSubstrings   string
+	passwordDisallowSequential bool
 }
 
+// supportedBackendKinds lists every backend kind the operator knows how to serve
+var supportedBackendKinds = []string{"MinioBackend", "WebDavBackend", "FilesystemBackend", "FtpBackend"}
+
 func getDefaultFTPPort() int {
 	// Check if running as root (UID 0) - can bind to port 21
 	// Otherwise default to port 2121 for unprivileged users
@@ -136,6 +217,31 @@ func parseFlags() (*appConfig, zap.Options) {
 	flag.StringVar(&config.ftpTLSCertName, "ftp-tls-cert-name", "tls.crt", "Filename of the FTP TLS certificate within --ftp-tls-cert-path")
 	flag.StringVar(&config.ftpTLSCertKey, "ftp-tls-cert-key", "tls.key", "Filename of the FTP TLS private key within --ftp-tls-cert-path")
 	flag.BoolVar(&config.ftpForceTLS, "ftp-force-tls", false, "Require clients to upgrade to TLS before issuing any FTP command (AUTH TLS must be the first command)")
+	flag.IntVar(&config.ftpIdleTimeout, "ftp-idle-timeout", 300, "Seconds a control connection may wait for the next command before it is closed (0 disables)")
+	flag.IntVar(&config.ftpMaxCmdLength, "ftp-max-command-length", 0, "Reject FTP command lines longer than this many bytes with 500 (0 disables)")
+	flag.BoolVar(&config.ftpDropLongLines, "ftp-drop-long-command-lines", false, "Close the connection instead of replying 500 when a command line exceeds --ftp-max-command-length")
+	flag.DurationVar(&config.ftpDataIdle, "ftp-data-idle-timeout", 0, "Close passive data connections no transfer has used within this long, keeping the control connection (0 disables)")
+	flag.IntVar(&config.ftpMaxDataConns, "ftp-max-data-conns-per-session", 0, "Refuse PASV/EPSV with 425 while a session holds this many open passive data connections (0 disables)")
+	flag.StringVar(&config.ftpRetryHint, "ftp-retry-hint", "", "Hint appended to transient replies refusing work because of a lockout or busy backend, e.g. \"retry in 30 seconds\"")
+	flag.BoolVar(&config.ftpShowHomePath, "ftp-show-home-path", false, "Show users without chroot their home directory path in PWD replies")
+	flag.StringVar(&config.ftpWelcomeMessage, "ftp-greeting", "", "Text of the 220 banner sent to new connections (default \"Welcome to KubeFTPd\")")
+	flag.BoolVar(&config.ftpHideIdentity, "ftp-hide-server-identity", false, "Send a generic banner by default and leave the server name and version out of STAT replies")
+	flag.BoolVar(&config.ftpAllowUTF8Off, "ftp-allow-utf8-off", false, "Honour OPTS UTF8 OFF, treating the session's file names as Latin-1 instead of refusing it")
+	flag.StringVar(&config.ftpNameCharset, "ftp-filename-charset", "", "Refuse uploads and renames to file names with characters outside this regular expression character class, e.g. \"A-Za-z0-9._-\" (empty allows any name)")
+	flag.StringVar(&config.ftpICAPServer, "ftp-icap-server", "", "Scan every upload with this ICAP server before storing it, e.g. icap://clamav-icap:1344/avscan, rejecting infected uploads (empty disables scanning)")
+	flag.StringVar(&config.ftpSystemType, "ftp-system-type", "UNIX Type: L8", "Reply to the SYST command, which clients use to pick a directory listing parser")
+	flag.DurationVar(&config.ftpGreetingDelay, "ftp-greeting-delay", 0, "Delay before sending the welcome banner on each new connection, to slow down scanners (0 disables)")
+	flag.DurationVar(&config.ftpErrorLogLimit, "ftp-error-log-interval", 0, "Collapse identical consecutive operation errors in a session into one log line and a repeat count per this interval (0 logs every error)")
+	flag.DurationVar(&config.ftpSlowOpLimit, "ftp-slow-operation-threshold", 0, "Log a warning and count FTP operations that take at least this long (0 disables)")
+	flag.DurationVar(&config.quotaUsageRefresh, "quota-usage-refresh-interval", time.Minute, "Serve quota usage from a cache refreshed in the background once older than this (0 computes it on every check)")
+	flag.BoolVar(&config.ftpRequireSize, "ftp-require-upload-size", false, "Reject uploads from users with a byte quota unless the size was announced with ALLO")
+	flag.BoolVar(&config.ftpRejectSizeDiff, "ftp-reject-upload-size-mismatch", false, "Reject uploads whose size differs from the size announced with ALLO, deleting them unless they replaced an existing file")
+	flag.BoolVar(&config.ftpFailTruncated, "ftp-fail-truncated-downloads", false, "Fail downloads whose backend stream ends before the file's size instead of completing them short")
+	flag.BoolVar(&config.ftpIdempotentMkd, "ftp-idempotent-mkdir", false, "Reply success to MKD of a directory that already exists instead of 550")
+	flag.BoolVar(&config.ftpNormalizeSlash, "ftp-normalize-backslashes", false, "Treat backslashes in client paths as directory separators for Windows clients")
+	flag.StringVar(&config.ftpRedactCommands, "ftp-redact-commands", "", "Comma-separated commands, or SITE subcommands like \"SITE TOKEN\", whose parameters are redacted in command logs")
+	flag.StringVar(&config.metricsUserTags, "metrics-user-tags", "", "Comma-separated User tag keys exported as labels of kubeftpd_user_tag_info; keep them low-cardinality")
+	flag.StringVar(&config.ftpDisableFeats, "ftp-disable-features", "", "Comma-separated FEAT tokens to leave out of the feature advertisement for strict clients (e.g. MLST,EPSV)")
 
 	// Built-in anonymous user flags
 	flag.BoolVar(&config.enableAnonymous, "enable-anonymous", false, "Enable anonymous FTP access (RFC 1635)")
@@ -149,11 +255,77 @@ func parseFlags() (*appConfig, zap.Options) {
 	flag.StringVar(&config.adminHomeDir, "admin-home-dir", "/", "Home directory for admin user")
 	flag.StringVar(&config.adminBackendKind, "admin-backend-kind", "FilesystemBackend", "Backend kind for admin user")
 	flag.StringVar(&config.adminBackendName, "admin-backend-name", "admin-backend", "Backend name for admin user")
+	flag.IntVar(&config.builtInRetryAttempts, "builtin-user-retry-attempts", 5, "Attempts at the initial built-in user reconciliation before giving up until the next controller reconcile")
+	flag.DurationVar(&config.builtInRetryBackoff, "builtin-user-retry-backoff", 2*time.Second, "Delay before the first built-in user reconciliation retry; doubles on each further retry")
 
 	// Profiling flags
 	flag.BoolVar(&config.enableProfiling, "enable-profiling", false, "Enable Go profiling endpoints (/debug/pprof/)")
 	flag.StringVar(&config.profilingAddr, "profiling-addr", "127.0.0.1:6060", "Address for pprof endpoints (loopback only recommended)")
 
+	// HTTP status flags
+	flag.StringVar(&config.statusPath, "status-path", "/",
+		"Path of the HTTP status endpoint, e.g. /status, to keep it clear of other handlers on the metrics server")
+	flag.BoolVar(&config.statusIncludeStats, "status-include-stats", false,
+		"Include aggregate FTP server stats (uptime, connections, bytes, active sessions) in the HTTP status response")
+	flag.Float64Var(&config.statusDegradedErrorRate, "status-degraded-error-rate", 0.5,
+		"Report the HTTP status as degraded once this fraction of recent backend operations failed (0 ignores the error rate)")
+	flag.IntVar(&config.statusDegradedHTTPCode, "status-degraded-http-code", http.StatusOK,
+		"HTTP response code of the status endpoint while degraded or unhealthy, e.g. 503 for monitors that only check the code")
+
+	// Backend allowlist
+	flag.StringVar(&config.enabledBackendKinds, "enabled-backend-kinds", "",
+		"Comma-separated list of backend kinds to serve (MinioBackend, WebDavBackend, FilesystemBackend, FtpBackend); empty enables all")
+	flag.BoolVar(&config.requireHTTPSBackends, "require-https-backends", false,
+		"Reject MinioBackends whose endpoint is not https:// in the reconciler and admission webhook")
+	flag.BoolVar(&config.backendSelfTest, "backend-selftest", false,
+		"Write, read back and delete a temporary object on each backend at startup, failing readiness if any backend fails")
+	flag.BoolVar(&config.requireUniqueUsernames, "require-unique-usernames", false,
+		"Refuse logins for usernames defined by more than one enabled User across namespaces, and deny such Users in the admission webhook")
+	flag.BoolVar(&config.requirePasswordSecretKeys, "require-password-secret-keys", false,
+		"Mark Users not ready when the Secret or key referenced by spec.passwordSecret is missing or empty")
+	flag.BoolVar(&config.forceChroot, "force-chroot", false,
+		"Confine every user to their home directory, even Users with spec.chroot set to false")
+	flag.IntVar(&config.autoDisableOnViolations, "auto-disable-on-violations", 0,
+		"Set enabled to false on a User after this many attempts to access paths outside its home directory within an hour, counted per replica (0 disables)")
+
+	// Password policy flags
+	defaultPolicy := ftpv1.DefaultPasswordPolicy()
+	flag.IntVar(&config.passwordMinLength, "password-min-length", defaultPolicy.MinLength,
+		"Minimum password length enforced by the webhook and SITE PASSWD")
+	flag.StringVar(&config.passwordRequiredClasses, "password-required-classes", strings.Join(defaultPolicy.RequiredClasses, ","),
+		"Comma-separated character classes a password must contain (upper, lower, digit, special), or \"none\"")
+	flag.StringVar(&config.passwordBannedSubstrings, "password-banned-substrings", strings.Join(defaultPolicy.BannedSubstrings, ","),
+		"Comma-separated substrings a password may not contain (case-insensitive), or \"none\"")
+	flag.BoolVar(&config.passwordDisallowSequential, "password-disallow-sequential", defaultPolicy.DisallowSequential,
+		"Reject passwords containing sequential characters such as \"123\" or \"abc\"")
+
+	// User cache flags
+	flag.DurationVar(&config.userCacheMaxStaleness, "user-cache-max-staleness", 15*time.Minute,
+		"How long cached users may keep authenticating past the cache TTL while the Kubernetes API server is unreachable (0 disables)")
+	flag.StringVar(&config.usersFile, "users-file", "",
+		"YAML file of User manifests to serve instead of the User resources in the cluster, reloaded when it changes. "+
+			"Only users come from the file: the API server, the kubeftpd CRDs and the referenced backend resources are still required")
+
+	// Transfer event flags
+	flag.BoolVar(&config.emitTransferEvents, "emit-transfer-events", false,
+		"Record a Kubernetes Event on the User for each completed upload or download")
+
+	// Virtual host flags
+	flag.StringVar(&config.virtualHosts, "virtual-hosts", "",
+		"Comma-separated virtual host profiles selectable with the FTP HOST command, as host=Kind/[namespace/]name[:/home]; they only fill in a backend or home directory a user leaves unset")
+	flag.StringVar(&config.ftpErrorMessages, "ftp-error-messages", "",
+		"Semicolon-separated reply templates overriding error texts, as category=template with categories permission-denied, not-found and quota-exceeded; {path} and {error} are substituted")
+	flag.StringVar(&config.ftpScheduledBanners, "ftp-scheduled-banners", "",
+		"Semicolon-separated welcome banner lines shown to connections during a daily UTC window, as HH:MM-HH:MM=message")
+
+	// Maintenance mode flags
+	flag.StringVar(&config.maintenanceMessage, "maintenance-message", "",
+		"When set, reject new FTP logins with this message while existing sessions continue")
+	flag.StringVar(&config.maintenanceConfigMap, "maintenance-configmap", "",
+		"ConfigMap ([namespace/]name) whose maintenanceMessage and globalReadOnly keys are watched to toggle maintenance and read-only mode at runtime")
+	flag.BoolVar(&config.globalReadOnly, "global-read-only", false,
+		"Reject uploads, deletes, renames and new directories for all users")
+
 	opts := zap.Options{Development: true}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
@@ -175,6 +347,178 @@ func processEnvironmentOverrides(config *appConfig) {
 		}
 	}
 
+	if envFtpIdleTimeout := os.Getenv("FTP_IDLE_TIMEOUT"); envFtpIdleTimeout != "" {
+		if seconds, err := strconv.Atoi(envFtpIdleTimeout); err == nil {
+			config.ftpIdleTimeout = seconds
+		} else {
+			setupLog.Error(err, "invalid FTP_IDLE_TIMEOUT environment variable", "value", envFtpIdleTimeout)
+			os.Exit(1)
+		}
+	}
+
+	if envMaxCmdLength := os.Getenv("FTP_MAX_COMMAND_LENGTH"); envMaxCmdLength != "" {
+		if n, err := strconv.Atoi(envMaxCmdLength); err == nil {
+			config.ftpMaxCmdLength = n
+		} else {
+			setupLog.Error(err, "invalid FTP_MAX_COMMAND_LENGTH environment variable", "value", envMaxCmdLength)
+			os.Exit(1)
+		}
+	}
+
+	if envDropLongLines := os.Getenv("FTP_DROP_LONG_COMMAND_LINES"); envDropLongLines != "" {
+		if drop, err := strconv.ParseBool(envDropLongLines); err == nil {
+			config.ftpDropLongLines = drop
+		} else {
+			setupLog.Error(err, "invalid FTP_DROP_LONG_COMMAND_LINES environment variable", "value", envDropLongLines)
+			os.Exit(1)
+		}
+	}
+
+	if envDataIdle := os.Getenv("FTP_DATA_IDLE_TIMEOUT"); envDataIdle != "" {
+		if d, err := time.ParseDuration(envDataIdle); err == nil {
+			config.ftpDataIdle = d
+		} else {
+			setupLog.Error(err, "invalid FTP_DATA_IDLE_TIMEOUT environment variable", "value", envDataIdle)
+			os.Exit(1)
+		}
+	}
+
+	if envMaxDataConns := os.Getenv("FTP_MAX_DATA_CONNS_PER_SESSION"); envMaxDataConns != "" {
+		if n, err := strconv.Atoi(envMaxDataConns); err == nil {
+			config.ftpMaxDataConns = n
+		} else {
+			setupLog.Error(err, "invalid FTP_MAX_DATA_CONNS_PER_SESSION environment variable", "value", envMaxDataConns)
+			os.Exit(1)
+		}
+	}
+
+	if envRetryHint := os.Getenv("FTP_RETRY_HINT"); envRetryHint != "" {
+		config.ftpRetryHint = envRetryHint
+	}
+
+	if envShowHomePath := os.Getenv("FTP_SHOW_HOME_PATH"); envShowHomePath != "" {
+		if enabled, err := strconv.ParseBool(envShowHomePath); err == nil {
+			config.ftpShowHomePath = enabled
+		} else {
+			setupLog.Error(err, "invalid FTP_SHOW_HOME_PATH environment variable", "value", envShowHomePath)
+			os.Exit(1)
+		}
+	}
+
+	if envAllowUTF8Off := os.Getenv("FTP_ALLOW_UTF8_OFF"); envAllowUTF8Off != "" {
+		if enabled, err := strconv.ParseBool(envAllowUTF8Off); err == nil {
+			config.ftpAllowUTF8Off = enabled
+		} else {
+			setupLog.Error(err, "invalid FTP_ALLOW_UTF8_OFF environment variable", "value", envAllowUTF8Off)
+			os.Exit(1)
+		}
+	}
+
+	if envNameCharset := os.Getenv("FTP_FILENAME_CHARSET"); envNameCharset != "" {
+		config.ftpNameCharset = envNameCharset
+	}
+
+	if envICAPServer := os.Getenv("FTP_ICAP_SERVER"); envICAPServer != "" {
+		config.ftpICAPServer = envICAPServer
+	}
+
+	if envSystemType := os.Getenv("FTP_SYSTEM_TYPE"); envSystemType != "" {
+		config.ftpSystemType = envSystemType
+	}
+
+	if envGreetingDelay := os.Getenv("FTP_GREETING_DELAY"); envGreetingDelay != "" {
+		if d, err := time.ParseDuration(envGreetingDelay); err == nil {
+			config.ftpGreetingDelay = d
+		} else {
+			setupLog.Error(err, "invalid FTP_GREETING_DELAY environment variable", "value", envGreetingDelay)
+			os.Exit(1)
+		}
+	}
+
+	if envSlowOp := os.Getenv("FTP_SLOW_OPERATION_THRESHOLD"); envSlowOp != "" {
+		if d, err := time.ParseDuration(envSlowOp); err == nil {
+			config.ftpSlowOpLimit = d
+		} else {
+			setupLog.Error(err, "invalid FTP_SLOW_OPERATION_THRESHOLD environment variable", "value", envSlowOp)
+			os.Exit(1)
+		}
+	}
+
+	if envErrorLog := os.Getenv("FTP_ERROR_LOG_INTERVAL"); envErrorLog != "" {
+		if d, err := time.ParseDuration(envErrorLog); err == nil {
+			config.ftpErrorLogLimit = d
+		} else {
+			setupLog.Error(err, "invalid FTP_ERROR_LOG_INTERVAL environment variable", "value", envErrorLog)
+			os.Exit(1)
+		}
+	}
+
+	if envNormalize := os.Getenv("FTP_NORMALIZE_BACKSLASHES"); envNormalize != "" {
+		if enabled, err := strconv.ParseBool(envNormalize); err == nil {
+			config.ftpNormalizeSlash = enabled
+		} else {
+			setupLog.Error(err, "invalid FTP_NORMALIZE_BACKSLASHES environment variable", "value", envNormalize)
+			os.Exit(1)
+		}
+	}
+
+	if envUsageRefresh := os.Getenv("QUOTA_USAGE_REFRESH_INTERVAL"); envUsageRefresh != "" {
+		if d, err := time.ParseDuration(envUsageRefresh); err == nil {
+			config.quotaUsageRefresh = d
+		} else {
+			setupLog.Error(err, "invalid QUOTA_USAGE_REFRESH_INTERVAL environment variable", "value", envUsageRefresh)
+			os.Exit(1)
+		}
+	}
+
+	if envRequireSize := os.Getenv("FTP_REQUIRE_UPLOAD_SIZE"); envRequireSize != "" {
+		if enabled, err := strconv.ParseBool(envRequireSize); err == nil {
+			config.ftpRequireSize = enabled
+		} else {
+			setupLog.Error(err, "invalid FTP_REQUIRE_UPLOAD_SIZE environment variable", "value", envRequireSize)
+			os.Exit(1)
+		}
+	}
+
+	if envRejectSizeDiff := os.Getenv("FTP_REJECT_UPLOAD_SIZE_MISMATCH"); envRejectSizeDiff != "" {
+		if enabled, err := strconv.ParseBool(envRejectSizeDiff); err == nil {
+			config.ftpRejectSizeDiff = enabled
+		} else {
+			setupLog.Error(err, "invalid FTP_REJECT_UPLOAD_SIZE_MISMATCH environment variable", "value", envRejectSizeDiff)
+			os.Exit(1)
+		}
+	}
+
+	if envFailTruncated := os.Getenv("FTP_FAIL_TRUNCATED_DOWNLOADS"); envFailTruncated != "" {
+		if enabled, err := strconv.ParseBool(envFailTruncated); err == nil {
+			config.ftpFailTruncated = enabled
+		} else {
+			setupLog.Error(err, "invalid FTP_FAIL_TRUNCATED_DOWNLOADS environment variable", "value", envFailTruncated)
+			os.Exit(1)
+		}
+	}
+
+	if envIdempotentMkdir := os.Getenv("FTP_IDEMPOTENT_MKDIR"); envIdempotentMkdir != "" {
+		if enabled, err := strconv.ParseBool(envIdempotentMkdir); err == nil {
+			config.ftpIdempotentMkd = enabled
+		} else {
+			setupLog.Error(err, "invalid FTP_IDEMPOTENT_MKDIR environment variable", "value", envIdempotentMkdir)
+			os.Exit(1)
+		}
+	}
+
+	if envDisableFeats := os.Getenv("FTP_DISABLE_FEATURES"); envDisableFeats != "" {
+		config.ftpDisableFeats = envDisableFeats
+	}
+
+	if envRedactCommands := os.Getenv("FTP_REDACT_COMMANDS"); envRedactCommands != "" {
+		config.ftpRedactCommands = envRedactCommands
+	}
+
+	if envUserTags := os.Getenv("METRICS_USER_TAGS"); envUserTags != "" {
+		config.metricsUserTags = envUserTags
+	}
+
 	if envFtpPasvPorts := os.Getenv("FTP_PASSIVE_PORTS"); envFtpPasvPorts != "" {
 		config.ftpPasvPorts = envFtpPasvPorts
 	} else {
@@ -188,6 +532,18 @@ func processEnvironmentOverrides(config *appConfig) {
 	if envFtpWelcome := os.Getenv("FTP_WELCOME_MESSAGE"); envFtpWelcome != "" {
 		config.ftpWelcomeMessage = envFtpWelcome
 	}
+	if envGreeting := os.Getenv("FTP_GREETING"); envGreeting != "" {
+		config.ftpWelcomeMessage = envGreeting
+	}
+
+	if envHideIdentity := os.Getenv("FTP_HIDE_SERVER_IDENTITY"); envHideIdentity != "" {
+		if enabled, err := strconv.ParseBool(envHideIdentity); err == nil {
+			config.ftpHideIdentity = enabled
+		} else {
+			setupLog.Error(err, "invalid FTP_HIDE_SERVER_IDENTITY environment variable", "value", envHideIdentity)
+			os.Exit(1)
+		}
+	}
 
 	if envFtpPublicIP := os.Getenv("FTP_PUBLIC_IP"); envFtpPublicIP != "" {
 		config.ftpPublicIP = envFtpPublicIP
@@ -207,6 +563,295 @@ func processEnvironmentOverrides(config *appConfig) {
 			os.Exit(1)
 		}
 	}
+
+	if envStatusPath := os.Getenv("STATUS_PATH"); envStatusPath != "" {
+		config.statusPath = envStatusPath
+	}
+
+	if envStatusIncludeStats := os.Getenv("STATUS_INCLUDE_STATS"); envStatusIncludeStats != "" {
+		if enabled, err := strconv.ParseBool(envStatusIncludeStats); err == nil {
+			config.statusIncludeStats = enabled
+		} else {
+			setupLog.Error(err, "invalid STATUS_INCLUDE_STATS environment variable", "value", envStatusIncludeStats)
+			os.Exit(1)
+		}
+	}
+
+	if envDegradedErrorRate := os.Getenv("STATUS_DEGRADED_ERROR_RATE"); envDegradedErrorRate != "" {
+		if rate, err := strconv.ParseFloat(envDegradedErrorRate, 64); err == nil {
+			config.statusDegradedErrorRate = rate
+		} else {
+			setupLog.Error(err, "invalid STATUS_DEGRADED_ERROR_RATE environment variable", "value", envDegradedErrorRate)
+			os.Exit(1)
+		}
+	}
+
+	if envDegradedHTTPCode := os.Getenv("STATUS_DEGRADED_HTTP_CODE"); envDegradedHTTPCode != "" {
+		if code, err := strconv.Atoi(envDegradedHTTPCode); err == nil {
+			config.statusDegradedHTTPCode = code
+		} else {
+			setupLog.Error(err, "invalid STATUS_DEGRADED_HTTP_CODE environment variable", "value", envDegradedHTTPCode)
+			os.Exit(1)
+		}
+	}
+
+	if envEnabledBackendKinds := os.Getenv("ENABLED_BACKEND_KINDS"); envEnabledBackendKinds != "" {
+		config.enabledBackendKinds = envEnabledBackendKinds
+	}
+
+	if envRequireHTTPS := os.Getenv("REQUIRE_HTTPS_BACKENDS"); envRequireHTTPS != "" {
+		if enabled, err := strconv.ParseBool(envRequireHTTPS); err == nil {
+			config.requireHTTPSBackends = enabled
+		} else {
+			setupLog.Error(err, "invalid REQUIRE_HTTPS_BACKENDS environment variable", "value", envRequireHTTPS)
+			os.Exit(1)
+		}
+	}
+
+	if envBackendSelfTest := os.Getenv("BACKEND_SELFTEST"); envBackendSelfTest != "" {
+		if enabled, err := strconv.ParseBool(envBackendSelfTest); err == nil {
+			config.backendSelfTest = enabled
+		} else {
+			setupLog.Error(err, "invalid BACKEND_SELFTEST environment variable", "value", envBackendSelfTest)
+			os.Exit(1)
+		}
+	}
+
+	if envRequireUnique := os.Getenv("REQUIRE_UNIQUE_USERNAMES"); envRequireUnique != "" {
+		if enabled, err := strconv.ParseBool(envRequireUnique); err == nil {
+			config.requireUniqueUsernames = enabled
+		} else {
+			setupLog.Error(err, "invalid REQUIRE_UNIQUE_USERNAMES environment variable", "value", envRequireUnique)
+			os.Exit(1)
+		}
+	}
+
+	if envRequireSecretKeys := os.Getenv("REQUIRE_PASSWORD_SECRET_KEYS"); envRequireSecretKeys != "" {
+		if enabled, err := strconv.ParseBool(envRequireSecretKeys); err == nil {
+			config.requirePasswordSecretKeys = enabled
+		} else {
+			setupLog.Error(err, "invalid REQUIRE_PASSWORD_SECRET_KEYS environment variable", "value", envRequireSecretKeys)
+			os.Exit(1)
+		}
+	}
+
+	if envForceChroot := os.Getenv("FORCE_CHROOT"); envForceChroot != "" {
+		if enabled, err := strconv.ParseBool(envForceChroot); err == nil {
+			config.forceChroot = enabled
+		} else {
+			setupLog.Error(err, "invalid FORCE_CHROOT environment variable", "value", envForceChroot)
+			os.Exit(1)
+		}
+	}
+
+	if envAutoDisable := os.Getenv("AUTO_DISABLE_ON_VIOLATIONS"); envAutoDisable != "" {
+		if n, err := strconv.Atoi(envAutoDisable); err == nil {
+			config.autoDisableOnViolations = n
+		} else {
+			setupLog.Error(err, "invalid AUTO_DISABLE_ON_VIOLATIONS environment variable", "value", envAutoDisable)
+			os.Exit(1)
+		}
+	}
+
+	if envRetryAttempts := os.Getenv("BUILTIN_USER_RETRY_ATTEMPTS"); envRetryAttempts != "" {
+		if n, err := strconv.Atoi(envRetryAttempts); err == nil {
+			config.builtInRetryAttempts = n
+		} else {
+			setupLog.Error(err, "invalid BUILTIN_USER_RETRY_ATTEMPTS environment variable", "value", envRetryAttempts)
+			os.Exit(1)
+		}
+	}
+
+	if envRetryBackoff := os.Getenv("BUILTIN_USER_RETRY_BACKOFF"); envRetryBackoff != "" {
+		if d, err := time.ParseDuration(envRetryBackoff); err == nil {
+			config.builtInRetryBackoff = d
+		} else {
+			setupLog.Error(err, "invalid BUILTIN_USER_RETRY_BACKOFF environment variable", "value", envRetryBackoff)
+			os.Exit(1)
+		}
+	}
+
+	if envMinLength := os.Getenv("PASSWORD_MIN_LENGTH"); envMinLength != "" {
+		if n, err := strconv.Atoi(envMinLength); err == nil {
+			config.passwordMinLength = n
+		} else {
+			setupLog.Error(err, "invalid PASSWORD_MIN_LENGTH environment variable", "value", envMinLength)
+			os.Exit(1)
+		}
+	}
+
+	if envRequiredClasses := os.Getenv("PASSWORD_REQUIRED_CLASSES"); envRequiredClasses != "" {
+		config.passwordRequiredClasses = envRequiredClasses
+	}
+
+	if envBannedSubstrings := os.Getenv("PASSWORD_BANNED_SUBSTRINGS"); envBannedSubstrings != "" {
+		config.passwordBannedSubstrings = envBannedSubstrings
+	}
+
+	if envDisallowSequential := os.Getenv("PASSWORD_DISALLOW_SEQUENTIAL"); envDisallowSequential != "" {
+		if enabled, err := strconv.ParseBool(envDisallowSequential); err == nil {
+			config.passwordDisallowSequential = enabled
+		} else {
+			setupLog.Error(err, "invalid PASSWORD_DISALLOW_SEQUENTIAL environment variable", "value", envDisallowSequential)
+			os.Exit(1)
+		}
+	}
+
+	if envMaxStaleness := os.Getenv("USER_CACHE_MAX_STALENESS"); envMaxStaleness != "" {
+		if d, err := time.ParseDuration(envMaxStaleness); err == nil {
+			config.userCacheMaxStaleness = d
+		} else {
+			setupLog.Error(err, "invalid USER_CACHE_MAX_STALENESS environment variable", "value", envMaxStaleness)
+			os.Exit(1)
+		}
+	}
+
+	if envUsersFile := os.Getenv("USERS_FILE"); envUsersFile != "" {
+		config.usersFile = envUsersFile
+	}
+
+	if envEmitTransferEvents := os.Getenv("EMIT_TRANSFER_EVENTS"); envEmitTransferEvents != "" {
+		if enabled, err := strconv.ParseBool(envEmitTransferEvents); err == nil {
+			config.emitTransferEvents = enabled
+		} else {
+			setupLog.Error(err, "invalid EMIT_TRANSFER_EVENTS environment variable", "value", envEmitTransferEvents)
+			os.Exit(1)
+		}
+	}
+
+	if envVirtualHosts := os.Getenv("VIRTUAL_HOSTS"); envVirtualHosts != "" {
+		config.virtualHosts = envVirtualHosts
+	}
+
+	if envErrorMessages := os.Getenv("FTP_ERROR_MESSAGES"); envErrorMessages != "" {
+		config.ftpErrorMessages = envErrorMessages
+	}
+
+	if envScheduledBanners := os.Getenv("FTP_SCHEDULED_BANNERS"); envScheduledBanners != "" {
+		config.ftpScheduledBanners = envScheduledBanners
+	}
+
+	if envMaintenanceMessage := os.Getenv("MAINTENANCE_MESSAGE"); envMaintenanceMessage != "" {
+		config.maintenanceMessage = envMaintenanceMessage
+	}
+
+	if envMaintenanceConfigMap := os.Getenv("MAINTENANCE_CONFIGMAP"); envMaintenanceConfigMap != "" {
+		config.maintenanceConfigMap = envMaintenanceConfigMap
+	}
+
+	if envGlobalReadOnly := os.Getenv("GLOBAL_READ_ONLY"); envGlobalReadOnly != "" {
+		if enabled, err := strconv.ParseBool(envGlobalReadOnly); err == nil {
+			config.globalReadOnly = enabled
+		} else {
+			setupLog.Error(err, "invalid GLOBAL_READ_ONLY environment variable", "value", envGlobalReadOnly)
+			os.Exit(1)
+		}
+	}
+}
+
+// parseConfigMapRef parses a [namespace/]name ConfigMap reference, defaulting
+// the namespace when omitted.
+func parseConfigMapRef(value, defaultNamespace string) (types.NamespacedName, error) {
+	namespace, name, found := strings.Cut(value, "/")
+	if !found {
+		namespace, name = defaultNamespace, value
+	}
+	if namespace == "" || name == "" || strings.Contains(name, "/") {
+		return types.NamespacedName{}, fmt.Errorf("invalid ConfigMap reference %q: expected [namespace/]name", value)
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, nil
+}
+
+// setupMaintenanceMode applies the static maintenance message and read-only
+// switch and, when a ConfigMap is configured, registers a controller that
+// keeps them in sync.
+func setupMaintenanceMode(mgr ctrl.Manager, config *appConfig, ftpServer *ftp.Server, namespace string) error {
+	ftpServer.Maintenance.Set(config.maintenanceMessage)
+	if config.maintenanceMessage != "" {
+		setupLog.Info("Maintenance mode enabled; new FTP logins will be rejected")
+	}
+	ftpServer.ReadOnly.Set(config.globalReadOnly)
+	if config.globalReadOnly {
+		setupLog.Info("Global read-only mode enabled; FTP writes will be rejected")
+	}
+	if config.maintenanceConfigMap == "" {
+		return nil
+	}
+
+	ref, err := parseConfigMapRef(config.maintenanceConfigMap, namespace)
+	if err != nil {
+		return err
+	}
+	reconciler := &controller.MaintenanceReconciler{
+		Client:          mgr.GetClient(),
+		ConfigMap:       ref,
+		DefaultMessage:  config.maintenanceMessage,
+		DefaultReadOnly: config.globalReadOnly,
+		Apply:           ftpServer.Maintenance.Set,
+		ApplyReadOnly:   ftpServer.ReadOnly.Set,
+	}
+	if err := reconciler.SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create controller Maintenance: %w", err)
+	}
+	setupLog.Info("Watching ConfigMap for maintenance mode", "configmap", ref.String())
+	return nil
+}
+
+// parsePasswordPolicy builds the password strength policy from its settings.
+// A list value of "none" clears that list.
+func parsePasswordPolicy(minLength int, requiredClasses, bannedSubstrings string, disallowSequential bool) (ftpv1.PasswordPolicy, error) {
+	policy := ftpv1.PasswordPolicy{MinLength: minLength, DisallowSequential: disallowSequential}
+	if minLength < 0 {
+		return policy, fmt.Errorf("password minimum length must not be negative, got %d", minLength)
+	}
+	for _, class := range splitCommaList(requiredClasses) {
+		class = strings.ToLower(class)
+		if !slices.Contains(ftpv1.PasswordClasses, class) {
+			return policy, fmt.Errorf("unsupported password character class %q (supported: %s)", class, strings.Join(ftpv1.PasswordClasses, ", "))
+		}
+		policy.RequiredClasses = append(policy.RequiredClasses, class)
+	}
+	policy.BannedSubstrings = splitCommaList(bannedSubstrings)
+	return policy, nil
+}
+
+// splitCommaList splits a comma-separated list, dropping blank items and
+// treating "none" as empty
+func splitCommaList(value string) []string {
+	if strings.EqualFold(strings.TrimSpace(value), "none") {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseEnabledBackendKinds parses a comma-separated backend kind allowlist.
+// An empty value returns nil, meaning all kinds are enabled.
+func parseEnabledBackendKinds(value string) ([]string, error) {
+	var kinds []string
+	for _, kind := range strings.Split(value, ",") {
+		kind = strings.TrimSpace(kind)
+		if kind == "" {
+			continue
+		}
+		supported := false
+		for _, s := range supportedBackendKinds {
+			if kind == s {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return nil, fmt.Errorf("unsupported backend kind %q (supported: %s)", kind, strings.Join(supportedBackendKinds, ", "))
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds, nil
 }
 
 func setupTLSOptions(enableHTTP2 bool) []func(*tls.Config) {
@@ -262,15 +907,228 @@ func setupWebhookServer(config *appConfig, tlsOpts []func(*tls.Config)) (webhook
 	return webhookServer, webhookCertWatcher, nil
 }
 
-func createHTTPHandler() *http.ServeMux {
+// statusResponse is the JSON body served on the HTTP root endpoint
+type statusResponse struct {
+	Service          string               `json:"service"`
+	Version          string               `json:"version"`
+	Commit           string               `json:"commit"`
+	Date             string               `json:"date"`
+	Status           string               `json:"status"`
+	NotReadyBackends []string             `json:"notReadyBackends,omitempty"`
+	BackendErrorRate *float64             `json:"backendErrorRate,omitempty"`
+	Stats            *metrics.ServerStats `json:"stats,omitempty"`
+}
+
+// Values of statusResponse.Status
+const (
+	statusRunning   = "running"
+	statusDegraded  = "degraded"
+	statusUnhealthy = "unhealthy"
+)
+
+// minErrorRateOperations is how many recent backend operations are needed
+// before their error rate can mark the server degraded
+const minErrorRateOperations = 10
+
+// statusHealth derives the status reported on the HTTP root endpoint from
+// backend readiness and the recent backend error rate
+type statusHealth struct {
+	// reader lists backends; nil until the manager is created
+	reader client.Reader
+	// kinds are the backend kinds whose readiness is checked
+	kinds []string
+	// errorRateThreshold marks the server degraded once this fraction of
+	// recent backend operations failed. Zero ignores the error rate.
+	errorRateThreshold float64
+	// degradedHTTPStatus is the response code while not running
+	degradedHTTPStatus int
+}
+
+// readinessCheckedKinds are the backend kinds with a reconciler that reports
+// their readiness
+var readinessCheckedKinds = []string{"MinioBackend", "WebDavBackend", "FilesystemBackend"}
+
+// newStatusHealth checks the readiness of the enabled backend kinds
+func newStatusHealth(enabledKinds []string, errorRateThreshold float64, degradedHTTPStatus int) *statusHealth {
+	health := &statusHealth{errorRateThreshold: errorRateThreshold, degradedHTTPStatus: degradedHTTPStatus}
+	for _, kind := range readinessCheckedKinds {
+		if !isDisabledBackendController(kind, enabledKinds) {
+			health.kinds = append(health.kinds, kind)
+		}
+	}
+	return health
+}
+
+// notReadyBackends returns "Kind namespace/name" for each backend that is not
+// ready, and how many backends were checked
+func (h *statusHealth) notReadyBackends(ctx context.Context) ([]string, int, error) {
+	var notReady []string
+	total := 0
+	check := func(kind, namespace, name string, ready bool) {
+		total++
+		if !ready {
+			notReady = append(notReady, kind+" "+namespace+"/"+name)
+		}
+	}
+	for _, kind := range h.kinds {
+		switch kind {
+		case "MinioBackend":
+			var list ftpv1.MinioBackendList
+			if err := h.reader.List(ctx, &list); err != nil {
+				return nil, 0, err
+			}
+			for _, backend := range list.Items {
+				check(kind, backend.Namespace, backend.Name, backend.Status.Ready)
+			}
+		case "WebDavBackend":
+			var list ftpv1.WebDavBackendList
+			if err := h.reader.List(ctx, &list); err != nil {
+				return nil, 0, err
+			}
+			for _, backend := range list.Items {
+				check(kind, backend.Namespace, backend.Name, backend.Status.Ready)
+			}
+		case "FilesystemBackend":
+			var list ftpv1.FilesystemBackendList
+			if err := h.reader.List(ctx, &list); err != nil {
+				return nil, 0, err
+			}
+			for _, backend := range list.Items {
+				check(kind, backend.Namespace, backend.Name, backend.Status.Ready)
+			}
+		}
+	}
+	return notReady, total, nil
+}
+
+// evaluate fills in the response's status: unhealthy when no backend is
+// ready, degraded when some backend is not ready or too many recent backend
+// operations failed, and running otherwise
+func (h *statusHealth) evaluate(ctx context.Context, resp *statusResponse) {
+	resp.Status = statusRunning
+	if h == nil {
+		return
+	}
+	if h.reader != nil {
+		notReady, total, err := h.notReadyBackends(ctx)
+		if err != nil {
+			setupLog.Error(err, "Failed to list backends for status")
+			resp.Status = statusDegraded
+			return
+		}
+		resp.NotReadyBackends = notReady
+		if len(notReady) > 0 {
+			resp.Status = statusDegraded
+			if len(notReady) == total {
+				resp.Status = statusUnhealthy
+				return
+			}
+		}
+	}
+	if h.errorRateThreshold > 0 {
+		rate, operations := metrics.GetBackendErrorRate()
+		if operations >= minErrorRateOperations && rate >= h.errorRateThreshold {
+			resp.BackendErrorRate = &rate
+			resp.Status = statusDegraded
+		}
+	}
+}
+
+// createHTTPHandler serves the status JSON on statusPath. health may be nil,
+// in which case the status is always "running". The mux is mounted on "/" of
+// the metrics server whatever the path, so other handlers can be added to it.
+func createHTTPHandler(statusPath string, includeStats bool, health *statusHealth) *http.ServeMux {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc(statusPath, func(w http.ResponseWriter, r *http.Request) {
+		resp := statusResponse{
+			Service: "kubeftpd",
+			Version: version,
+			Commit:  commit,
+			Date:    date,
+		}
+		health.evaluate(r.Context(), &resp)
+		if includeStats {
+			stats := metrics.GetServerStats()
+			resp.Stats = &stats
+		}
 		w.Header().Set("Content-Type", "application/json")
-		_, _ = fmt.Fprintf(w, `{"service":"kubeftpd","version":"%s","commit":"%s","date":"%s","status":"running"}`+"\n", version, commit, date)
+		if resp.Status != statusRunning && health.degradedHTTPStatus != 0 {
+			w.WriteHeader(health.degradedHTTPStatus)
+		}
+		_ = json.NewEncoder(w).Encode(resp)
 	})
 	return mux
 }
 
+// userCacheRefresher reloads the FTP user cache on demand
+type userCacheRefresher interface {
+	RefreshUserCache(ctx context.Context) error
+	CachedUserCount() int
+}
+
+// refreshCacheResponse is the JSON returned by /admin/refresh-cache
+type refreshCacheResponse struct {
+	Users int `json:"users"`
+}
+
+// registerAdminHandlers adds the troubleshooting endpoints to mux. They must
+// only be served behind the metrics server's authentication filter.
+func registerAdminHandlers(mux *http.ServeMux, refresher userCacheRefresher) {
+	mux.HandleFunc("/admin/refresh-cache", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := refresher.RefreshUserCache(r.Context()); err != nil {
+			setupLog.Error(err, "Failed to refresh user cache on demand")
+			http.Error(w, "failed to refresh user cache: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		users := refresher.CachedUserCount()
+		setupLog.Info("User cache refreshed on demand", "users", users)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(refreshCacheResponse{Users: users})
+	})
+}
+
+// listenerPauser pauses and resumes accepting FTP connections
+type listenerPauser interface {
+	Set(paused bool)
+	Paused() bool
+}
+
+// pauseResponse is the JSON returned by /admin/pause and /admin/resume
+type pauseResponse struct {
+	Paused bool `json:"paused"`
+}
+
+// registerPauseHandlers adds /admin/pause and /admin/resume, which stop and
+// restart accepting new FTP connections while existing sessions continue.
+// Like the other admin endpoints they must only be served behind the metrics
+// server's authentication filter.
+func registerPauseHandlers(mux *http.ServeMux, pauser listenerPauser) {
+	handle := func(pattern string, paused bool) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				w.Header().Set("Allow", http.MethodPost)
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			pauser.Set(paused)
+			if paused {
+				setupLog.Info("FTP listener paused; new connections will be refused")
+			} else {
+				setupLog.Info("FTP listener resumed")
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(pauseResponse{Paused: pauser.Paused()})
+		})
+	}
+	handle("/admin/pause", true)
+	handle("/admin/resume", false)
+}
+
 // startProfilingServer starts a pprof server on a dedicated loopback address.
 // It must not be exposed on a shared or public-facing port.
 func startProfilingServer(ctx context.Context, addr string) {
@@ -324,7 +1182,7 @@ func setupMetricsServer(config *appConfig, tlsOpts []func(*tls.Config), mux *htt
 	return metricsServerOptions, metricsCertWatcher, nil
 }
 
-func setupControllers(mgr ctrl.Manager, config *appConfig) error {
+func setupControllers(mgr ctrl.Manager, config *appConfig, enabledKinds []string, selfTests *controller.BackendSelfTests) error {
 	// Get the operator namespace for built-in user creation
 	operatorNamespace := os.Getenv("POD_NAMESPACE")
 	if operatorNamespace == "" {
@@ -350,14 +1208,18 @@ func setupControllers(mgr ctrl.Manager, config *appConfig) error {
 		name       string
 		reconciler interface{ SetupWithManager(ctrl.Manager) error }
 	}{
-		{"User", &controller.UserReconciler{Client: mgr.GetClient(), Scheme: mgr.GetScheme()}},
-		{"MinioBackend", &controller.MinioBackendReconciler{Client: mgr.GetClient(), Scheme: mgr.GetScheme()}},
-		{"WebDavBackend", &controller.WebDavBackendReconciler{Client: mgr.GetClient(), Scheme: mgr.GetScheme()}},
-		{"FilesystemBackend", &controller.FilesystemBackendReconciler{Client: mgr.GetClient(), Scheme: mgr.GetScheme()}},
+		{"User", &controller.UserReconciler{Client: mgr.GetClient(), Scheme: mgr.GetScheme(), RequirePasswordSecretKeys: config.requirePasswordSecretKeys}},
+		{"MinioBackend", &controller.MinioBackendReconciler{Client: mgr.GetClient(), Scheme: mgr.GetScheme(), RequireHTTPS: config.requireHTTPSBackends, SelfTests: selfTests}},
+		{"WebDavBackend", &controller.WebDavBackendReconciler{Client: mgr.GetClient(), Scheme: mgr.GetScheme(), SelfTests: selfTests}},
+		{"FilesystemBackend", &controller.FilesystemBackendReconciler{Client: mgr.GetClient(), Scheme: mgr.GetScheme(), SelfTests: selfTests}},
 		{"BuiltInUserManager", &controller.BuiltInUserManager{Client: mgr.GetClient(), Scheme: mgr.GetScheme(), Config: builtInConfig}},
 	}
 
 	for _, c := range controllers {
+		if isDisabledBackendController(c.name, enabledKinds) {
+			setupLog.Info("Skipping controller for disabled backend kind", "kind", c.name)
+			continue
+		}
 		if err := c.reconciler.SetupWithManager(mgr); err != nil {
 			return fmt.Errorf("unable to create controller %s: %w", c.name, err)
 		}
@@ -366,6 +1228,58 @@ func setupControllers(mgr ctrl.Manager, config *appConfig) error {
 	return nil
 }
 
+// registerBackendWebhooks serves the MinioBackend admission webhook enforcing
+// the HTTPS policy. Without webhook certificates only the reconciler enforces it.
+func registerBackendWebhooks(mgr ctrl.Manager, config *appConfig) {
+	if config.webhookCertPath == "" {
+		setupLog.Info("No webhook certificates configured, HTTPS backend policy is enforced by the reconciler only")
+		return
+	}
+	decoder := admission.NewDecoder(mgr.GetScheme())
+	validator := &ftpwebhook.MinioBackendValidator{RequireHTTPS: true}
+	_ = validator.InjectDecoder(&decoder)
+	mgr.GetWebhookServer().Register("/validate-ftp-golder-org-v1-miniobackend", &webhook.Admission{Handler: validator})
+	setupLog.Info("Registered MinioBackend HTTPS validation webhook")
+}
+
+// registerUserWebhook serves the User admission webhook with the unique username
+// policy. Without webhook certificates only the FTP server's user cache enforces it.
+func registerUserWebhook(mgr ctrl.Manager, config *appConfig) {
+	if config.webhookCertPath == "" {
+		setupLog.Info("No webhook certificates configured, unique usernames are enforced at login only")
+		return
+	}
+	decoder := admission.NewDecoder(mgr.GetScheme())
+	validator := &ftpwebhook.UserValidator{Client: mgr.GetClient(), RequireUniqueUsernames: true}
+	_ = validator.InjectDecoder(&decoder)
+	mgr.GetWebhookServer().Register("/validate-ftp-golder-org-v1-user", &webhook.Admission{Handler: validator})
+	setupLog.Info("Registered User validation webhook with unique username policy")
+}
+
+// isDisabledBackendController reports whether the named controller reconciles a
+// backend kind that is not in the allowlist. Non-backend controllers are never disabled.
+func isDisabledBackendController(name string, enabledKinds []string) bool {
+	if len(enabledKinds) == 0 {
+		return false
+	}
+	isBackend := false
+	for _, kind := range supportedBackendKinds {
+		if name == kind {
+			isBackend = true
+			break
+		}
+	}
+	if !isBackend {
+		return false
+	}
+	for _, kind := range enabledKinds {
+		if name == kind {
+			return false
+		}
+	}
+	return true
+}
+
 func addCertWatchersToManager(mgr ctrl.Manager, metricsCertWatcher, webhookCertWatcher *certwatcher.CertWatcher) error {
 	if metricsCertWatcher != nil {
 		setupLog.Info("Adding metrics certificate watcher to manager")
@@ -391,6 +1305,35 @@ func buildFTPServer(config *appConfig, kubeClient client.Client) *ftp.Server {
 		s.TLSKeyFile = filepath.Join(config.ftpTLSCertPath, config.ftpTLSCertKey)
 		s.ForceTLS = config.ftpForceTLS
 	}
+	s.UserCacheMaxStaleness = config.userCacheMaxStaleness
+	s.UsersFile = config.usersFile
+	s.IdleTimeout = time.Duration(config.ftpIdleTimeout) * time.Second
+	s.DataIdleTimeout = config.ftpDataIdle
+	s.MaxCommandLength = config.ftpMaxCmdLength
+	s.DropLongCommandLines = config.ftpDropLongLines
+	s.MaxDataConnsPerSession = config.ftpMaxDataConns
+	s.RetryHint = config.ftpRetryHint
+	s.SystemType = config.ftpSystemType
+	s.HideServerIdentity = config.ftpHideIdentity
+	s.AllowUTF8Off = config.ftpAllowUTF8Off
+	s.FilenameCharset = config.ftpNameCharset
+	s.ShowHomePath = config.ftpShowHomePath
+	s.RequireUniqueUsernames = config.requireUniqueUsernames
+	s.ForceChroot = config.forceChroot
+	s.AutoDisableOnViolations = config.autoDisableOnViolations
+	s.GreetingDelay = config.ftpGreetingDelay
+	s.RequireUploadSize = config.ftpRequireSize
+	s.RejectUploadSizeMismatch = config.ftpRejectSizeDiff
+	s.FailTruncatedDownloads = config.ftpFailTruncated
+	s.IdempotentMkdir = config.ftpIdempotentMkd
+	s.SlowOperationThreshold = config.ftpSlowOpLimit
+	s.ErrorLogInterval = config.ftpErrorLogLimit
+	s.ICAPServer = config.ftpICAPServer
+	s.QuotaUsageRefreshInterval = config.quotaUsageRefresh
+	s.DisabledFeatures = splitCommaList(config.ftpDisableFeats)
+	s.RedactCommands = splitCommaList(config.ftpRedactCommands)
+	s.MetricTagKeys = splitCommaList(config.metricsUserTags)
+	s.NormalizeBackslashes = config.ftpNormalizeSlash
 	return s
 }
 
@@ -414,23 +1357,73 @@ func applyFTPTLSEnvOverrides(config *appConfig) {
 	}
 }
 
-func setupHealthChecks(mgr ctrl.Manager) error {
+func setupHealthChecks(mgr ctrl.Manager, selfTests *controller.BackendSelfTests) error {
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		return fmt.Errorf("unable to set up health check: %w", err)
 	}
 	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
 		return fmt.Errorf("unable to set up ready check: %w", err)
 	}
+	if selfTests != nil {
+		if err := mgr.AddReadyzCheck("backend-selftest", selfTests.Check); err != nil {
+			return fmt.Errorf("unable to set up backend self-test ready check: %w", err)
+		}
+	}
 	return nil
 }
 
 func main() {
+	// "kubeftpd probe --backend <file>" checks a backend manifest and exits
+	if len(os.Args) > 1 && os.Args[1] == "probe" {
+		os.Exit(runProbe(os.Args[2:], os.Stdout))
+	}
+	// "kubeftpd dump-users" lists Users with their resolved backends and exits
+	if len(os.Args) > 1 && os.Args[1] == "dump-users" {
+		os.Exit(runDumpUsers(os.Args[2:], os.Stdout))
+	}
+
 	config, opts := parseFlags()
 	processEnvironmentOverrides(config)
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 	setupLog.Info("Starting KubeFTPd", "version", version, "commit", commit, "date", date)
 
+	enabledKinds, err := parseEnabledBackendKinds(config.enabledBackendKinds)
+	if err != nil {
+		setupLog.Error(err, "invalid enabled backend kinds", "value", config.enabledBackendKinds)
+		os.Exit(1)
+	}
+	storage.SetEnabledBackendKinds(enabledKinds)
+
+	passwordPolicy, err := parsePasswordPolicy(config.passwordMinLength, config.passwordRequiredClasses,
+		config.passwordBannedSubstrings, config.passwordDisallowSequential)
+	if err != nil {
+		setupLog.Error(err, "invalid password policy")
+		os.Exit(1)
+	}
+	ftpv1.SetPasswordPolicy(passwordPolicy)
+	if len(enabledKinds) > 0 {
+		setupLog.Info("Restricting served backend kinds", "kinds", enabledKinds)
+	}
+
+	virtualHosts, err := ftp.ParseVirtualHosts(config.virtualHosts)
+	if err != nil {
+		setupLog.Error(err, "invalid virtual hosts", "value", config.virtualHosts)
+		os.Exit(1)
+	}
+
+	errorMessages, err := ftp.ParseErrorMessages(config.ftpErrorMessages)
+	if err != nil {
+		setupLog.Error(err, "invalid FTP error messages", "value", config.ftpErrorMessages)
+		os.Exit(1)
+	}
+
+	scheduledBanners, err := ftp.ParseScheduledBanners(config.ftpScheduledBanners)
+	if err != nil {
+		setupLog.Error(err, "invalid FTP scheduled banners", "value", config.ftpScheduledBanners)
+		os.Exit(1)
+	}
+
 	tlsOpts := setupTLSOptions(config.enableHTTP2)
 
 	webhookServer, webhookCertWatcher, err := setupWebhookServer(config, tlsOpts)
@@ -439,7 +1432,12 @@ func main() {
 		os.Exit(1)
 	}
 
-	mux := createHTTPHandler()
+	if !strings.HasPrefix(config.statusPath, "/") {
+		setupLog.Error(nil, "invalid status path, it must start with /", "path", config.statusPath)
+		os.Exit(1)
+	}
+	health := newStatusHealth(enabledKinds, config.statusDegradedErrorRate, config.statusDegradedHTTPCode)
+	mux := createHTTPHandler(config.statusPath, config.statusIncludeStats, health)
 	metricsServerOptions, metricsCertWatcher, err := setupMetricsServer(config, tlsOpts, mux)
 	if err != nil {
 		setupLog.Error(err, "Failed to setup metrics server")
@@ -457,17 +1455,32 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := setupControllers(mgr, config); err != nil {
+	health.reader = mgr.GetClient()
+
+	var selfTests *controller.BackendSelfTests
+	if config.backendSelfTest {
+		selfTests = controller.NewBackendSelfTests()
+	}
+
+	if err := setupControllers(mgr, config, enabledKinds, selfTests); err != nil {
 		setupLog.Error(err, "Failed to setup controllers")
 		os.Exit(1)
 	}
 
+	if config.requireHTTPSBackends {
+		registerBackendWebhooks(mgr, config)
+	}
+
+	if config.requireUniqueUsernames {
+		registerUserWebhook(mgr, config)
+	}
+
 	if err := addCertWatchersToManager(mgr, metricsCertWatcher, webhookCertWatcher); err != nil {
 		setupLog.Error(err, "Failed to add certificate watchers")
 		os.Exit(1)
 	}
 
-	if err := setupHealthChecks(mgr); err != nil {
+	if err := setupHealthChecks(mgr, selfTests); err != nil {
 		setupLog.Error(err, "Failed to setup health checks")
 		os.Exit(1)
 	}
@@ -499,9 +1512,9 @@ func main() {
 		Config: builtInConfig,
 	}
 
-	if err := builtInUserManager.UpdateConfig(ctx, builtInConfig); err != nil {
+	if err := builtInUserManager.UpdateConfigWithRetry(ctx, builtInConfig, config.builtInRetryAttempts, config.builtInRetryBackoff); err != nil {
 		setupLog.Error(err, "Failed to reconcile built-in users")
-		// Don't exit here as this might be a temporary error
+		// Don't exit here as the error might be temporary
 		// The controller will retry during normal reconciliation
 	} else {
 		setupLog.Info("Successfully reconciled built-in users based on configuration")
@@ -509,6 +1522,25 @@ func main() {
 
 	// Start FTP server
 	ftpServer := buildFTPServer(config, mgr.GetClient())
+	if config.emitTransferEvents {
+		ftpServer.TransferEvents = mgr.GetEventRecorder("kubeftpd-ftp")
+	}
+	if config.autoDisableOnViolations > 0 {
+		ftpServer.ViolationEvents = mgr.GetEventRecorder("kubeftpd-ftp")
+	}
+	ftpServer.VirtualHosts = virtualHosts
+	ftpServer.ErrorMessages = errorMessages
+	ftpServer.ScheduledBanners = scheduledBanners
+	if config.secureMetrics {
+		registerAdminHandlers(mux, ftpServer)
+		registerPauseHandlers(mux, ftpServer.Pause)
+	} else {
+		setupLog.Info("Admin HTTP endpoints disabled; they require --metrics-secure for authentication")
+	}
+	if err := setupMaintenanceMode(mgr, config, ftpServer, operatorNamespace); err != nil {
+		setupLog.Error(err, "Failed to setup maintenance mode")
+		os.Exit(1)
+	}
 	ctx, cancel := context.WithCancel(ctrl.SetupSignalHandler())
 	defer cancel()
 
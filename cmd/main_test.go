@@ -3,11 +3,21 @@ package main
 import (
 	"crypto/tls"
 	"encoding/json"
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/ftp"
+	"github.com/rossigee/kubeftpd/internal/metrics"
 )
 
 func TestGetDefaultFTPPort(t *testing.T) {
@@ -154,7 +164,7 @@ func TestSetupTLSOptions(t *testing.T) {
 }
 
 func TestCreateHTTPHandler(t *testing.T) {
-	mux := createHTTPHandler()
+	mux := createHTTPHandler("/", false, nil)
 	assert.NotNil(t, mux)
 
 	// Test the root endpoint returns JSON
@@ -182,6 +192,209 @@ func TestCreateHTTPHandler(t *testing.T) {
 	assert.NotEmpty(t, response["commit"])
 	assert.NotEmpty(t, response["date"])
 	assert.Equal(t, "running", response["status"])
+	assert.NotContains(t, response, "stats")
+}
+
+func TestCreateHTTPHandler_StatusPath(t *testing.T) {
+	mux := createHTTPHandler("/status", false, nil)
+	registerAdminHandlers(mux, ftp.NewServer("", 0, "", "", "", nil))
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/status", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var response statusResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "kubeftpd", response.Service)
+	assert.Equal(t, "running", response.Status)
+
+	// The root no longer serves the status, and other handlers still resolve
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, 404, w.Code)
+	_, pattern := mux.Handler(httptest.NewRequest("POST", "/admin/refresh-cache", nil))
+	assert.Equal(t, "/admin/refresh-cache", pattern)
+}
+
+func TestCreateHTTPHandler_IncludeStats(t *testing.T) {
+	before := metrics.GetServerStats()
+
+	// Simulate a session that uploads and downloads some data
+	metrics.RecordConnection()
+	metrics.RecordFileTransfer("statsuser", "upload", "FilesystemBackend", 1024, time.Second)
+	metrics.RecordFileTransfer("statsuser", "download", "FilesystemBackend", 512, time.Second)
+	defer metrics.RecordConnectionClosed("statsuser", time.Second)
+
+	mux := createHTTPHandler("/", true, nil)
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+
+	var response struct {
+		Status string               `json:"status"`
+		Stats  *metrics.ServerStats `json:"stats"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "running", response.Status)
+	if assert.NotNil(t, response.Stats) {
+		assert.GreaterOrEqual(t, response.Stats.UptimeSeconds, int64(0))
+		assert.Equal(t, before.TotalConnections+1, response.Stats.TotalConnections)
+		assert.Equal(t, before.BytesTransferred+1536, response.Stats.BytesTransferred)
+		assert.Equal(t, before.ActiveSessions+1, response.Stats.ActiveSessions)
+	}
+}
+
+// getStatus serves one request to the status endpoint and decodes the reply
+func getStatus(t *testing.T, health *statusHealth) (int, statusResponse) {
+	w := httptest.NewRecorder()
+	createHTTPHandler("/", false, health).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	var response statusResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w.Code, response
+}
+
+func newStatusHealthClient(objects ...client.Object) client.Client {
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+}
+
+func TestCreateHTTPHandler_BackendReadiness(t *testing.T) {
+	ready := &ftpv1.MinioBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "minio", Namespace: "team-a"},
+		Status:     ftpv1.MinioBackendStatus{Ready: true},
+	}
+	failing := &ftpv1.FilesystemBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "nfs", Namespace: "team-b"},
+		Status:     ftpv1.FilesystemBackendStatus{Ready: false, Message: "Base path is not writable"},
+	}
+
+	t.Run("all backends ready", func(t *testing.T) {
+		health := newStatusHealth(nil, 0, http.StatusServiceUnavailable)
+		health.reader = newStatusHealthClient(ready)
+
+		code, response := getStatus(t, health)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "running", response.Status)
+		assert.Empty(t, response.NotReadyBackends)
+	})
+
+	t.Run("some backend not ready", func(t *testing.T) {
+		health := newStatusHealth(nil, 0, http.StatusOK)
+		health.reader = newStatusHealthClient(ready, failing)
+
+		code, response := getStatus(t, health)
+		assert.Equal(t, http.StatusOK, code, "degraded keeps 200 by default")
+		assert.Equal(t, "degraded", response.Status)
+		assert.Equal(t, []string{"FilesystemBackend team-b/nfs"}, response.NotReadyBackends)
+	})
+
+	t.Run("no backend ready", func(t *testing.T) {
+		health := newStatusHealth(nil, 0, http.StatusServiceUnavailable)
+		health.reader = newStatusHealthClient(failing)
+
+		code, response := getStatus(t, health)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "unhealthy", response.Status)
+	})
+
+	t.Run("disabled kinds are not checked", func(t *testing.T) {
+		health := newStatusHealth([]string{"MinioBackend"}, 0, http.StatusServiceUnavailable)
+		health.reader = newStatusHealthClient(ready, failing)
+
+		code, response := getStatus(t, health)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "running", response.Status)
+	})
+}
+
+func TestCreateHTTPHandler_BackendErrorRate(t *testing.T) {
+	for i := 0; i < minErrorRateOperations; i++ {
+		metrics.RecordBackendOperation("status-test", "MinioBackend", "stat", "error", time.Millisecond)
+	}
+
+	code, response := getStatus(t, newStatusHealth(nil, 0.5, http.StatusServiceUnavailable))
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "degraded", response.Status)
+	if assert.NotNil(t, response.BackendErrorRate) {
+		assert.GreaterOrEqual(t, *response.BackendErrorRate, 0.5)
+	}
+
+	_, response = getStatus(t, newStatusHealth(nil, 0, http.StatusServiceUnavailable))
+	assert.Equal(t, "running", response.Status, "a zero threshold ignores the error rate")
+}
+
+func TestRegisterAdminHandlers_RefreshCache(t *testing.T) {
+	users := []client.Object{
+		&ftpv1.User{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice", Namespace: "default"},
+			Spec:       ftpv1.UserSpec{Username: "alice", Enabled: true},
+		},
+		&ftpv1.User{
+			ObjectMeta: metav1.ObjectMeta{Name: "bob", Namespace: "team-b"},
+			Spec:       ftpv1.UserSpec{Username: "bob", Enabled: true},
+		},
+	}
+	auth := ftp.NewKubeAuth(newStatusHealthClient(users...))
+	require.Zero(t, auth.CachedUserCount())
+
+	mux := http.NewServeMux()
+	registerAdminHandlers(mux, auth)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/refresh-cache", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, http.MethodPost, w.Header().Get("Allow"))
+	assert.Zero(t, auth.CachedUserCount(), "GET must not refresh")
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/refresh-cache", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var response refreshCacheResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Users)
+	assert.Equal(t, 2, auth.CachedUserCount())
+}
+
+func TestRegisterAdminHandlers_RefreshCacheNotStarted(t *testing.T) {
+	mux := http.NewServeMux()
+	registerAdminHandlers(mux, ftp.NewServer("", 0, "", "", "", nil))
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/refresh-cache", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "FTP server not started")
+}
+
+func TestRegisterPauseHandlers(t *testing.T) {
+	pause := &ftp.PauseMode{}
+	mux := http.NewServeMux()
+	registerPauseHandlers(mux, pause)
+
+	post := func(path string) pauseResponse {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		var response pauseResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/pause", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.False(t, pause.Paused(), "GET must not pause")
+
+	assert.True(t, post("/admin/pause").Paused)
+	assert.True(t, pause.Paused())
+	assert.True(t, post("/admin/pause").Paused, "pausing twice is harmless")
+
+	assert.False(t, post("/admin/resume").Paused)
+	assert.False(t, pause.Paused())
 }
 
 func TestSetupCertWatcher(t *testing.T) {
@@ -228,3 +441,110 @@ func TestAddCertWatchersToManager(t *testing.T) {
 	err := addCertWatchersToManager(nil, nil, nil)
 	assert.NoError(t, err)
 }
+
+func TestParseEnabledBackendKinds(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    []string
+		expectError bool
+	}{
+		{name: "empty enables all", value: "", expected: nil},
+		{name: "single kind", value: "FilesystemBackend", expected: []string{"FilesystemBackend"}},
+		{name: "multiple kinds with spaces", value: "MinioBackend, FilesystemBackend", expected: []string{"MinioBackend", "FilesystemBackend"}},
+		{name: "unknown kind", value: "MinioBackend,FTPBackend", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kinds, err := parseEnabledBackendKinds(tt.value)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, kinds)
+		})
+	}
+}
+
+func TestParsePasswordPolicy(t *testing.T) {
+	tests := []struct {
+		name             string
+		minLength        int
+		requiredClasses  string
+		bannedSubstrings string
+		expected         ftpv1.PasswordPolicy
+		expectError      bool
+	}{
+		{
+			name:             "classes and substrings",
+			minLength:        12,
+			requiredClasses:  "Upper, digit",
+			bannedSubstrings: "acme, , summer",
+			expected: ftpv1.PasswordPolicy{
+				MinLength:        12,
+				RequiredClasses:  []string{"upper", "digit"},
+				BannedSubstrings: []string{"acme", "summer"},
+			},
+		},
+		{
+			name:             "none clears lists",
+			minLength:        8,
+			requiredClasses:  "none",
+			bannedSubstrings: "NONE",
+			expected:         ftpv1.PasswordPolicy{MinLength: 8},
+		},
+		{name: "unknown class", minLength: 8, requiredClasses: "upper,emoji", expectError: true},
+		{name: "negative length", minLength: -1, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := parsePasswordPolicy(tt.minLength, tt.requiredClasses, tt.bannedSubstrings, false)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, policy)
+		})
+	}
+}
+
+func TestIsDisabledBackendController(t *testing.T) {
+	enabled := []string{"MinioBackend", "FilesystemBackend"}
+
+	assert.False(t, isDisabledBackendController("MinioBackend", enabled))
+	assert.False(t, isDisabledBackendController("FilesystemBackend", enabled))
+	assert.True(t, isDisabledBackendController("WebDavBackend", enabled))
+	assert.False(t, isDisabledBackendController("User", enabled))
+	assert.False(t, isDisabledBackendController("BuiltInUserManager", enabled))
+	assert.False(t, isDisabledBackendController("WebDavBackend", nil))
+}
+
+func TestParseConfigMapRef(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    string
+		expectError bool
+	}{
+		{name: "name only uses default namespace", value: "kubeftpd-maintenance", expected: "kubeftpd/kubeftpd-maintenance"},
+		{name: "namespaced", value: "ops/maintenance", expected: "ops/maintenance"},
+		{name: "missing name", value: "ops/", expectError: true},
+		{name: "too many segments", value: "ops/a/b", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := parseConfigMapRef(tt.value, "kubeftpd")
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, ref.String())
+		})
+	}
+}
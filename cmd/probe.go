@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/backends"
+)
+
+// Exit codes returned by the probe subcommand
+const (
+	probeExitOK     = 0
+	probeExitFailed = 1
+	probeExitUsage  = 2
+)
+
+// probeDefaultTimeout bounds the connectivity check when --timeout is not given
+const probeDefaultTimeout = 30 * time.Second
+
+// runProbe implements "kubeftpd probe --backend <file>". It loads a backend
+// manifest, constructs the backend and performs a lightweight connectivity
+// check without contacting the Kubernetes API. Credentials and CA bundles must
+// therefore be inline in the manifest rather than referenced from Secrets,
+// except for an FtpBackend, whose credentials Secret is passed with --secret.
+func runProbe(args []string, out io.Writer) int {
+	fs := flag.NewFlagSet("probe", flag.ContinueOnError)
+	fs.SetOutput(out)
+	backendFile := fs.String("backend", "", "Path to a MinioBackend, WebDavBackend, FilesystemBackend or FtpBackend manifest")
+	secretFile := fs.String("secret", "", "Path to the Secret manifest holding an FtpBackend's credentials")
+	timeout := fs.Duration("timeout", probeDefaultTimeout, "Maximum time to wait for the connectivity check")
+	if err := fs.Parse(args); err != nil {
+		return probeExitUsage
+	}
+	if *backendFile == "" {
+		_, _ = fmt.Fprintln(out, "error: --backend is required")
+		fs.Usage()
+		return probeExitUsage
+	}
+
+	data, err := os.ReadFile(*backendFile)
+	if err != nil {
+		_, _ = fmt.Fprintf(out, "FAIL: failed to read %s: %v\n", *backendFile, err)
+		return probeExitFailed
+	}
+
+	var secretData []byte
+	if *secretFile != "" {
+		if secretData, err = os.ReadFile(*secretFile); err != nil {
+			_, _ = fmt.Fprintf(out, "FAIL: failed to read %s: %v\n", *secretFile, err)
+			return probeExitFailed
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	kind, name, err := probeBackend(ctx, data, secretData)
+	if err != nil {
+		_, _ = fmt.Fprintf(out, "FAIL: %s %s: %v\n", kind, name, err)
+		return probeExitFailed
+	}
+
+	_, _ = fmt.Fprintf(out, "OK: %s %s is reachable\n", kind, name)
+	return probeExitOK
+}
+
+// probeBackend decodes a backend manifest and checks that the backend is usable.
+// secretData is the manifest of an FtpBackend's credentials Secret, if given.
+// It returns the decoded kind and name so callers can report them.
+func probeBackend(ctx context.Context, data, secretData []byte) (string, string, error) {
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+	obj, gvk, err := decoder.Decode(data, nil, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decode manifest: %w", err)
+	}
+
+	switch backend := obj.(type) {
+	case *ftpv1.MinioBackend:
+		if backend.Spec.Credentials.UseSecret != nil || (backend.Spec.TLS != nil && (backend.Spec.TLS.CASecretRef != nil || backend.Spec.TLS.CABundleRef != nil)) {
+			return gvk.Kind, backend.Name, fmt.Errorf("secret references are not supported in probe mode")
+		}
+		// NewMinioBackend checks the bucket; listing confirms the credentials can read it
+		minioBackend, err := backends.NewMinioBackend(ctx, backend, nil)
+		if err != nil {
+			return gvk.Kind, backend.Name, err
+		}
+		if _, err := minioBackend.ListObjects("", false); err != nil {
+			return gvk.Kind, backend.Name, fmt.Errorf("failed to list bucket %s: %w", backend.Spec.Bucket, err)
+		}
+		return gvk.Kind, backend.Name, nil
+	case *ftpv1.WebDavBackend:
+		if backend.Spec.Credentials.UseSecret != nil || (backend.Spec.TLS != nil && backend.Spec.TLS.CASecretRef != nil) {
+			return gvk.Kind, backend.Name, fmt.Errorf("secret references are not supported in probe mode")
+		}
+		webdavBackend, err := backends.NewWebDavBackend(ctx, backend, nil)
+		if err != nil {
+			return gvk.Kind, backend.Name, err
+		}
+		if _, err := webdavBackend.ReadDir("/"); err != nil {
+			return gvk.Kind, backend.Name, fmt.Errorf("failed to list base path: %w", err)
+		}
+		return gvk.Kind, backend.Name, nil
+	case *ftpv1.FilesystemBackend:
+		filesystemBackend, err := backends.NewFilesystemBackend(backend, nil)
+		if err != nil {
+			return gvk.Kind, backend.Name, err
+		}
+		if _, err := filesystemBackend.ListFiles("", false); err != nil {
+			return gvk.Kind, backend.Name, fmt.Errorf("failed to list base path: %w", err)
+		}
+		return gvk.Kind, backend.Name, nil
+	case *ftpv1.FtpBackend:
+		if backend.Spec.TLS != nil && backend.Spec.TLS.CASecretRef != nil {
+			return gvk.Kind, backend.Name, fmt.Errorf("secret references are not supported in probe mode")
+		}
+		if secretData == nil {
+			return gvk.Kind, backend.Name, fmt.Errorf("the credentials Secret %s must be passed with --secret", backend.Spec.Credentials.UseSecret.Name)
+		}
+		secretObj, _, err := decoder.Decode(secretData, nil, nil)
+		if err != nil {
+			return gvk.Kind, backend.Name, fmt.Errorf("failed to decode secret manifest: %w", err)
+		}
+		secret, ok := secretObj.(*corev1.Secret)
+		if !ok || secret.Name != backend.Spec.Credentials.UseSecret.Name {
+			return gvk.Kind, backend.Name, fmt.Errorf("--secret must be the Secret %s referenced by credentials.useSecret", backend.Spec.Credentials.UseSecret.Name)
+		}
+		// NewFtpBackendFromSecret logs in; listing confirms the base path is readable
+		ftpBackend, err := backends.NewFtpBackendFromSecret(ctx, backend, secret)
+		if err != nil {
+			return gvk.Kind, backend.Name, err
+		}
+		defer func() { _ = ftpBackend.Close() }()
+		if _, err := ftpBackend.ReadDir("/"); err != nil {
+			return gvk.Kind, backend.Name, fmt.Errorf("failed to list base path: %w", err)
+		}
+		return gvk.Kind, backend.Name, nil
+	default:
+		return gvk.Kind, "", fmt.Errorf("unsupported kind %s (expected MinioBackend, WebDavBackend, FilesystemBackend or FtpBackend)", gvk.Kind)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goftp.io/server/v2"
+	filedriver "goftp.io/server/v2/driver/file"
+)
+
+func writeProbeManifest(t *testing.T, manifest string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "backend.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(manifest), 0600))
+	return file
+}
+
+func filesystemBackendManifest(basePath string) string {
+	return fmt.Sprintf(`apiVersion: ftp.golder.org/v1
+kind: FilesystemBackend
+metadata:
+  name: probe-fs
+  namespace: default
+spec:
+  basePath: %q
+`, basePath)
+}
+
+// ftpProbeServer starts a remote FTP server serving an empty directory to
+// "remote"/"secret" and returns its port
+func ftpProbeServer(t *testing.T) int {
+	t.Helper()
+	driver, err := filedriver.NewDriver(t.TempDir())
+	require.NoError(t, err)
+	ftpServer, err := server.NewServer(&server.Options{
+		Driver: driver,
+		Auth:   &server.SimpleAuth{Name: "remote", Password: "secret"},
+		Perm:   server.NewSimplePerm("remote", "remote"),
+		Logger: &server.DiscardLogger{},
+	})
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = ftpServer.Serve(listener) }()
+	t.Cleanup(func() { _ = ftpServer.Shutdown() })
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func ftpBackendManifest(port int) string {
+	return fmt.Sprintf(`apiVersion: ftp.golder.org/v1
+kind: FtpBackend
+metadata:
+  name: probe-ftp
+  namespace: default
+spec:
+  host: 127.0.0.1
+  port: %d
+  timeoutSeconds: 5
+  tls:
+    mode: none
+  credentials:
+    useSecret:
+      name: remote-ftp
+`, port)
+}
+
+func ftpSecretManifest(name, password string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Secret
+metadata:
+  name: %s
+  namespace: default
+data:
+  username: cmVtb3Rl
+  password: %s
+`, name, base64.StdEncoding.EncodeToString([]byte(password)))
+}
+
+func TestRunProbe(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         func(t *testing.T) []string
+		expectedCode int
+		expectedOut  string
+	}{
+		{
+			name: "reachable filesystem path",
+			args: func(t *testing.T) []string {
+				return []string{"--backend", writeProbeManifest(t, filesystemBackendManifest(t.TempDir()))}
+			},
+			expectedCode: probeExitOK,
+			expectedOut:  "OK: FilesystemBackend probe-fs is reachable",
+		},
+		{
+			name: "invalid filesystem path",
+			args: func(t *testing.T) []string {
+				missing := filepath.Join(t.TempDir(), "does-not-exist")
+				return []string{"--backend", writeProbeManifest(t, filesystemBackendManifest(missing))}
+			},
+			expectedCode: probeExitFailed,
+			expectedOut:  "FAIL: FilesystemBackend probe-fs",
+		},
+		{
+			name: "reachable FTP server",
+			args: func(t *testing.T) []string {
+				return []string{
+					"--backend", writeProbeManifest(t, ftpBackendManifest(ftpProbeServer(t))),
+					"--secret", writeProbeManifest(t, ftpSecretManifest("remote-ftp", "secret")),
+				}
+			},
+			expectedCode: probeExitOK,
+			expectedOut:  "OK: FtpBackend probe-ftp is reachable",
+		},
+		{
+			name: "FTP login refused",
+			args: func(t *testing.T) []string {
+				return []string{
+					"--backend", writeProbeManifest(t, ftpBackendManifest(ftpProbeServer(t))),
+					"--secret", writeProbeManifest(t, ftpSecretManifest("remote-ftp", "wrong")),
+				}
+			},
+			expectedCode: probeExitFailed,
+			expectedOut:  "FAIL: FtpBackend probe-ftp",
+		},
+		{
+			name: "FTP credentials secret not given",
+			args: func(t *testing.T) []string {
+				return []string{"--backend", writeProbeManifest(t, ftpBackendManifest(ftpProbeServer(t)))}
+			},
+			expectedCode: probeExitFailed,
+			expectedOut:  "the credentials Secret remote-ftp must be passed with --secret",
+		},
+		{
+			name: "FTP credentials secret does not match",
+			args: func(t *testing.T) []string {
+				return []string{
+					"--backend", writeProbeManifest(t, ftpBackendManifest(ftpProbeServer(t))),
+					"--secret", writeProbeManifest(t, ftpSecretManifest("other", "secret")),
+				}
+			},
+			expectedCode: probeExitFailed,
+			expectedOut:  "--secret must be the Secret remote-ftp",
+		},
+		{
+			name: "unsupported kind",
+			args: func(t *testing.T) []string {
+				return []string{"--backend", writeProbeManifest(t, `apiVersion: ftp.golder.org/v1
+kind: User
+metadata:
+  name: not-a-backend
+spec:
+  username: test
+`)}
+			},
+			expectedCode: probeExitFailed,
+			expectedOut:  "unsupported kind User",
+		},
+		{
+			name: "missing manifest file",
+			args: func(t *testing.T) []string {
+				return []string{"--backend", filepath.Join(t.TempDir(), "missing.yaml")}
+			},
+			expectedCode: probeExitFailed,
+			expectedOut:  "failed to read",
+		},
+		{
+			name: "missing backend flag",
+			args: func(t *testing.T) []string {
+				return nil
+			},
+			expectedCode: probeExitUsage,
+			expectedOut:  "--backend is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			code := runProbe(tt.args(t), &out)
+			assert.Equal(t, tt.expectedCode, code)
+			assert.Contains(t, out.String(), tt.expectedOut)
+		})
+	}
+}
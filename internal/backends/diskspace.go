@@ -0,0 +1,64 @@
+package backends
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+)
+
+// ErrInsufficientSpace is returned when a write would leave a filesystem
+// backend with less free space than its MinFreeBytes
+var ErrInsufficientSpace = errors.New("insufficient free space on backend")
+
+// DiskSpaceFunc reports the available and total bytes of the filesystem
+// holding path. It is swapped out in tests to simulate disk pressure.
+type DiskSpaceFunc func(path string) (available, total int64, err error)
+
+// StatfsDiskSpace reports disk space for path using statfs(2). Values that
+// would overflow an int64 are reported as math.MaxInt64.
+func StatfsDiskSpace(path string) (available, total int64, err error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return -1, -1, err
+	}
+
+	const maxInt64 = 1<<63 - 1
+
+	// Calculate with overflow protection
+	if stat.Bavail > 0 && stat.Bsize > 0 && stat.Bavail <= maxInt64/uint64(stat.Bsize) {
+		available = int64(stat.Bavail) * int64(stat.Bsize) // nolint:gosec // Overflow protected
+	} else {
+		available = maxInt64 // Use max value if overflow would occur
+	}
+
+	if stat.Blocks > 0 && stat.Bsize > 0 && stat.Blocks <= maxInt64/uint64(stat.Bsize) {
+		total = int64(stat.Blocks) * int64(stat.Bsize) // nolint:gosec // Overflow protected
+	} else {
+		total = maxInt64 // Use max value if overflow would occur
+	}
+
+	return available, total, nil
+}
+
+// checkFreeSpace refuses a write of size bytes (zero or less if unknown) that
+// would take the backend below its minimum free space. Failing to read the
+// free space does not block writes.
+func (f *filesystemBackendImpl) checkFreeSpace(size int64) error {
+	if f.minFreeBytes <= 0 {
+		return nil
+	}
+
+	diskSpace := f.diskSpace
+	if diskSpace == nil {
+		diskSpace = StatfsDiskSpace
+	}
+	available, _, err := diskSpace(f.basePath)
+	if err != nil {
+		return nil
+	}
+
+	if available-max(size, 0) < f.minFreeBytes {
+		return fmt.Errorf("%w: %d bytes free, %d required", ErrInsufficientSpace, available, f.minFreeBytes)
+	}
+	return nil
+}
@@ -1,10 +1,15 @@
 package backends
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -27,13 +32,38 @@ type FilesystemBackend interface {
 	IsReadOnly() bool
 }
 
+// ErrParentNotFound is returned for uploads into a missing directory when the
+// backend does not create parent directories
+var ErrParentNotFound = errors.New("parent directory does not exist")
+
+// compressedSuffix is appended to the on-disk name of files stored
+// compressed when CompressAtRest is enabled. Clients never see it.
+const compressedSuffix = ".gz"
+
+// compressedMarker is the gzip header extra subfield (RFC 1952) that marks a
+// file as compressed by CompressAtRest. Its 8 bytes hold the uncompressed size,
+// little-endian, written once the upload has finished. Other .gz files are
+// served as they are.
+var compressedMarker = []byte{'K', 'F', 8, 0}
+
+// compressedSizeOffset is where the marker's size is stored: after the
+// 10-byte gzip header, the 2-byte extra field length and the subfield header
+const compressedSizeOffset = 10 + 2 + 4
+
 // filesystemBackendImpl implements FilesystemBackend using local filesystem
 type filesystemBackendImpl struct {
-	basePath    string
-	readOnly    bool
-	fileMode    os.FileMode
-	dirMode     os.FileMode
-	maxFileSize int64
+	basePath       string
+	readOnly       bool
+	fileMode       os.FileMode
+	dirMode        os.FileMode
+	maxFileSize    int64
+	compressAtRest bool
+	followSymlinks bool
+	minFreeBytes   int64
+	diskSpace      DiskSpaceFunc // nil uses StatfsDiskSpace
+	// strictParents refuses uploads whose parent directory does not exist
+	// instead of creating it
+	strictParents bool
 }
 
 // NewFilesystemBackend creates a new filesystem backend
@@ -56,11 +86,15 @@ func NewFilesystemBackend(backend *ftpv1.FilesystemBackend, kubeClient client.Cl
 	}
 
 	return &filesystemBackendImpl{
-		basePath:    basePath,
-		readOnly:    backend.Spec.ReadOnly,
-		fileMode:    fileMode,
-		dirMode:     dirMode,
-		maxFileSize: backend.Spec.MaxFileSize,
+		basePath:       basePath,
+		readOnly:       backend.Spec.ReadOnly,
+		fileMode:       fileMode,
+		dirMode:        dirMode,
+		maxFileSize:    backend.Spec.MaxFileSize,
+		compressAtRest: backend.Spec.CompressAtRest,
+		followSymlinks: backend.Spec.FollowSymlinks,
+		minFreeBytes:   backend.Spec.MinFreeBytes,
+		strictParents:  !backend.Spec.CreatesParents(),
 	}, nil
 }
 
@@ -131,12 +165,7 @@ func (f *filesystemBackendImpl) ListFiles(dirPath string, recursive bool) ([]Fil
 				return err
 			}
 
-			files = append(files, FileInfo{
-				Name:    filepath.Base(relPath),
-				Size:    info.Size(),
-				ModTime: info.ModTime(),
-				IsDir:   info.IsDir(),
-			})
+			files = append(files, f.clientFileInfo(filepath.Base(relPath), path, info))
 
 			return nil
 		})
@@ -155,43 +184,129 @@ func (f *filesystemBackendImpl) ListFiles(dirPath string, recursive bool) ([]Fil
 			continue
 		}
 
-		files = append(files, FileInfo{
-			Name:    entry.Name(),
-			Size:    info.Size(),
-			ModTime: info.ModTime(),
-			IsDir:   entry.IsDir(),
-		})
+		files = append(files, f.clientFileInfo(entry.Name(), filepath.Join(fullPath, entry.Name()), info))
 	}
 
 	return files, nil
 }
 
+// clientFileInfo converts an on-disk entry into the FileInfo clients see,
+// stripping the compressed suffix and reporting the uncompressed size.
+// info must come from Lstat so symbolic links can be detected.
+func (f *filesystemBackendImpl) clientFileInfo(name, path string, info os.FileInfo) FileInfo {
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, statErr := os.Stat(path)
+		if !f.followSymlinks || statErr != nil {
+			// Unfollowed and dangling links are reported as links
+			linkTarget, _ := os.Readlink(path)
+			owner, group := fileOwnership(info)
+			return FileInfo{
+				Name:       name,
+				Size:       info.Size(),
+				Mode:       info.Mode(),
+				ModTime:    info.ModTime(),
+				IsSymlink:  true,
+				LinkTarget: linkTarget,
+				Owner:      owner,
+				Group:      group,
+				Nlink:      fileLinkCount(info),
+			}
+		}
+		info = target
+	}
+
+	size := info.Size()
+	if f.compressAtRest && !info.IsDir() && strings.HasSuffix(name, compressedSuffix) {
+		if logical, ok := compressedLogicalSize(path); ok {
+			name = strings.TrimSuffix(name, compressedSuffix)
+			size = logical
+		}
+	}
+
+	owner, group := fileOwnership(info)
+	return FileInfo{
+		Name:    name,
+		Size:    size,
+		ModTime: info.ModTime(),
+		IsDir:   info.IsDir(),
+		Owner:   owner,
+		Group:   group,
+		Nlink:   fileLinkCount(info),
+	}
+}
+
+// resolveFile returns the on-disk path for a client path and whether the
+// file is stored compressed. Files written before CompressAtRest was enabled
+// are still found under their original name.
+func (f *filesystemBackendImpl) resolveFile(filePath string) (string, bool) {
+	fullPath := f.getFullPath(filePath)
+	if !f.compressAtRest {
+		return fullPath, false
+	}
+
+	if _, ok := compressedLogicalSize(fullPath + compressedSuffix); ok {
+		return fullPath + compressedSuffix, true
+	}
+
+	return fullPath, false
+}
+
+// compressedLogicalSize returns the uncompressed size recorded in the header
+// of a file written by CompressAtRest, and false for any other file,
+// including .gz files that were already on disk
+func compressedLogicalSize(path string) (int64, bool) {
+	file, err := os.Open(path) // nolint:gosec // File path is validated and controlled by backend
+	if err != nil {
+		return 0, false
+	}
+	defer func() { _ = file.Close() }()
+
+	header := make([]byte, compressedSizeOffset+8)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return 0, false
+	}
+	const flagExtra = 0x04
+	if header[0] != 0x1f || header[1] != 0x8b || header[3]&flagExtra == 0 ||
+		binary.LittleEndian.Uint16(header[10:12]) < uint16(len(compressedMarker)+8) ||
+		!bytes.Equal(header[12:compressedSizeOffset], compressedMarker) {
+		return 0, false
+	}
+	return int64(binary.LittleEndian.Uint64(header[compressedSizeOffset:])), true
+}
+
 // StatFile gets file/directory information
 func (f *filesystemBackendImpl) StatFile(filePath string) (*FileInfo, error) {
-	fullPath := f.getFullPath(filePath)
+	fullPath, _ := f.resolveFile(filePath)
+
+	// The base path itself may be a mounted symlink and is always followed
+	lstat := os.Lstat
+	if fullPath == f.basePath {
+		lstat = os.Stat
+	}
 
-	info, err := os.Stat(fullPath)
+	info, err := lstat(fullPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to stat file %s: %w", filePath, err)
 	}
 
-	return &FileInfo{
-		Name:    filepath.Base(filePath),
-		Size:    info.Size(),
-		ModTime: info.ModTime(),
-		IsDir:   info.IsDir(),
-	}, nil
+	fileInfo := f.clientFileInfo(filepath.Base(fullPath), fullPath, info)
+	fileInfo.Name = filepath.Base(filePath)
+	return &fileInfo, nil
 }
 
 // GetFile retrieves a file with optional range
 func (f *filesystemBackendImpl) GetFile(filePath string, offset, length int64) (io.ReadCloser, error) {
-	fullPath := f.getFullPath(filePath)
+	fullPath, compressed := f.resolveFile(filePath)
 
 	file, err := os.Open(fullPath) // nolint:gosec // File path is validated and controlled by backend
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
 	}
 
+	if compressed {
+		return openCompressed(file, offset, length)
+	}
+
 	if offset > 0 {
 		_, err = file.Seek(offset, io.SeekStart)
 		if err != nil {
@@ -210,6 +325,34 @@ func (f *filesystemBackendImpl) GetFile(filePath string, offset, length int64) (
 	return file, nil
 }
 
+// openCompressed wraps a compressed file in a decompressing reader. Offsets
+// refer to the uncompressed content, so leading bytes are decoded and
+// discarded since gzip streams cannot be seeked.
+func openCompressed(file *os.File, offset, length int64) (io.ReadCloser, error) {
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to open compressed file: %w", err)
+	}
+
+	if offset > 0 {
+		if _, err := io.CopyN(io.Discard, gz, offset); err != nil && !errors.Is(err, io.EOF) {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to seek to offset %d: %w", offset, err)
+		}
+	}
+
+	var reader io.Reader = gz
+	if length > 0 {
+		reader = io.LimitReader(gz, length)
+	}
+
+	return &limitedReadCloser{
+		reader: reader,
+		closer: file,
+	}, nil
+}
+
 // PutFile uploads a file
 func (f *filesystemBackendImpl) PutFile(filePath string, reader io.Reader, size int64) error {
 	if f.readOnly {
@@ -221,17 +364,29 @@ func (f *filesystemBackendImpl) PutFile(filePath string, reader io.Reader, size
 		return fmt.Errorf("file size %d exceeds maximum allowed size %d", size, f.maxFileSize)
 	}
 
+	if err := f.checkFreeSpace(size); err != nil {
+		return err
+	}
+
 	fullPath := f.getFullPath(filePath)
 
 	// Ensure directory exists
 	dir := filepath.Dir(fullPath)
-	if err := os.MkdirAll(dir, f.dirMode); err != nil {
+	if f.strictParents {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return fmt.Errorf("%w: %s", ErrParentNotFound, filepath.Dir(filePath))
+		}
+	} else if err := os.MkdirAll(dir, f.dirMode); err != nil {
 		return fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
 
+	if f.compressAtRest {
+		return f.putCompressedFile(filePath, fullPath, reader, size)
+	}
+
 	// Write to temporary file with verification
 	tempPath := fullPath + ".tmp"
-	bytesWritten, err := f.writeToTempFile(tempPath, reader)
+	bytesWritten, err := f.writeToTempFile(tempPath, reader, false)
 	if err != nil {
 		return err
 	}
@@ -251,15 +406,74 @@ func (f *filesystemBackendImpl) PutFile(filePath string, reader io.Reader, size
 	return f.verifyFinalFile(fullPath, size, bytesWritten)
 }
 
-// writeToTempFile handles the actual file writing with proper error handling
-func (f *filesystemBackendImpl) writeToTempFile(tempPath string, reader io.Reader) (int64, error) {
+// putCompressedFile stores a file gzip-compressed under its name plus
+// compressedSuffix. Size checks are made against the uncompressed byte
+// count since that is what the client sent.
+func (f *filesystemBackendImpl) putCompressedFile(filePath, fullPath string, reader io.Reader, size int64) error {
+	compressedPath := fullPath + compressedSuffix
+	tempPath := compressedPath + ".tmp"
+
+	bytesWritten, err := f.writeToTempFile(tempPath, reader, true)
+	if err != nil {
+		return err
+	}
+
+	if size > 0 && bytesWritten != size {
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("file size mismatch: expected %d, got %d", size, bytesWritten)
+	}
+
+	if err = os.Rename(tempPath, compressedPath); err != nil {
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("failed to finalize file %s: %w", filePath, err)
+	}
+
+	// Drop any uncompressed copy left from before compression was enabled
+	// so the file is not listed twice.
+	if info, statErr := os.Stat(fullPath); statErr == nil && !info.IsDir() {
+		_ = os.Remove(fullPath)
+	}
+
+	logicalSize, ok := compressedLogicalSize(compressedPath)
+	if !ok {
+		_ = os.Remove(compressedPath)
+		return fmt.Errorf("final file verification failed: %s is not marked as compressed", compressedPath)
+	}
+	if logicalSize != bytesWritten {
+		_ = os.Remove(compressedPath)
+		return fmt.Errorf("final file size verification failed: expected %d, got %d", bytesWritten, logicalSize)
+	}
+
+	return nil
+}
+
+// writeToTempFile handles the actual file writing with proper error handling.
+// When compress is set the data is gzipped and the returned count is the
+// number of uncompressed bytes read.
+func (f *filesystemBackendImpl) writeToTempFile(tempPath string, reader io.Reader, compress bool) (int64, error) {
 	file, err := os.OpenFile(tempPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, f.fileMode) // nolint:gosec // File path is validated and controlled by backend
 	if err != nil {
 		return 0, fmt.Errorf("failed to create temporary file %s: %w", tempPath, err)
 	}
 
 	// Copy data and track bytes written
-	bytesWritten, copyErr := io.Copy(file, reader)
+	var bytesWritten int64
+	var copyErr error
+	if compress {
+		gz := gzip.NewWriter(file)
+		gz.Extra = append(slices.Clone(compressedMarker), make([]byte, 8)...)
+		bytesWritten, copyErr = io.Copy(gz, reader)
+		if closeErr := gz.Close(); copyErr == nil {
+			copyErr = closeErr
+		}
+		if copyErr == nil {
+			// The size is only known now, so it is filled into the marker in place
+			size := binary.LittleEndian.AppendUint64(nil, uint64(bytesWritten))
+			_, copyErr = file.WriteAt(size, compressedSizeOffset)
+		}
+	} else {
+		bytesWritten, copyErr = io.Copy(file, reader)
+	}
 
 	// Force flush to disk before closing
 	if syncErr := file.Sync(); syncErr != nil {
@@ -331,7 +545,7 @@ func (f *filesystemBackendImpl) RemoveFile(filePath string) error {
 		return fmt.Errorf("backend is read-only")
 	}
 
-	fullPath := f.getFullPath(filePath)
+	fullPath, _ := f.resolveFile(filePath)
 	return os.Remove(fullPath)
 }
 
@@ -366,8 +580,16 @@ func (f *filesystemBackendImpl) CopyFile(srcPath, dstPath string, deleteSource b
 		return fmt.Errorf("backend is read-only")
 	}
 
-	srcFullPath := f.getFullPath(srcPath)
+	if err := f.checkFreeSpace(0); err != nil {
+		return err
+	}
+
+	srcFullPath, compressed := f.resolveFile(srcPath)
 	dstFullPath := f.getFullPath(dstPath)
+	if compressed {
+		// Compressed data is copied as-is and keeps its suffix
+		dstFullPath += compressedSuffix
+	}
 
 	// Ensure destination directory exists
 	dstDir := filepath.Dir(dstFullPath)
@@ -394,6 +616,18 @@ func (f *filesystemBackendImpl) CopyFile(srcPath, dstPath string, deleteSource b
 		return fmt.Errorf("failed to copy file data: %w", err)
 	}
 
+	// Remove the other stored form of the destination so it cannot shadow
+	// the copy
+	if f.compressAtRest {
+		stale := strings.TrimSuffix(dstFullPath, compressedSuffix)
+		if !compressed {
+			stale = dstFullPath + compressedSuffix
+		}
+		if info, statErr := os.Stat(stale); statErr == nil && !info.IsDir() {
+			_ = os.Remove(stale)
+		}
+	}
+
 	// Delete source if requested
 	if deleteSource {
 		if err = os.Remove(srcFullPath); err != nil {
@@ -1,9 +1,13 @@
 package backends
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -244,6 +248,95 @@ func TestFilesystemBackend_PutFile_ReadOnly(t *testing.T) {
 	assert.Contains(t, err.Error(), "read-only")
 }
 
+func TestFilesystemBackend_AutoCreateParents(t *testing.T) {
+	enabled, disabled := true, false
+	for _, tt := range []struct {
+		name              string
+		autoCreateParents *bool
+		wantErr           bool
+	}{
+		{name: "default creates parents"},
+		{name: "enabled creates parents", autoCreateParents: &enabled},
+		{name: "disabled rejects missing parent", autoCreateParents: &disabled, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			testDir := createTestDir(t)
+			backendCR := &ftpv1.FilesystemBackend{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-backend", Namespace: "default"},
+				Spec: ftpv1.FilesystemBackendSpec{
+					BasePath:          testDir,
+					AutoCreateParents: tt.autoCreateParents,
+				},
+			}
+			backend, err := NewFilesystemBackend(backendCR, fake.NewClientBuilder().Build())
+			require.NoError(t, err)
+
+			err = backend.PutFile("incoming/2024/test.txt", strings.NewReader("test content"), 12)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrParentNotFound)
+				assert.NoDirExists(t, filepath.Join(testDir, "incoming"))
+
+				require.NoError(t, backend.MakeDir("incoming/2024"))
+				require.NoError(t, backend.PutFile("incoming/2024/test.txt", strings.NewReader("test content"), 12))
+				assert.FileExists(t, filepath.Join(testDir, "incoming", "2024", "test.txt"))
+				return
+			}
+			require.NoError(t, err)
+			assert.DirExists(t, filepath.Join(testDir, "incoming", "2024"))
+			assert.FileExists(t, filepath.Join(testDir, "incoming", "2024", "test.txt"))
+		})
+	}
+}
+
+func TestFilesystemBackend_MinFreeBytes(t *testing.T) {
+	tests := []struct {
+		name      string
+		available int64
+		size      int64
+		wantErr   bool
+	}{
+		{name: "plenty of space", available: 10000, size: 12},
+		{name: "already below the floor", available: 500, size: 12, wantErr: true},
+		{name: "upload would cross the floor", available: 1010, size: 12, wantErr: true},
+		{name: "unknown size below the floor", available: 999, size: -1, wantErr: true},
+		{name: "unknown size above the floor", available: 1001, size: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testDir := createTestDir(t)
+			backend := createTestBackend(t, testDir, false).(*filesystemBackendImpl)
+			backend.minFreeBytes = 1000
+			backend.diskSpace = func(path string) (int64, int64, error) {
+				assert.Equal(t, testDir, path)
+				return tt.available, 1 << 30, nil
+			}
+
+			err := backend.PutFile("test.txt", strings.NewReader("test content"), tt.size)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInsufficientSpace)
+				assert.NoFileExists(t, filepath.Join(testDir, "test.txt"))
+				return
+			}
+			require.NoError(t, err)
+			assert.FileExists(t, filepath.Join(testDir, "test.txt"))
+		})
+	}
+}
+
+func TestFilesystemBackend_MinFreeBytes_CopyFile(t *testing.T) {
+	testDir := createTestDir(t)
+	backend := createTestBackend(t, testDir, false).(*filesystemBackendImpl)
+	require.NoError(t, backend.PutFile("source.txt", strings.NewReader("test content"), 12))
+
+	backend.minFreeBytes = 1000
+	backend.diskSpace = func(string) (int64, int64, error) { return 10, 1 << 30, nil }
+
+	err := backend.CopyFile("source.txt", "copy.txt", false)
+	assert.ErrorIs(t, err, ErrInsufficientSpace)
+	assert.NoFileExists(t, filepath.Join(testDir, "copy.txt"))
+}
+
 func TestFilesystemBackend_GetFile(t *testing.T) {
 	testDir := createTestDir(t)
 	backend := createTestBackend(t, testDir, false)
@@ -434,6 +527,154 @@ func TestFilesystemBackend_CopyFile_WithDelete(t *testing.T) {
 	assert.Equal(t, testContent, string(destContent))
 }
 
+func createCompressedTestBackend(t *testing.T, basePath string) FilesystemBackend {
+	backendCR := &ftpv1.FilesystemBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backend",
+			Namespace: "default",
+		},
+		Spec: ftpv1.FilesystemBackendSpec{
+			BasePath:       basePath,
+			FileMode:       "0644",
+			DirMode:        "0755",
+			CompressAtRest: true,
+		},
+	}
+
+	backend, err := NewFilesystemBackend(backendCR, fake.NewClientBuilder().Build())
+	require.NoError(t, err)
+	return backend
+}
+
+func TestFilesystemBackend_CompressAtRest_RoundTrip(t *testing.T) {
+	testDir := createTestDir(t)
+	backend := createCompressedTestBackend(t, testDir)
+
+	testContent := strings.Repeat("compressible content ", 200)
+	err := backend.PutFile("dir/report.txt", strings.NewReader(testContent), int64(len(testContent)))
+	require.NoError(t, err)
+
+	// On disk only the compressed form exists and it is smaller
+	assert.NoFileExists(t, filepath.Join(testDir, "dir", "report.txt"))
+	raw, err := os.ReadFile(filepath.Join(testDir, "dir", "report.txt.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x1f, 0x8b}, raw[:2], "expected gzip magic bytes")
+	assert.Less(t, len(raw), len(testContent))
+
+	// Clients see the original name and logical size
+	info, err := backend.StatFile("dir/report.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "report.txt", info.Name)
+	assert.Equal(t, int64(len(testContent)), info.Size)
+
+	files, err := backend.ListFiles("dir", false)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "report.txt", files[0].Name)
+	assert.Equal(t, int64(len(testContent)), files[0].Size)
+
+	// Full and ranged reads return the original content
+	reader, err := backend.GetFile("dir/report.txt", 0, 0)
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	assert.Equal(t, testContent, string(content))
+
+	reader, err = backend.GetFile("dir/report.txt", 21, 10)
+	require.NoError(t, err)
+	content, err = io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	assert.Equal(t, testContent[21:31], string(content))
+
+	// Rename and delete operate on the compressed file
+	require.NoError(t, backend.CopyFile("dir/report.txt", "dir/renamed.txt", true))
+	assert.FileExists(t, filepath.Join(testDir, "dir", "renamed.txt.gz"))
+	assert.NoFileExists(t, filepath.Join(testDir, "dir", "report.txt.gz"))
+
+	require.NoError(t, backend.RemoveFile("dir/renamed.txt"))
+	assert.NoFileExists(t, filepath.Join(testDir, "dir", "renamed.txt.gz"))
+}
+
+func TestFilesystemBackend_CompressAtRest_ReadsExistingPlainFiles(t *testing.T) {
+	testDir := createTestDir(t)
+	backend := createCompressedTestBackend(t, testDir)
+
+	testContent := "written before compression was enabled"
+	err := os.WriteFile(filepath.Join(testDir, "legacy.txt"), []byte(testContent), 0644)
+	require.NoError(t, err)
+
+	reader, err := backend.GetFile("legacy.txt", 0, 0)
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	assert.Equal(t, testContent, string(content))
+
+	// Overwriting replaces the plain file with a compressed one
+	require.NoError(t, backend.PutFile("legacy.txt", strings.NewReader("new"), 3))
+	assert.NoFileExists(t, filepath.Join(testDir, "legacy.txt"))
+	assert.FileExists(t, filepath.Join(testDir, "legacy.txt.gz"))
+}
+
+func TestFilesystemBackend_Symlinks(t *testing.T) {
+	testDir := createTestDir(t)
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "target.txt"), []byte("linked content"), 0644))
+	require.NoError(t, os.Symlink("target.txt", filepath.Join(testDir, "link.txt")))
+	require.NoError(t, os.Symlink("missing.txt", filepath.Join(testDir, "dangling.txt")))
+
+	findFile := func(files []FileInfo, name string) FileInfo {
+		for _, file := range files {
+			if file.Name == name {
+				return file
+			}
+		}
+		t.Fatalf("%s not listed", name)
+		return FileInfo{}
+	}
+
+	t.Run("not followed", func(t *testing.T) {
+		backend := createTestBackend(t, testDir, false)
+
+		files, err := backend.ListFiles("/", false)
+		require.NoError(t, err)
+		link := findFile(files, "link.txt")
+		assert.True(t, link.IsSymlink)
+		assert.Equal(t, "target.txt", link.LinkTarget)
+		assert.False(t, findFile(files, "target.txt").IsSymlink)
+
+		info, err := backend.StatFile("link.txt")
+		require.NoError(t, err)
+		assert.True(t, info.IsSymlink)
+		assert.Equal(t, "target.txt", info.LinkTarget)
+	})
+
+	t.Run("followed", func(t *testing.T) {
+		backendCR := &ftpv1.FilesystemBackend{
+			Spec: ftpv1.FilesystemBackendSpec{BasePath: testDir, FollowSymlinks: true},
+		}
+		backend, err := NewFilesystemBackend(backendCR, fake.NewClientBuilder().Build())
+		require.NoError(t, err)
+
+		files, err := backend.ListFiles("/", false)
+		require.NoError(t, err)
+		link := findFile(files, "link.txt")
+		assert.False(t, link.IsSymlink)
+		assert.Equal(t, int64(len("linked content")), link.Size)
+
+		// Links with no target are still reported as links
+		dangling := findFile(files, "dangling.txt")
+		assert.True(t, dangling.IsSymlink)
+		assert.Equal(t, "missing.txt", dangling.LinkTarget)
+
+		info, err := backend.StatFile("link.txt")
+		require.NoError(t, err)
+		assert.False(t, info.IsSymlink)
+		assert.Equal(t, int64(len("linked content")), info.Size)
+	})
+}
+
 func TestFilesystemBackend_PathSecurity(t *testing.T) {
 	testDir := createTestDir(t)
 	backend := createTestBackend(t, testDir, false)
@@ -508,7 +749,7 @@ func TestFilesystemBackendImpl_WriteToTempFile(t *testing.T) {
 	testContent := "Hello, temporary file!"
 	reader := strings.NewReader(testContent)
 
-	bytesWritten, err := backend.writeToTempFile(tempFile.Name(), reader)
+	bytesWritten, err := backend.writeToTempFile(tempFile.Name(), reader, false)
 
 	assert.NoError(t, err)
 	assert.Equal(t, int64(len(testContent)), bytesWritten)
@@ -518,3 +759,46 @@ func TestFilesystemBackendImpl_WriteToTempFile(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, testContent, string(content))
 }
+
+func TestFilesystemBackend_CompressAtRest_ServesOtherGzipFilesAsIs(t *testing.T) {
+	testDir := createTestDir(t)
+	backend := createCompressedTestBackend(t, testDir)
+
+	// A .gz file that was already on disk is not one the backend compressed
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	_, err := gz.Write([]byte("archived log lines"))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "app.log.gz"), archive.Bytes(), 0644))
+
+	files, err := backend.ListFiles("/", false)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "app.log.gz", files[0].Name)
+	assert.Equal(t, int64(archive.Len()), files[0].Size)
+
+	_, err = backend.StatFile("app.log")
+	assert.Error(t, err, "the archive must not be served under the stripped name")
+
+	reader, err := backend.GetFile("app.log.gz", 0, 0)
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	assert.Equal(t, archive.Bytes(), content)
+}
+
+func TestCompressedLogicalSize_Beyond4GiB(t *testing.T) {
+	// The size recorded in the marker is not limited to 32 bits like the gzip trailer
+	path := filepath.Join(createTestDir(t), "large.bin.gz")
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Extra = append(slices.Clone(compressedMarker), binary.LittleEndian.AppendUint64(nil, 5<<30)...)
+	require.NoError(t, gz.Close())
+	require.NoError(t, os.WriteFile(path, compressed.Bytes(), 0644))
+
+	size, ok := compressedLogicalSize(path)
+	require.True(t, ok)
+	assert.Equal(t, int64(5<<30), size)
+}
@@ -0,0 +1,249 @@
+package backends
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/secsy/goftp"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+// defaultFtpTimeout bounds remote commands when TimeoutSeconds is unset
+const defaultFtpTimeout = 30 * time.Second
+
+// ftpClient is the subset of the goftp client used by the FTP backend, so
+// tests can stand in for the remote server
+type ftpClient interface {
+	Getwd() (string, error)
+	Stat(path string) (os.FileInfo, error)
+	ReadDir(path string) ([]os.FileInfo, error)
+	Retrieve(path string, dest io.Writer) error
+	Store(path string, src io.Reader) error
+	Delete(path string) error
+	Rename(from, to string) error
+	Mkdir(path string) (string, error)
+	Rmdir(path string) error
+	Close() error
+}
+
+// ftpBackendImpl implements FtpBackend by translating each call to a command
+// on the remote server
+type ftpBackendImpl struct {
+	client   ftpClient
+	basePath string
+}
+
+// newFtpBackendImpl logs in to the remote server described by an FtpBackend
+func newFtpBackendImpl(ctx context.Context, backend *ftpv1.FtpBackend, kubeClient client.Client) (FtpBackend, error) {
+	username, password, err := getFtpCredentialsFromSecret(ctx, &backend.Spec.Credentials.UseSecret, backend.Namespace, kubeClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials from secret: %w", err)
+	}
+	return dialFtpBackend(ctx, backend, username, password, kubeClient)
+}
+
+// dialFtpBackend logs in to the remote server with the given credentials.
+// kubeClient is only used to load a CA bundle from tls.caSecretRef.
+func dialFtpBackend(ctx context.Context, backend *ftpv1.FtpBackend, username, password string, kubeClient client.Client) (FtpBackend, error) {
+	timeout := defaultFtpTimeout
+	if backend.Spec.TimeoutSeconds > 0 {
+		timeout = time.Duration(backend.Spec.TimeoutSeconds) * time.Second
+	}
+	config := goftp.Config{
+		User:     username,
+		Password: password,
+		Timeout:  timeout,
+	}
+
+	if tlsSpec := backend.Spec.TLS; tlsSpec != nil && tlsSpec.Mode != "none" {
+		tlsConfig, err := buildTLSConfig(ctx, tlsSpec.InsecureSkipVerify, tlsSpec.CACert, tlsSpec.CASecretRef, backend.Namespace, kubeClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure TLS: %w", err)
+		}
+		tlsConfig.ServerName = backend.Spec.Host
+		config.TLSConfig = tlsConfig
+		config.TLSMode = goftp.TLSExplicit
+		if tlsSpec.Mode == "implicit" {
+			config.TLSMode = goftp.TLSImplicit
+		}
+	}
+
+	port := backend.Spec.Port
+	if port == 0 {
+		port = 21
+	}
+	remote, err := goftp.DialConfig(config, net.JoinHostPort(backend.Spec.Host, strconv.Itoa(int(port))))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to FTP server: %w", err)
+	}
+
+	// goftp connects lazily; PWD confirms the server is reachable and the login works
+	if _, err := remote.Getwd(); err != nil {
+		_ = remote.Close()
+		return nil, fmt.Errorf("failed to log in to FTP server: %w", err)
+	}
+
+	return &ftpBackendImpl{
+		client:   remote,
+		basePath: backend.Spec.BasePath,
+	}, nil
+}
+
+// getFtpCredentialsFromSecret retrieves remote FTP credentials from a Kubernetes Secret
+func getFtpCredentialsFromSecret(ctx context.Context, secretRef *ftpv1.FtpSecretRef, backendNamespace string, kubeClient client.Client) (string, string, error) {
+	secretNamespace := backendNamespace
+	if secretRef.Namespace != nil && *secretRef.Namespace != "" {
+		secretNamespace = *secretRef.Namespace
+	}
+
+	secret := &corev1.Secret{}
+	err := kubeClient.Get(ctx, client.ObjectKey{
+		Name:      secretRef.Name,
+		Namespace: secretNamespace,
+	}, secret)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get secret %s/%s: %w", secretNamespace, secretRef.Name, err)
+	}
+	return ftpCredentialsFromSecret(secretRef, secret)
+}
+
+// ftpCredentialsFromSecret reads the username and password keys named by
+// secretRef from an already loaded Secret
+func ftpCredentialsFromSecret(secretRef *ftpv1.FtpSecretRef, secret *corev1.Secret) (string, string, error) {
+	usernameKey := secretRef.UsernameKey
+	if usernameKey == "" {
+		usernameKey = "username"
+	}
+	passwordKey := secretRef.PasswordKey
+	if passwordKey == "" {
+		passwordKey = "password"
+	}
+
+	username, exists := secret.Data[usernameKey]
+	if !exists {
+		return "", "", fmt.Errorf("username not found in secret with key %s", usernameKey)
+	}
+
+	password, exists := secret.Data[passwordKey]
+	if !exists {
+		return "", "", fmt.Errorf("password not found in secret with key %s", passwordKey)
+	}
+
+	return string(username), string(password), nil
+}
+
+// Stat returns file/directory information
+func (f *ftpBackendImpl) Stat(filePath string) (*FileInfo, error) {
+	info, err := f.client.Stat(f.getFullPath(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", filePath, err)
+	}
+	return ftpFileInfo(info), nil
+}
+
+// Open streams a file from the remote server. The transfer runs until the
+// returned reader is drained or closed.
+func (f *ftpBackendImpl) Open(filePath string) (io.ReadCloser, error) {
+	fullPath := f.getFullPath(filePath)
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(f.client.Retrieve(fullPath, writer))
+	}()
+	return reader, nil
+}
+
+// WriteFile uploads a file to the remote server, returning the bytes sent
+func (f *ftpBackendImpl) WriteFile(filePath string, reader io.Reader) (int64, error) {
+	counter := &ftpCountingReader{reader: reader}
+	if err := f.client.Store(f.getFullPath(filePath), counter); err != nil {
+		return counter.bytesRead, fmt.Errorf("failed to store %s: %w", filePath, err)
+	}
+	return counter.bytesRead, nil
+}
+
+// Remove deletes a file
+func (f *ftpBackendImpl) Remove(filePath string) error {
+	if err := f.client.Delete(f.getFullPath(filePath)); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// Rename moves a file or directory on the remote server
+func (f *ftpBackendImpl) Rename(oldPath, newPath string) error {
+	if err := f.client.Rename(f.getFullPath(oldPath), f.getFullPath(newPath)); err != nil {
+		return fmt.Errorf("failed to rename %s to %s: %w", oldPath, newPath, err)
+	}
+	return nil
+}
+
+// Mkdir creates a directory
+func (f *ftpBackendImpl) Mkdir(dirPath string) error {
+	if _, err := f.client.Mkdir(f.getFullPath(dirPath)); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dirPath, err)
+	}
+	return nil
+}
+
+// RemoveDir deletes an empty directory
+func (f *ftpBackendImpl) RemoveDir(dirPath string) error {
+	if err := f.client.Rmdir(f.getFullPath(dirPath)); err != nil {
+		return fmt.Errorf("failed to remove directory %s: %w", dirPath, err)
+	}
+	return nil
+}
+
+// ReadDir lists a directory
+func (f *ftpBackendImpl) ReadDir(dirPath string) ([]*FileInfo, error) {
+	entries, err := f.client.ReadDir(f.getFullPath(dirPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dirPath, err)
+	}
+	infos := make([]*FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		infos = append(infos, ftpFileInfo(entry))
+	}
+	return infos, nil
+}
+
+// Close releases the connections to the remote server
+func (f *ftpBackendImpl) Close() error {
+	return f.client.Close()
+}
+
+// getFullPath places a path under the backend's base path on the remote server
+func (f *ftpBackendImpl) getFullPath(filePath string) string {
+	return path.Join("/", f.basePath, filePath)
+}
+
+// ftpFileInfo converts a remote listing entry to a FileInfo
+func ftpFileInfo(info os.FileInfo) *FileInfo {
+	return &FileInfo{
+		Name:    info.Name(),
+		Size:    info.Size(),
+		Mode:    info.Mode(),
+		ModTime: info.ModTime(),
+		IsDir:   info.IsDir(),
+	}
+}
+
+// ftpCountingReader counts the bytes handed to the remote server
+type ftpCountingReader struct {
+	reader    io.Reader
+	bytesRead int64
+}
+
+func (r *ftpCountingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.bytesRead += int64(n)
+	return n, err
+}
@@ -0,0 +1,190 @@
+package backends
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeFtpClient is an in-memory remote FTP server
+type fakeFtpClient struct {
+	files  map[string][]byte
+	dirs   map[string]bool
+	closed bool
+}
+
+func newFakeFtpClient() *fakeFtpClient {
+	return &fakeFtpClient{files: map[string][]byte{}, dirs: map[string]bool{"/": true}}
+}
+
+// fakeFtpFileInfo describes an entry of fakeFtpClient
+type fakeFtpFileInfo struct {
+	name  string
+	size  int64
+	isDir bool
+}
+
+func (fi fakeFtpFileInfo) Name() string { return fi.name }
+func (fi fakeFtpFileInfo) Size() int64  { return fi.size }
+func (fi fakeFtpFileInfo) Mode() os.FileMode {
+	if fi.isDir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+func (fi fakeFtpFileInfo) ModTime() time.Time { return time.Time{} }
+func (fi fakeFtpFileInfo) IsDir() bool        { return fi.isDir }
+func (fi fakeFtpFileInfo) Sys() interface{}   { return nil }
+
+func (c *fakeFtpClient) Getwd() (string, error) { return "/", nil }
+
+func (c *fakeFtpClient) Stat(p string) (os.FileInfo, error) {
+	if data, ok := c.files[p]; ok {
+		return fakeFtpFileInfo{name: path.Base(p), size: int64(len(data))}, nil
+	}
+	if c.dirs[p] {
+		return fakeFtpFileInfo{name: path.Base(p), isDir: true}, nil
+	}
+	return nil, errors.New("550 not found")
+}
+
+func (c *fakeFtpClient) ReadDir(p string) ([]os.FileInfo, error) {
+	if !c.dirs[p] {
+		return nil, errors.New("550 not found")
+	}
+	var entries []os.FileInfo
+	for name, data := range c.files {
+		if path.Dir(name) == p {
+			entries = append(entries, fakeFtpFileInfo{name: path.Base(name), size: int64(len(data))})
+		}
+	}
+	for name := range c.dirs {
+		if name != p && path.Dir(name) == p {
+			entries = append(entries, fakeFtpFileInfo{name: path.Base(name), isDir: true})
+		}
+	}
+	return entries, nil
+}
+
+func (c *fakeFtpClient) Retrieve(p string, dest io.Writer) error {
+	data, ok := c.files[p]
+	if !ok {
+		return errors.New("550 not found")
+	}
+	_, err := dest.Write(data)
+	return err
+}
+
+func (c *fakeFtpClient) Store(p string, src io.Reader) error {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return err
+	}
+	c.files[p] = data
+	return nil
+}
+
+func (c *fakeFtpClient) Delete(p string) error {
+	if _, ok := c.files[p]; !ok {
+		return errors.New("550 not found")
+	}
+	delete(c.files, p)
+	return nil
+}
+
+func (c *fakeFtpClient) Rename(from, to string) error {
+	data, ok := c.files[from]
+	if !ok {
+		return errors.New("550 not found")
+	}
+	delete(c.files, from)
+	c.files[to] = data
+	return nil
+}
+
+func (c *fakeFtpClient) Mkdir(p string) (string, error) {
+	c.dirs[p] = true
+	return p, nil
+}
+
+func (c *fakeFtpClient) Rmdir(p string) error {
+	if !c.dirs[p] {
+		return errors.New("550 not found")
+	}
+	delete(c.dirs, p)
+	return nil
+}
+
+func (c *fakeFtpClient) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestFtpBackend_Operations(t *testing.T) {
+	remote := newFakeFtpClient()
+	remote.dirs["/export"] = true
+	backend := &ftpBackendImpl{client: remote, basePath: "/export"}
+
+	// put
+	n, err := backend.WriteFile("/report.csv", strings.NewReader("a,b\n"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+	assert.Equal(t, []byte("a,b\n"), remote.files["/export/report.csv"], "paths are placed under the base path")
+
+	// stat
+	info, err := backend.Stat("/report.csv")
+	require.NoError(t, err)
+	assert.Equal(t, "report.csv", info.Name)
+	assert.Equal(t, int64(4), info.Size)
+	assert.False(t, info.IsDir)
+
+	_, err = backend.Stat("/missing.csv")
+	assert.Error(t, err)
+
+	// list
+	require.NoError(t, backend.Mkdir("/archive"))
+	entries, err := backend.ReadDir("/")
+	require.NoError(t, err)
+	names := map[string]bool{}
+	for _, entry := range entries {
+		names[entry.Name] = entry.IsDir
+	}
+	assert.Equal(t, map[string]bool{"report.csv": false, "archive": true}, names)
+
+	// get
+	reader, err := backend.Open("/report.csv")
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	_, err = io.Copy(&buf, reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	assert.Equal(t, "a,b\n", buf.String())
+
+	reader, err = backend.Open("/missing.csv")
+	require.NoError(t, err)
+	_, err = io.ReadAll(reader)
+	assert.Error(t, err, "a failed retrieve surfaces when reading")
+
+	// rename
+	require.NoError(t, backend.Rename("/report.csv", "/archive/report.csv"))
+	assert.Contains(t, remote.files, "/export/archive/report.csv")
+	assert.NotContains(t, remote.files, "/export/report.csv")
+
+	// delete
+	require.NoError(t, backend.Remove("/archive/report.csv"))
+	assert.Empty(t, remote.files)
+	assert.Error(t, backend.Remove("/archive/report.csv"))
+	require.NoError(t, backend.RemoveDir("/archive"))
+	assert.NotContains(t, remote.dirs, "/export/archive")
+
+	require.NoError(t, backend.Close())
+	assert.True(t, remote.closed)
+}
@@ -2,10 +2,12 @@ package backends
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"io/fs"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
@@ -18,6 +20,16 @@ type ObjectInfo struct {
 	LastModified time.Time
 	ETag         string
 	ContentType  string
+	// Tags are the object's tags. Listings only report them when the backend
+	// requires an object tag.
+	Tags map[string]string
+}
+
+// UploadedPart describes a completed part of a multipart upload
+type UploadedPart struct {
+	PartNumber int
+	ETag       string
+	Size       int64
 }
 
 // FileInfo represents file/directory information
@@ -27,6 +39,15 @@ type FileInfo struct {
 	Mode    fs.FileMode
 	ModTime time.Time
 	IsDir   bool
+	// IsSymlink is set for symbolic links that are listed rather than followed
+	IsSymlink bool
+	// LinkTarget is the target of a symbolic link, as stored in the link
+	LinkTarget string
+	// Owner and Group name the file's on-disk owner, when the backend knows it
+	Owner string
+	Group string
+	// Nlink is the file's hard link count, or 0 when the backend doesn't know it
+	Nlink uint64
 }
 
 // MinioBackend interface for MinIO operations
@@ -39,6 +60,12 @@ type MinioBackend interface {
 	RemoveObjects(prefix string, recursive bool) error
 	CopyObject(srcObject, dstObject string, deleteSource bool) error
 
+	// Multipart operations, used to resume interrupted uploads
+	NewMultipartUpload(objectName string) (string, error)
+	UploadPart(objectName, uploadID string, partNumber int, reader io.Reader, size int64) (UploadedPart, error)
+	CompleteMultipartUpload(objectName, uploadID string, parts []UploadedPart) error
+	AbortMultipartUpload(objectName, uploadID string) error
+
 	// Directory operations
 	ListObjects(prefix string, recursive bool) ([]*ObjectInfo, error)
 }
@@ -59,6 +86,24 @@ type WebDavBackend interface {
 	ReadDir(path string) ([]*FileInfo, error)
 }
 
+// FtpBackend interface for operations proxied to a remote FTP server
+type FtpBackend interface {
+	// File operations
+	Stat(path string) (*FileInfo, error)
+	Open(path string) (io.ReadCloser, error)
+	WriteFile(path string, reader io.Reader) (int64, error)
+	Remove(path string) error
+	Rename(oldPath, newPath string) error
+
+	// Directory operations
+	Mkdir(path string) error
+	RemoveDir(path string) error
+	ReadDir(path string) ([]*FileInfo, error)
+
+	// Close releases the connections to the remote server
+	Close() error
+}
+
 // NewMinioBackend creates a new MinIO backend from a MinioBackend CRD
 func NewMinioBackend(ctx context.Context, backend *ftpv1.MinioBackend, kubeClient client.Client) (MinioBackend, error) {
 	return newMinioBackendImpl(ctx, backend, kubeClient)
@@ -68,3 +113,20 @@ func NewMinioBackend(ctx context.Context, backend *ftpv1.MinioBackend, kubeClien
 func NewWebDavBackend(ctx context.Context, backend *ftpv1.WebDavBackend, kubeClient client.Client) (WebDavBackend, error) {
 	return newWebDavBackendImpl(ctx, backend, kubeClient)
 }
+
+// NewFtpBackend creates a new FTP backend from an FtpBackend CRD
+func NewFtpBackend(ctx context.Context, backend *ftpv1.FtpBackend, kubeClient client.Client) (FtpBackend, error) {
+	return newFtpBackendImpl(ctx, backend, kubeClient)
+}
+
+// NewFtpBackendFromSecret creates a new FTP backend from an FtpBackend CRD,
+// reading the credentials from the given Secret instead of fetching the one
+// referenced by credentials.useSecret. It is used where the Kubernetes API is
+// not available, so a CA bundle must be inline in tls.caCert.
+func NewFtpBackendFromSecret(ctx context.Context, backend *ftpv1.FtpBackend, secret *corev1.Secret) (FtpBackend, error) {
+	username, password, err := ftpCredentialsFromSecret(&backend.Spec.Credentials.UseSecret, secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials from secret: %w", err)
+	}
+	return dialFtpBackend(ctx, backend, username, password, nil)
+}
@@ -2,25 +2,39 @@ package backends
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
 )
 
+// ErrUploadVerification is returned when an uploaded object's stored size does
+// not match the bytes that were sent. The object has been removed.
+var ErrUploadVerification = errors.New("upload verification failed")
+
 // minioBackendImpl implements MinioBackend interface using minio-go client
 type minioBackendImpl struct {
-	client     *minio.Client
-	bucket     string
-	pathPrefix string
+	client             *minio.Client
+	bucket             string
+	pathPrefix         string
+	sse                encrypt.ServerSide            // nil when server-side encryption is not requested
+	presign            *presignedDownloads           // nil unless PresignedDownloads is set
+	storageClass       string                        // requested for uploads no rule matches; empty keeps the bucket default
+	storageClassRules  []ftpv1.MinioStorageClassRule // per-prefix overrides of storageClass
+	multipartThreshold int64                         // known-size uploads below this use a single PUT; 0 leaves it to the client
+	partSize           uint64                        // multipart part size; 0 uses the client default
+	objectTags         map[string]string             // tags added to uploads; nil unless RequiredObjectTag is set
 }
 
 // newMinioBackendImpl creates a new MinIO backend implementation
@@ -46,11 +60,20 @@ func newMinioBackendImpl(ctx context.Context, backend *ftpv1.MinioBackend, kubeC
 	// Configure TLS if specified
 	var transport *http.Transport
 	if backend.Spec.TLS != nil {
+		caCert := backend.Spec.TLS.CACert
+		caSecretRef := backend.Spec.TLS.CASecretRef
+		if backend.Spec.TLS.CABundleRef != nil {
+			bundle, err := loadCABundle(ctx, backend.Spec.TLS.CABundleRef, backend.Namespace, kubeClient)
+			if err != nil {
+				return nil, fmt.Errorf("failed to build TLS config: %w", err)
+			}
+			caCert, caSecretRef = string(bundle), nil
+		}
 		tlsConfig, err := buildTLSConfig(
 			ctx,
 			backend.Spec.TLS.InsecureSkipVerify,
-			backend.Spec.TLS.CACert,
-			backend.Spec.TLS.CASecretRef,
+			caCert,
+			caSecretRef,
 			backend.Namespace,
 			kubeClient,
 		)
@@ -67,6 +90,19 @@ func newMinioBackendImpl(ctx context.Context, backend *ftpv1.MinioBackend, kubeC
 		}
 	}
 
+	// Bound dialing the endpoint so an unreachable backend fails promptly
+	connectTimeout := time.Duration(backend.Spec.ConnectTimeoutSeconds) * time.Second
+	if connectTimeout > 0 {
+		if transport == nil {
+			defaultTransport, err := minio.DefaultTransport(useSSL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create MinIO transport: %w", err)
+			}
+			transport = defaultTransport
+		}
+		transport.DialContext = (&net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}).DialContext
+	}
+
 	// Create MinIO client
 	var minioClient *minio.Client
 	var err error
@@ -90,17 +126,102 @@ func newMinioBackendImpl(ctx context.Context, backend *ftpv1.MinioBackend, kubeC
 		return nil, fmt.Errorf("failed to create MinIO client: %w", err)
 	}
 
-	// Test connection
-	_, err = minioClient.BucketExists(ctx, backend.Spec.Bucket)
+	sse, err := buildServerSideEncryption(backend.Spec.ServerSideEncryption)
 	if err != nil {
+		return nil, err
+	}
+
+	// Test connection, within the connect timeout when one is set
+	checkCtx := ctx
+	if connectTimeout > 0 {
+		var cancel context.CancelFunc
+		checkCtx, cancel = context.WithTimeout(ctx, connectTimeout)
+		defer cancel()
+	}
+	_, err = minioClient.BucketExists(checkCtx, backend.Spec.Bucket)
+	if err != nil {
+		if ctx.Err() == nil && errors.Is(checkCtx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("failed to connect to MinIO bucket %s: no response from %s within %s: %w", backend.Spec.Bucket, endpoint, connectTimeout, err)
+		}
 		return nil, fmt.Errorf("failed to connect to MinIO bucket %s: %w", backend.Spec.Bucket, err)
 	}
 
-	return &minioBackendImpl{
-		client:     minioClient,
-		bucket:     backend.Spec.Bucket,
-		pathPrefix: backend.Spec.PathPrefix,
-	}, nil
+	impl := &minioBackendImpl{
+		client:             minioClient,
+		bucket:             backend.Spec.Bucket,
+		pathPrefix:         backend.Spec.PathPrefix,
+		sse:                sse,
+		storageClass:       backend.Spec.StorageClass,
+		storageClassRules:  backend.Spec.StorageClassRules,
+		multipartThreshold: backend.Spec.MultipartThreshold,
+		partSize:           uint64(backend.Spec.PartSize),
+	}
+	if key, value, ok := backend.Spec.ObjectTag(); ok {
+		impl.objectTags = map[string]string{key: value}
+	}
+	if backend.Spec.PresignedDownloads {
+		impl.presign = newPresignedDownloads(time.Duration(backend.Spec.PresignExpirySeconds)*time.Second, transport)
+	}
+	return impl, nil
+}
+
+// buildServerSideEncryption converts the backend's SSE settings into minio-go options
+func buildServerSideEncryption(config *ftpv1.MinioSSEConfig) (encrypt.ServerSide, error) {
+	if config == nil {
+		return nil, nil
+	}
+	switch config.Type {
+	case "SSE-S3":
+		return encrypt.NewSSE(), nil
+	case "SSE-KMS":
+		sse, err := encrypt.NewSSEKMS(config.KMSKeyID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure SSE-KMS: %w", err)
+		}
+		return sse, nil
+	default:
+		return nil, fmt.Errorf("unsupported server-side encryption type: %s", config.Type)
+	}
+}
+
+// putObjectOptions returns the options applied to the upload of objectName
+func (m *minioBackendImpl) putObjectOptions(objectName string) minio.PutObjectOptions {
+	return minio.PutObjectOptions{ServerSideEncryption: m.sse, StorageClass: m.storageClassFor(objectName), UserTags: m.objectTags}
+}
+
+// uploadOptions returns the options for a PutObject of size bytes, adding the
+// backend's multipart settings. A size of -1 is a stream, which minio-go always
+// sends as a multipart upload of PartSize parts.
+func (m *minioBackendImpl) uploadOptions(objectName string, size int64) minio.PutObjectOptions {
+	opts := m.putObjectOptions(objectName)
+	opts.PartSize = m.partSize
+	if size >= 0 && size < m.multipartThreshold {
+		opts.DisableMultipart = true
+	}
+	return opts
+}
+
+// hasObjectTags reports whether tags include every tag the backend requires
+func (m *minioBackendImpl) hasObjectTags(tags map[string]string) bool {
+	for key, value := range m.objectTags {
+		if got, ok := tags[key]; !ok || got != value {
+			return false
+		}
+	}
+	return true
+}
+
+// storageClassFor returns the storage class of the first rule whose prefix
+// contains objectName, or the backend's default. Leading slashes are ignored
+// so rules match whether or not object keys keep them.
+func (m *minioBackendImpl) storageClassFor(objectName string) string {
+	name := "/" + strings.TrimLeft(objectName, "/")
+	for _, rule := range m.storageClassRules {
+		if strings.HasPrefix(name, "/"+strings.TrimLeft(rule.PathPrefix, "/")) {
+			return rule.StorageClass
+		}
+	}
+	return m.storageClass
 }
 
 // getMinioCredentialsFromSecret retrieves MinIO credentials from a Kubernetes Secret
@@ -162,28 +283,25 @@ func (m *minioBackendImpl) StatObject(objectName string) (*ObjectInfo, error) {
 		LastModified: objInfo.LastModified,
 		ETag:         objInfo.ETag,
 		ContentType:  objInfo.ContentType,
+		Tags:         objInfo.UserTags,
 	}, nil
 }
 
-// GetObject retrieves an object with optional range
+// GetObject retrieves an object with optional range. A nonzero offset or
+// length becomes an HTTP Range request, so only the requested bytes are
+// transferred from MinIO. With presigned downloads the object is fetched
+// through a cached presigned URL.
 func (m *minioBackendImpl) GetObject(objectName string, offset, length int64) (io.ReadCloser, error) {
 	ctx := context.Background()
 	fullPath := m.getFullPath(objectName)
 
-	opts := minio.GetObjectOptions{}
-	if offset > 0 || length > 0 {
-		// Set range if specified
-		if length > 0 {
-			err := opts.SetRange(offset, offset+length-1)
-			if err != nil {
-				return nil, fmt.Errorf("failed to set range: %w", err)
-			}
-		} else {
-			err := opts.SetRange(offset, 0)
-			if err != nil {
-				return nil, fmt.Errorf("failed to set range: %w", err)
-			}
-		}
+	if m.presign != nil {
+		return m.getPresignedObject(ctx, fullPath, objectName, offset, length)
+	}
+
+	opts, err := rangeGetObjectOptions(offset, length)
+	if err != nil {
+		return nil, err
 	}
 
 	reader, err := m.client.GetObject(ctx, m.bucket, fullPath, opts)
@@ -194,13 +312,31 @@ func (m *minioBackendImpl) GetObject(objectName string, offset, length int64) (i
 	return reader, nil
 }
 
+// rangeGetObjectOptions builds GetObject options covering length bytes from
+// offset. A length of zero or less reads to the end of the object.
+func rangeGetObjectOptions(offset, length int64) (minio.GetObjectOptions, error) {
+	opts := minio.GetObjectOptions{}
+	if offset <= 0 && length <= 0 {
+		return opts, nil
+	}
+
+	end := int64(0)
+	if length > 0 {
+		end = offset + length - 1
+	}
+	if err := opts.SetRange(offset, end); err != nil {
+		return opts, fmt.Errorf("failed to set range: %w", err)
+	}
+	return opts, nil
+}
+
 // PutObject uploads an object
 func (m *minioBackendImpl) PutObject(objectName string, reader io.Reader, size int64) error {
 	ctx := context.Background()
 	fullPath := m.getFullPath(objectName)
 
 	// Upload object and get upload info
-	uploadInfo, err := m.client.PutObject(ctx, m.bucket, fullPath, reader, size, minio.PutObjectOptions{})
+	uploadInfo, err := m.client.PutObject(ctx, m.bucket, fullPath, reader, size, m.uploadOptions(objectName, size))
 	if err != nil {
 		return fmt.Errorf("failed to put object %s: %w", objectName, err)
 	}
@@ -215,16 +351,58 @@ func (m *minioBackendImpl) PutObject(objectName string, reader io.Reader, size i
 	if size > 0 && objInfo.Size != size {
 		// Cleanup partial/corrupt object
 		_ = m.client.RemoveObject(ctx, m.bucket, fullPath, minio.RemoveObjectOptions{})
-		return fmt.Errorf("object size verification failed for %s: expected %d, got %d", objectName, size, objInfo.Size)
+		return fmt.Errorf("object size verification failed for %s: expected %d, got %d: %w", objectName, size, objInfo.Size, ErrUploadVerification)
 	}
 
 	// For streaming uploads (size unknown), verify uploaded size matches reported upload info
 	if size <= 0 && uploadInfo.Size != objInfo.Size {
 		// Cleanup inconsistent object
 		_ = m.client.RemoveObject(ctx, m.bucket, fullPath, minio.RemoveObjectOptions{})
-		return fmt.Errorf("streaming upload verification failed for %s: upload reported %d bytes, object size %d", objectName, uploadInfo.Size, objInfo.Size)
+		return fmt.Errorf("streaming upload verification failed for %s: upload reported %d bytes, object size %d: %w", objectName, uploadInfo.Size, objInfo.Size, ErrUploadVerification)
+	}
+
+	return nil
+}
+
+// NewMultipartUpload starts a multipart upload and returns its upload ID
+func (m *minioBackendImpl) NewMultipartUpload(objectName string) (string, error) {
+	core := minio.Core{Client: m.client}
+	uploadID, err := core.NewMultipartUpload(context.Background(), m.bucket, m.getFullPath(objectName), m.putObjectOptions(objectName))
+	if err != nil {
+		return "", fmt.Errorf("failed to start multipart upload for %s: %w", objectName, err)
+	}
+	return uploadID, nil
+}
+
+// UploadPart uploads one part of a multipart upload
+func (m *minioBackendImpl) UploadPart(objectName, uploadID string, partNumber int, reader io.Reader, size int64) (UploadedPart, error) {
+	core := minio.Core{Client: m.client}
+	part, err := core.PutObjectPart(context.Background(), m.bucket, m.getFullPath(objectName), uploadID, partNumber, reader, size, minio.PutObjectPartOptions{})
+	if err != nil {
+		return UploadedPart{}, fmt.Errorf("failed to upload part %d of %s: %w", partNumber, objectName, err)
+	}
+	return UploadedPart{PartNumber: part.PartNumber, ETag: part.ETag, Size: part.Size}, nil
+}
+
+// CompleteMultipartUpload assembles the uploaded parts into the final object
+func (m *minioBackendImpl) CompleteMultipartUpload(objectName, uploadID string, parts []UploadedPart) error {
+	core := minio.Core{Client: m.client}
+	completeParts := make([]minio.CompletePart, 0, len(parts))
+	for _, part := range parts {
+		completeParts = append(completeParts, minio.CompletePart{PartNumber: part.PartNumber, ETag: part.ETag})
+	}
+	if _, err := core.CompleteMultipartUpload(context.Background(), m.bucket, m.getFullPath(objectName), uploadID, completeParts, m.putObjectOptions(objectName)); err != nil {
+		return fmt.Errorf("failed to complete multipart upload for %s: %w", objectName, err)
 	}
+	return nil
+}
 
+// AbortMultipartUpload discards a multipart upload and its parts
+func (m *minioBackendImpl) AbortMultipartUpload(objectName, uploadID string) error {
+	core := minio.Core{Client: m.client}
+	if err := core.AbortMultipartUpload(context.Background(), m.bucket, m.getFullPath(objectName), uploadID); err != nil {
+		return fmt.Errorf("failed to abort multipart upload for %s: %w", objectName, err)
+	}
 	return nil
 }
 
@@ -248,8 +426,9 @@ func (m *minioBackendImpl) RemoveObjects(prefix string, recursive bool) error {
 
 	// List objects with prefix
 	opts := minio.ListObjectsOptions{
-		Prefix:    fullPrefix,
-		Recursive: recursive,
+		Prefix:       fullPrefix,
+		Recursive:    recursive,
+		WithMetadata: m.objectTags != nil,
 	}
 
 	objectsCh := m.client.ListObjects(ctx, m.bucket, opts)
@@ -261,7 +440,8 @@ func (m *minioBackendImpl) RemoveObjects(prefix string, recursive bool) error {
 	go func() {
 		defer close(objectNames)
 		for objInfo := range objectsCh {
-			if objInfo.Err != nil {
+			// Objects without the required tag are not the user's to delete
+			if objInfo.Err != nil || !m.hasObjectTags(objInfo.UserTags) {
 				continue
 			}
 			objectNames <- objInfo
@@ -295,8 +475,9 @@ func (m *minioBackendImpl) CopyObject(srcObject, dstObject string, deleteSource
 	}
 
 	dst := minio.CopyDestOptions{
-		Bucket: m.bucket,
-		Object: fullDstPath,
+		Bucket:     m.bucket,
+		Object:     fullDstPath,
+		Encryption: m.sse,
 	}
 
 	_, err := m.client.CopyObject(ctx, dst, src)
@@ -323,6 +504,8 @@ func (m *minioBackendImpl) ListObjects(prefix string, recursive bool) ([]*Object
 	opts := minio.ListObjectsOptions{
 		Prefix:    fullPrefix,
 		Recursive: recursive,
+		// Tags are only listed with metadata, which MinIO returns at extra cost
+		WithMetadata: m.objectTags != nil,
 	}
 
 	var objects []*ObjectInfo
@@ -342,6 +525,7 @@ func (m *minioBackendImpl) ListObjects(prefix string, recursive bool) ([]*Object
 			LastModified: objInfo.LastModified,
 			ETag:         objInfo.ETag,
 			ContentType:  objInfo.ContentType,
+			Tags:         objInfo.UserTags,
 		})
 	}
 
@@ -0,0 +1,108 @@
+package backends
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// defaultPresignExpiry is used when PresignExpirySeconds is unset
+const defaultPresignExpiry = 15 * time.Minute
+
+// presignedDownloads fetches objects through presigned GET URLs. Each URL is
+// cached and reused until half its validity has passed, so repeated downloads
+// of an object skip request signing.
+type presignedDownloads struct {
+	expiry     time.Duration
+	httpClient *http.Client
+
+	mu   sync.Mutex
+	urls map[string]presignedURL // object key -> cached URL
+}
+
+// presignedURL is a cached presigned GET URL
+type presignedURL struct {
+	url     *url.URL
+	renewAt time.Time
+}
+
+// newPresignedDownloads returns a presigned download cache. A nil transport
+// uses http.DefaultTransport.
+func newPresignedDownloads(expiry time.Duration, transport *http.Transport) *presignedDownloads {
+	if expiry <= 0 {
+		expiry = defaultPresignExpiry
+	}
+	httpClient := &http.Client{}
+	if transport != nil {
+		httpClient.Transport = transport
+	}
+	return &presignedDownloads{
+		expiry:     expiry,
+		httpClient: httpClient,
+		urls:       make(map[string]presignedURL),
+	}
+}
+
+// presignedGetURL returns a cached presigned URL for the object, presigning a
+// new one when none is cached or the cached one is due for renewal
+func (m *minioBackendImpl) presignedGetURL(ctx context.Context, fullPath string) (*url.URL, error) {
+	p := m.presign
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if cached, ok := p.urls[fullPath]; ok && time.Now().Before(cached.renewAt) {
+		return cached.url, nil
+	}
+
+	u, err := m.client.PresignedGetObject(ctx, m.bucket, fullPath, p.expiry, nil)
+	if err != nil {
+		return nil, err
+	}
+	p.urls[fullPath] = presignedURL{url: u, renewAt: time.Now().Add(p.expiry / 2)}
+	return u, nil
+}
+
+// forget drops a cached URL the server refused, so the next fetch presigns again
+func (p *presignedDownloads) forget(fullPath string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.urls, fullPath)
+}
+
+// getPresignedObject fetches an object, or the requested range of it, with a
+// plain HTTP GET of its presigned URL
+func (m *minioBackendImpl) getPresignedObject(ctx context.Context, fullPath, objectName string, offset, length int64) (io.ReadCloser, error) {
+	u, err := m.presignedGetURL(ctx, fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign object %s: %w", objectName, err)
+	}
+
+	opts, err := rangeGetObjectOptions(offset, length)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", objectName, err)
+	}
+	if rangeHeader := opts.Header().Get("Range"); rangeHeader != "" {
+		req.Header.Set("Range", rangeHeader)
+	}
+
+	resp, err := m.presign.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", objectName, err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		_ = resp.Body.Close()
+		if resp.StatusCode == http.StatusForbidden {
+			m.presign.forget(fullPath)
+		}
+		return nil, fmt.Errorf("failed to get object %s: presigned GET returned %s", objectName, resp.Status)
+	}
+	return resp.Body, nil
+}
@@ -2,8 +2,19 @@ package backends
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -442,6 +453,56 @@ func TestNewMinioBackend_InvalidCredentials(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to get credentials from secret")
 }
 
+func TestNewMinioBackend_ConnectTimeout(t *testing.T) {
+	// An endpoint that accepts connections but never answers
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	var mu sync.Mutex
+	var conns []net.Conn
+	defer func() {
+		_ = listener.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+	}()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+		}
+	}()
+
+	backend := &ftpv1.MinioBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-backend", Namespace: "test-namespace"},
+		Spec: ftpv1.MinioBackendSpec{
+			Endpoint:              "http://" + listener.Addr().String(),
+			Bucket:                "test-bucket",
+			ConnectTimeoutSeconds: 1,
+			Credentials: ftpv1.MinioCredentials{
+				AccessKeyID:     "test-access-key",
+				SecretAccessKey: "test-secret-key",
+			},
+		},
+	}
+
+	start := time.Now()
+	minioBackend, err := NewMinioBackend(context.Background(), backend, nil)
+	elapsed := time.Since(start)
+
+	require.Error(t, err)
+	assert.Nil(t, minioBackend)
+	assert.Less(t, elapsed, 5*time.Second, "the bucket check should give up after the connect timeout")
+	assert.Contains(t, err.Error(), "failed to connect to MinIO bucket test-bucket")
+	assert.Contains(t, err.Error(), "no response from "+listener.Addr().String()+" within 1s")
+}
+
 // Regression test for the recent MinIO empty directory fix
 func TestMinioBackend_EmptyDirectoryRegression(t *testing.T) {
 	// This test verifies the fix for empty directory handling (commit 4da8db3)
@@ -513,3 +574,401 @@ func TestMinioBackend_PathPrefixHandling(t *testing.T) {
 	// Verify that we get to the connection phase (credentials were processed correctly)
 	assert.Contains(t, err.Error(), "failed to connect to MinIO bucket")
 }
+
+// newRecordingS3Server starts a minimal S3 endpoint that accepts single-part
+// uploads and records the headers of each PUT request.
+func newRecordingS3Server(t *testing.T) (*httptest.Server, func() []http.Header) {
+	var mu sync.Mutex
+	var puts []http.Header
+	sizes := map[string]int{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			puts = append(puts, r.Header.Clone())
+			size := len(body)
+			// Plain-HTTP uploads use aws-chunked encoding; report the decoded size
+			if decoded, err := strconv.Atoi(r.Header.Get("X-Amz-Decoded-Content-Length")); err == nil {
+				size = decoded
+			}
+			sizes[r.URL.Path] = size
+			w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+			w.WriteHeader(http.StatusOK)
+		case http.MethodHead:
+			w.Header().Set("Content-Length", strconv.Itoa(sizes[r.URL.Path]))
+			w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+			w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []http.Header {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]http.Header(nil), puts...)
+	}
+}
+
+func TestMinioBackend_PutObjectServerSideEncryption(t *testing.T) {
+	tests := []struct {
+		name       string
+		sse        *ftpv1.MinioSSEConfig
+		wantSSE    string
+		wantKMSKey string
+	}{
+		{name: "no SSE by default"},
+		{name: "SSE-S3", sse: &ftpv1.MinioSSEConfig{Type: "SSE-S3"}, wantSSE: "AES256"},
+		{name: "SSE-KMS with key", sse: &ftpv1.MinioSSEConfig{Type: "SSE-KMS", KMSKeyID: "ftp-uploads"}, wantSSE: "aws:kms", wantKMSKey: "ftp-uploads"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, putHeaders := newRecordingS3Server(t)
+
+			client, err := minio.New(strings.TrimPrefix(server.URL, "http://"), &minio.Options{
+				Creds:  credentials.NewStaticV4("test-access", "test-secret", ""),
+				Region: "us-east-1",
+			})
+			require.NoError(t, err)
+
+			sse, err := buildServerSideEncryption(tt.sse)
+			require.NoError(t, err)
+			backend := &minioBackendImpl{client: client, bucket: "test-bucket", sse: sse}
+
+			content := "encrypted content"
+			require.NoError(t, backend.PutObject("report.txt", strings.NewReader(content), int64(len(content))))
+
+			headers := putHeaders()
+			require.Len(t, headers, 1)
+			assert.Equal(t, tt.wantSSE, headers[0].Get("X-Amz-Server-Side-Encryption"))
+			assert.Equal(t, tt.wantKMSKey, headers[0].Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"))
+		})
+	}
+}
+
+func TestMinioBackend_PutObjectStorageClass(t *testing.T) {
+	rules := []ftpv1.MinioStorageClassRule{
+		{PathPrefix: "/archive/", StorageClass: "GLACIER"},
+		{PathPrefix: "reports", StorageClass: "STANDARD_IA"},
+	}
+
+	tests := []struct {
+		name         string
+		defaultClass string
+		object       string
+		wantClass    string
+	}{
+		{name: "bucket default when unset", object: "/upload.txt"},
+		{name: "backend default", defaultClass: "REDUCED_REDUNDANCY", object: "/upload.txt", wantClass: "REDUCED_REDUNDANCY"},
+		{name: "prefix rule overrides default", defaultClass: "REDUCED_REDUNDANCY", object: "/archive/2024/data.csv", wantClass: "GLACIER"},
+		{name: "rule matches without leading slash", defaultClass: "REDUCED_REDUNDANCY", object: "reports/q1.pdf", wantClass: "STANDARD_IA"},
+		{name: "similar prefix falls back to default", defaultClass: "REDUCED_REDUNDANCY", object: "/archived.txt", wantClass: "REDUCED_REDUNDANCY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, putHeaders := newRecordingS3Server(t)
+
+			client, err := minio.New(strings.TrimPrefix(server.URL, "http://"), &minio.Options{
+				Creds:  credentials.NewStaticV4("test-access", "test-secret", ""),
+				Region: "us-east-1",
+			})
+			require.NoError(t, err)
+
+			backend := &minioBackendImpl{client: client, bucket: "test-bucket", storageClass: tt.defaultClass, storageClassRules: rules}
+
+			content := "tiered content"
+			require.NoError(t, backend.PutObject(tt.object, strings.NewReader(content), int64(len(content))))
+
+			headers := putHeaders()
+			require.Len(t, headers, 1)
+			assert.Equal(t, tt.wantClass, headers[0].Get("X-Amz-Storage-Class"))
+		})
+	}
+}
+
+// multipartRecorder is a fake S3 server that accepts single and multipart
+// uploads, recording the size of every single PUT and of each part by number
+type multipartRecorder struct {
+	mu         sync.Mutex
+	singlePuts []int
+	parts      map[int]int
+	sizes      map[string]int
+}
+
+func newMultipartS3Server(t *testing.T) (*httptest.Server, *multipartRecorder) {
+	rec := &multipartRecorder{sizes: map[string]int{}}
+	pending := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		query := r.URL.Query()
+		switch {
+		case r.Method == http.MethodPost && query.Has("uploads"):
+			pending = 0
+			fmt.Fprintf(w, "<InitiateMultipartUploadResult><Bucket>test-bucket</Bucket><Key>%s</Key><UploadId>upload-1</UploadId></InitiateMultipartUploadResult>", r.URL.Path)
+		case r.Method == http.MethodPost && query.Has("uploadId"):
+			_, _ = io.Copy(io.Discard, r.Body)
+			rec.sizes[r.URL.Path] = pending
+			fmt.Fprintf(w, `<CompleteMultipartUploadResult><Bucket>test-bucket</Bucket><Key>%s</Key><ETag>"d41d8cd98f00b204e9800998ecf8427e-1"</ETag></CompleteMultipartUploadResult>`, r.URL.Path)
+		case r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			size := len(body)
+			// Plain-HTTP uploads use aws-chunked encoding; report the decoded size
+			if decoded, err := strconv.Atoi(r.Header.Get("X-Amz-Decoded-Content-Length")); err == nil {
+				size = decoded
+			}
+			if partNumber, err := strconv.Atoi(query.Get("partNumber")); err == nil {
+				// Parts of a known-size upload may arrive out of order
+				if rec.parts == nil {
+					rec.parts = map[int]int{}
+				}
+				rec.parts[partNumber] = size
+				pending += size
+			} else {
+				rec.singlePuts = append(rec.singlePuts, size)
+				rec.sizes[r.URL.Path] = size
+			}
+			w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodHead:
+			w.Header().Set("Content-Length", strconv.Itoa(rec.sizes[r.URL.Path]))
+			w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+			w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, rec
+}
+
+func TestMinioBackend_PutObjectMultipart(t *testing.T) {
+	const mib = 1024 * 1024
+
+	tests := []struct {
+		name           string
+		threshold      int64
+		partSize       uint64
+		contentSize    int
+		streamed       bool
+		wantSinglePuts []int
+		wantParts      map[int]int
+	}{
+		{
+			name:        "streamed upload uses configured part size",
+			partSize:    5 * mib,
+			contentSize: 11 * mib,
+			streamed:    true,
+			wantParts:   map[int]int{1: 5 * mib, 2: 5 * mib, 3: 1 * mib},
+		},
+		{
+			name:        "known size above threshold is multipart",
+			threshold:   8 * mib,
+			partSize:    5 * mib,
+			contentSize: 11 * mib,
+			wantParts:   map[int]int{1: 5 * mib, 2: 5 * mib, 3: 1 * mib},
+		},
+		{
+			name:           "known size below threshold is a single PUT",
+			threshold:      8 * mib,
+			partSize:       5 * mib,
+			contentSize:    6 * mib,
+			wantSinglePuts: []int{6 * mib},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, rec := newMultipartS3Server(t)
+
+			client, err := minio.New(strings.TrimPrefix(server.URL, "http://"), &minio.Options{
+				Creds:  credentials.NewStaticV4("test-access", "test-secret", ""),
+				Region: "us-east-1",
+			})
+			require.NoError(t, err)
+
+			backend := &minioBackendImpl{client: client, bucket: "test-bucket", multipartThreshold: tt.threshold, partSize: tt.partSize}
+
+			content := strings.Repeat("x", tt.contentSize)
+			size := int64(len(content))
+			if tt.streamed {
+				size = -1
+			}
+			require.NoError(t, backend.PutObject("/large.bin", strings.NewReader(content), size))
+
+			rec.mu.Lock()
+			defer rec.mu.Unlock()
+			assert.Equal(t, tt.wantSinglePuts, rec.singlePuts)
+			assert.Equal(t, tt.wantParts, rec.parts)
+		})
+	}
+}
+
+func TestRangeGetObjectOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		offset    int64
+		length    int64
+		wantRange string
+	}{
+		{name: "whole object", offset: 0, length: 0, wantRange: ""},
+		{name: "offset to end", offset: 100, length: 0, wantRange: "bytes=100-"},
+		{name: "offset and length", offset: 100, length: 50, wantRange: "bytes=100-149"},
+		{name: "prefix", offset: 0, length: 10, wantRange: "bytes=0-9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := rangeGetObjectOptions(tt.offset, tt.length)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRange, opts.Header().Get("Range"))
+		})
+	}
+}
+
+func TestMinioBackend_GetObjectRangedRequest(t *testing.T) {
+	content := "0123456789abcdefghijklmnopqrstuvwxyz"
+	var mu sync.Mutex
+	var ranges []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		mu.Unlock()
+
+		w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		var start, end int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err != nil {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			w.WriteHeader(http.StatusOK)
+			_, _ = io.WriteString(w, content)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(content)))
+		w.Header().Set("Content-Length", strconv.Itoa(end-start+1))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = io.WriteString(w, content[start:end+1])
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := minio.New(strings.TrimPrefix(server.URL, "http://"), &minio.Options{
+		Creds:  credentials.NewStaticV4("test-access", "test-secret", ""),
+		Region: "us-east-1",
+	})
+	require.NoError(t, err)
+	backend := &minioBackendImpl{client: client, bucket: "test-bucket"}
+
+	reader, err := backend.GetObject("large.bin", 10, 6)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "abcdef", string(data))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.NotEmpty(t, ranges)
+	for _, got := range ranges {
+		assert.Equal(t, "bytes=10-15", got, "every request must be ranged")
+	}
+}
+
+func TestMinioBackend_GetObjectPresigned(t *testing.T) {
+	content := "0123456789abcdefghijklmnopqrstuvwxyz"
+	var mu sync.Mutex
+	var requests []*http.Request
+	forbid := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		mu.Lock()
+		requests = append(requests, r.Clone(context.Background()))
+		refuse := forbid
+		mu.Unlock()
+
+		if refuse {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var start, end int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err != nil {
+			w.WriteHeader(http.StatusOK)
+			_, _ = io.WriteString(w, content)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(content)))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = io.WriteString(w, content[start:end+1])
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := minio.New(strings.TrimPrefix(server.URL, "http://"), &minio.Options{
+		Creds:  credentials.NewStaticV4("test-access", "test-secret", ""),
+		Region: "us-east-1",
+	})
+	require.NoError(t, err)
+	backend := &minioBackendImpl{client: client, bucket: "test-bucket", presign: newPresignedDownloads(time.Minute, nil)}
+
+	read := func(offset, length int64) (string, error) {
+		reader, err := backend.GetObject("large.bin", offset, length)
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = reader.Close() }()
+		data, err := io.ReadAll(reader)
+		return string(data), err
+	}
+
+	data, err := read(0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+	data, err = read(10, 6)
+	require.NoError(t, err)
+	assert.Equal(t, "abcdef", data)
+
+	mu.Lock()
+	require.Len(t, requests, 2)
+	for _, r := range requests {
+		assert.Equal(t, "/test-bucket/large.bin", r.URL.Path)
+		assert.NotEmpty(t, r.URL.Query().Get("X-Amz-Signature"), "fetch must use a presigned URL")
+		assert.Equal(t, "60", r.URL.Query().Get("X-Amz-Expires"))
+		assert.Empty(t, r.Header.Get("Authorization"), "presigned fetches are not signed per request")
+	}
+	assert.Equal(t, requests[0].URL.RawQuery, requests[1].URL.RawQuery, "the presigned URL is reused")
+	assert.Empty(t, requests[0].Header.Get("Range"))
+	assert.Equal(t, "bytes=10-15", requests[1].Header.Get("Range"))
+	forbid = true
+	mu.Unlock()
+
+	// A refused URL is dropped from the cache
+	_, err = read(0, 0)
+	assert.ErrorContains(t, err, "403")
+	backend.presign.mu.Lock()
+	assert.Empty(t, backend.presign.urls)
+	backend.presign.mu.Unlock()
+}
+
+func TestBuildServerSideEncryption_UnsupportedType(t *testing.T) {
+	_, err := buildServerSideEncryption(&ftpv1.MinioSSEConfig{Type: "SSE-C"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported server-side encryption type")
+}
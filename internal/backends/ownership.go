@@ -0,0 +1,63 @@
+package backends
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+// ownerNames and groupNames cache uid and gid lookups, since a listing
+// resolves the same few ids for every entry
+var ownerNames, groupNames sync.Map
+
+// fileOwnership returns the owner and group names of an on-disk file, falling
+// back to the numeric ids when they don't resolve to a name. Both are empty
+// when the platform doesn't report ownership.
+func fileOwnership(info os.FileInfo) (owner, group string) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", ""
+	}
+	return lookupName(&ownerNames, stat.Uid, lookupUserName), lookupName(&groupNames, stat.Gid, lookupGroupName)
+}
+
+// fileLinkCount returns the hard link count of an on-disk file, or 0 when the
+// platform doesn't report it
+func fileLinkCount(info os.FileInfo) uint64 {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0
+	}
+	return uint64(stat.Nlink)
+}
+
+// lookupName resolves id with lookup, remembering the result in cache
+func lookupName(cache *sync.Map, id uint32, lookup func(string) (string, error)) string {
+	if name, ok := cache.Load(id); ok {
+		return name.(string)
+	}
+	name := strconv.FormatUint(uint64(id), 10)
+	if resolved, err := lookup(name); err == nil && resolved != "" {
+		name = resolved
+	}
+	cache.Store(id, name)
+	return name
+}
+
+func lookupUserName(uid string) (string, error) {
+	u, err := user.LookupId(uid)
+	if err != nil {
+		return "", err
+	}
+	return u.Username, nil
+}
+
+func lookupGroupName(gid string) (string, error) {
+	g, err := user.LookupGroupId(gid)
+	if err != nil {
+		return "", err
+	}
+	return g.Name, nil
+}
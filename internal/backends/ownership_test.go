@@ -0,0 +1,60 @@
+package backends
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expectedOwnership returns the names the current process's files are owned by
+func expectedOwnership() (owner, group string) {
+	owner = strconv.Itoa(os.Getuid())
+	if u, err := user.LookupId(owner); err == nil {
+		owner = u.Username
+	}
+	group = strconv.Itoa(os.Getgid())
+	if g, err := user.LookupGroupId(group); err == nil {
+		group = g.Name
+	}
+	return owner, group
+}
+
+func TestFilesystemBackend_Ownership(t *testing.T) {
+	testDir := createTestDir(t)
+	backend := createTestBackend(t, testDir, false)
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "owned.txt"), []byte("data"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(testDir, "reports"), 0755))
+
+	owner, group := expectedOwnership()
+
+	info, err := backend.StatFile("owned.txt")
+	require.NoError(t, err)
+	assert.Equal(t, owner, info.Owner)
+	assert.Equal(t, group, info.Group)
+
+	files, err := backend.ListFiles("", false)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	for _, file := range files {
+		assert.Equal(t, owner, file.Owner, file.Name)
+		assert.Equal(t, group, file.Group, file.Name)
+	}
+
+	// Hard links are counted
+	require.NoError(t, os.Link(filepath.Join(testDir, "owned.txt"), filepath.Join(testDir, "linked.txt")))
+	info, err = backend.StatFile("owned.txt")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), info.Nlink)
+}
+
+func TestLookupName_FallsBackToID(t *testing.T) {
+	var cache sync.Map
+	name := lookupName(&cache, 4242, func(string) (string, error) { return "", os.ErrNotExist })
+	assert.Equal(t, "4242", name)
+}
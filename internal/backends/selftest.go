@@ -0,0 +1,83 @@
+package backends
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// selfTestPrefix names the temporary objects written by the backend self-test
+const selfTestPrefix = ".kubeftpd-selftest-"
+
+// selfTestPayload is the content written and read back by the self-test
+var selfTestPayload = []byte("kubeftpd backend self-test\n")
+
+// selfTestName returns a unique name for a self-test object so concurrent
+// replicas testing the same backend don't collide
+func selfTestName() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return selfTestPrefix + "probe"
+	}
+	return selfTestPrefix + hex.EncodeToString(buf)
+}
+
+// runSelfTest writes the payload, reads it back and deletes it, reporting the
+// first step that fails
+func runSelfTest(name string, write func(string, io.Reader, int64) error,
+	read func(string) (io.ReadCloser, error), remove func(string) error) error {
+	if err := write(name, bytes.NewReader(selfTestPayload), int64(len(selfTestPayload))); err != nil {
+		return fmt.Errorf("self-test write of %s failed: %w", name, err)
+	}
+
+	reader, err := read(name)
+	if err != nil {
+		_ = remove(name)
+		return fmt.Errorf("self-test read of %s failed: %w", name, err)
+	}
+	data, err := io.ReadAll(reader)
+	_ = reader.Close()
+	if err != nil {
+		_ = remove(name)
+		return fmt.Errorf("self-test read of %s failed: %w", name, err)
+	}
+	if !bytes.Equal(data, selfTestPayload) {
+		_ = remove(name)
+		return fmt.Errorf("self-test read of %s returned %d bytes that do not match what was written", name, len(data))
+	}
+
+	if err := remove(name); err != nil {
+		return fmt.Errorf("self-test delete of %s failed: %w", name, err)
+	}
+	return nil
+}
+
+// SelfTestMinio writes, reads back and deletes a temporary object in the
+// backend's bucket to confirm the credentials allow all three
+func SelfTestMinio(backend MinioBackend) error {
+	return runSelfTest(selfTestName(), backend.PutObject,
+		func(name string) (io.ReadCloser, error) { return backend.GetObject(name, 0, -1) },
+		backend.RemoveObject)
+}
+
+// SelfTestWebDav writes, reads back and deletes a temporary file under the
+// backend's base path
+func SelfTestWebDav(backend WebDavBackend) error {
+	return runSelfTest(selfTestName(),
+		func(name string, reader io.Reader, _ int64) error {
+			_, err := backend.WriteFile("/"+name, reader)
+			return err
+		},
+		func(name string) (io.ReadCloser, error) { return backend.Open("/" + name) },
+		func(name string) error { return backend.Remove("/" + name) })
+}
+
+// SelfTestFilesystem writes, reads back and deletes a temporary file in the
+// backend's base path
+func SelfTestFilesystem(backend FilesystemBackend) error {
+	return runSelfTest(selfTestName(), backend.PutFile,
+		func(name string) (io.ReadCloser, error) { return backend.GetFile(name, 0, -1) },
+		backend.RemoveFile)
+}
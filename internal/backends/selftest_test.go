@@ -0,0 +1,26 @@
+package backends
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfTestFilesystem(t *testing.T) {
+	t.Run("writable backend passes and leaves nothing behind", func(t *testing.T) {
+		testDir := createTestDir(t)
+		require.NoError(t, SelfTestFilesystem(createTestBackend(t, testDir, false)))
+
+		entries, err := os.ReadDir(testDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("read-only backend fails on write", func(t *testing.T) {
+		err := SelfTestFilesystem(createTestBackend(t, createTestDir(t), true))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "self-test write")
+	})
+}
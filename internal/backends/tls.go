@@ -64,3 +64,59 @@ func buildTLSConfig(
 
 	return cfg, nil
 }
+
+// loadCABundle reads the PEM-encoded CA bundle from the Secret or ConfigMap
+// caBundleRef points at. backendNamespace is the default namespace for the lookup.
+func loadCABundle(
+	ctx context.Context,
+	caBundleRef *ftpv1.TLSCABundleRef,
+	backendNamespace string,
+	kubeClient client.Client,
+) ([]byte, error) {
+	ns := backendNamespace
+	if caBundleRef.Namespace != nil && *caBundleRef.Namespace != "" {
+		ns = *caBundleRef.Namespace
+	}
+	key := caBundleRef.Key
+	if key == "" {
+		key = "ca.crt"
+	}
+	kind := caBundleRef.Kind
+	if kind == "" {
+		kind = "ConfigMap"
+	}
+	objectKey := client.ObjectKey{Name: caBundleRef.Name, Namespace: ns}
+
+	var data []byte
+	switch kind {
+	case "Secret":
+		secret := &corev1.Secret{}
+		if err := kubeClient.Get(ctx, objectKey, secret); err != nil {
+			return nil, fmt.Errorf("failed to get CA secret %s/%s: %w", ns, caBundleRef.Name, err)
+		}
+		value, exists := secret.Data[key]
+		if !exists {
+			return nil, fmt.Errorf("key %q not found in CA secret %s/%s", key, ns, caBundleRef.Name)
+		}
+		data = value
+	case "ConfigMap":
+		configMap := &corev1.ConfigMap{}
+		if err := kubeClient.Get(ctx, objectKey, configMap); err != nil {
+			return nil, fmt.Errorf("failed to get CA configmap %s/%s: %w", ns, caBundleRef.Name, err)
+		}
+		if value, exists := configMap.Data[key]; exists {
+			data = []byte(value)
+		} else if value, exists := configMap.BinaryData[key]; exists {
+			data = value
+		} else {
+			return nil, fmt.Errorf("key %q not found in CA configmap %s/%s", key, ns, caBundleRef.Name)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported CA bundle kind %q (expected Secret or ConfigMap)", kind)
+	}
+
+	if len(data) == 0 {
+		return nil, fmt.Errorf("CA bundle %s %s/%s key %q is empty", kind, ns, caBundleRef.Name, key)
+	}
+	return data, nil
+}
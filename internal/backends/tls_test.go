@@ -17,6 +17,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
@@ -276,3 +277,102 @@ func TestNewMinioBackend_WithCASecretRef_Missing(t *testing.T) {
 	assert.ErrorContains(t, err, "failed to build TLS config")
 	assert.ErrorContains(t, err, "failed to get CA secret")
 }
+
+func TestLoadCABundle(t *testing.T) {
+	caPEM := selfSignedCA(t)
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	objects := []client.Object{
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "trust-bundle", Namespace: "default"},
+			Data:       map[string]string{"ca.crt": string(caPEM), "bundle.pem": string(caPEM), "empty": ""},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "binary-bundle", Namespace: "certs"},
+			BinaryData: map[string][]byte{"ca.crt": caPEM},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-ca", Namespace: "default"},
+			Data:       map[string][]byte{"ca.crt": caPEM},
+		},
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	certs := "certs"
+
+	tests := []struct {
+		name    string
+		ref     ftpv1.TLSCABundleRef
+		wantErr string
+	}{
+		{name: "configmap by default", ref: ftpv1.TLSCABundleRef{Name: "trust-bundle"}},
+		{name: "configmap custom key", ref: ftpv1.TLSCABundleRef{Kind: "ConfigMap", Name: "trust-bundle", Key: "bundle.pem"}},
+		{name: "configmap binary data", ref: ftpv1.TLSCABundleRef{Name: "binary-bundle", Namespace: &certs}},
+		{name: "secret", ref: ftpv1.TLSCABundleRef{Kind: "Secret", Name: "my-ca"}},
+		{name: "missing configmap", ref: ftpv1.TLSCABundleRef{Name: "missing"}, wantErr: "failed to get CA configmap default/missing"},
+		{name: "missing secret", ref: ftpv1.TLSCABundleRef{Kind: "Secret", Name: "missing"}, wantErr: "failed to get CA secret default/missing"},
+		{name: "missing key", ref: ftpv1.TLSCABundleRef{Name: "trust-bundle", Key: "other.pem"}, wantErr: `key "other.pem" not found in CA configmap default/trust-bundle`},
+		{name: "empty key", ref: ftpv1.TLSCABundleRef{Name: "trust-bundle", Key: "empty"}, wantErr: "is empty"},
+		{name: "unknown kind", ref: ftpv1.TLSCABundleRef{Kind: "Pod", Name: "trust-bundle"}, wantErr: "unsupported CA bundle kind"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundle, err := loadCABundle(context.Background(), &tt.ref, "default", kubeClient)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, caPEM, bundle)
+		})
+	}
+}
+
+func TestNewMinioBackend_WithCABundleRef(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, ftpv1.AddToScheme(scheme))
+
+	configMaps := []client.Object{
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "minio-ca", Namespace: "default"},
+			Data:       map[string]string{"ca.crt": string(selfSignedCA(t))},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "not-a-ca", Namespace: "default"},
+			Data:       map[string]string{"ca.crt": "not-a-pem"},
+		},
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMaps...).Build()
+
+	newBackend := func(ref *ftpv1.TLSCABundleRef) *ftpv1.MinioBackend {
+		return &ftpv1.MinioBackend{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec: ftpv1.MinioBackendSpec{
+				Endpoint: "https://minio.example.com:9000",
+				Bucket:   "test-bucket",
+				Credentials: ftpv1.MinioCredentials{
+					AccessKeyID:     "access",
+					SecretAccessKey: "secret",
+				},
+				TLS: &ftpv1.MinioTLSConfig{
+					CABundleRef: ref,
+					// CABundleRef wins; the missing secret is never read
+					CASecretRef: &ftpv1.TLSCASecretRef{Name: "does-not-exist"},
+				},
+			},
+		}
+	}
+
+	// Expect a connection error (no real MinIO), not a TLS config error
+	_, err := NewMinioBackend(context.Background(), newBackend(&ftpv1.TLSCABundleRef{Name: "minio-ca"}), kubeClient)
+	assert.ErrorContains(t, err, "failed to connect to MinIO bucket")
+
+	_, err = NewMinioBackend(context.Background(), newBackend(&ftpv1.TLSCABundleRef{Name: "does-not-exist"}), kubeClient)
+	assert.ErrorContains(t, err, "failed to build TLS config")
+	assert.ErrorContains(t, err, "failed to get CA configmap")
+
+	_, err = NewMinioBackend(context.Background(), newBackend(&ftpv1.TLSCABundleRef{Name: "not-a-ca"}), kubeClient)
+	assert.ErrorContains(t, err, "failed to build TLS config")
+	assert.ErrorContains(t, err, "no valid PEM certificates found")
+}
@@ -63,27 +63,10 @@ func newWebDavBackendImpl(ctx context.Context, backend *ftpv1.WebDavBackend, kub
 		}
 	}
 
-	// Configure HTTP client
-	httpClient := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-
-	// Configure TLS if specified
-	if backend.Spec.TLS != nil {
-		tlsConfig, err := buildTLSConfig(
-			ctx,
-			backend.Spec.TLS.InsecureSkipVerify,
-			backend.Spec.TLS.CACert,
-			backend.Spec.TLS.CASecretRef,
-			backend.Namespace,
-			kubeClient,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to build TLS config: %w", err)
-		}
-		httpClient.Transport = &http.Transport{
-			TLSClientConfig: tlsConfig,
-		}
+	// Sessions on the same backend share one pooled HTTP client
+	httpClient, err := sharedWebDavClient(ctx, backend, kubeClient)
+	if err != nil {
+		return nil, err
 	}
 
 	// Test connection with a PROPFIND request
@@ -0,0 +1,108 @@
+package backends
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+// Connection pool defaults, matching the kubebuilder defaults on WebDavConnectionPool
+const (
+	defaultWebDavMaxIdleConns        = 100
+	defaultWebDavMaxIdleConnsPerHost = 10
+	defaultWebDavIdleConnTimeout     = 90 * time.Second
+	defaultWebDavRequestTimeout      = 30 * time.Second
+)
+
+// webDavClientEntry is a cached HTTP client and the spec fields it was built from
+type webDavClientEntry struct {
+	tls    *ftpv1.WebDavTLSConfig
+	pool   *ftpv1.WebDavConnectionPool
+	client *http.Client
+}
+
+// webDavClients holds one HTTP client per WebDavBackend, so every session using
+// a backend shares its connection pool instead of dialing afresh.
+var webDavClients = struct {
+	sync.Mutex
+	entries map[client.ObjectKey]*webDavClientEntry
+}{entries: make(map[client.ObjectKey]*webDavClientEntry)}
+
+// sharedWebDavClient returns the HTTP client for a backend, building a new one
+// when the backend's TLS or connection pool settings have changed. A CA bundle
+// read from a Secret is picked up when the client is rebuilt.
+func sharedWebDavClient(ctx context.Context, backend *ftpv1.WebDavBackend, kubeClient client.Client) (*http.Client, error) {
+	key := client.ObjectKeyFromObject(backend)
+
+	webDavClients.Lock()
+	defer webDavClients.Unlock()
+
+	entry, ok := webDavClients.entries[key]
+	if ok && reflect.DeepEqual(entry.tls, backend.Spec.TLS) && reflect.DeepEqual(entry.pool, backend.Spec.ConnectionPool) {
+		return entry.client, nil
+	}
+
+	httpClient, err := newWebDavHTTPClient(ctx, backend, kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		entry.client.CloseIdleConnections()
+	}
+	webDavClients.entries[key] = &webDavClientEntry{
+		tls:    backend.Spec.TLS.DeepCopy(),
+		pool:   backend.Spec.ConnectionPool.DeepCopy(),
+		client: httpClient,
+	}
+	return httpClient, nil
+}
+
+// newWebDavHTTPClient builds an HTTP client with a transport tuned from the
+// backend's connection pool and TLS settings
+func newWebDavHTTPClient(ctx context.Context, backend *ftpv1.WebDavBackend, kubeClient client.Client) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = defaultWebDavMaxIdleConns
+	transport.MaxIdleConnsPerHost = defaultWebDavMaxIdleConnsPerHost
+	transport.IdleConnTimeout = defaultWebDavIdleConnTimeout
+	timeout := defaultWebDavRequestTimeout
+
+	if pool := backend.Spec.ConnectionPool; pool != nil {
+		if pool.MaxIdleConns > 0 {
+			transport.MaxIdleConns = int(pool.MaxIdleConns)
+		}
+		if pool.MaxIdleConnsPerHost > 0 {
+			transport.MaxIdleConnsPerHost = int(pool.MaxIdleConnsPerHost)
+		}
+		transport.MaxConnsPerHost = int(pool.MaxConnsPerHost)
+		if pool.IdleConnTimeoutSeconds > 0 {
+			transport.IdleConnTimeout = time.Duration(pool.IdleConnTimeoutSeconds) * time.Second
+		}
+		if pool.RequestTimeoutSeconds > 0 {
+			timeout = time.Duration(pool.RequestTimeoutSeconds) * time.Second
+		}
+	}
+
+	if backend.Spec.TLS != nil {
+		tlsConfig, err := buildTLSConfig(
+			ctx,
+			backend.Spec.TLS.InsecureSkipVerify,
+			backend.Spec.TLS.CACert,
+			backend.Spec.TLS.CASecretRef,
+			backend.Namespace,
+			kubeClient,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build TLS config: %w", err)
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
@@ -0,0 +1,137 @@
+package backends
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+// newPoolTestServer starts a WebDAV stub that counts new connections and the
+// peak number of requests in flight
+func newPoolTestServer(t *testing.T, delay time.Duration) (*httptest.Server, *int32, *int32) {
+	t.Helper()
+	var newConns, inFlight, peak int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if current <= old || atomic.CompareAndSwapInt32(&peak, old, current) {
+				break
+			}
+		}
+		time.Sleep(delay)
+		switch r.Method {
+		case "PROPFIND":
+			w.WriteHeader(207)
+		case "MKCOL":
+			w.WriteHeader(201)
+		default:
+			w.WriteHeader(200)
+		}
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&newConns, 1)
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+	return server, &newConns, &peak
+}
+
+func newPoolTestBackend(name, endpoint string, pool *ftpv1.WebDavConnectionPool) *ftpv1.WebDavBackend {
+	return &ftpv1.WebDavBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "pool-test"},
+		Spec: ftpv1.WebDavBackendSpec{
+			Endpoint:       endpoint,
+			Credentials:    ftpv1.WebDavCredentials{Username: "testuser", Password: "testpass"},
+			ConnectionPool: pool,
+		},
+	}
+}
+
+func TestWebDavBackend_SharedClientReused(t *testing.T) {
+	server, newConns, _ := newPoolTestServer(t, 0)
+	backend := newPoolTestBackend("shared", server.URL, nil)
+
+	first, err := newWebDavBackendImpl(context.TODO(), backend, nil)
+	require.NoError(t, err)
+	second, err := newWebDavBackendImpl(context.TODO(), backend, nil)
+	require.NoError(t, err)
+
+	firstClient := first.(*webDavBackendImpl).client
+	assert.Same(t, firstClient, second.(*webDavBackendImpl).client)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, first.Mkdir("/dir"))
+		require.NoError(t, second.Mkdir("/dir"))
+	}
+	// Both backends' connectivity checks and operations ran over one connection
+	assert.Equal(t, int32(1), atomic.LoadInt32(newConns))
+
+	transport := firstClient.Transport.(*http.Transport)
+	assert.Equal(t, defaultWebDavMaxIdleConns, transport.MaxIdleConns)
+	assert.Equal(t, defaultWebDavMaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, defaultWebDavIdleConnTimeout, transport.IdleConnTimeout)
+	assert.Equal(t, defaultWebDavRequestTimeout, firstClient.Timeout)
+}
+
+func TestWebDavBackend_ConnectionPoolLimits(t *testing.T) {
+	server, _, peak := newPoolTestServer(t, 20*time.Millisecond)
+	pool := &ftpv1.WebDavConnectionPool{
+		MaxIdleConns:           4,
+		MaxIdleConnsPerHost:    2,
+		MaxConnsPerHost:        1,
+		IdleConnTimeoutSeconds: 15,
+		RequestTimeoutSeconds:  5,
+	}
+	backend, err := newWebDavBackendImpl(context.TODO(), newPoolTestBackend("limited", server.URL, pool), nil)
+	require.NoError(t, err)
+
+	httpClient := backend.(*webDavBackendImpl).client
+	transport := httpClient.Transport.(*http.Transport)
+	assert.Equal(t, 4, transport.MaxIdleConns)
+	assert.Equal(t, 2, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 1, transport.MaxConnsPerHost)
+	assert.Equal(t, 15*time.Second, transport.IdleConnTimeout)
+	assert.Equal(t, 5*time.Second, httpClient.Timeout)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, backend.Mkdir("/dir"))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(peak), "MaxConnsPerHost should serialize requests")
+}
+
+func TestWebDavBackend_ClientRebuiltOnSpecChange(t *testing.T) {
+	server, _, _ := newPoolTestServer(t, 0)
+	backend := newPoolTestBackend("rebuilt", server.URL, nil)
+
+	first, err := newWebDavBackendImpl(context.TODO(), backend, nil)
+	require.NoError(t, err)
+
+	backend.Spec.ConnectionPool = &ftpv1.WebDavConnectionPool{MaxConnsPerHost: 3}
+	second, err := newWebDavBackendImpl(context.TODO(), backend, nil)
+	require.NoError(t, err)
+
+	secondClient := second.(*webDavBackendImpl).client
+	assert.NotSame(t, first.(*webDavBackendImpl).client, secondClient)
+	assert.Equal(t, 3, secondClient.Transport.(*http.Transport).MaxConnsPerHost)
+}
@@ -19,6 +19,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -322,6 +323,38 @@ func (r *BuiltInUserManager) UpdateConfig(ctx context.Context, config BuiltInUse
 	return r.reconcileBuiltInUsers(ctx)
 }
 
+// maxBuiltInUserRetryBackoff caps the delay between startup reconciliation attempts
+const maxBuiltInUserRetryBackoff = time.Minute
+
+// UpdateConfigWithRetry is UpdateConfig with up to attempts tries, doubling the
+// delay between them from backoff, so a transient API error at startup does not
+// leave built-in users missing. It returns the last error once attempts run out
+// or ctx is cancelled.
+func (r *BuiltInUserManager) UpdateConfigWithRetry(ctx context.Context, config BuiltInUserConfig, attempts int, backoff time.Duration) error {
+	log := logf.FromContext(ctx)
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = r.UpdateConfig(ctx, config); err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+		log.Info("Built-in user reconciliation failed, retrying", "attempt", attempt, "attempts", attempts, "backoff", backoff, "error", err.Error())
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("built-in user reconciliation cancelled: %w", err)
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBuiltInUserRetryBackoff)
+	}
+	return fmt.Errorf("built-in user reconciliation failed after %d attempts: %w", attempts, err)
+}
+
 // SetupWithManager sets up the controller with the Manager
 func (r *BuiltInUserManager) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -1,9 +1,18 @@
 package controller
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
 	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
 )
@@ -444,3 +453,75 @@ func TestBuiltInUserManager_passwordSecretNeedsUpdate(t *testing.T) {
 		})
 	}
 }
+
+// newFlakyBuiltInUserManager returns a manager whose first failures Get calls
+// fail with a transient server error, and a counter of Get calls made
+func newFlakyBuiltInUserManager(t *testing.T, failures int) (*BuiltInUserManager, client.Client, *int) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, ftpv1.AddToScheme(scheme))
+
+	calls := 0
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				calls++
+				if calls <= failures {
+					return errors.NewServiceUnavailable("apiserver starting")
+				}
+				return c.Get(ctx, key, obj, opts...)
+			},
+		}).
+		Build()
+
+	return &BuiltInUserManager{Client: fakeClient, Scheme: scheme}, fakeClient, &calls
+}
+
+func TestBuiltInUserManager_UpdateConfigWithRetry(t *testing.T) {
+	config := BuiltInUserConfig{
+		EnableAnonymous:      true,
+		AnonymousHomeDir:     "/pub",
+		AnonymousBackendKind: "FilesystemBackend",
+		AnonymousBackendName: "anonymous-backend",
+		EnableAdmin:          true,
+		AdminPasswordSecret:  "admin-secret",
+		AdminHomeDir:         "/",
+		AdminBackendKind:     "FilesystemBackend",
+		AdminBackendName:     "admin-backend",
+		Namespace:            "default",
+	}
+
+	t.Run("transient failure then success creates built-in users", func(t *testing.T) {
+		manager, fakeClient, _ := newFlakyBuiltInUserManager(t, 2)
+
+		err := manager.UpdateConfigWithRetry(context.Background(), config, 5, time.Millisecond)
+		require.NoError(t, err)
+
+		for _, name := range []string{"builtin-anonymous", "builtin-admin"} {
+			user := &ftpv1.User{}
+			err := fakeClient.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "default"}, user)
+			assert.NoError(t, err, "%s should exist", name)
+		}
+	})
+
+	t.Run("gives up after the configured attempts", func(t *testing.T) {
+		manager, _, calls := newFlakyBuiltInUserManager(t, 100)
+
+		err := manager.UpdateConfigWithRetry(context.Background(), config, 3, time.Millisecond)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed after 3 attempts")
+		assert.True(t, errors.IsServiceUnavailable(err))
+		assert.Equal(t, 3, *calls, "each attempt stops at the first failed Get")
+	})
+
+	t.Run("stops retrying when the context is cancelled", func(t *testing.T) {
+		manager, _, calls := newFlakyBuiltInUserManager(t, 100)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := manager.UpdateConfigWithRetry(ctx, config, 5, time.Hour)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cancelled")
+		assert.Equal(t, 1, *calls)
+	})
+}
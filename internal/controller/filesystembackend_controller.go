@@ -18,8 +18,9 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"os"
-	"syscall"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -29,6 +30,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/backends"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -37,8 +39,19 @@ import (
 type FilesystemBackendReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// DiskSpace reports free and total space; nil uses statfs
+	DiskSpace backends.DiskSpaceFunc
+	// SelfTests, when set, runs a write-read-delete self-test against each
+	// writable backend and marks backends that fail it as not ready
+	SelfTests *BackendSelfTests
+	// SelfTest runs the self-test; nil uses backends.SelfTestFilesystem
+	SelfTest func(backends.FilesystemBackend) error
 }
 
+// freeSpaceCheckInterval is how often backends with MinFreeBytes are rechecked,
+// since free space changes without any change to the spec
+const freeSpaceCheckInterval = time.Minute
+
 //+kubebuilder:rbac:groups=ftp.golder.org,resources=filesystembackends,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=ftp.golder.org,resources=filesystembackends/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=ftp.golder.org,resources=filesystembackends/finalizers,verbs=update
@@ -77,6 +90,23 @@ func (r *FilesystemBackendReconciler) reconcileNormal(ctx context.Context, backe
 		message = err.Error()
 	}
 
+	// Confirm the base path can be written, read and cleaned up. Backends that
+	// failed are retried on each reconcile so a fixed mount recovers.
+	if ready && !backend.Spec.ReadOnly && r.SelfTests != nil {
+		if r.SelfTests.Pending("FilesystemBackend", backend.Namespace, backend.Name) ||
+			r.SelfTests.Result("FilesystemBackend", backend.Namespace, backend.Name) != nil {
+			err := r.runSelfTest(backend)
+			if err != nil {
+				log.Error(err, "filesystem backend self-test failed", "backend", backend.Name)
+			}
+			r.SelfTests.Record("FilesystemBackend", backend.Namespace, backend.Name, err)
+		}
+		if err := r.SelfTests.Result("FilesystemBackend", backend.Namespace, backend.Name); err != nil {
+			ready = false
+			message = "Self-test failed: " + err.Error()
+		}
+	}
+
 	// Get storage statistics if available
 	var availableSpace, totalSpace *int64
 	if ready {
@@ -89,6 +119,12 @@ func (r *FilesystemBackendReconciler) reconcileNormal(ctx context.Context, backe
 		}
 	}
 
+	// Stop accepting writes under disk pressure
+	if ready && backend.Spec.MinFreeBytes > 0 && availableSpace != nil && *availableSpace < backend.Spec.MinFreeBytes {
+		ready = false
+		message = fmt.Sprintf("Free space %d bytes is below minFreeBytes %d", *availableSpace, backend.Spec.MinFreeBytes)
+	}
+
 	// Update status
 	now := metav1.Now()
 	backend.Status = ftpv1.FilesystemBackendStatus{
@@ -104,13 +140,17 @@ func (r *FilesystemBackendReconciler) reconcileNormal(ctx context.Context, backe
 		return ctrl.Result{}, err
 	}
 
-	// Don't requeue - only reconcile on spec changes
+	// Only backends with a free space floor need periodic rechecks
+	if backend.Spec.MinFreeBytes > 0 {
+		return ctrl.Result{RequeueAfter: freeSpaceCheckInterval}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
 // reconcileDelete handles deletion of the backend
 func (r *FilesystemBackendReconciler) reconcileDelete(ctx context.Context, backend *ftpv1.FilesystemBackend) (ctrl.Result, error) {
-	// No cleanup needed for filesystem backends
+	// No cleanup needed for filesystem backends beyond dropping the self-test result
+	r.SelfTests.Forget("FilesystemBackend", backend.Namespace, backend.Name)
 	return ctrl.Result{}, nil
 }
 
@@ -171,30 +211,30 @@ func (r *FilesystemBackendReconciler) validateBackend(ctx context.Context, backe
 	return true, "Filesystem backend is ready", nil
 }
 
-// getStorageStats returns storage statistics for the given path
-func (r *FilesystemBackendReconciler) getStorageStats(path string) (available int64, total int64) {
-	var stat syscall.Statfs_t
-	if err := syscall.Statfs(path, &stat); err != nil {
-		return -1, -1
+// runSelfTest writes, reads back and deletes a temporary file in the base path
+func (r *FilesystemBackendReconciler) runSelfTest(backend *ftpv1.FilesystemBackend) error {
+	fsBackend, err := backends.NewFilesystemBackend(backend, r.Client)
+	if err != nil {
+		return fmt.Errorf("failed to create filesystem backend: %w", err)
 	}
-
-	// Calculate available and total space in bytes
-	// Use math.MaxInt64 to prevent potential overflow issues
-	const maxInt64 = 1<<63 - 1
-
-	// Calculate with overflow protection
-	if stat.Bavail > 0 && stat.Bsize > 0 && stat.Bavail <= maxInt64/uint64(stat.Bsize) {
-		available = int64(stat.Bavail) * int64(stat.Bsize) // nolint:gosec // Overflow protected
-	} else {
-		available = maxInt64 // Use max value if overflow would occur
+	selfTest := r.SelfTest
+	if selfTest == nil {
+		selfTest = backends.SelfTestFilesystem
 	}
+	return selfTest(fsBackend)
+}
 
-	if stat.Blocks > 0 && stat.Bsize > 0 && stat.Blocks <= maxInt64/uint64(stat.Bsize) {
-		total = int64(stat.Blocks) * int64(stat.Bsize) // nolint:gosec // Overflow protected
-	} else {
-		total = maxInt64 // Use max value if overflow would occur
+// getStorageStats returns storage statistics for the given path, or -1 for
+// both when they cannot be read
+func (r *FilesystemBackendReconciler) getStorageStats(path string) (available int64, total int64) {
+	diskSpace := r.DiskSpace
+	if diskSpace == nil {
+		diskSpace = backends.StatfsDiskSpace
+	}
+	available, total, err := diskSpace(path)
+	if err != nil {
+		return -1, -1
 	}
-
 	return available, total
 }
 
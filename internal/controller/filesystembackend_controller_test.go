@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -16,6 +17,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/backends"
 )
 
 func createTestScheme() *runtime.Scheme {
@@ -560,3 +562,136 @@ func TestFilesystemBackendReconciler_GetStorageStats(t *testing.T) {
 	assert.Equal(t, int64(-1), available)
 	assert.Equal(t, int64(-1), total)
 }
+
+func TestFilesystemBackendReconciler_MinFreeBytes(t *testing.T) {
+	tests := []struct {
+		name        string
+		available   int64
+		wantReady   bool
+		wantMessage string
+	}{
+		{name: "above the floor", available: 4096, wantReady: true, wantMessage: "Filesystem backend is ready"},
+		{name: "below the floor", available: 512, wantReady: false, wantMessage: "Free space 512 bytes is below minFreeBytes 1024"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testDir := createTestDir(t)
+			scheme := createTestScheme()
+
+			backend := &ftpv1.FilesystemBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-backend",
+					Namespace: "default",
+				},
+				Spec: ftpv1.FilesystemBackendSpec{
+					BasePath:     testDir,
+					MinFreeBytes: 1024,
+				},
+			}
+
+			client := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(backend).
+				WithStatusSubresource(&ftpv1.FilesystemBackend{}).
+				Build()
+
+			reconciler := &FilesystemBackendReconciler{
+				Client: client,
+				Scheme: scheme,
+				DiskSpace: func(path string) (int64, int64, error) {
+					return tt.available, 1 << 20, nil
+				},
+			}
+
+			ctx := context.Background()
+			req := ctrl.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "test-backend",
+					Namespace: "default",
+				},
+			}
+
+			result, err := reconciler.Reconcile(ctx, req)
+			require.NoError(t, err)
+			assert.Equal(t, freeSpaceCheckInterval, result.RequeueAfter, "free space is rechecked periodically")
+
+			var updatedBackend ftpv1.FilesystemBackend
+			require.NoError(t, client.Get(ctx, req.NamespacedName, &updatedBackend))
+			assert.Equal(t, tt.wantReady, updatedBackend.Status.Ready)
+			assert.Equal(t, tt.wantMessage, updatedBackend.Status.Message)
+			require.NotNil(t, updatedBackend.Status.AvailableSpace)
+			assert.Equal(t, tt.available, *updatedBackend.Status.AvailableSpace)
+		})
+	}
+}
+
+func TestFilesystemBackendReconciler_SelfTest(t *testing.T) {
+	tests := []struct {
+		name      string
+		selfTest  func(backends.FilesystemBackend) error
+		wantReady bool
+	}{
+		{name: "passing backend is ready", selfTest: nil, wantReady: true},
+		{
+			name:      "failing backend is not ready",
+			selfTest:  func(backends.FilesystemBackend) error { return errors.New("permission denied") },
+			wantReady: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := createTestScheme()
+			backend := &ftpv1.FilesystemBackend{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-backend", Namespace: "default"},
+				Spec:       ftpv1.FilesystemBackendSpec{BasePath: createTestDir(t)},
+			}
+			client := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(backend).
+				WithStatusSubresource(&ftpv1.FilesystemBackend{}).
+				Build()
+
+			selfTests := NewBackendSelfTests()
+			reconciler := &FilesystemBackendReconciler{
+				Client:    client,
+				Scheme:    scheme,
+				SelfTests: selfTests,
+				SelfTest:  tt.selfTest,
+			}
+
+			ctx := context.Background()
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-backend", Namespace: "default"}}
+			_, err := reconciler.Reconcile(ctx, req)
+			require.NoError(t, err)
+
+			var updated ftpv1.FilesystemBackend
+			require.NoError(t, client.Get(ctx, req.NamespacedName, &updated))
+			assert.Equal(t, tt.wantReady, updated.Status.Ready)
+			assert.False(t, selfTests.Pending("FilesystemBackend", "default", "test-backend"))
+			if tt.wantReady {
+				assert.NoError(t, selfTests.Check(nil))
+			} else {
+				assert.Contains(t, updated.Status.Message, "Self-test failed")
+				assert.Error(t, selfTests.Check(nil))
+			}
+		})
+	}
+}
+
+func TestBackendSelfTests(t *testing.T) {
+	var disabled *BackendSelfTests
+	assert.False(t, disabled.Pending("MinioBackend", "default", "b"))
+	assert.NoError(t, disabled.Check(nil))
+
+	selfTests := NewBackendSelfTests()
+	assert.True(t, selfTests.Pending("MinioBackend", "default", "b"))
+
+	selfTests.Record("MinioBackend", "default", "b", errors.New("access denied"))
+	assert.False(t, selfTests.Pending("MinioBackend", "default", "b"))
+	assert.EqualError(t, selfTests.Check(nil), "backend self-test failed: MinioBackend/default/b")
+
+	selfTests.Forget("MinioBackend", "default", "b")
+	assert.NoError(t, selfTests.Check(nil))
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// MaintenanceMessageKey is the ConfigMap key holding the maintenance message
+const MaintenanceMessageKey = "maintenanceMessage"
+
+// GlobalReadOnlyKey is the ConfigMap key that, when "true", makes the whole
+// server read-only
+const GlobalReadOnlyKey = "globalReadOnly"
+
+// MaintenanceReconciler watches a single ConfigMap and applies its maintenance
+// message to the FTP server. A non-empty message puts the server into
+// maintenance mode; an empty or missing key ends it. When the ConfigMap does
+// not exist, DefaultMessage (from --maintenance-message) applies. The
+// globalReadOnly key toggles the emergency read-only switch the same way,
+// falling back to DefaultReadOnly.
+type MaintenanceReconciler struct {
+	client.Client
+	ConfigMap       types.NamespacedName
+	DefaultMessage  string
+	DefaultReadOnly bool
+	// Apply receives the maintenance message to enforce ("" disables maintenance)
+	Apply func(message string)
+	// ApplyReadOnly receives the global read-only state. May be nil.
+	ApplyReadOnly func(enabled bool)
+}
+
+// Reconcile reads the maintenance ConfigMap and applies its message
+func (r *MaintenanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	configMap := &corev1.ConfigMap{}
+	message := r.DefaultMessage
+	readOnly := r.DefaultReadOnly
+	if err := r.Get(ctx, r.ConfigMap, configMap); err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+	} else {
+		message = configMap.Data[MaintenanceMessageKey]
+		if value, ok := configMap.Data[GlobalReadOnlyKey]; ok {
+			if enabled, err := strconv.ParseBool(value); err == nil {
+				readOnly = enabled
+			} else {
+				log.Error(err, "Ignoring invalid globalReadOnly value", "configmap", r.ConfigMap.String(), "value", value)
+			}
+		}
+	}
+
+	log.Info("Applying maintenance mode", "configmap", r.ConfigMap.String(), "enabled", message != "", "read_only", readOnly)
+	r.Apply(message)
+	if r.ApplyReadOnly != nil {
+		r.ApplyReadOnly(readOnly)
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *MaintenanceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("maintenance").
+		For(&corev1.ConfigMap{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return obj.GetName() == r.ConfigMap.Name && obj.GetNamespace() == r.ConfigMap.Namespace
+		}))).
+		Complete(r)
+}
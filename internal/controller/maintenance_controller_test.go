@@ -0,0 +1,111 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestMaintenanceReconciler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	ref := types.NamespacedName{Namespace: "kubeftpd", Name: "maintenance"}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ref.Name, Namespace: ref.Namespace},
+		Data:       map[string]string{MaintenanceMessageKey: "Upgrading storage, back at 02:00"},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+
+	applied := "unset"
+	r := &MaintenanceReconciler{
+		Client:         fakeClient,
+		ConfigMap:      ref,
+		DefaultMessage: "flag message",
+		Apply:          func(message string) { applied = message },
+	}
+	ctx := context.Background()
+	req := reconcile.Request{NamespacedName: ref}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "Upgrading storage, back at 02:00", applied)
+
+	// Clearing the message ends maintenance
+	configMap.Data = map[string]string{}
+	require.NoError(t, fakeClient.Update(ctx, configMap))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "", applied)
+
+	// Deleting the ConfigMap falls back to the flag value
+	require.NoError(t, fakeClient.Delete(ctx, configMap))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "flag message", applied)
+}
+
+func TestMaintenanceReconciler_GlobalReadOnly(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	ref := types.NamespacedName{Namespace: "kubeftpd", Name: "maintenance"}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ref.Name, Namespace: ref.Namespace},
+		Data:       map[string]string{GlobalReadOnlyKey: "true"},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+
+	readOnly := false
+	r := &MaintenanceReconciler{
+		Client:        fakeClient,
+		ConfigMap:     ref,
+		Apply:         func(string) {},
+		ApplyReadOnly: func(enabled bool) { readOnly = enabled },
+	}
+	ctx := context.Background()
+	req := reconcile.Request{NamespacedName: ref}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, readOnly)
+
+	// Disabling the key restores writes
+	configMap.Data[GlobalReadOnlyKey] = "false"
+	require.NoError(t, fakeClient.Update(ctx, configMap))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.False(t, readOnly)
+
+	// An unparseable value falls back to the flag value
+	r.DefaultReadOnly = true
+	configMap.Data[GlobalReadOnlyKey] = "maybe"
+	require.NoError(t, fakeClient.Update(ctx, configMap))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, readOnly)
+}
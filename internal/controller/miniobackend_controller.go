@@ -37,6 +37,12 @@ import (
 type MinioBackendReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// RequireHTTPS marks backends with a plain http:// endpoint as not ready
+	// instead of connecting to them
+	RequireHTTPS bool
+	// SelfTests, when set, runs a write-read-delete self-test alongside each
+	// connectivity test and marks backends that fail it as not ready
+	SelfTests *BackendSelfTests
 }
 
 // +kubebuilder:rbac:groups=ftp.golder.org,resources=miniobackends,verbs=get;list;watch;create;update;patch;delete
@@ -74,8 +80,23 @@ func (r *MinioBackendReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return r.handleMinioBackendDeletion(ctx, backend)
 	}
 
-	// Only test connectivity if this is a new backend or if the spec has changed
-	shouldTestConnectivity := r.shouldTestConnectivity(backend)
+	// Refuse insecure endpoints before sending credentials to them
+	if err := ftpv1.ValidateMinioEndpoint(backend.Spec.Endpoint, r.RequireHTTPS); err != nil {
+		log.Info("MinioBackend rejected by HTTPS policy", "backend", backend.Name, "endpoint", backend.Spec.Endpoint)
+		r.updateMinioBackendStatus(ctx, backend, metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			Reason:             "InsecureEndpoint",
+			Message:            err.Error(),
+			LastTransitionTime: metav1.Now(),
+		})
+		return ctrl.Result{}, nil
+	}
+
+	// Only test connectivity if this is a new backend, if the spec has changed
+	// or if this process has not self-tested it yet
+	shouldTestConnectivity := r.shouldTestConnectivity(backend) ||
+		r.SelfTests.Pending("MinioBackend", backend.Namespace, backend.Name)
 
 	if shouldTestConnectivity {
 		// Test connectivity to MinIO
@@ -97,6 +118,27 @@ func (r *MinioBackendReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			return ctrl.Result{RequeueAfter: time.Minute * 5}, nil
 		}
 
+		// Confirm the credentials can write, read and delete, not just connect
+		if r.SelfTests != nil {
+			err := r.runSelfTest(ctx, backend)
+			r.SelfTests.Record("MinioBackend", backend.Namespace, backend.Name, err)
+			if err != nil {
+				log.Error(err, "MinIO self-test failed", "backend", backend.Name)
+				r.updateMinioBackendStatus(ctx, backend, metav1.Condition{
+					Type:               "Ready",
+					Status:             metav1.ConditionFalse,
+					Reason:             "SelfTestFailed",
+					Message:            err.Error(),
+					LastTransitionTime: metav1.Now(),
+				})
+				r.markConnectivityTested(backend)
+				if err := r.Status().Update(ctx, backend); err != nil {
+					log.Error(err, "Failed to update connectivity test timestamp")
+				}
+				return ctrl.Result{}, nil
+			}
+		}
+
 		// Update status to ready
 		r.updateMinioBackendStatus(ctx, backend, metav1.Condition{
 			Type:               "Ready",
@@ -131,6 +173,15 @@ func (r *MinioBackendReconciler) testMinioConnectivity(ctx context.Context, back
 	return nil
 }
 
+// runSelfTest writes, reads back and deletes a temporary object on the backend
+func (r *MinioBackendReconciler) runSelfTest(ctx context.Context, backend *ftpv1.MinioBackend) error {
+	b, err := backends.NewMinioBackend(ctx, backend, r.Client)
+	if err != nil {
+		return fmt.Errorf("failed to create MinIO backend: %w", err)
+	}
+	return backends.SelfTestMinio(b)
+}
+
 // shouldTestConnectivity determines if we should test connectivity for this backend
 func (r *MinioBackendReconciler) shouldTestConnectivity(backend *ftpv1.MinioBackend) bool {
 	// Always test if we haven't tested before
@@ -161,6 +212,7 @@ func (r *MinioBackendReconciler) updateMinioBackendStatus(ctx context.Context, b
 func (r *MinioBackendReconciler) handleMinioBackendDeletion(ctx context.Context, backend *ftpv1.MinioBackend) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
 	log.Info("Handling MinioBackend deletion", "backend", backend.Name)
+	r.SelfTests.Forget("MinioBackend", backend.Namespace, backend.Name)
 
 	// Perform any cleanup operations here
 	// For now, we just remove the finalizer
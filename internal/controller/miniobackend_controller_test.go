@@ -22,6 +22,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -87,5 +88,44 @@ var _ = Describe("MinioBackend Controller", func() {
 			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
+		It("should mark an http endpoint not ready when HTTPS is required", func() {
+			insecureName := types.NamespacedName{Name: "insecure-resource", Namespace: "default"}
+			Expect(k8sClient.Create(ctx, &ftpv1.MinioBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       insecureName.Name,
+					Namespace:  insecureName.Namespace,
+					Finalizers: []string{"ftp.golder.org/finalizer"},
+				},
+				Spec: ftpv1.MinioBackendSpec{
+					Endpoint: "http://minio:9000",
+					Bucket:   "test-bucket",
+					Credentials: ftpv1.MinioCredentials{
+						AccessKeyID:     "testkey",
+						SecretAccessKey: "testsecret",
+					},
+				},
+			})).To(Succeed())
+
+			controllerReconciler := &MinioBackendReconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				RequireHTTPS: true,
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: insecureName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &ftpv1.MinioBackend{}
+			Expect(k8sClient.Get(ctx, insecureName, resource)).To(Succeed())
+			ready := meta.FindStatusCondition(resource.Status.Conditions, "Ready")
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+			Expect(ready.Reason).To(Equal("InsecureEndpoint"))
+
+			resource.Finalizers = nil
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
 	})
 })
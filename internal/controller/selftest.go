@@ -0,0 +1,108 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BackendSelfTests records the outcome of the write-read-delete self-test run
+// once per backend after startup. Backend reconcilers consult it to decide
+// whether a backend still needs testing, and Check reports the manager as not
+// ready while any backend has failed. A nil *BackendSelfTests disables the
+// self-test.
+type BackendSelfTests struct {
+	mu      sync.Mutex
+	results map[string]error
+}
+
+// NewBackendSelfTests returns an empty self-test tracker
+func NewBackendSelfTests() *BackendSelfTests {
+	return &BackendSelfTests{results: make(map[string]error)}
+}
+
+// selfTestKey identifies a backend across kinds
+func selfTestKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// Pending reports whether the backend has not been self-tested by this process
+func (s *BackendSelfTests) Pending(kind, namespace, name string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, tested := s.results[selfTestKey(kind, namespace, name)]
+	return !tested
+}
+
+// Record stores the self-test outcome for a backend; a nil err is a pass
+func (s *BackendSelfTests) Record(kind, namespace, name string, err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[selfTestKey(kind, namespace, name)] = err
+}
+
+// Result returns the recorded failure for a backend, or nil if it passed or
+// has not been tested
+func (s *BackendSelfTests) Result(kind, namespace, name string) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.results[selfTestKey(kind, namespace, name)]
+}
+
+// Forget drops a deleted backend so its failure no longer affects readiness
+func (s *BackendSelfTests) Forget(kind, namespace, name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.results, selfTestKey(kind, namespace, name))
+}
+
+// Check implements healthz.Checker, failing while any backend has failed its
+// self-test
+func (s *BackendSelfTests) Check(_ *http.Request) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var failed []string
+	for key, err := range s.results {
+		if err != nil {
+			failed = append(failed, key)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	sort.Strings(failed)
+	return fmt.Errorf("backend self-test failed: %s", strings.Join(failed, ", "))
+}
@@ -21,13 +21,16 @@ import (
 	"fmt"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
 )
@@ -36,6 +39,9 @@ import (
 type UserReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// RequirePasswordSecretKeys marks Users not ready when their PasswordSecret
+	// or its key is missing, instead of leaving the failure to login time
+	RequirePasswordSecretKeys bool
 }
 
 // +kubebuilder:rbac:groups=ftp.golder.org,resources=users,verbs=get;list;watch;create;update;patch;delete
@@ -43,6 +49,12 @@ type UserReconciler struct {
 // +kubebuilder:rbac:groups=ftp.golder.org,resources=users/finalizers,verbs=update
 // +kubebuilder:rbac:groups=ftp.golder.org,resources=miniobackends,verbs=get;list;watch
 // +kubebuilder:rbac:groups=ftp.golder.org,resources=webdavbackends,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ftp.golder.org,resources=filesystembackends,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ftp.golder.org,resources=ftpbackends,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ftp.golder.org,resources=permissiontemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;update
 
 // Reconcile handles User CRD changes and validates user configuration
 func (r *UserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -88,6 +100,35 @@ func (r *UserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		return ctrl.Result{RequeueAfter: time.Minute * 5}, nil
 	}
 
+	if r.RequirePasswordSecretKeys {
+		if err := r.validatePasswordSecret(ctx, user); err != nil {
+			log.Info("User password secret is not usable", "user", user.Name, "reason", err.Error())
+			r.updateUserStatus(ctx, user, metav1.Condition{
+				Type:               "Ready",
+				Status:             metav1.ConditionFalse,
+				Reason:             "PasswordSecretInvalid",
+				Message:            err.Error(),
+				LastTransitionTime: metav1.Now(),
+			})
+			return ctrl.Result{RequeueAfter: time.Minute * 5}, nil
+		}
+	}
+
+	// Resolve permissions from any referenced template
+	effective, err := r.resolveEffectivePermissions(ctx, user)
+	if err != nil {
+		log.Error(err, "Failed to resolve permission template", "user", user.Name)
+		r.updateUserStatus(ctx, user, metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			Reason:             "PermissionTemplateNotFound",
+			Message:            err.Error(),
+			LastTransitionTime: metav1.Now(),
+		})
+		return ctrl.Result{RequeueAfter: time.Minute * 5}, nil
+	}
+	user.Status.EffectivePermissions = &effective
+
 	// Update status to ready
 	r.updateUserStatus(ctx, user, metav1.Condition{
 		Type:               "Ready",
@@ -120,16 +161,17 @@ func (r *UserReconciler) validateUser(ctx context.Context, user *ftpv1.User) err
 		return err
 	}
 
-	if user.Spec.HomeDirectory == "" {
-		return fmt.Errorf("homeDirectory is required")
+	if err := ftpv1.ValidateHomeDirectory(user.Spec.HomeDirectory); err != nil {
+		return err
 	}
 
-	// Validate backend reference
-	backendNamespace := user.Namespace
-	if user.Spec.Backend.Namespace != nil {
-		backendNamespace = *user.Spec.Backend.Namespace
+	if err := ftpv1.ValidateHomeByCIDR(user.Spec.HomeByCIDR); err != nil {
+		return err
 	}
 
+	// Validate backend reference
+	backendNamespace := user.Spec.Backend.ResolveNamespace(user.Namespace)
+
 	switch user.Spec.Backend.Kind {
 	case "MinioBackend":
 		backend := &ftpv1.MinioBackend{}
@@ -138,7 +180,7 @@ func (r *UserReconciler) validateUser(ctx context.Context, user *ftpv1.User) err
 			Namespace: backendNamespace,
 		}, backend)
 		if err != nil {
-			return fmt.Errorf("failed to find MinioBackend %s/%s: %w", backendNamespace, user.Spec.Backend.Name, err)
+			return backendLookupError("MinioBackend", backendNamespace, user.Spec.Backend.Name, err)
 		}
 	case "WebDavBackend":
 		backend := &ftpv1.WebDavBackend{}
@@ -147,7 +189,7 @@ func (r *UserReconciler) validateUser(ctx context.Context, user *ftpv1.User) err
 			Namespace: backendNamespace,
 		}, backend)
 		if err != nil {
-			return fmt.Errorf("failed to find WebDavBackend %s/%s: %w", backendNamespace, user.Spec.Backend.Name, err)
+			return backendLookupError("WebDavBackend", backendNamespace, user.Spec.Backend.Name, err)
 		}
 	case "FilesystemBackend":
 		backend := &ftpv1.FilesystemBackend{}
@@ -156,7 +198,16 @@ func (r *UserReconciler) validateUser(ctx context.Context, user *ftpv1.User) err
 			Namespace: backendNamespace,
 		}, backend)
 		if err != nil {
-			return fmt.Errorf("failed to find FilesystemBackend %s/%s: %w", backendNamespace, user.Spec.Backend.Name, err)
+			return backendLookupError("FilesystemBackend", backendNamespace, user.Spec.Backend.Name, err)
+		}
+	case "FtpBackend":
+		backend := &ftpv1.FtpBackend{}
+		err := r.Get(ctx, client.ObjectKey{
+			Name:      user.Spec.Backend.Name,
+			Namespace: backendNamespace,
+		}, backend)
+		if err != nil {
+			return backendLookupError("FtpBackend", backendNamespace, user.Spec.Backend.Name, err)
 		}
 	default:
 		return fmt.Errorf("unsupported backend kind: %s", user.Spec.Backend.Kind)
@@ -165,6 +216,48 @@ func (r *UserReconciler) validateUser(ctx context.Context, user *ftpv1.User) err
 	return nil
 }
 
+// backendLookupError describes a failed backend lookup, calling out missing
+// RBAC when a User references a backend in a namespace the operator cannot read
+func backendLookupError(kind, namespace, name string, err error) error {
+	if errors.IsForbidden(err) {
+		return fmt.Errorf("access to %s %s/%s denied; the operator needs permission to read %s resources in namespace %s: %w", kind, namespace, name, kind, namespace, err)
+	}
+	return fmt.Errorf("failed to find %s %s/%s: %w", kind, namespace, name, err)
+}
+
+// validatePasswordSecret checks that the User's PasswordSecret exists and
+// holds a non-empty value under its key. Users without one pass.
+func (r *UserReconciler) validatePasswordSecret(ctx context.Context, user *ftpv1.User) error {
+	ref := user.Spec.PasswordSecret
+	if ref == nil {
+		return nil
+	}
+	namespace := user.Namespace
+	if ref.Namespace != nil && *ref.Namespace != "" {
+		namespace = *ref.Namespace
+	}
+	key := ref.Key
+	if key == "" {
+		key = "password"
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("password secret %s/%s not found", namespace, ref.Name)
+		}
+		return fmt.Errorf("failed to get password secret %s/%s: %w", namespace, ref.Name, err)
+	}
+	value, ok := secret.Data[key]
+	if !ok {
+		return fmt.Errorf("password secret %s/%s has no key %q", namespace, ref.Name, key)
+	}
+	if len(value) == 0 {
+		return fmt.Errorf("key %q of password secret %s/%s is empty", key, namespace, ref.Name)
+	}
+	return nil
+}
+
 // validateUserType validates user type specific requirements
 func (r *UserReconciler) validateUserType(user *ftpv1.User, userType string) error {
 	switch userType {
@@ -224,6 +317,73 @@ func (r *UserReconciler) validateRegularUser(user *ftpv1.User) error {
 	return nil
 }
 
+// resolveEffectivePermissions returns the permissions in force for the user. When a
+// PermissionTemplate is referenced its permissions apply in place of the inline
+// block, with each field the user sets in PermissionOverrides replacing the template's.
+func (r *UserReconciler) resolveEffectivePermissions(ctx context.Context, user *ftpv1.User) (ftpv1.UserPermissions, error) {
+	ref := user.Spec.PermissionTemplateRef
+	if ref == nil {
+		return user.Spec.Permissions, nil
+	}
+
+	templateNamespace := user.Namespace
+	if ref.Namespace != nil && *ref.Namespace != "" {
+		templateNamespace = *ref.Namespace
+	}
+
+	template := &ftpv1.PermissionTemplate{}
+	if err := r.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: templateNamespace}, template); err != nil {
+		return ftpv1.UserPermissions{}, fmt.Errorf("failed to find PermissionTemplate %s/%s: %w", templateNamespace, ref.Name, err)
+	}
+
+	// Overrides are pointers so an explicit false denies a permission the
+	// template grants; the inline Permissions block cannot tell false from unset
+	permissions := template.Spec.Permissions
+	if overrides := user.Spec.PermissionOverrides; overrides != nil {
+		if overrides.Read != nil {
+			permissions.Read = *overrides.Read
+		}
+		if overrides.Write != nil {
+			permissions.Write = *overrides.Write
+		}
+		if overrides.Delete != nil {
+			permissions.Delete = *overrides.Delete
+		}
+		if overrides.List != nil {
+			permissions.List = *overrides.List
+		}
+	}
+	return permissions, nil
+}
+
+// usersForPermissionTemplate maps a PermissionTemplate to the users that reference it
+func (r *UserReconciler) usersForPermissionTemplate(ctx context.Context, obj client.Object) []reconcile.Request {
+	userList := &ftpv1.UserList{}
+	if err := r.List(ctx, userList); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list users for PermissionTemplate", "template", obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, user := range userList.Items {
+		ref := user.Spec.PermissionTemplateRef
+		if ref == nil || ref.Name != obj.GetName() {
+			continue
+		}
+		templateNamespace := user.Namespace
+		if ref.Namespace != nil && *ref.Namespace != "" {
+			templateNamespace = *ref.Namespace
+		}
+		if templateNamespace != obj.GetNamespace() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKey{Name: user.Name, Namespace: user.Namespace},
+		})
+	}
+	return requests
+}
+
 // updateUserStatus updates the user status with the given condition
 func (r *UserReconciler) updateUserStatus(ctx context.Context, user *ftpv1.User, condition metav1.Condition) {
 	user.Status.Conditions = []metav1.Condition{condition}
@@ -253,6 +413,7 @@ func (r *UserReconciler) handleUserDeletion(ctx context.Context, user *ftpv1.Use
 func (r *UserReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&ftpv1.User{}).
+		Watches(&ftpv1.PermissionTemplate{}, handler.EnqueueRequestsFromMapFunc(r.usersForPermissionTemplate)).
 		Named("user").
 		Complete(r)
 }
@@ -23,17 +23,74 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
 )
 
+// TestUserHomeDirectoryValidation tests that home directories must be absolute and normalized
+func TestUserHomeDirectoryValidation(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = ftpv1.AddToScheme(scheme)
+
+	backend := &ftpv1.FilesystemBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-backend", Namespace: "default"},
+		Spec:       ftpv1.FilesystemBackendSpec{BasePath: "/tmp/test"},
+	}
+	reconciler := &UserReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(backend).Build(),
+		Scheme: scheme,
+	}
+
+	tests := []struct {
+		name          string
+		homeDirectory string
+		errorMsg      string
+	}{
+		{name: "absolute home accepted", homeDirectory: "/home/testuser"},
+		{name: "root home accepted", homeDirectory: "/"},
+		{name: "empty home rejected", homeDirectory: "", errorMsg: "homeDirectory is required"},
+		{name: "relative home rejected", homeDirectory: "home/testuser", errorMsg: "must be an absolute path"},
+		{name: "traversal rejected", homeDirectory: "/home/testuser/../../etc", errorMsg: "must not contain '..'"},
+		{name: "trailing traversal rejected", homeDirectory: "/home/..", errorMsg: "must not contain '..'"},
+		{name: "duplicate slashes rejected", homeDirectory: "/home//testuser", errorMsg: "is not normalized"},
+		{name: "trailing slash rejected", homeDirectory: "/home/testuser/", errorMsg: "is not normalized"},
+		{name: "dot segment rejected", homeDirectory: "/home/./testuser", errorMsg: "is not normalized"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &ftpv1.User{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-user", Namespace: "default"},
+				Spec: ftpv1.UserSpec{
+					Username:      "testuser",
+					Password:      "testpass",
+					HomeDirectory: tt.homeDirectory,
+					Backend:       ftpv1.BackendReference{Kind: "FilesystemBackend", Name: "test-backend"},
+					Permissions:   ftpv1.UserPermissions{Read: true, Write: true, List: true},
+				},
+			}
+
+			err := reconciler.validateUser(context.Background(), user)
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
 // TestUserTypeValidation tests the validation of different user types
 func TestUserTypeValidation(t *testing.T) {
 	scheme := runtime.NewScheme()
@@ -588,6 +645,286 @@ func TestUserReconciler_Reconcile(t *testing.T) {
 	}
 }
 
+func TestUserReconciler_PermissionTemplate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := ftpv1.AddToScheme(scheme)
+	assert.NoError(t, err)
+
+	backend := &ftpv1.FilesystemBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backend",
+			Namespace: "default",
+		},
+		Spec: ftpv1.FilesystemBackendSpec{
+			BasePath: "/data",
+		},
+	}
+
+	template := &ftpv1.PermissionTemplate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "read-only",
+			Namespace: "default",
+		},
+		Spec: ftpv1.PermissionTemplateSpec{
+			Permissions: ftpv1.UserPermissions{
+				Read: true,
+				List: true,
+			},
+		},
+	}
+
+	allow, deny := true, false
+
+	tests := []struct {
+		name          string
+		templateName  string
+		inline        ftpv1.UserPermissions
+		overrides     *ftpv1.PermissionOverrides
+		wantReady     bool
+		wantEffective *ftpv1.UserPermissions
+	}{
+		{
+			name:          "user inherits template permissions",
+			templateName:  "read-only",
+			wantReady:     true,
+			wantEffective: &ftpv1.UserPermissions{Read: true, List: true},
+		},
+		{
+			name:          "overrides replace individual template fields",
+			templateName:  "read-only",
+			overrides:     &ftpv1.PermissionOverrides{Write: &allow, List: &deny},
+			wantReady:     true,
+			wantEffective: &ftpv1.UserPermissions{Read: true, Write: true},
+		},
+		{
+			name:          "overrides denying everything",
+			templateName:  "read-only",
+			overrides:     &ftpv1.PermissionOverrides{Read: &deny, Write: &deny, Delete: &deny, List: &deny},
+			wantReady:     true,
+			wantEffective: &ftpv1.UserPermissions{},
+		},
+		{
+			name:          "inline permissions do not replace the template",
+			templateName:  "read-only",
+			inline:        ftpv1.UserPermissions{Read: true, Write: true, Delete: true, List: true},
+			wantReady:     true,
+			wantEffective: &ftpv1.UserPermissions{Read: true, List: true},
+		},
+		{
+			name:         "missing template",
+			templateName: "does-not-exist",
+			wantReady:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &ftpv1.User{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-user",
+					Namespace:  "default",
+					Finalizers: []string{"ftp.golder.org/finalizer"},
+				},
+				Spec: ftpv1.UserSpec{
+					Username:      "testuser",
+					Password:      "testpass",
+					Enabled:       true,
+					HomeDirectory: "/home/testuser",
+					Backend: ftpv1.BackendReference{
+						Kind: "FilesystemBackend",
+						Name: "test-backend",
+					},
+					Permissions:           tt.inline,
+					PermissionTemplateRef: &ftpv1.PermissionTemplateReference{Name: tt.templateName},
+					PermissionOverrides:   tt.overrides,
+				},
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(user, backend, template).
+				WithStatusSubresource(&ftpv1.User{}).
+				Build()
+
+			reconciler := &UserReconciler{
+				Client: fakeClient,
+				Scheme: scheme,
+			}
+
+			_, err := reconciler.Reconcile(context.Background(), reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: user.Name, Namespace: user.Namespace},
+			})
+			assert.NoError(t, err)
+
+			updated := &ftpv1.User{}
+			err = fakeClient.Get(context.Background(), types.NamespacedName{Name: user.Name, Namespace: user.Namespace}, updated)
+			assert.NoError(t, err)
+
+			if assert.Len(t, updated.Status.Conditions, 1) {
+				assert.Equal(t, tt.wantReady, updated.Status.Conditions[0].Status == metav1.ConditionTrue)
+			}
+			assert.Equal(t, tt.wantEffective, updated.Status.EffectivePermissions)
+		})
+	}
+}
+
+func TestUserReconciler_RequirePasswordSecretKeys(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, ftpv1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	backend := &ftpv1.FilesystemBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-backend", Namespace: "default"},
+		Spec:       ftpv1.FilesystemBackendSpec{BasePath: "/data"},
+	}
+	secret := func(data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "ftp-password", Namespace: "default"},
+			Data:       data,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		secret      *corev1.Secret
+		key         string
+		disabled    bool
+		wantReady   bool
+		wantMessage string
+	}{
+		{
+			name:      "present key is ready",
+			secret:    secret(map[string][]byte{"password": []byte("s3cret")}),
+			wantReady: true,
+		},
+		{
+			name:      "custom key is ready",
+			secret:    secret(map[string][]byte{"ftp": []byte("s3cret")}),
+			key:       "ftp",
+			wantReady: true,
+		},
+		{
+			name:        "missing secret",
+			wantMessage: "password secret default/ftp-password not found",
+		},
+		{
+			name:        "missing key",
+			secret:      secret(map[string][]byte{"other": []byte("s3cret")}),
+			wantMessage: `password secret default/ftp-password has no key "password"`,
+		},
+		{
+			name:        "empty value",
+			secret:      secret(map[string][]byte{"password": {}}),
+			wantMessage: `key "password" of password secret default/ftp-password is empty`,
+		},
+		{
+			name:      "missing secret is ignored when not required",
+			disabled:  true,
+			wantReady: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &ftpv1.User{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-user",
+					Namespace:  "default",
+					Finalizers: []string{"ftp.golder.org/finalizer"},
+				},
+				Spec: ftpv1.UserSpec{
+					Username:       "testuser",
+					PasswordSecret: &ftpv1.UserSecretRef{Name: "ftp-password", Key: tt.key},
+					Enabled:        true,
+					HomeDirectory:  "/home/testuser",
+					Backend: ftpv1.BackendReference{
+						Kind: "FilesystemBackend",
+						Name: "test-backend",
+					},
+				},
+			}
+
+			objs := []client.Object{user, backend}
+			if tt.secret != nil {
+				objs = append(objs, tt.secret)
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objs...).
+				WithStatusSubresource(&ftpv1.User{}).
+				Build()
+
+			reconciler := &UserReconciler{
+				Client:                    fakeClient,
+				Scheme:                    scheme,
+				RequirePasswordSecretKeys: !tt.disabled,
+			}
+
+			result, err := reconciler.Reconcile(context.Background(), reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: user.Name, Namespace: user.Namespace},
+			})
+			assert.NoError(t, err)
+
+			updated := &ftpv1.User{}
+			assert.NoError(t, fakeClient.Get(context.Background(), types.NamespacedName{Name: user.Name, Namespace: user.Namespace}, updated))
+			if !assert.Len(t, updated.Status.Conditions, 1) {
+				return
+			}
+			condition := updated.Status.Conditions[0]
+			if tt.wantReady {
+				assert.Equal(t, metav1.ConditionTrue, condition.Status)
+				return
+			}
+			assert.Equal(t, metav1.ConditionFalse, condition.Status)
+			assert.Equal(t, "PasswordSecretInvalid", condition.Reason)
+			assert.Equal(t, tt.wantMessage, condition.Message)
+			assert.True(t, result.RequeueAfter > 0, "an unusable secret should be rechecked")
+		})
+	}
+}
+
+func TestUserReconciler_usersForPermissionTemplate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := ftpv1.AddToScheme(scheme)
+	assert.NoError(t, err)
+
+	otherNamespace := "shared"
+	users := []client.Object{
+		&ftpv1.User{
+			ObjectMeta: metav1.ObjectMeta{Name: "same-ns", Namespace: "default"},
+			Spec:       ftpv1.UserSpec{PermissionTemplateRef: &ftpv1.PermissionTemplateReference{Name: "uploaders"}},
+		},
+		&ftpv1.User{
+			ObjectMeta: metav1.ObjectMeta{Name: "cross-ns", Namespace: "team-a"},
+			Spec: ftpv1.UserSpec{PermissionTemplateRef: &ftpv1.PermissionTemplateReference{
+				Name: "uploaders", Namespace: &otherNamespace,
+			}},
+		},
+		&ftpv1.User{
+			ObjectMeta: metav1.ObjectMeta{Name: "no-template", Namespace: "default"},
+		},
+	}
+
+	reconciler := &UserReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(users...).Build(),
+		Scheme: scheme,
+	}
+
+	requests := reconciler.usersForPermissionTemplate(context.Background(), &ftpv1.PermissionTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "uploaders", Namespace: "default"},
+	})
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "same-ns", Namespace: "default"}},
+	}, requests)
+
+	requests = reconciler.usersForPermissionTemplate(context.Background(), &ftpv1.PermissionTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "uploaders", Namespace: "shared"},
+	})
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "cross-ns", Namespace: "team-a"}},
+	}, requests)
+}
+
 func TestUserReconciler_validateUser(t *testing.T) {
 	scheme := runtime.NewScheme()
 	err := ftpv1.AddToScheme(scheme)
@@ -736,3 +1073,92 @@ func TestUserReconciler_validateUser(t *testing.T) {
 		})
 	}
 }
+
+func TestUserReconciler_validateUser_CrossNamespaceBackend(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := ftpv1.AddToScheme(scheme)
+	assert.NoError(t, err)
+
+	sharedBackend := &ftpv1.FilesystemBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "shared-backend",
+			Namespace: "shared",
+		},
+		Spec: ftpv1.FilesystemBackendSpec{BasePath: "/data"},
+	}
+
+	newUser := func(namespace *string) *ftpv1.User {
+		return &ftpv1.User{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "testuser",
+				Namespace: "team-a",
+			},
+			Spec: ftpv1.UserSpec{
+				Username:      "testuser",
+				Password:      "testpass",
+				HomeDirectory: "/home/testuser",
+				Backend: ftpv1.BackendReference{
+					Kind:      "FilesystemBackend",
+					Name:      "shared-backend",
+					Namespace: namespace,
+				},
+			},
+		}
+	}
+	shared := "shared"
+	other := "other"
+
+	forbidden := interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if key.Namespace == "shared" {
+				return errors.NewForbidden(schema.GroupResource{Group: "ftp.golder.org", Resource: "filesystembackends"}, key.Name, nil)
+			}
+			return c.Get(ctx, key, obj, opts...)
+		},
+	}
+
+	tests := []struct {
+		name         string
+		user         *ftpv1.User
+		interceptors *interceptor.Funcs
+		errMsg       string
+	}{
+		{
+			name: "backend in referenced namespace",
+			user: newUser(&shared),
+		},
+		{
+			name:   "backend defaults to the user's namespace",
+			user:   newUser(nil),
+			errMsg: "failed to find FilesystemBackend team-a/shared-backend",
+		},
+		{
+			name:   "backend absent from referenced namespace",
+			user:   newUser(&other),
+			errMsg: "failed to find FilesystemBackend other/shared-backend",
+		},
+		{
+			name:         "operator lacks RBAC in referenced namespace",
+			user:         newUser(&shared),
+			interceptors: &forbidden,
+			errMsg:       "access to FilesystemBackend shared/shared-backend denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sharedBackend)
+			if tt.interceptors != nil {
+				builder = builder.WithInterceptorFuncs(*tt.interceptors)
+			}
+			reconciler := &UserReconciler{Client: builder.Build(), Scheme: scheme}
+
+			err := reconciler.validateUser(context.Background(), tt.user)
+			if tt.errMsg != "" {
+				assert.ErrorContains(t, err, tt.errMsg)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -37,6 +37,9 @@ import (
 type WebDavBackendReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// SelfTests, when set, runs a write-read-delete self-test alongside each
+	// connectivity test and marks backends that fail it as not ready
+	SelfTests *BackendSelfTests
 }
 
 // +kubebuilder:rbac:groups=ftp.golder.org,resources=webdavbackends,verbs=get;list;watch;create;update;patch;delete
@@ -74,8 +77,10 @@ func (r *WebDavBackendReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return r.handleWebDavBackendDeletion(ctx, backend)
 	}
 
-	// Only test connectivity if this is a new backend or if the spec has changed
-	shouldTestConnectivity := r.shouldTestConnectivity(backend)
+	// Only test connectivity if this is a new backend, if the spec has changed
+	// or if this process has not self-tested it yet
+	shouldTestConnectivity := r.shouldTestConnectivity(backend) ||
+		r.SelfTests.Pending("WebDavBackend", backend.Namespace, backend.Name)
 
 	if shouldTestConnectivity {
 		// Test connectivity to WebDAV
@@ -97,6 +102,27 @@ func (r *WebDavBackendReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			return ctrl.Result{RequeueAfter: time.Minute * 5}, nil
 		}
 
+		// Confirm the credentials can write, read and delete, not just connect
+		if r.SelfTests != nil {
+			err := r.runSelfTest(ctx, backend)
+			r.SelfTests.Record("WebDavBackend", backend.Namespace, backend.Name, err)
+			if err != nil {
+				log.Error(err, "WebDAV self-test failed", "backend", backend.Name)
+				r.updateWebDavBackendStatus(ctx, backend, metav1.Condition{
+					Type:               "Ready",
+					Status:             metav1.ConditionFalse,
+					Reason:             "SelfTestFailed",
+					Message:            err.Error(),
+					LastTransitionTime: metav1.Now(),
+				})
+				r.markConnectivityTested(backend)
+				if err := r.Status().Update(ctx, backend); err != nil {
+					log.Error(err, "Failed to update connectivity test timestamp")
+				}
+				return ctrl.Result{}, nil
+			}
+		}
+
 		// Update status to ready
 		r.updateWebDavBackendStatus(ctx, backend, metav1.Condition{
 			Type:               "Ready",
@@ -131,6 +157,15 @@ func (r *WebDavBackendReconciler) testWebDavConnectivity(ctx context.Context, ba
 	return nil
 }
 
+// runSelfTest writes, reads back and deletes a temporary object on the backend
+func (r *WebDavBackendReconciler) runSelfTest(ctx context.Context, backend *ftpv1.WebDavBackend) error {
+	b, err := backends.NewWebDavBackend(ctx, backend, r.Client)
+	if err != nil {
+		return fmt.Errorf("failed to create WebDAV backend: %w", err)
+	}
+	return backends.SelfTestWebDav(b)
+}
+
 // shouldTestConnectivity determines if we should test connectivity for this backend
 func (r *WebDavBackendReconciler) shouldTestConnectivity(backend *ftpv1.WebDavBackend) bool {
 	// Always test if we haven't tested before
@@ -161,6 +196,7 @@ func (r *WebDavBackendReconciler) updateWebDavBackendStatus(ctx context.Context,
 func (r *WebDavBackendReconciler) handleWebDavBackendDeletion(ctx context.Context, backend *ftpv1.WebDavBackend) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
 	log.Info("Handling WebDavBackend deletion", "backend", backend.Name)
+	r.SelfTests.Forget("WebDavBackend", backend.Namespace, backend.Name)
 
 	// Perform any cleanup operations here
 	// For now, we just remove the finalizer
@@ -0,0 +1,124 @@
+package ftp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"goftp.io/server/v2"
+)
+
+// errUploadSizeRequired rejects uploads from quota-limited users that did not
+// announce the file size with ALLO when RequireUploadSize is enabled.
+var errUploadSizeRequired = errors.New("upload size must be announced with ALLO before STOR")
+
+// errUploadSizeMismatch rejects uploads whose byte count differs from the size
+// announced with ALLO when RejectUploadSizeMismatch is enabled.
+var errUploadSizeMismatch = errors.New("upload size does not match the size announced with ALLO")
+
+// commandAllo replaces goftp's no-op ALLO so the announced size of the next
+// upload is remembered for quota pre-checks.
+type commandAllo struct {
+	auth *KubeAuth
+}
+
+func (cmd commandAllo) IsExtend() bool {
+	return false
+}
+
+func (cmd commandAllo) RequireParam() bool {
+	return true
+}
+
+func (cmd commandAllo) RequireAuth() bool {
+	return true
+}
+
+func (cmd commandAllo) Execute(sess *server.Session, param string) {
+	size, err := parseAlloParam(param)
+	if err != nil {
+		sess.WriteMessage(501, err.Error())
+		return
+	}
+
+	cmd.auth.setSessionUploadSize(sessionIDForAddr(sess.RemoteAddr()), size)
+	sess.WriteMessage(200, fmt.Sprintf("ALLO %d bytes accepted", size))
+}
+
+// parseAlloParam parses "ALLO <size> [R <record-size>]", ignoring the record size
+func parseAlloParam(param string) (int64, error) {
+	fields := strings.Fields(param)
+	if len(fields) == 0 {
+		return 0, errors.New("missing size")
+	}
+	size, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size %q", fields[0])
+	}
+	return size, nil
+}
+
+// announcedUploadSize returns and forgets the size announced with ALLO for the
+// session's next upload, or -1 when none was announced.
+func (driver *KubeDriver) announcedUploadSize(ctx *server.Context) int64 {
+	if driver.auth == nil {
+		return -1
+	}
+	sessionID := driver.auth.getSessionID(ctx)
+	if sessionID == "" {
+		sessionID = driver.sessionID
+	}
+	return driver.auth.takeSessionUploadSize(sessionID)
+}
+
+// checkUploadSize enforces RequireUploadSize for users with a byte quota and
+// rejects announced uploads that would not fit in the remaining quota.
+func (driver *KubeDriver) checkUploadSize(announced int64) error {
+	limit := driver.user.Spec.QuotaBytes
+	if limit <= 0 {
+		return nil
+	}
+	if announced < 0 {
+		if driver.requireUploadSize {
+			return errUploadSizeRequired
+		}
+		return nil
+	}
+
+	used, err := driver.quotaUsage()
+	if err != nil {
+		return fmt.Errorf("failed to compute quota usage: %w", err)
+	}
+	if used+announced > limit {
+		return fmt.Errorf("%w: upload of %d bytes exceeds remaining %d of %d bytes", errQuotaExceeded, announced, max(limit-used, 0), limit)
+	}
+	return nil
+}
+
+// checksUploadSize reports whether RejectUploadSizeMismatch applies to an
+// upload. ASCII conversion changes the byte count, and removing a mismatched
+// append would lose the data already stored, so only whole binary uploads
+// with an announced size are checked.
+func (driver *KubeDriver) checksUploadSize(announced, offset int64, ascii bool) bool {
+	return driver.rejectSizeMismatch && announced >= 0 && offset == 0 && !ascii
+}
+
+// checkUploadSizeMatch enforces RejectUploadSizeMismatch once an upload has been
+// stored at resolvedPath, failing it when the bytes received differ from the
+// announced size. A new file is deleted so a truncated or padded upload is not
+// left behind; one that replaced an existing file is kept, since deleting it
+// would leave the user with neither version.
+func (driver *KubeDriver) checkUploadSizeMatch(resolvedPath string, announced, received int64, replaced bool) error {
+	if received == announced {
+		return nil
+	}
+	if replaced {
+		driver.operationLogger().Info("Keeping upload with mismatched size that replaced an existing file",
+			"username", driver.getAuthenticatedUsername(), "resolved_path", resolvedPath)
+	} else if err := driver.storageImpl.DeleteFile(resolvedPath); err != nil {
+		driver.operationLogger().Error(err, "Failed to remove upload with mismatched size",
+			"username", driver.getAuthenticatedUsername(), "resolved_path", resolvedPath)
+	}
+	return fmt.Errorf("%w: received %d of %d bytes", errUploadSizeMismatch, received, announced)
+}
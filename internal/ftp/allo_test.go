@@ -0,0 +1,164 @@
+package ftp
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rossigee/kubeftpd/internal/storage"
+)
+
+func TestParseAlloParam(t *testing.T) {
+	tests := []struct {
+		name    string
+		param   string
+		want    int64
+		wantErr bool
+	}{
+		{name: "size only", param: "1024", want: 1024},
+		{name: "with record size", param: "2048 R 512", want: 2048},
+		{name: "zero", param: "0", want: 0},
+		{name: "negative", param: "-1", wantErr: true},
+		{name: "not a number", param: "lots", wantErr: true},
+		{name: "empty", param: " ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, err := parseAlloParam(tt.param)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, size)
+		})
+	}
+}
+
+func TestKubeAuth_SessionUploadSize(t *testing.T) {
+	auth := NewKubeAuth(nil)
+
+	assert.Equal(t, int64(-1), auth.takeSessionUploadSize("ftp-session-1"))
+
+	auth.setSessionUploadSize("ftp-session-1", 42)
+	assert.Equal(t, int64(42), auth.takeSessionUploadSize("ftp-session-1"))
+	assert.Equal(t, int64(-1), auth.takeSessionUploadSize("ftp-session-1"), "announcement applies to one upload only")
+}
+
+func TestKubeDriver_PutFile_RequireUploadSize(t *testing.T) {
+	tests := []struct {
+		name              string
+		quotaBytes        int64
+		requireUploadSize bool
+		announce          int64
+		wantErr           string
+	}{
+		{name: "unannounced with quota is rejected", quotaBytes: 1000, requireUploadSize: true, announce: -1, wantErr: errUploadSizeRequired.Error()},
+		{name: "announced within quota is accepted", quotaBytes: 1000, requireUploadSize: true, announce: 4},
+		{name: "announced beyond quota is rejected", quotaBytes: 1000, requireUploadSize: true, announce: 900, wantErr: "quota exceeded"},
+		{name: "announced beyond quota is rejected without the option", quotaBytes: 1000, announce: 900, wantErr: "quota exceeded"},
+		{name: "unannounced without the option is accepted", quotaBytes: 1000, announce: -1},
+		{name: "unannounced without a quota is accepted", requireUploadSize: true, announce: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver, mockStorage := newQuotaTestDriver(tt.quotaBytes, false)
+			driver.auth = NewKubeAuth(nil)
+			driver.sessionID = "ftp-session-127.0.0.1:50000"
+			driver.requireUploadSize = tt.requireUploadSize
+			if tt.announce >= 0 {
+				driver.auth.setSessionUploadSize(driver.sessionID, tt.announce)
+			}
+
+			reader := strings.NewReader("data")
+			mockStorage.On("PutFile", "/new.csv", reader, int64(0)).Return(int64(4), nil).Maybe()
+
+			_, err := driver.PutFile(nil, "/new.csv", reader, 0)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				mockStorage.AssertNotCalled(t, "PutFile", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestKubeDriver_PutFile_RejectUploadSizeMismatch(t *testing.T) {
+	tests := []struct {
+		name               string
+		rejectSizeMismatch bool
+		announce           int64
+		existing           bool
+		wantErr            bool
+		wantDelete         bool
+	}{
+		{name: "matching size is accepted", rejectSizeMismatch: true, announce: 4},
+		{name: "mismatched size is rejected and removed", rejectSizeMismatch: true, announce: 10, wantErr: true, wantDelete: true},
+		{name: "mismatched overwrite is rejected but kept", rejectSizeMismatch: true, announce: 10, existing: true, wantErr: true},
+		{name: "unannounced size is accepted", rejectSizeMismatch: true, announce: -1},
+		{name: "mismatched size is accepted without the option", announce: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver, mockStorage := newQuotaTestDriver(0, false)
+			driver.auth = NewKubeAuth(nil)
+			driver.sessionID = "ftp-session-127.0.0.1:50000"
+			driver.rejectSizeMismatch = tt.rejectSizeMismatch
+			if tt.announce >= 0 {
+				driver.auth.setSessionUploadSize(driver.sessionID, tt.announce)
+			}
+
+			reader := strings.NewReader("data")
+			if tt.existing {
+				mockStorage.On("Stat", "/new.csv").Return(&MockFileInfo{name: "new.csv", size: 10}, nil).Maybe()
+			} else {
+				mockStorage.On("Stat", "/new.csv").Return((*MockFileInfo)(nil), os.ErrNotExist).Maybe()
+			}
+			mockStorage.On("PutFile", "/new.csv", reader, int64(0)).Return(int64(4), nil)
+			mockStorage.On("DeleteFile", "/new.csv").Return(nil).Maybe()
+
+			size, err := driver.PutFile(nil, "/new.csv", reader, 0)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errUploadSizeMismatch)
+				assert.ErrorContains(t, err, "received 4 of 10 bytes")
+				if tt.wantDelete {
+					mockStorage.AssertCalled(t, "DeleteFile", "/new.csv")
+				} else {
+					mockStorage.AssertNotCalled(t, "DeleteFile", mock.Anything)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(4), size)
+			mockStorage.AssertNotCalled(t, "DeleteFile", mock.Anything)
+		})
+	}
+}
+
+func TestKubeDriver_PutFile_RejectUploadSizeMismatchDatePartition(t *testing.T) {
+	driver, mockStorage := newQuotaTestDriver(0, false)
+	mockStorage.capabilities = storage.Capabilities{DatePartition: true}
+	driver.auth = NewKubeAuth(nil)
+	driver.sessionID = "ftp-session-127.0.0.1:50000"
+	driver.rejectSizeMismatch = true
+	driver.auth.setSessionUploadSize(driver.sessionID, 10)
+
+	reader := strings.NewReader("data")
+	mockStorage.On("Stat", datePartitioned("new.csv")).Return((*MockFileInfo)(nil), os.ErrNotExist)
+	mockStorage.On("PutFile", datePartitioned("new.csv"), reader, int64(0)).Return(int64(4), nil)
+	mockStorage.On("DeleteFile", datePartitioned("new.csv")).Return(nil)
+
+	_, err := driver.PutFile(nil, "/new.csv", reader, 0)
+	assert.ErrorIs(t, err, errUploadSizeMismatch)
+	// The file removed is the one stored under the date directories
+	mockStorage.AssertCalled(t, "DeleteFile", datePartitioned("new.csv"))
+	mockStorage.AssertNotCalled(t, "DeleteFile", "/new.csv")
+}
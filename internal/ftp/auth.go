@@ -4,18 +4,25 @@ import (
 	"context"
 	"crypto/subtle"
 	"fmt"
+	"net"
+	"regexp"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"goftp.io/server/v2"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
 	"github.com/rossigee/kubeftpd/internal/metrics"
+	"github.com/rossigee/kubeftpd/internal/storage"
 )
 
 var (
@@ -61,12 +68,80 @@ func recordAuthFailure(reason string) {
 	authFailures.WithLabelValues(reason).Inc()
 }
 
+// userCacheTTL is how long a cached user is served before GetUser revalidates it
+// against the API server. It matches the background cache refresh interval.
+const userCacheTTL = 5 * time.Minute
+
 // KubeAuth implements FTP authentication against Kubernetes User CRDs
 type KubeAuth struct {
-	client         client.Client
-	userCache      sync.Map // Thread-safe cache for User objects: string -> *ftpv1.User
-	sessionUserMap sync.Map // Thread-safe map for session-based authentication: sessionID -> string
-	bruteForce     *BruteForceProtector
+	client             client.Client
+	userCache          sync.Map // Thread-safe cache for User objects: string -> *ftpv1.User
+	userLoadedAt       sync.Map // Time each cached user was last loaded from the API server: string -> time.Time
+	loginAliases       sync.Map // Login alias of a cached user: alias -> canonical username
+	userAliases        sync.Map // Login aliases registered for a cached user: canonical username -> []string
+	sessionUserMap     sync.Map // Thread-safe map for session-based authentication: sessionID -> string
+	sessionHostMap     sync.Map // Virtual host selected with HOST: sessionID -> string
+	sessionAllo        sync.Map // Upload size announced with ALLO: sessionID -> int64
+	sessionCaps        sync.Map // Capabilities of the session's storage: sessionID -> storage.Capabilities
+	sessionTypes       sync.Map // Transfer type set with TYPE or the user's default: sessionID -> string
+	sessionLegacyNames sync.Map // Sessions that sent OPTS UTF8 OFF: sessionID -> struct{}
+	legacyListNames    sync.Map // Entries being listed under Latin-1 names: Latin-1 path -> UTF-8 path
+	sessionErrorLogs   sync.Map // Repeated error collapsing: sessionID -> *errorLogLimiter
+	sessionDataIdle    sync.Map // Pending close of an unused passive data connection: sessionID -> *sessionTimer
+	sessionDataConns   sync.Map // Open passive data channels: sessionID -> *dataChannels
+	sessionConns       sync.Map // Control connection of each session: sessionID -> *sessionConn
+	sessionDeadlines   sync.Map // Pending close at the user's MaxSessionDuration: sessionID -> *sessionTimer
+	ambiguousNames     sync.Map // Usernames defined by more than one enabled User: username -> []string
+	chrootViolations   sync.Map // Chroot violations counted toward AutoDisableOnViolations: namespace/name -> *violationCount
+	bruteForce         *BruteForceProtector
+	// singleSessionMu serializes logins of users with SingleSession set
+	singleSessionMu sync.Mutex
+	// MaxStaleness is how long past userCacheTTL a cached user may still be served
+	// when the API server cannot be reached to revalidate it. Zero disables the grace.
+	MaxStaleness time.Duration
+	// Maintenance, when it holds a message, rejects new logins. May be nil.
+	Maintenance *MaintenanceMode
+	// DataIdleTimeout closes a passive data connection that no transfer has used
+	// for this long. Zero leaves data connections open.
+	DataIdleTimeout time.Duration
+	// MaxDataConnsPerSession refuses PASV and EPSV with 425 while a session
+	// holds this many open passive data channels. Zero leaves it unlimited.
+	MaxDataConnsPerSession int
+	// RetryHint is appended to transient rejections caused by rate limits and
+	// concurrency caps, e.g. "retry in 30 seconds". Empty sends no hint.
+	RetryHint string
+	// RequireUniqueUsernames refuses logins for a username that more than one
+	// enabled User defines, instead of serving whichever the API server lists first
+	RequireUniqueUsernames bool
+	// AutoDisableOnViolations sets enabled to false on a User once its sessions
+	// have tried this many paths outside the home directory within an hour.
+	// Counts are kept in memory per replica. Zero disables it.
+	AutoDisableOnViolations int
+	// ViolationEvents, when set, records a Warning Event on each User disabled
+	// by AutoDisableOnViolations
+	ViolationEvents events.EventRecorder
+	// SystemType is the reply to SYST. Empty sends "UNIX Type: L8".
+	SystemType string
+	// HideServerIdentity answers STAT without the server name and version
+	HideServerIdentity bool
+	// AllowUTF8Off honours OPTS UTF8 OFF, after which the session's paths are
+	// Latin-1. Otherwise goftp refuses it.
+	AllowUTF8Off bool
+	// FilenameCharset, when set, matches the file names STOR, APPE and RNTO
+	// may create. Others are refused with 553.
+	FilenameCharset *regexp.Regexp
+	// ErrorLogInterval, when positive, collapses identical consecutive errors
+	// logged by a session's operations into one summary line per interval
+	ErrorLogInterval time.Duration
+	// ICAPScanner, when set, scans uploads before they are stored and
+	// rejects infected ones
+	ICAPScanner *icapScanner
+	// ShowHomePath makes PWD show users without chroot their home directory
+	// path, e.g. /home/alice/docs instead of /docs
+	ShowHomePath bool
+	// UsersFile, when set, is a YAML file of User manifests served instead of
+	// the User resources in the cluster
+	UsersFile string
 }
 
 // NewKubeAuth creates a new KubeAuth instance
@@ -82,12 +157,36 @@ func (auth *KubeAuth) CheckPasswd(ctx *server.Context, username, password string
 	logger := ctrl.Log.WithName("auth")
 	logger.Info("Authenticating user", "username", username)
 
+	// result is reported on the auth span when it ends
+	result := "failure"
+	var span trace.Span
+	if isTracingEnabled() {
+		_, span = tracer.Start(context.Background(), "ftp.auth",
+			trace.WithAttributes(
+				attribute.String("ftp.user", username),
+			))
+		defer func() {
+			span.SetAttributes(attribute.String("ftp.auth.result", result))
+			span.End()
+		}()
+	}
+
 	clientIP := auth.clientIPFromCtx(ctx)
 
+	// Refuse new logins during maintenance; established sessions are unaffected
+	if message := auth.Maintenance.Message(); message != "" {
+		logger.Info("Rejecting login during maintenance", "username", username, "message", message)
+		recordAuthFailure("maintenance")
+		metrics.RecordUserLogin("maintenance")
+		result = "maintenance"
+		return false, nil
+	}
+
 	// Reject immediately if the username or source IP is currently locked out.
 	if auth.bruteForce.IsLockedOut(username, clientIP) {
 		recordAuthFailure("locked_out")
 		metrics.RecordUserLogin("locked_out")
+		result = "locked_out"
 		return false, nil
 	}
 
@@ -100,15 +199,37 @@ func (auth *KubeAuth) CheckPasswd(ctx *server.Context, username, password string
 		logger.Info("User not found", "username", username)
 		auth.bruteForce.RecordFailure(username, clientIP)
 		metrics.RecordUserLogin("user_not_found")
+		result = "user_not_found"
 		return false, nil
 	}
 
+	if span != nil {
+		span.SetAttributes(
+			attribute.String("ftp.backend", user.Spec.Backend.Kind),
+			attribute.String("ftp.user_type", user.Spec.Type),
+		)
+	}
+
 	// Check if user is enabled
 	if !user.Spec.Enabled {
 		logger.Info("User is disabled", "username", username)
 		auth.bruteForce.RecordFailure(username, clientIP)
 		recordAuthFailure("user_disabled")
 		metrics.RecordUserLogin("failure")
+		result = "user_disabled"
+		return false, nil
+	}
+
+	// Enforce the user's client address restrictions
+	if allowed, err := auth.isClientIPAllowed(authCtx, user, clientIP); !allowed {
+		if err != nil {
+			logger.Error(err, "Failed to evaluate client address restrictions", "username", username, "client_ip", clientIP)
+		} else {
+			logger.Info("Client address not permitted for user", "username", username, "client_ip", clientIP)
+		}
+		recordAuthFailure("ip_denied")
+		metrics.RecordUserLogin("failure")
+		result = "ip_denied"
 		return false, nil
 	}
 
@@ -133,6 +254,7 @@ func (auth *KubeAuth) CheckPasswd(ctx *server.Context, username, password string
 			logger.Error(err, "Failed to check admin password", "username", username)
 			recordAuthFailure("secret_error")
 			recordAuthAttempt("admin", "failure")
+			result = "secret_error"
 			return false, nil
 		}
 		if authenticated {
@@ -149,6 +271,7 @@ func (auth *KubeAuth) CheckPasswd(ctx *server.Context, username, password string
 			logger.Error(err, "Failed to check password for user", "username", username)
 			recordAuthFailure("secret_error")
 			recordAuthAttempt("regular", "failure")
+			result = "secret_error"
 			return false, nil
 		}
 		if authenticated {
@@ -164,13 +287,28 @@ func (auth *KubeAuth) CheckPasswd(ctx *server.Context, username, password string
 		}
 	}
 
+	if authenticated && user.Spec.SingleSession != "" && !auth.claimSingleSession(auth.getSessionID(ctx), user) {
+		logger.Info("User already has an active session", "username", user.Spec.Username, "login_name", username)
+		recordAuthFailure("single_session")
+		metrics.RecordUserLogin("failure")
+		result = "single_session"
+		return false, nil
+	}
+
 	if authenticated {
-		logger.Info("User authenticated successfully", "username", username, "user_type", userType)
+		logger.Info("User authenticated successfully", "username", user.Spec.Username, "login_name", username, "user_type", userType)
 		auth.bruteForce.RecordSuccess(username, clientIP)
-		// Store in session-based map using connection identifier
+		// Store the canonical username in the session-based map so login
+		// aliases share the user's backend and settings
 		sessionID := auth.getSessionID(ctx)
-		auth.setSessionUser(sessionID, username)
+		auth.setSessionUser(sessionID, user.Spec.Username)
+		if user.Spec.DefaultTransferType != "" {
+			auth.setSessionTransferType(sessionID, user.Spec.DefaultTransferType)
+		}
+		auth.startSessionDeadline(sessionID, user.Spec.Username, user.Spec.MaxSessionDuration.Duration)
 		metrics.RecordUserLogin("success")
+		metrics.RecordConnectionLogin(user.Spec.Username, clientIP)
+		result = "success"
 		return true, nil
 	}
 
@@ -215,34 +353,140 @@ func (auth *KubeAuth) checkAdminPassword(ctx context.Context, user *ftpv1.User,
 	return subtle.ConstantTimeCompare([]byte(userPassword), []byte(password)) == 1, nil
 }
 
-// GetUser returns a user from cache or loads from Kubernetes
+// GetUser returns a user from cache or loads from Kubernetes. Cached users older
+// than userCacheTTL are revalidated; if the API server is unavailable they keep
+// being served until they exceed userCacheTTL plus MaxStaleness. With UsersFile
+// set only the users loaded from the file are served.
 func (auth *KubeAuth) GetUser(ctx context.Context, username string) *ftpv1.User {
+	// Users defined in a file never come from the API server
+	if auth.UsersFile != "" {
+		return auth.cachedUser(username)
+	}
+
 	// Try cache first
+	var staleUser *ftpv1.User
+	var staleAge time.Duration
 	if cachedUser, ok := auth.userCache.Load(username); ok {
-		return cachedUser.(*ftpv1.User)
+		loadedAt, tracked := auth.userLoadedAt.Load(username)
+		if !tracked {
+			return cachedUser.(*ftpv1.User)
+		}
+		staleAge = time.Since(loadedAt.(time.Time))
+		if staleAge <= userCacheTTL {
+			return cachedUser.(*ftpv1.User)
+		}
+		staleUser = cachedUser.(*ftpv1.User)
+	} else if canonical, ok := auth.loginAliases.Load(username); ok && canonical.(string) != username {
+		// Resolve login aliases to the user they belong to
+		if user := auth.GetUser(ctx, canonical.(string)); user != nil && slices.Contains(user.Spec.LoginAliases, username) {
+			return user
+		}
 	}
 
 	// Load from Kubernetes
 	userList := &ftpv1.UserList{}
 	if err := auth.client.List(ctx, userList); err != nil {
 		logger := getLogger()
+		if staleUser != nil && staleAge <= userCacheTTL+auth.MaxStaleness {
+			logger.Info("Serving stale cached user while API server is unavailable",
+				"username", username, "age", staleAge.String(), "error", err.Error())
+			return staleUser
+		}
+		if staleUser != nil {
+			auth.evictUser(username)
+		}
 		logger.Error(err, "Failed to list users", "username", username)
 		return nil
 	}
 
+	if auth.RequireUniqueUsernames {
+		if owners := duplicateUsernames(userList.Items)[username]; len(owners) > 0 {
+			getLogger().Info("Refusing ambiguous username defined by multiple users",
+				"username", username, "users", owners)
+			auth.ambiguousNames.Store(username, owners)
+			auth.evictUser(username)
+			return nil
+		}
+		auth.ambiguousNames.Delete(username)
+	}
+
+	// An exact username match wins over another user's login alias
 	for _, user := range userList.Items {
 		if user.Spec.Username == username {
 			userCopy := user.DeepCopy()
-			auth.userCache.Store(username, userCopy)
+			auth.cacheUser(userCopy)
+			return userCopy
+		}
+	}
+	for _, user := range userList.Items {
+		if slices.Contains(user.Spec.LoginAliases, username) {
+			userCopy := user.DeepCopy()
+			auth.cacheUser(userCopy)
 			return userCopy
 		}
 	}
 
+	// The user no longer exists
+	if staleUser != nil {
+		auth.evictUser(username)
+	}
 	return nil
 }
 
-// RefreshUserCache refreshes the user cache from Kubernetes
+// cachedUser returns a user from the cache without contacting the API server
+func (auth *KubeAuth) cachedUser(username string) *ftpv1.User {
+	if cached, ok := auth.userCache.Load(username); ok {
+		return cached.(*ftpv1.User)
+	}
+	if canonical, ok := auth.loginAliases.Load(username); ok {
+		if cached, ok := auth.userCache.Load(canonical); ok {
+			return cached.(*ftpv1.User)
+		}
+	}
+	return nil
+}
+
+// cacheUser stores a user loaded from the API server and records when it was loaded.
+// Users referencing a PermissionTemplate have their resolved permissions applied so
+// that storage permission checks see the effective values.
+func (auth *KubeAuth) cacheUser(user *ftpv1.User) {
+	if user.Spec.PermissionTemplateRef != nil && user.Status.EffectivePermissions != nil {
+		user.Spec.Permissions = *user.Status.EffectivePermissions
+	}
+	auth.userCache.Store(user.Spec.Username, user)
+	auth.userLoadedAt.Store(user.Spec.Username, time.Now())
+	auth.forgetLoginAliases(user.Spec.Username)
+	for _, alias := range user.Spec.LoginAliases {
+		auth.loginAliases.Store(alias, user.Spec.Username)
+	}
+	auth.userAliases.Store(user.Spec.Username, slices.Clone(user.Spec.LoginAliases))
+}
+
+// evictUser removes a user, its load time and its login aliases from the cache
+func (auth *KubeAuth) evictUser(username string) {
+	auth.userCache.Delete(username)
+	auth.userLoadedAt.Delete(username)
+	auth.forgetLoginAliases(username)
+}
+
+// forgetLoginAliases removes the login aliases registered for username, leaving
+// any alias that has since been registered for another user
+func (auth *KubeAuth) forgetLoginAliases(username string) {
+	aliases, ok := auth.userAliases.LoadAndDelete(username)
+	if !ok {
+		return
+	}
+	for _, alias := range aliases.([]string) {
+		auth.loginAliases.CompareAndDelete(alias, username)
+	}
+}
+
+// RefreshUserCache refreshes the user cache from Kubernetes, or from UsersFile
+// when one is set
 func (auth *KubeAuth) RefreshUserCache(ctx context.Context) error {
+	if auth.UsersFile != "" {
+		return auth.LoadUsersFile()
+	}
 	logger := getLogger()
 	logger.Info("Refreshing user cache")
 
@@ -252,19 +496,56 @@ func (auth *KubeAuth) RefreshUserCache(ctx context.Context) error {
 		return err
 	}
 
-	// Clear existing cache and populate with fresh data
+	auth.replaceCachedUsers(userList.Items)
+	logger.Info("User cache refreshed", "user_count", len(userList.Items))
+	return nil
+}
+
+// replaceCachedUsers clears the cache and fills it with users, leaving out
+// usernames that more than one enabled User defines when RequireUniqueUsernames
+// is set
+func (auth *KubeAuth) replaceCachedUsers(users []ftpv1.User) {
+	logger := getLogger()
 	auth.userCache.Range(func(key, value interface{}) bool {
-		auth.userCache.Delete(key)
+		auth.evictUser(key.(string))
 		return true
 	})
 
-	for _, user := range userList.Items {
-		userCopy := user.DeepCopy()
-		auth.userCache.Store(user.Spec.Username, userCopy)
+	var duplicates map[string][]string
+	auth.ambiguousNames.Range(func(key, value interface{}) bool {
+		auth.ambiguousNames.Delete(key)
+		return true
+	})
+	if auth.RequireUniqueUsernames {
+		duplicates = duplicateUsernames(users)
+		for username, owners := range duplicates {
+			logger.Info("Not caching ambiguous username defined by multiple users",
+				"username", username, "users", owners)
+			auth.ambiguousNames.Store(username, owners)
+		}
 	}
 
-	logger.Info("User cache refreshed", "user_count", len(userList.Items))
-	return nil
+	for _, user := range users {
+		if _, ambiguous := duplicates[user.Spec.Username]; ambiguous {
+			continue
+		}
+		auth.cacheUser(user.DeepCopy())
+		for _, alias := range user.Spec.LoginAliases {
+			if _, ambiguous := duplicates[alias]; ambiguous {
+				auth.loginAliases.CompareAndDelete(alias, user.Spec.Username)
+			}
+		}
+	}
+}
+
+// CachedUserCount returns how many users the cache holds
+func (auth *KubeAuth) CachedUserCount() int {
+	count := 0
+	auth.userCache.Range(func(key, value interface{}) bool {
+		count++
+		return true
+	})
+	return count
 }
 
 // StartCacheRefresh starts a background goroutine to periodically refresh the user cache
@@ -290,16 +571,71 @@ func (auth *KubeAuth) StartCacheRefresh(ctx context.Context, interval time.Durat
 // UpdateUser updates a user in the cache
 func (auth *KubeAuth) UpdateUser(user *ftpv1.User) {
 	if user != nil && user.Spec.Username != "" {
-		userCopy := user.DeepCopy()
-		auth.userCache.Store(user.Spec.Username, userCopy)
+		if auth.RequireUniqueUsernames && auth.isAmbiguous(user) {
+			auth.evictUser(user.Spec.Username)
+			logger := getLogger()
+			logger.Info("Evicted ambiguous username defined by multiple users",
+				"username", user.Spec.Username, "user", user.Namespace+"/"+user.Name)
+			return
+		}
+		auth.cacheUser(user.DeepCopy())
 		logger := getLogger()
 		logger.Info("Updated user in cache", "username", user.Spec.Username)
 	}
 }
 
+// isAmbiguous reports whether an updated user must stay out of the cache: its
+// username is already known to be ambiguous, or a different enabled User holds
+// it in the cache. A newly found collision is remembered until the next refresh
+// or lookup re-evaluates it.
+func (auth *KubeAuth) isAmbiguous(user *ftpv1.User) bool {
+	if _, ambiguous := auth.ambiguousNames.Load(user.Spec.Username); ambiguous {
+		return true
+	}
+	if !user.Spec.Enabled {
+		return false
+	}
+	cached, ok := auth.userCache.Load(user.Spec.Username)
+	if !ok {
+		return false
+	}
+	existing := cached.(*ftpv1.User)
+	if !existing.Spec.Enabled || (existing.Namespace == user.Namespace && existing.Name == user.Name) {
+		return false
+	}
+	auth.ambiguousNames.Store(user.Spec.Username, []string{
+		existing.Namespace + "/" + existing.Name, user.Namespace + "/" + user.Name,
+	})
+	return true
+}
+
+// duplicateUsernames returns the login names, usernames or login aliases,
+// defined by more than one enabled User, mapped to the namespace/name of each
+// User defining them
+func duplicateUsernames(users []ftpv1.User) map[string][]string {
+	owners := make(map[string][]string)
+	for _, user := range users {
+		if !user.Spec.Enabled {
+			continue
+		}
+		owner := user.Namespace + "/" + user.Name
+		for _, name := range append([]string{user.Spec.Username}, user.Spec.LoginAliases...) {
+			if !slices.Contains(owners[name], owner) {
+				owners[name] = append(owners[name], owner)
+			}
+		}
+	}
+	for username, names := range owners {
+		if len(names) < 2 {
+			delete(owners, username)
+		}
+	}
+	return owners
+}
+
 // DeleteUser removes a user from the cache
 func (auth *KubeAuth) DeleteUser(username string) {
-	auth.userCache.Delete(username)
+	auth.evictUser(username)
 	logger := getLogger()
 	logger.Info("Deleted user from cache", "username", username)
 }
@@ -323,8 +659,16 @@ func (auth *KubeAuth) getSessionID(ctx *server.Context) string {
 		return fmt.Sprintf("session-%p", ctx)
 	}
 
-	// remoteAddr.String() already includes both IP and port (e.g., "192.168.1.100:54321")
-	// This ensures each connection gets a unique session ID even from the same client IP
+	return sessionIDForAddr(remoteAddr)
+}
+
+// sessionIDForAddr derives the session identifier from a client's remote address.
+// remoteAddr.String() already includes both IP and port (e.g., "192.168.1.100:54321")
+// This ensures each connection gets a unique session ID even from the same client IP
+func sessionIDForAddr(remoteAddr net.Addr) string {
+	if remoteAddr == nil {
+		return ""
+	}
 	return fmt.Sprintf("ftp-session-%s", remoteAddr.String())
 }
 
@@ -353,6 +697,76 @@ func (auth *KubeAuth) ClearSessionUser(sessionID string) {
 	}
 }
 
+// setSessionHost records the virtual host selected by a session's HOST command
+func (auth *KubeAuth) setSessionHost(sessionID, host string) {
+	if sessionID != "" {
+		auth.sessionHostMap.Store(sessionID, host)
+	}
+}
+
+// GetSessionHost returns the virtual host selected for a session, if any
+func (auth *KubeAuth) GetSessionHost(sessionID string) string {
+	if sessionID == "" {
+		return ""
+	}
+	if host, ok := auth.sessionHostMap.Load(sessionID); ok {
+		return host.(string)
+	}
+	return ""
+}
+
+// ClearSessionHost removes the virtual host mapping for a session
+func (auth *KubeAuth) ClearSessionHost(sessionID string) {
+	if sessionID != "" {
+		auth.sessionHostMap.Delete(sessionID)
+	}
+}
+
+// setSessionUploadSize records the size announced with ALLO for a session's next upload
+func (auth *KubeAuth) setSessionUploadSize(sessionID string, size int64) {
+	if sessionID != "" {
+		auth.sessionAllo.Store(sessionID, size)
+	}
+}
+
+// takeSessionUploadSize returns and clears the size announced for a session,
+// or -1 when none was announced
+func (auth *KubeAuth) takeSessionUploadSize(sessionID string) int64 {
+	if sessionID == "" {
+		return -1
+	}
+	if size, ok := auth.sessionAllo.LoadAndDelete(sessionID); ok {
+		return size.(int64)
+	}
+	return -1
+}
+
+// setSessionCapabilities records the capabilities of a session's storage once it is initialized
+func (auth *KubeAuth) setSessionCapabilities(sessionID string, caps storage.Capabilities) {
+	if sessionID != "" {
+		auth.sessionCaps.Store(sessionID, caps)
+	}
+}
+
+// GetSessionCapabilities returns the capabilities of a session's storage, and
+// false when the storage has not been initialized yet
+func (auth *KubeAuth) GetSessionCapabilities(sessionID string) (storage.Capabilities, bool) {
+	if sessionID == "" {
+		return storage.Capabilities{}, false
+	}
+	if caps, ok := auth.sessionCaps.Load(sessionID); ok {
+		return caps.(storage.Capabilities), true
+	}
+	return storage.Capabilities{}, false
+}
+
+// ClearSessionCapabilities removes the storage capabilities recorded for a session
+func (auth *KubeAuth) ClearSessionCapabilities(sessionID string) {
+	if sessionID != "" {
+		auth.sessionCaps.Delete(sessionID)
+	}
+}
+
 // getUserPassword retrieves the user's password from either direct field or secret
 func (auth *KubeAuth) getUserPassword(ctx context.Context, user *ftpv1.User) (string, error) {
 	// If plaintext password is provided, use it
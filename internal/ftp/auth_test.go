@@ -2,12 +2,16 @@ package ftp
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -176,6 +180,39 @@ func TestKubeAuth_CheckPasswd(t *testing.T) {
 	}
 }
 
+func TestKubeAuth_CheckPasswdMaintenance(t *testing.T) {
+	testUser := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "testuser", Namespace: "default"},
+		Spec: ftpv1.UserSpec{
+			Username:      "testuser",
+			Password:      "testpass",
+			Enabled:       true,
+			Backend:       ftpv1.BackendReference{Kind: "MinioBackend", Name: "test-backend"},
+			HomeDirectory: "/test",
+		},
+	}
+
+	auth := NewKubeAuth(&MockClient{})
+	auth.userCache.Store("testuser", testUser)
+	auth.Maintenance = &MaintenanceMode{}
+
+	authenticated, err := auth.CheckPasswd(nil, "testuser", "testpass")
+	assert.NoError(t, err)
+	assert.True(t, authenticated)
+
+	// Maintenance mode denies new logins even with valid credentials
+	auth.Maintenance.Set("Down for scheduled maintenance until 02:00 UTC")
+	authenticated, err = auth.CheckPasswd(nil, "testuser", "testpass")
+	assert.NoError(t, err)
+	assert.False(t, authenticated)
+
+	// Clearing the message restores access
+	auth.Maintenance.Set("")
+	authenticated, err = auth.CheckPasswd(nil, "testuser", "testpass")
+	assert.NoError(t, err)
+	assert.True(t, authenticated)
+}
+
 func TestKubeAuth_GetUser(t *testing.T) {
 	scheme := runtime.NewScheme()
 	err := ftpv1.AddToScheme(scheme)
@@ -224,6 +261,75 @@ func TestKubeAuth_GetUser(t *testing.T) {
 	assert.Nil(t, user3)
 }
 
+func TestKubeAuth_GetUserStalenessGrace(t *testing.T) {
+	newCachedUser := func() *ftpv1.User {
+		return &ftpv1.User{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "testuser",
+				Namespace: "default",
+			},
+			Spec: ftpv1.UserSpec{
+				Username:      "testuser",
+				Password:      "testpass",
+				Enabled:       true,
+				HomeDirectory: "/test",
+				Backend: ftpv1.BackendReference{
+					Kind: "FilesystemBackend",
+					Name: "test-backend",
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		age          time.Duration
+		maxStaleness time.Duration
+		wantAuth     bool
+	}{
+		{
+			name:         "stale user within grace window still authenticates",
+			age:          userCacheTTL + 5*time.Minute,
+			maxStaleness: 15 * time.Minute,
+			wantAuth:     true,
+		},
+		{
+			name:         "stale user beyond grace window is evicted",
+			age:          userCacheTTL + 20*time.Minute,
+			maxStaleness: 15 * time.Minute,
+			wantAuth:     false,
+		},
+		{
+			name:         "no grace configured",
+			age:          userCacheTTL + time.Minute,
+			maxStaleness: 0,
+			wantAuth:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Simulate an API server outage
+			mockClient := &MockClient{}
+			mockClient.On("List", mock.Anything, mock.Anything, mock.Anything).
+				Return(errors.New("connection refused"))
+
+			auth := NewKubeAuth(mockClient)
+			auth.MaxStaleness = tt.maxStaleness
+			auth.userCache.Store("testuser", newCachedUser())
+			auth.userLoadedAt.Store("testuser", time.Now().Add(-tt.age))
+
+			gotAuth, err := auth.CheckPasswd(nil, "testuser", "testpass")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantAuth, gotAuth)
+
+			_, cached := auth.userCache.Load("testuser")
+			assert.Equal(t, tt.wantAuth, cached, "user should only remain cached while within the grace window")
+			mockClient.AssertCalled(t, "List", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
 func TestKubeAuth_RefreshUserCache(t *testing.T) {
 	scheme := runtime.NewScheme()
 	err := ftpv1.AddToScheme(scheme)
@@ -267,6 +373,111 @@ func TestKubeAuth_RefreshUserCache(t *testing.T) {
 	assert.Equal(t, "testuser", user.Spec.Username)
 }
 
+func TestKubeAuth_RequireUniqueUsernames(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := ftpv1.AddToScheme(scheme)
+	assert.NoError(t, err)
+
+	newUser := func(namespace string) *ftpv1.User {
+		return &ftpv1.User{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "shared",
+				Namespace: namespace,
+			},
+			Spec: ftpv1.UserSpec{
+				Username:      "shared",
+				Password:      "testpass",
+				Enabled:       true,
+				HomeDirectory: "/" + namespace,
+				Backend: ftpv1.BackendReference{
+					Kind: "FilesystemBackend",
+					Name: "test-backend",
+				},
+			},
+		}
+	}
+
+	t.Run("lookup refuses a username defined in two namespaces", func(t *testing.T) {
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(newUser("team-a"), newUser("team-b")).
+			Build()
+
+		auth := NewKubeAuth(fakeClient)
+		auth.RequireUniqueUsernames = true
+
+		assert.Nil(t, auth.GetUser(context.Background(), "shared"))
+		gotAuth, err := auth.CheckPasswd(nil, "shared", "testpass")
+		assert.NoError(t, err)
+		assert.False(t, gotAuth)
+
+		// Without the policy the first listed user is served
+		auth = NewKubeAuth(fakeClient)
+		assert.NotNil(t, auth.GetUser(context.Background(), "shared"))
+	})
+
+	t.Run("refresh does not cache a duplicated username", func(t *testing.T) {
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(newUser("team-a"), newUser("team-b")).
+			Build()
+
+		auth := NewKubeAuth(fakeClient)
+		auth.RequireUniqueUsernames = true
+		assert.NoError(t, auth.RefreshUserCache(context.Background()))
+
+		_, cached := auth.userCache.Load("shared")
+		assert.False(t, cached)
+
+		// A watch update for either user must not cache it either
+		auth.UpdateUser(newUser("team-a"))
+		_, cached = auth.userCache.Load("shared")
+		assert.False(t, cached)
+	})
+
+	t.Run("update from another namespace evicts the cached user", func(t *testing.T) {
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(newUser("team-a")).
+			Build()
+
+		auth := NewKubeAuth(fakeClient)
+		auth.RequireUniqueUsernames = true
+		assert.NotNil(t, auth.GetUser(context.Background(), "shared"))
+
+		auth.UpdateUser(newUser("team-b"))
+		_, cached := auth.userCache.Load("shared")
+		assert.False(t, cached)
+
+		// Once the API server lists a single owner again the username resolves
+		assert.NotNil(t, auth.GetUser(context.Background(), "shared"))
+	})
+
+	t.Run("login alias colliding with a username is ambiguous", func(t *testing.T) {
+		aliased := newUser("team-b")
+		aliased.Name = "other"
+		aliased.Spec.Username = "other"
+		aliased.Spec.LoginAliases = []string{"shared"}
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(newUser("team-a"), aliased).
+			Build()
+
+		auth := NewKubeAuth(fakeClient)
+		auth.RequireUniqueUsernames = true
+		assert.Nil(t, auth.GetUser(context.Background(), "shared"))
+
+		assert.NoError(t, auth.RefreshUserCache(context.Background()))
+		_, cached := auth.userCache.Load("shared")
+		assert.False(t, cached)
+		_, cached = auth.loginAliases.Load("shared")
+		assert.False(t, cached)
+
+		// The alias owner keeps its own username
+		assert.NotNil(t, auth.GetUser(context.Background(), "other"))
+	})
+}
+
 func TestKubeAuth_StartCacheRefresh(t *testing.T) {
 	scheme := runtime.NewScheme()
 	err := ftpv1.AddToScheme(scheme)
@@ -753,3 +964,154 @@ func TestAuthMetrics_NoUsernameLabel(t *testing.T) {
 	assert.True(t, foundAttempts, "kubeftpd_auth_attempts_total must be present in gathered metrics")
 	assert.True(t, foundLogins, "kubeftpd_user_logins_total must be present in gathered metrics")
 }
+
+func TestKubeAuth_CheckPasswdTracing(t *testing.T) {
+	t.Setenv("OTEL_SERVICE_NAME", "kubeftpd")
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	originalTracer := tracer
+	tracer = provider.Tracer("kubeftpd/ftp")
+	defer func() { tracer = originalTracer }()
+
+	scheme := runtime.NewScheme()
+	_ = ftpv1.AddToScheme(scheme)
+
+	user := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "traceuser",
+			Namespace: "default",
+		},
+		Spec: ftpv1.UserSpec{
+			Username:      "traceuser",
+			Password:      "testpass",
+			Enabled:       true,
+			HomeDirectory: "/test",
+			Backend: ftpv1.BackendReference{
+				Kind: "FilesystemBackend",
+				Name: "test-backend",
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(user).
+		Build()
+
+	auth := NewKubeAuth(fakeClient)
+
+	authenticated, err := auth.CheckPasswd(nil, "traceuser", "testpass")
+	assert.NoError(t, err)
+	assert.True(t, authenticated)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "ftp.auth", spans[0].Name())
+
+		attrs := make(map[attribute.Key]string)
+		for _, kv := range spans[0].Attributes() {
+			attrs[kv.Key] = kv.Value.Emit()
+		}
+		assert.Equal(t, "traceuser", attrs["ftp.user"])
+		assert.Equal(t, "FilesystemBackend", attrs["ftp.backend"])
+		assert.Equal(t, "success", attrs["ftp.auth.result"])
+	}
+}
+
+func TestKubeAuth_LoginAliases(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := ftpv1.AddToScheme(scheme)
+	assert.NoError(t, err)
+
+	testUser := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "scanners",
+			Namespace: "default",
+		},
+		Spec: ftpv1.UserSpec{
+			Username:     "scanners",
+			LoginAliases: []string{"scanner-lobby", "scanner-office"},
+			Password:     "testpass",
+			Enabled:      true,
+			Backend: ftpv1.BackendReference{
+				Kind: "MinioBackend",
+				Name: "shared-backend",
+			},
+			HomeDirectory: "/scans",
+			Permissions: ftpv1.UserPermissions{
+				Write: true,
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(testUser).
+		Build()
+
+	for _, login := range []string{"scanner-lobby", "scanner-office", "scanners"} {
+		t.Run(login, func(t *testing.T) {
+			auth := NewKubeAuth(fakeClient)
+
+			user := auth.GetUser(context.Background(), login)
+			if assert.NotNil(t, user) {
+				assert.Equal(t, "scanners", user.Spec.Username)
+				assert.Equal(t, "shared-backend", user.Spec.Backend.Name)
+			}
+
+			ok, err := auth.CheckPasswd(nil, login, "testpass")
+			assert.NoError(t, err)
+			assert.True(t, ok)
+
+			ok, err = auth.CheckPasswd(nil, login, "wrongpass")
+			assert.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+
+	// Aliases resolve through the cache once the user is loaded
+	auth := NewKubeAuth(fakeClient)
+	auth.UpdateUser(testUser)
+	user := auth.GetUser(context.Background(), "scanner-office")
+	if assert.NotNil(t, user) {
+		assert.Equal(t, "scanners", user.Spec.Username)
+	}
+
+	// Dropping an alias stops it resolving from the cache
+	updated := testUser.DeepCopy()
+	updated.Spec.LoginAliases = []string{"scanner-lobby"}
+	auth.UpdateUser(updated)
+	_, aliased := auth.loginAliases.Load("scanner-office")
+	assert.False(t, aliased)
+
+	// Unknown names are not resolved
+	assert.Nil(t, auth.GetUser(context.Background(), "scanner-unknown"))
+
+	// Evicting a user keeps an alias that now belongs to another user
+	moved := testUser.DeepCopy()
+	moved.Name = "lobby"
+	moved.Spec.Username = "lobby"
+	moved.Spec.LoginAliases = []string{"scanner-lobby"}
+	auth.UpdateUser(moved)
+	auth.DeleteUser("scanners")
+	canonical, aliased := auth.loginAliases.Load("scanner-lobby")
+	if assert.True(t, aliased) {
+		assert.Equal(t, "lobby", canonical)
+	}
+
+	// An exact username match wins over another user's alias
+	owner := testUser.DeepCopy()
+	owner.Name = "team-lobby"
+	owner.Spec.Username = "scanner-lobby"
+	owner.Spec.LoginAliases = nil
+	fakeClient = fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(testUser, owner).
+		Build()
+	auth = NewKubeAuth(fakeClient)
+	user = auth.GetUser(context.Background(), "scanner-lobby")
+	if assert.NotNil(t, user) {
+		assert.Equal(t, "scanner-lobby", user.Spec.Username)
+	}
+}
@@ -0,0 +1,42 @@
+package ftp
+
+import (
+	"context"
+
+	"goftp.io/server/v2"
+
+	"github.com/rossigee/kubeftpd/internal/storage"
+)
+
+// backpressureCommands are the data transfer commands refused while the
+// user's backend is at its MaxConcurrentOperations limit
+var backpressureCommands = []string{"RETR", "STOR", "APPE", "LIST", "NLST", "MLSD"}
+
+// commandBackpressure wraps a transfer command so it is refused with a 450
+// temporary reply, which clients retry, rather than failing mid-transfer
+// while the backend is saturated.
+type commandBackpressure struct {
+	auth *KubeAuth
+	next server.Command
+}
+
+func (cmd commandBackpressure) IsExtend() bool {
+	return cmd.next.IsExtend()
+}
+
+func (cmd commandBackpressure) RequireParam() bool {
+	return cmd.next.RequireParam()
+}
+
+func (cmd commandBackpressure) RequireAuth() bool {
+	return cmd.next.RequireAuth()
+}
+
+func (cmd commandBackpressure) Execute(sess *server.Session, param string) {
+	if user := cmd.auth.GetUser(context.Background(), sess.LoginUser()); user != nil && storage.BackendSaturated(user) {
+		getLogger().Info("Refusing transfer while backend is saturated", "username", user.Spec.Username, "backend", user.Spec.Backend.Name)
+		cmd.auth.writeTransient(sess, replyBackendBusy)
+		return
+	}
+	cmd.next.Execute(sess, param)
+}
@@ -0,0 +1,133 @@
+package ftp
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScheduledBanner is a line added to the welcome banner of connections
+// accepted between Start and End (UTC) each day. A window whose End is before
+// its Start runs past midnight.
+type ScheduledBanner struct {
+	Start   time.Duration // offset from midnight
+	End     time.Duration // offset from midnight
+	Message string
+}
+
+// ParseScheduledBanners parses semicolon-separated HH:MM-HH:MM=message
+// entries, e.g. "22:00-02:00=Maintenance tonight at 23:00 UTC". An empty
+// value returns nil.
+func ParseScheduledBanners(value string) ([]ScheduledBanner, error) {
+	var banners []ScheduledBanner
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		window, message, ok := strings.Cut(entry, "=")
+		message = strings.TrimSpace(message)
+		start, end, okWindow := strings.Cut(strings.TrimSpace(window), "-")
+		if !ok || !okWindow || message == "" {
+			return nil, fmt.Errorf("invalid scheduled banner %q: expected HH:MM-HH:MM=message", entry)
+		}
+		startOffset, err := parseClockTime(start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scheduled banner %q: %w", entry, err)
+		}
+		endOffset, err := parseClockTime(end)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scheduled banner %q: %w", entry, err)
+		}
+		if startOffset == endOffset {
+			return nil, fmt.Errorf("invalid scheduled banner %q: window is empty", entry)
+		}
+		banners = append(banners, ScheduledBanner{Start: startOffset, End: endOffset, Message: message})
+	}
+	return banners, nil
+}
+
+// parseClockTime converts HH:MM into an offset from midnight
+func parseClockTime(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: expected HH:MM", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// activeAt reports whether now falls in the banner's daily window
+func (b ScheduledBanner) activeAt(now time.Time) bool {
+	now = now.UTC()
+	offset := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute +
+		time.Duration(now.Second())*time.Second
+	if b.Start < b.End {
+		return offset >= b.Start && offset < b.End
+	}
+	return offset >= b.Start || offset < b.End
+}
+
+// activeBanners returns the messages of the banners whose window contains now
+func activeBanners(banners []ScheduledBanner, now time.Time) []string {
+	var messages []string
+	for _, banner := range banners {
+		if banner.activeAt(now) {
+			messages = append(messages, banner.Message)
+		}
+	}
+	return messages
+}
+
+// scheduledBannerListener adds the scheduled banners active when a connection
+// is accepted to its welcome banner, as leading lines of a multi-line 220 reply
+type scheduledBannerListener struct {
+	net.Listener
+	banners []ScheduledBanner
+	// now returns the current time; replaced in tests
+	now func() time.Time
+}
+
+func (l *scheduledBannerListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return conn, err
+	}
+	now := time.Now
+	if l.now != nil {
+		now = l.now
+	}
+	messages := activeBanners(l.banners, now())
+	if len(messages) == 0 {
+		return conn, nil
+	}
+	return &scheduledBannerConn{Conn: conn, messages: messages}, nil
+}
+
+// scheduledBannerConn prefixes its first write, which carries the 220 welcome
+// banner, with the active scheduled banner lines
+type scheduledBannerConn struct {
+	net.Conn
+	messages []string
+	once     sync.Once
+}
+
+func (c *scheduledBannerConn) Write(b []byte) (int, error) {
+	var prefix []byte
+	c.once.Do(func() {
+		if !bytes.HasPrefix(b, []byte("220 ")) {
+			return
+		}
+		for _, message := range c.messages {
+			prefix = append(prefix, "220-"+message+"\r\n"...)
+		}
+	})
+	if len(prefix) == 0 {
+		return c.Conn.Write(b)
+	}
+	if _, err := c.Conn.Write(prefix); err != nil {
+		return 0, err
+	}
+	return c.Conn.Write(b)
+}
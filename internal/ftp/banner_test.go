@@ -0,0 +1,90 @@
+package ftp
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScheduledBanners(t *testing.T) {
+	banners, err := ParseScheduledBanners("22:00-02:00=Maintenance tonight; 09:30-10:00 = Standup ")
+	require.NoError(t, err)
+	assert.Equal(t, []ScheduledBanner{
+		{Start: 22 * time.Hour, End: 2 * time.Hour, Message: "Maintenance tonight"},
+		{Start: 9*time.Hour + 30*time.Minute, End: 10 * time.Hour, Message: "Standup"},
+	}, banners)
+
+	banners, err = ParseScheduledBanners("")
+	require.NoError(t, err)
+	assert.Nil(t, banners)
+
+	for _, invalid := range []string{"22:00=no end", "22:00-02:00", "25:00-02:00=bad hour", "10:00-10:00=empty window", "noon-1pm=words"} {
+		_, err := ParseScheduledBanners(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestActiveBanners_ClockCrossesWindow(t *testing.T) {
+	banners, err := ParseScheduledBanners("22:00-02:00=Maintenance tonight;12:00-13:00=Lunch")
+	require.NoError(t, err)
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 3, 14, hour, minute, 0, 0, time.UTC)
+	}
+
+	assert.Empty(t, activeBanners(banners, at(21, 59)))
+	assert.Equal(t, []string{"Maintenance tonight"}, activeBanners(banners, at(22, 0)))
+	assert.Equal(t, []string{"Maintenance tonight"}, activeBanners(banners, at(1, 59)), "window runs past midnight")
+	assert.Empty(t, activeBanners(banners, at(2, 0)))
+	assert.Equal(t, []string{"Lunch"}, activeBanners(banners, at(12, 30)))
+
+	// Windows are evaluated in UTC
+	tokyo := time.FixedZone("JST", 9*60*60)
+	assert.Equal(t, []string{"Maintenance tonight"}, activeBanners(banners, time.Date(2026, 3, 15, 7, 0, 0, 0, tokyo)))
+}
+
+// readBanner accepts one connection through listener, writes a welcome line
+// and returns the lines the client receives up to the final 220 reply
+func readBanner(t *testing.T, listener *scheduledBannerListener) []string {
+	t.Helper()
+	client, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = client.Close() }()
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	go func() { _, _ = conn.Write([]byte("220 Welcome to KubeFTPd\r\n")) }()
+
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(5*time.Second)))
+	reader := bufio.NewReader(client)
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		lines = append(lines, line)
+		if len(line) >= 4 && line[:4] == "220 " {
+			return lines
+		}
+	}
+}
+
+func TestScheduledBannerListener(t *testing.T) {
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	now := time.Date(2026, 3, 14, 21, 59, 0, 0, time.UTC)
+	listener := &scheduledBannerListener{
+		Listener: inner,
+		banners:  []ScheduledBanner{{Start: 22 * time.Hour, End: 2 * time.Hour, Message: "Maintenance tonight"}},
+		now:      func() time.Time { return now },
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	assert.Equal(t, []string{"220 Welcome to KubeFTPd\r\n"}, readBanner(t, listener))
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, []string{"220-Maintenance tonight\r\n", "220 Welcome to KubeFTPd\r\n"}, readBanner(t, listener))
+}
@@ -119,6 +119,13 @@ func (b *BruteForceProtector) IsLockedOut(username, clientIP string) bool {
 	return false
 }
 
+// IsIPLockedOut reports whether the client address is currently locked out,
+// without consulting the username
+func (b *BruteForceProtector) IsIPLockedOut(clientIP string) bool {
+	ip := extractIP(clientIP)
+	return ip != "" && b.entryFor(&b.byIP, ip).isLocked(time.Now())
+}
+
 // RecordFailure records a failed authentication attempt and locks out if threshold reached.
 func (b *BruteForceProtector) RecordFailure(username, clientIP string) {
 	logger := ctrl.Log.WithName("bruteforce")
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -355,6 +356,42 @@ func TestKubeDriver_ChrootFileOperations(t *testing.T) {
 	mockStorage.AssertExpectations(t)
 }
 
+// Test that CWD .. at the home root stays at the root instead of failing
+func TestKubeDriver_ChrootParentAtRoot(t *testing.T) {
+	chrootUser := &ftpv1.User{
+		Spec: ftpv1.UserSpec{
+			Username:      "chrootuser",
+			HomeDirectory: "/chroot/user",
+			Chroot:        true,
+			Permissions:   ftpv1.UserPermissions{Read: true, List: true},
+		},
+	}
+	driver := &KubeDriver{
+		authenticatedUser: "chrootuser",
+		user:              chrootUser,
+		storageImpl:       &MockStorage{},
+	}
+
+	// The server joins parent references onto the absolute working directory
+	// before calling the driver, so they arrive cleaned to the root
+	for _, path := range []string{"/..", "/../..", "/../../../"} {
+		resolved, err := driver.validateChrootPath(path)
+		require.NoError(t, err)
+		assert.Equal(t, "/chroot/user", resolved)
+	}
+
+	send, mockStorage := pwdSession(t, true, false)
+	for _, command := range []string{"CWD ..", "CWD ../..", "CDUP"} {
+		assert.Equal(t, "250 Directory changed to /", send(command))
+		assert.Equal(t, `257 "/" is the current directory`, send("PWD"))
+	}
+	mockStorage.AssertNotCalled(t, "Stat", "/home")
+
+	// Traversal past the root into another path stays inside the home directory
+	assert.Equal(t, "250 Directory changed to /other", send("CWD ../other"))
+	mockStorage.AssertCalled(t, "Stat", "/home/guest/other")
+}
+
 // Test that user initialization is required for chroot validation
 func TestKubeDriver_ChrootValidationRequiresUser(t *testing.T) {
 	driver := &KubeDriver{
@@ -474,3 +511,31 @@ func TestScannerChrootScenarios(t *testing.T) {
 		})
 	}
 }
+
+// Test that ForceChroot confines users whose spec disables chroot
+func TestKubeDriver_ForceChroot(t *testing.T) {
+	user := &ftpv1.User{
+		Spec: ftpv1.UserSpec{
+			Username:      "loose",
+			HomeDirectory: "/home/loose",
+			Chroot:        false,
+		},
+	}
+
+	driver := &KubeDriver{authenticatedUser: "loose", user: user}
+	resolved, err := driver.validateChrootPath("/etc/passwd")
+	require.NoError(t, err)
+	assert.Equal(t, "/etc/passwd", resolved, "without ForceChroot the spec is honoured")
+
+	driver.forceChroot = true
+	resolved, err = driver.validateChrootPath("/etc/passwd")
+	require.NoError(t, err)
+	assert.Equal(t, "/home/loose/etc/passwd", resolved)
+
+	resolved, err = driver.validateChrootPath("/../../home/other/private.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "/home/loose/home/other/private.txt", resolved)
+
+	_, err = driver.validateChrootPath("../../etc/passwd")
+	assert.Error(t, err, "relative traversal out of home should be denied")
+}
@@ -0,0 +1,87 @@
+package ftp
+
+import (
+	"goftp.io/server/v2"
+)
+
+// buildCommands returns the default goftp command set with KubeFTPd's
+// overrides and extensions. HOST is only offered when virtual hosts are configured.
+func buildCommands(auth *KubeAuth, hosts map[string]VirtualHost) map[string]server.Command {
+	defaults := server.DefaultCommands()
+	commands := make(map[string]server.Command, len(defaults)+2)
+	for name, cmd := range defaults {
+		commands[name] = cmd
+	}
+	commands["PASS"] = commandPass{auth: auth, next: defaults["PASS"]}
+	commands["ALLO"] = commandAllo{auth: auth}
+	commands["REST"] = commandRest{auth: auth, next: defaults["REST"]}
+	commands["TYPE"] = commandType{auth: auth, next: defaults["TYPE"]}
+	commands["SYST"] = commandSyst{systemType: auth.SystemType}
+	commands["PWD"] = commandPwd{auth: auth}
+	commands["XPWD"] = commandPwd{auth: auth}
+	commands["LIST"] = commandList{auth: auth, next: defaults["LIST"]}
+	commands["NLST"] = commandNlst{auth: auth, next: defaults["NLST"]}
+	commands["MLSD"] = commandMLSD{auth: auth, next: defaults["MLSD"]}
+	if auth.HideServerIdentity {
+		commands["STAT"] = commandStat{next: defaults["STAT"]}
+	}
+	if auth.DataIdleTimeout > 0 {
+		for _, name := range passiveCommands {
+			if next, ok := commands[name]; ok {
+				commands[name] = commandDataIdle{auth: auth, next: next}
+			}
+		}
+		for _, name := range transferCommands {
+			if next, ok := commands[name]; ok {
+				commands[name] = commandDataTransfer{auth: auth, next: next}
+			}
+		}
+	}
+	if auth.MaxDataConnsPerSession > 0 {
+		for _, name := range passiveCommands {
+			if next, ok := commands[name]; ok {
+				commands[name] = commandDataLimit{auth: auth, next: next}
+			}
+		}
+		for _, name := range transferCommands {
+			if next, ok := commands[name]; ok {
+				commands[name] = commandDataRelease{auth: auth, next: next}
+			}
+		}
+	}
+	for _, name := range backpressureCommands {
+		if next, ok := commands[name]; ok {
+			commands[name] = commandBackpressure{auth: auth, next: next}
+		}
+	}
+	for _, name := range fileLimitCommands {
+		if next, ok := commands[name]; ok {
+			commands[name] = commandFileLimit{auth: auth, next: next}
+		}
+	}
+	for _, name := range uploadCommands {
+		if next, ok := commands[name]; ok {
+			commands[name] = commandUploadPath{next: commandUploadParent{next: next}}
+		}
+	}
+	if auth.FilenameCharset != nil {
+		for _, name := range filenameCommands {
+			if next, ok := commands[name]; ok {
+				commands[name] = commandFilenameCharset{charset: auth.FilenameCharset, next: next}
+			}
+		}
+	}
+	commands["SITE"] = commandSite{auth: auth}
+	if auth.AllowUTF8Off {
+		commands["OPTS"] = commandOpts{auth: auth, next: defaults["OPTS"]}
+		for _, name := range legacyPathCommands {
+			if next, ok := commands[name]; ok {
+				commands[name] = commandLegacyPath{auth: auth, next: next}
+			}
+		}
+	}
+	if len(hosts) > 0 {
+		commands["HOST"] = commandHost{auth: auth, hosts: hosts}
+	}
+	return commands
+}
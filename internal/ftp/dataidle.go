@@ -0,0 +1,98 @@
+package ftp
+
+import (
+	"time"
+
+	"goftp.io/server/v2"
+
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+// passiveCommands open a passive data connection that is closed if no
+// transfer uses it within the data idle timeout
+var passiveCommands = []string{"PASV", "EPSV"}
+
+// transferCommands consume the session's data connection
+var transferCommands = []string{"RETR", "STOR", "APPE", "LIST", "NLST", "MLSD"}
+
+// startDataIdleTimer closes socket after timeout unless a transfer claims it
+// first. Closing waits for goftp's accept to finish, so a data connection the
+// client never opens is still only released by goftp's own accept timeout.
+func (auth *KubeAuth) startDataIdleTimer(sessionID string, socket server.DataSocket, timeout time.Duration) {
+	auth.stopDataIdleTimer(sessionID)
+	pending := &sessionTimer{}
+	pending.timer = time.AfterFunc(timeout, func() {
+		auth.sessionDataIdle.CompareAndDelete(sessionID, pending)
+		getLogger().Info("Closing idle FTP data connection", "username", auth.GetSessionUser(sessionID),
+			"session_id", sessionID, "data_idle_timeout", timeout.String())
+		metrics.RecordIdleDataConnectionClosed()
+		_ = socket.Close()
+	})
+	auth.sessionDataIdle.Store(sessionID, pending)
+}
+
+// stopDataIdleTimer cancels a session's pending data idle timer, if any
+func (auth *KubeAuth) stopDataIdleTimer(sessionID string) {
+	if value, ok := auth.sessionDataIdle.LoadAndDelete(sessionID); ok {
+		value.(*sessionTimer).timer.Stop()
+	}
+}
+
+// sessionTimer is a session's pending timer as stored in a per-session map.
+// The timer's callback removes its own entry by comparing against the
+// sessionTimer, so it never reads the timer field that AfterFunc is still
+// assigning.
+type sessionTimer struct {
+	timer *time.Timer
+}
+
+// commandDataIdle wraps PASV and EPSV so the data connection they open is
+// closed after DataIdleTimeout without a transfer, leaving the control
+// connection untouched.
+type commandDataIdle struct {
+	auth *KubeAuth
+	next server.Command
+}
+
+func (cmd commandDataIdle) IsExtend() bool {
+	return cmd.next.IsExtend()
+}
+
+func (cmd commandDataIdle) RequireParam() bool {
+	return cmd.next.RequireParam()
+}
+
+func (cmd commandDataIdle) RequireAuth() bool {
+	return cmd.next.RequireAuth()
+}
+
+func (cmd commandDataIdle) Execute(sess *server.Session, param string) {
+	cmd.next.Execute(sess, param)
+	if socket := sess.DataConn(); socket != nil {
+		cmd.auth.startDataIdleTimer(sessionIDForAddr(sess.RemoteAddr()), socket, cmd.auth.DataIdleTimeout)
+	}
+}
+
+// commandDataTransfer wraps a transfer command so that using the data
+// connection cancels its idle timer
+type commandDataTransfer struct {
+	auth *KubeAuth
+	next server.Command
+}
+
+func (cmd commandDataTransfer) IsExtend() bool {
+	return cmd.next.IsExtend()
+}
+
+func (cmd commandDataTransfer) RequireParam() bool {
+	return cmd.next.RequireParam()
+}
+
+func (cmd commandDataTransfer) RequireAuth() bool {
+	return cmd.next.RequireAuth()
+}
+
+func (cmd commandDataTransfer) Execute(sess *server.Session, param string) {
+	cmd.auth.stopDataIdleTimer(sessionIDForAddr(sess.RemoteAddr()))
+	cmd.next.Execute(sess, param)
+}
@@ -0,0 +1,79 @@
+package ftp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// passivePort extracts the data port from a 227 PASV reply
+func passivePort(t *testing.T, reply string) int {
+	require.True(t, strings.HasPrefix(reply, "227 "), reply)
+	var h1, h2, h3, h4, p1, p2 int
+	_, err := fmt.Sscanf(reply[strings.Index(reply, "("):], "(%d,%d,%d,%d,%d,%d)", &h1, &h2, &h3, &h4, &p1, &p2)
+	require.NoError(t, err)
+	return p1<<8 | p2
+}
+
+func TestCommandDataIdle_ClosesIdleDataConnection(t *testing.T) {
+	auth := NewKubeAuth(nil)
+	auth.DataIdleTimeout = 100 * time.Millisecond
+	send := anonymousSessionWithAuth(t, auth)
+
+	port := passivePort(t, send("PASV"))
+	data, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	require.NoError(t, err)
+	defer func() { _ = data.Close() }()
+
+	require.NoError(t, data.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = data.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF, "idle data connection should be closed by the server")
+
+	assert.True(t, strings.HasPrefix(send("NOOP"), "200"), "control connection should stay open")
+}
+
+func TestCommandDataIdle_DisabledByDefault(t *testing.T) {
+	commands := buildCommands(NewKubeAuth(nil), nil)
+
+	assert.NotEqual(t, "ftp.commandDataIdle", fmt.Sprintf("%T", commands["PASV"]))
+	assert.NotEqual(t, "ftp.commandDataTransfer", fmt.Sprintf("%T", commands["RETR"]))
+}
+
+// closeCountingSocket records Close calls on a data socket
+type closeCountingSocket struct {
+	closed atomic.Int32
+}
+
+func (s *closeCountingSocket) Host() string                        { return "" }
+func (s *closeCountingSocket) Port() int                           { return 0 }
+func (s *closeCountingSocket) Read(p []byte) (int, error)          { return 0, io.EOF }
+func (s *closeCountingSocket) ReadFrom(r io.Reader) (int64, error) { return 0, errors.New("closed") }
+func (s *closeCountingSocket) Write(p []byte) (int, error)         { return 0, errors.New("closed") }
+func (s *closeCountingSocket) Close() error {
+	s.closed.Add(1)
+	return nil
+}
+
+func TestKubeAuth_DataIdleTimer(t *testing.T) {
+	auth := NewKubeAuth(nil)
+
+	idle := &closeCountingSocket{}
+	auth.startDataIdleTimer("session-1", idle, 20*time.Millisecond)
+	assert.Eventually(t, func() bool { return idle.closed.Load() == 1 }, time.Second, 5*time.Millisecond)
+	_, pending := auth.sessionDataIdle.Load("session-1")
+	assert.False(t, pending)
+
+	used := &closeCountingSocket{}
+	auth.startDataIdleTimer("session-2", used, 20*time.Millisecond)
+	auth.stopDataIdleTimer("session-2")
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, int32(0), used.closed.Load(), "a transfer should cancel the idle close")
+}
@@ -0,0 +1,146 @@
+package ftp
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"goftp.io/server/v2"
+)
+
+// passiveAcceptTimeout matches goftp's window for a client to connect to a
+// passive data port. A channel no transfer has used stops holding its port
+// after this long, so it no longer counts against the session's limit.
+const passiveAcceptTimeout = 60 * time.Second
+
+// dataChannels are a session's open passive data channels, each with the timer
+// that releases it once its accept window has passed
+type dataChannels struct {
+	mu      sync.Mutex
+	sockets map[server.DataSocket]*time.Timer
+}
+
+// sessionDataChannels returns the session's tracked data channels, creating
+// the entry on first use
+func (auth *KubeAuth) sessionDataChannels(sessionID string) *dataChannels {
+	value, _ := auth.sessionDataConns.LoadOrStore(sessionID, &dataChannels{sockets: make(map[server.DataSocket]*time.Timer)})
+	return value.(*dataChannels)
+}
+
+// openDataChannels returns how many passive data channels the session holds
+func (auth *KubeAuth) openDataChannels(sessionID string) int {
+	value, ok := auth.sessionDataConns.Load(sessionID)
+	if !ok {
+		return 0
+	}
+	channels := value.(*dataChannels)
+	channels.mu.Lock()
+	defer channels.mu.Unlock()
+	return len(channels.sockets)
+}
+
+// trackDataChannel counts socket against the session until a transfer uses it
+// or its accept window passes
+func (auth *KubeAuth) trackDataChannel(sessionID string, socket server.DataSocket) {
+	channels := auth.sessionDataChannels(sessionID)
+	channels.mu.Lock()
+	defer channels.mu.Unlock()
+	if _, ok := channels.sockets[socket]; ok {
+		return
+	}
+	channels.sockets[socket] = time.AfterFunc(passiveAcceptTimeout, func() {
+		auth.releaseDataChannel(sessionID, socket)
+	})
+}
+
+// releaseDataChannel stops counting socket against the session
+func (auth *KubeAuth) releaseDataChannel(sessionID string, socket server.DataSocket) {
+	value, ok := auth.sessionDataConns.Load(sessionID)
+	if !ok {
+		return
+	}
+	channels := value.(*dataChannels)
+	channels.mu.Lock()
+	defer channels.mu.Unlock()
+	if timer, ok := channels.sockets[socket]; ok {
+		timer.Stop()
+		delete(channels.sockets, socket)
+	}
+}
+
+// clearDataChannels forgets all of a session's data channels when it ends
+func (auth *KubeAuth) clearDataChannels(sessionID string) {
+	value, ok := auth.sessionDataConns.LoadAndDelete(sessionID)
+	if !ok {
+		return
+	}
+	channels := value.(*dataChannels)
+	channels.mu.Lock()
+	defer channels.mu.Unlock()
+	for _, timer := range channels.sockets {
+		timer.Stop()
+	}
+}
+
+// commandDataLimit wraps PASV and EPSV so a session holding
+// MaxDataConnsPerSession open passive data channels is refused another with
+// 425 instead of taking more ports from the passive pool.
+type commandDataLimit struct {
+	auth *KubeAuth
+	next server.Command
+}
+
+func (cmd commandDataLimit) IsExtend() bool {
+	return cmd.next.IsExtend()
+}
+
+func (cmd commandDataLimit) RequireParam() bool {
+	return cmd.next.RequireParam()
+}
+
+func (cmd commandDataLimit) RequireAuth() bool {
+	return cmd.next.RequireAuth()
+}
+
+func (cmd commandDataLimit) Execute(sess *server.Session, param string) {
+	sessionID := sessionIDForAddr(sess.RemoteAddr())
+	limit := cmd.auth.MaxDataConnsPerSession
+	if open := cmd.auth.openDataChannels(sessionID); open >= limit {
+		getLogger().Info("Refusing passive data connection over session limit", "username", sess.LoginUser(),
+			"session_id", sessionID, "open", open, "max_data_conns_per_session", limit)
+		sess.WriteMessage(425, fmt.Sprintf("Too many open data connections: %d of %d in use", open, limit))
+		return
+	}
+	previous := sess.DataConn()
+	cmd.next.Execute(sess, param)
+	if socket := sess.DataConn(); socket != nil && socket != previous {
+		cmd.auth.trackDataChannel(sessionID, socket)
+	}
+}
+
+// commandDataRelease wraps a transfer command so the data channel it consumes
+// no longer counts against the session's limit
+type commandDataRelease struct {
+	auth *KubeAuth
+	next server.Command
+}
+
+func (cmd commandDataRelease) IsExtend() bool {
+	return cmd.next.IsExtend()
+}
+
+func (cmd commandDataRelease) RequireParam() bool {
+	return cmd.next.RequireParam()
+}
+
+func (cmd commandDataRelease) RequireAuth() bool {
+	return cmd.next.RequireAuth()
+}
+
+func (cmd commandDataRelease) Execute(sess *server.Session, param string) {
+	socket := sess.DataConn()
+	cmd.next.Execute(sess, param)
+	if socket != nil && sess.DataConn() != socket {
+		cmd.auth.releaseDataChannel(sessionIDForAddr(sess.RemoteAddr()), socket)
+	}
+}
@@ -0,0 +1,58 @@
+package ftp
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandDataLimit_RejectsPassiveOverLimit(t *testing.T) {
+	auth := NewKubeAuth(nil)
+	auth.MaxDataConnsPerSession = 2
+	send := anonymousSessionWithAuth(t, auth)
+
+	ports := []int{passivePort(t, send("PASV")), passivePort(t, send("PASV"))}
+
+	reply := send("PASV")
+	assert.True(t, strings.HasPrefix(reply, "425 "), reply)
+	assert.Contains(t, reply, "2 of 2")
+	reply = send("EPSV")
+	assert.True(t, strings.HasPrefix(reply, "425 "), reply)
+
+	for _, port := range ports {
+		data, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		require.NoError(t, err, "allowed data channels should accept connections")
+		_ = data.Close()
+	}
+	assert.True(t, strings.HasPrefix(send("NOOP"), "200"), "control connection should stay open")
+}
+
+func TestCommandDataLimit_DisabledByDefault(t *testing.T) {
+	commands := buildCommands(NewKubeAuth(nil), nil)
+
+	assert.NotEqual(t, "ftp.commandDataLimit", fmt.Sprintf("%T", commands["PASV"]))
+	assert.NotEqual(t, "ftp.commandDataRelease", fmt.Sprintf("%T", commands["RETR"]))
+}
+
+func TestKubeAuth_DataChannels(t *testing.T) {
+	auth := NewKubeAuth(nil)
+	first, second := &closeCountingSocket{}, &closeCountingSocket{}
+
+	auth.trackDataChannel("session-1", first)
+	auth.trackDataChannel("session-1", second)
+	auth.trackDataChannel("session-1", second)
+	assert.Equal(t, 2, auth.openDataChannels("session-1"))
+	assert.Equal(t, 0, auth.openDataChannels("session-2"))
+
+	auth.releaseDataChannel("session-1", first)
+	assert.Equal(t, 1, auth.openDataChannels("session-1"), "a used channel should stop counting")
+
+	auth.clearDataChannels("session-1")
+	assert.Equal(t, 0, auth.openDataChannels("session-1"))
+	_, tracked := auth.sessionDataConns.Load("session-1")
+	assert.False(t, tracked)
+}
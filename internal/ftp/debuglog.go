@@ -0,0 +1,105 @@
+package ftp
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+)
+
+// DebugLoggingAnnotation on a User makes KubeLogger trace every command and
+// response of that user's sessions at info level.
+const DebugLoggingAnnotation = "kubeftpd.golder.org/debug-logging"
+
+// sessionTrace follows one session's command stream so KubeLogger can tell
+// which user it belongs to and whether debug tracing is on. goftp only exposes
+// its session id to the logger, so SITE DEBUG takes effect when the logger sees
+// the command's 200 reply.
+type sessionTrace struct {
+	mu           sync.Mutex
+	username     string
+	debug        bool
+	pendingDebug *bool
+}
+
+// trace returns the tracking state for a goftp session id
+func (kubeLogger *KubeLogger) trace(sessionId string) *sessionTrace {
+	trace, _ := kubeLogger.sessions.LoadOrStore(sessionId, &sessionTrace{})
+	return trace.(*sessionTrace)
+}
+
+// isDebugSession reports whether commands and responses of the session are traced
+func (kubeLogger *KubeLogger) isDebugSession(sessionId string) bool {
+	trace, ok := kubeLogger.sessions.Load(sessionId)
+	if !ok {
+		return false
+	}
+	t := trace.(*sessionTrace)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.debug
+}
+
+// observeCommand records the USER and SITE DEBUG commands of a session
+func (kubeLogger *KubeLogger) observeCommand(sessionId, command, params string) {
+	switch strings.ToUpper(command) {
+	case "USER":
+		t := kubeLogger.trace(sessionId)
+		t.mu.Lock()
+		t.username = params
+		t.mu.Unlock()
+	case "SITE":
+		fields := strings.Fields(params)
+		if len(fields) == 2 && strings.EqualFold(fields[0], "DEBUG") {
+			enable := strings.EqualFold(fields[1], "ON")
+			t := kubeLogger.trace(sessionId)
+			t.mu.Lock()
+			t.pendingDebug = &enable
+			t.mu.Unlock()
+		}
+	}
+}
+
+// observeResponse applies a pending SITE DEBUG once it is accepted and checks
+// the debug annotation of a user who has just logged in
+func (kubeLogger *KubeLogger) observeResponse(sessionId string, code int) {
+	trace, ok := kubeLogger.sessions.Load(sessionId)
+	if !ok {
+		return
+	}
+	t := trace.(*sessionTrace)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.pendingDebug != nil {
+		if code == 200 {
+			t.debug = *t.pendingDebug
+		}
+		t.pendingDebug = nil
+	}
+	if code == 230 && kubeLogger.auth != nil && t.username != "" {
+		if user := kubeLogger.auth.GetUser(context.Background(), t.username); user != nil &&
+			user.Annotations[DebugLoggingAnnotation] == "true" {
+			t.debug = true
+		}
+	}
+}
+
+// sessionLogger returns the logger for a session's commands and responses:
+// info level for debug sessions, debug verbosity otherwise
+func (kubeLogger *KubeLogger) sessionLogger(sessionId string) logr.Logger {
+	logger := kubeLogger.log()
+	if kubeLogger.isDebugSession(sessionId) {
+		return logger.WithValues("debug_session", true)
+	}
+	return logger.V(1)
+}
+
+// log returns the configured logger, defaulting to the FTP logger
+func (kubeLogger *KubeLogger) log() logr.Logger {
+	if kubeLogger.logger.GetSink() != nil {
+		return kubeLogger.logger
+	}
+	return getLogger()
+}
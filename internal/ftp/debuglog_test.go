@@ -0,0 +1,125 @@
+package ftp
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+// capturedLog collects lines written at info level
+type capturedLog struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (c *capturedLog) sessionLines(sessionId string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var lines []string
+	for _, line := range c.lines {
+		if strings.Contains(line, `"session_id"="`+sessionId+`"`) &&
+			(strings.Contains(line, `"FTP command"`) || strings.Contains(line, `"FTP response"`)) {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// newCachedAuth returns a KubeAuth with the given users cached, so lookups
+// don't need a Kubernetes client
+func newCachedAuth(users ...*ftpv1.User) *KubeAuth {
+	auth := NewKubeAuth(nil)
+	for _, user := range users {
+		auth.userCache.Store(user.Spec.Username, user)
+	}
+	return auth
+}
+
+func newTestUser(username string, annotations map[string]string) *ftpv1.User {
+	return &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: username, Annotations: annotations},
+		Spec:       ftpv1.UserSpec{Username: username},
+	}
+}
+
+func newCapturingKubeLogger(auth *KubeAuth) (*KubeLogger, *capturedLog) {
+	captured := &capturedLog{}
+	logger := funcr.New(func(prefix, args string) {
+		captured.mu.Lock()
+		captured.lines = append(captured.lines, args)
+		captured.mu.Unlock()
+	}, funcr.Options{})
+	return &KubeLogger{auth: auth, logger: logger}, captured
+}
+
+// login replays a successful USER/PASS exchange through the logger
+func login(logger *KubeLogger, sessionId, username string) {
+	logger.PrintCommand(sessionId, "USER", username)
+	logger.PrintResponse(sessionId, 331, "User name ok, password required")
+	logger.PrintCommand(sessionId, "PASS", "secret")
+	logger.PrintResponse(sessionId, 230, "Password ok, continue")
+}
+
+func TestKubeLogger_SiteDebugTracesOnlyTargetSession(t *testing.T) {
+	logger, captured := newCapturingKubeLogger(newCachedAuth(newTestUser("admin", nil), newTestUser("alice", nil)))
+
+	login(logger, "sess-a", "admin")
+	login(logger, "sess-b", "alice")
+	assert.Empty(t, captured.sessionLines("sess-a"), "commands are quiet before debug is enabled")
+
+	logger.PrintCommand("sess-a", "SITE", "DEBUG ON")
+	logger.PrintResponse("sess-a", 200, "Session debug logging enabled")
+
+	logger.PrintCommand("sess-a", "RETR", "report.csv")
+	logger.PrintResponse("sess-a", 226, "Transfer complete")
+	logger.PrintCommand("sess-b", "RETR", "other.csv")
+	logger.PrintResponse("sess-b", 226, "Transfer complete")
+
+	lines := captured.sessionLines("sess-a")
+	assert.Len(t, lines, 3)
+	assert.Contains(t, strings.Join(lines, "\n"), `"params"="report.csv"`)
+	assert.Empty(t, captured.sessionLines("sess-b"))
+
+	logger.PrintCommand("sess-a", "SITE", "DEBUG OFF")
+	logger.PrintResponse("sess-a", 200, "Session debug logging disabled")
+	logger.PrintCommand("sess-a", "LIST", "")
+	assert.Len(t, captured.sessionLines("sess-a"), 4, "only the SITE DEBUG OFF command itself is traced")
+}
+
+func TestKubeLogger_SiteDebugRefused(t *testing.T) {
+	logger, captured := newCapturingKubeLogger(newCachedAuth(newTestUser("alice", nil)))
+
+	login(logger, "sess-a", "alice")
+	logger.PrintCommand("sess-a", "SITE", "DEBUG ON")
+	logger.PrintResponse("sess-a", 550, "SITE DEBUG is restricted to admin users")
+	logger.PrintCommand("sess-a", "RETR", "report.csv")
+
+	assert.Empty(t, captured.sessionLines("sess-a"))
+}
+
+func TestKubeLogger_DebugAnnotation(t *testing.T) {
+	auth := newCachedAuth(
+		newTestUser("traced", map[string]string{DebugLoggingAnnotation: "true"}),
+		newTestUser("plain", nil),
+	)
+	logger, captured := newCapturingKubeLogger(auth)
+
+	login(logger, "sess-traced", "traced")
+	login(logger, "sess-plain", "plain")
+	logger.PrintCommand("sess-traced", "STOR", "upload.bin")
+	logger.PrintCommand("sess-plain", "STOR", "upload.bin")
+
+	// The 230 login reply and everything after it are traced
+	assert.Len(t, captured.sessionLines("sess-traced"), 2)
+	assert.Empty(t, captured.sessionLines("sess-plain"))
+
+	logger.Print("sess-traced", "Connection Terminated")
+	_, tracked := logger.sessions.Load("sess-traced")
+	assert.False(t, tracked)
+}
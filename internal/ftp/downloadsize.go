@@ -0,0 +1,61 @@
+package ftp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+// errTruncatedDownload fails a download whose backend reader ended before the
+// file's size when FailTruncatedDownloads is enabled
+var errTruncatedDownload = errors.New("download ended before the end of the file")
+
+// sizeCheckReader compares the bytes a backend streams for a whole-file
+// download with the size reported for the file. A mismatch is reported once,
+// when the backend reader ends; a download the client abandons is not checked.
+type sizeCheckReader struct {
+	io.ReadCloser
+	expected int64
+	read     int64
+	checked  bool
+	// fail turns a short read into an error so the client sees the transfer fail
+	fail     bool
+	mismatch func(read int64)
+}
+
+func (r *sizeCheckReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.read += int64(n)
+	if !errors.Is(err, io.EOF) || r.checked {
+		return n, err
+	}
+	r.checked = true
+	if r.read == r.expected {
+		return n, err
+	}
+	r.mismatch(r.read)
+	if r.fail && r.read < r.expected {
+		return n, fmt.Errorf("%w: sent %d of %d bytes", errTruncatedDownload, r.read, r.expected)
+	}
+	return n, err
+}
+
+// checkDownloadSize wraps the reader of a whole-file download so a backend
+// returning fewer or more bytes than the file's size is logged and counted
+func (driver *KubeDriver) checkDownloadSize(reader io.ReadCloser, path string, size int64) io.ReadCloser {
+	if size < 0 {
+		return reader
+	}
+	return &sizeCheckReader{
+		ReadCloser: reader,
+		expected:   size,
+		fail:       driver.failShortDownloads,
+		mismatch: func(read int64) {
+			driver.operationLogger().Info("Download size does not match the file size", "username", driver.getAuthenticatedUsername(),
+				"path", path, "size_bytes", size, "sent_bytes", read, "failed", driver.failShortDownloads && read < size)
+			metrics.RecordDownloadSizeMismatch(driver.getBackendType())
+		},
+	}
+}
@@ -0,0 +1,77 @@
+package ftp
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/metrics"
+	"github.com/rossigee/kubeftpd/internal/storage"
+)
+
+// newDownloadSizeTestDriver returns a driver whose storage reports size for
+// /data.bin but streams contents
+func newDownloadSizeTestDriver(size int64, contents string, offset int64) *KubeDriver {
+	mockStorage := &MockStorage{capabilities: storage.Capabilities{Range: true}}
+	mockStorage.On("GetFile", "/data.bin", offset).Return(size, io.NopCloser(strings.NewReader(contents)), nil)
+	user := &ftpv1.User{Spec: ftpv1.UserSpec{
+		Username:    "testuser",
+		Enabled:     true,
+		Permissions: ftpv1.UserPermissions{Read: true},
+		Backend:     ftpv1.BackendReference{Kind: "MinioBackend", Name: "archive"},
+	}}
+	return &KubeDriver{user: user, storageImpl: mockStorage}
+}
+
+func downloadSizeMismatches() float64 {
+	return testutil.ToFloat64(metrics.DownloadSizeMismatchesTotal.WithLabelValues("MinioBackend"))
+}
+
+func TestKubeDriver_GetFile_DownloadSize(t *testing.T) {
+	tests := []struct {
+		name         string
+		size         int64
+		contents     string
+		offset       int64
+		fail         bool
+		wantMismatch bool
+		wantErr      bool
+	}{
+		{name: "matching size", size: 8, contents: "complete", offset: 0},
+		{name: "short read is counted", size: 100, contents: "truncated", wantMismatch: true},
+		{name: "short read fails when enabled", size: 100, contents: "truncated", fail: true, wantMismatch: true, wantErr: true},
+		{name: "long read is counted but not failed", size: 4, contents: "grown object", fail: true, wantMismatch: true},
+		{name: "ranged download is not checked", size: 100, contents: "tail", offset: 50, fail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := newDownloadSizeTestDriver(tt.size, tt.contents, tt.offset)
+			driver.failShortDownloads = tt.fail
+			before := downloadSizeMismatches()
+
+			_, reader, err := driver.GetFile(nil, "/data.bin", tt.offset)
+			require.NoError(t, err)
+			data, err := io.ReadAll(reader)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errTruncatedDownload)
+				assert.ErrorContains(t, err, "sent 9 of 100 bytes")
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.contents, string(data), "every byte the backend returned is still sent")
+			require.NoError(t, reader.Close())
+
+			wantCount := before
+			if tt.wantMismatch {
+				wantCount++
+			}
+			assert.Equal(t, wantCount, downloadSizeMismatches())
+		})
+	}
+}
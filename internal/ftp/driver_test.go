@@ -1,25 +1,37 @@
 package ftp
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"io/fs"
+	"net"
 	"os"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"goftp.io/server/v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/metrics"
+	"github.com/rossigee/kubeftpd/internal/storage"
 )
 
 // MockStorage for testing
 type MockStorage struct {
 	mock.Mock
+	capabilities storage.Capabilities
 }
 
 func (m *MockStorage) ChangeDir(path string) error {
@@ -67,6 +79,10 @@ func (m *MockStorage) PutFile(path string, reader io.Reader, offset int64) (int6
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockStorage) Capabilities() storage.Capabilities {
+	return m.capabilities
+}
+
 // MockFileInfo for testing
 
 func (m *MockStorage) Close() error {
@@ -81,6 +97,7 @@ type MockFileInfo struct {
 	mode  fs.FileMode
 	owner string
 	group string
+	nlink uint64
 }
 
 func (m *MockFileInfo) Name() string       { return m.name }
@@ -91,6 +108,7 @@ func (m *MockFileInfo) IsDir() bool        { return m.isDir }
 func (m *MockFileInfo) Owner() string      { return m.owner }
 func (m *MockFileInfo) Group() string      { return m.group }
 func (m *MockFileInfo) Sys() interface{}   { return nil }
+func (m *MockFileInfo) Nlink() uint64      { return m.nlink }
 
 func TestKubeDriver_ensureUserInitialized(t *testing.T) {
 	scheme := runtime.NewScheme()
@@ -322,6 +340,35 @@ func TestKubeDriver_ListDir(t *testing.T) {
 	mockStorage.AssertExpectations(t)
 }
 
+func TestKubeDriver_ListDir_EmptyDirectory(t *testing.T) {
+	user := &ftpv1.User{Spec: ftpv1.UserSpec{
+		Username:    "guest",
+		Type:        "anonymous",
+		Enabled:     true,
+		Permissions: ftpv1.UserPermissions{Read: true, List: true},
+	}}
+	auth := NewKubeAuth(nil)
+	auth.userCache.Store("guest", user)
+	mockStorage := &MockStorage{}
+	mockStorage.On("Stat", "/empty").Return(&MockFileInfo{name: "empty", isDir: true}, nil)
+	mockStorage.On("ListDir", "/empty", mock.Anything).Return(nil)
+	send := anonymousSessionWithDriver(t, &KubeDriver{auth: auth, user: user, storageImpl: mockStorage})
+
+	port := passivePort(t, send("PASV"))
+	data, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	require.NoError(t, err)
+	defer func() { _ = data.Close() }()
+	require.True(t, strings.HasPrefix(send("LIST empty"), "150 "))
+	require.NoError(t, data.SetReadDeadline(time.Now().Add(5*time.Second)))
+	listing, err := io.ReadAll(data)
+	require.NoError(t, err)
+	assert.Empty(t, listing)
+
+	// The transfer's completion reply is read before the NOOP's
+	assert.True(t, strings.HasPrefix(send("NOOP"), "226 "), "an empty listing should succeed")
+	mockStorage.AssertExpectations(t)
+}
+
 func TestKubeDriver_DeleteDir(t *testing.T) {
 	scheme := runtime.NewScheme()
 	err := ftpv1.AddToScheme(scheme)
@@ -490,6 +537,324 @@ func TestKubeDriver_PutFile(t *testing.T) {
 	mockStorage.AssertExpectations(t)
 }
 
+func TestKubeDriver_PutFile_OverwritePolicy(t *testing.T) {
+	notFound := errors.New("file not found")
+	existing := &MockFileInfo{name: "report.txt", size: 10}
+
+	tests := []struct {
+		name       string
+		policy     string
+		stats      map[string]error // paths that are statted, nil error meaning the file exists
+		wantPath   string
+		wantErr    bool
+		wantUpload bool
+	}{
+		{
+			name:       "allow overwrites without statting",
+			policy:     "allow",
+			wantPath:   "/report.txt",
+			wantUpload: true,
+		},
+		{
+			name:       "empty policy behaves as allow",
+			policy:     "",
+			wantPath:   "/report.txt",
+			wantUpload: true,
+		},
+		{
+			name:    "deny rejects existing file",
+			policy:  "deny",
+			stats:   map[string]error{"/report.txt": nil},
+			wantErr: true,
+		},
+		{
+			name:       "deny accepts new file",
+			policy:     "deny",
+			stats:      map[string]error{"/report.txt": notFound},
+			wantPath:   "/report.txt",
+			wantUpload: true,
+		},
+		{
+			name:   "rename suffixes existing file",
+			policy: "rename",
+			stats: map[string]error{
+				"/report.txt":   nil,
+				"/report.1.txt": nil,
+				"/report.2.txt": notFound,
+			},
+			wantPath:   "/report.2.txt",
+			wantUpload: true,
+		},
+		{
+			name:    "stat failure rejects upload",
+			policy:  "deny",
+			stats:   map[string]error{"/report.txt": errors.New("connection refused")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testUser := &ftpv1.User{
+				ObjectMeta: metav1.ObjectMeta{Name: "testuser", Namespace: "default"},
+				Spec: ftpv1.UserSpec{
+					Username:        "testuser",
+					Enabled:         true,
+					Backend:         ftpv1.BackendReference{Kind: "MinioBackend", Name: "test-backend"},
+					HomeDirectory:   "/test",
+					OverwritePolicy: tt.policy,
+				},
+			}
+
+			reader := strings.NewReader("new content")
+			mockStorage := &MockStorage{}
+			for path, statErr := range tt.stats {
+				if statErr == nil {
+					mockStorage.On("Stat", path).Return(existing, nil)
+				} else {
+					mockStorage.On("Stat", path).Return((*MockFileInfo)(nil), statErr)
+				}
+			}
+			if tt.wantUpload {
+				mockStorage.On("PutFile", tt.wantPath, reader, int64(0)).Return(int64(reader.Len()), nil)
+			}
+
+			driver := &KubeDriver{
+				authenticatedUser: "testuser",
+				user:              testUser,
+				storageImpl:       mockStorage,
+			}
+
+			_, err := driver.PutFile(nil, "/report.txt", reader, 0)
+			if tt.wantErr {
+				assert.Error(t, err)
+				mockStorage.AssertNotCalled(t, "PutFile", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestKubeDriver_PutFile_OverwritePolicyContinuedUpload(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		cmd    string
+		offset int64
+	}{
+		{name: "deny allows resume", policy: "deny", cmd: "STOR", offset: 10},
+		{name: "rename resumes in place", policy: "rename", cmd: "STOR", offset: 10},
+		{name: "deny allows append", policy: "deny", cmd: "APPE", offset: -1},
+		{name: "rename appends in place", policy: "rename", cmd: "APPE", offset: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testUser := &ftpv1.User{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testuser",
+					Namespace: "default",
+				},
+				Spec: ftpv1.UserSpec{
+					Username:        "testuser",
+					Enabled:         true,
+					Backend:         ftpv1.BackendReference{Kind: "MinioBackend", Name: "test-backend"},
+					HomeDirectory:   "/test",
+					OverwritePolicy: tt.policy,
+				},
+			}
+
+			reader := strings.NewReader("more content")
+			mockStorage := &MockStorage{capabilities: storage.Capabilities{Append: true}}
+			mockStorage.On("PutFile", "/report.txt", reader, tt.offset).Return(int64(reader.Len()), nil)
+
+			driver := &KubeDriver{
+				authenticatedUser: "testuser",
+				user:              testUser,
+				storageImpl:       mockStorage,
+			}
+
+			_, err := driver.PutFile(&server.Context{Cmd: tt.cmd}, "/report.txt", reader, tt.offset)
+			assert.NoError(t, err)
+			mockStorage.AssertNotCalled(t, "Stat", mock.Anything)
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+// datePartitioned matches name stored under a YYYY/MM/DD upload date directory
+func datePartitioned(name string) interface{} {
+	pattern := regexp.MustCompile(`^/\d{4}/\d{2}/\d{2}/` + regexp.QuoteMeta(name) + `$`)
+	return mock.MatchedBy(func(p string) bool { return pattern.MatchString(p) })
+}
+
+func TestKubeDriver_PutFile_DatePartitionOverwritePolicy(t *testing.T) {
+	existing := &MockFileInfo{name: "report.txt", size: 10}
+	newUser := func(policy string) *ftpv1.User {
+		return &ftpv1.User{
+			ObjectMeta: metav1.ObjectMeta{Name: "testuser", Namespace: "default"},
+			Spec: ftpv1.UserSpec{
+				Username:        "testuser",
+				Enabled:         true,
+				Backend:         ftpv1.BackendReference{Kind: "FilesystemBackend", Name: "test-backend"},
+				HomeDirectory:   "/test",
+				OverwritePolicy: policy,
+			},
+		}
+	}
+
+	t.Run("deny checks the partitioned path", func(t *testing.T) {
+		mockStorage := &MockStorage{capabilities: storage.Capabilities{DatePartition: true}}
+		mockStorage.On("Stat", datePartitioned("report.txt")).Return(existing, nil)
+		driver := &KubeDriver{authenticatedUser: "testuser", user: newUser("deny"), storageImpl: mockStorage}
+
+		_, err := driver.PutFile(nil, "/report.txt", strings.NewReader("new content"), 0)
+		assert.Error(t, err)
+		mockStorage.AssertNotCalled(t, "Stat", "/report.txt")
+		mockStorage.AssertNotCalled(t, "PutFile", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rename suffixes the partitioned file", func(t *testing.T) {
+		reader := strings.NewReader("new content")
+		mockStorage := &MockStorage{capabilities: storage.Capabilities{DatePartition: true}}
+		mockStorage.On("Stat", datePartitioned("report.txt")).Return(existing, nil)
+		mockStorage.On("Stat", datePartitioned("report.1.txt")).Return((*MockFileInfo)(nil), os.ErrNotExist)
+		mockStorage.On("PutFile", datePartitioned("report.1.txt"), reader, int64(0)).Return(int64(reader.Len()), nil)
+		recorder := events.NewFakeRecorder(10)
+		driver := &KubeDriver{
+			auth:              NewKubeAuth(nil),
+			authenticatedUser: "testuser",
+			user:              newUser("rename"),
+			storageImpl:       mockStorage,
+			recorder:          recorder,
+		}
+
+		_, err := driver.PutFile(nil, "/report.txt", reader, 0)
+		require.NoError(t, err)
+		mockStorage.AssertExpectations(t)
+
+		// The event names the upload under its date directories
+		select {
+		case event := <-recorder.Events:
+			assert.Regexp(t, `^Normal UploadCompleted upload /\d{4}/\d{2}/\d{2}/report\.txt \(11 bytes\)$`, event)
+		default:
+			t.Fatal("expected a transfer event to be recorded")
+		}
+	})
+}
+
+func TestKubeDriver_PutFile_TransferEvent(t *testing.T) {
+	testUser := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testuser",
+			Namespace: "default",
+		},
+		Spec: ftpv1.UserSpec{
+			Username:      "testuser",
+			Enabled:       true,
+			HomeDirectory: "/test",
+			Backend: ftpv1.BackendReference{
+				Kind: "FilesystemBackend",
+				Name: "test-backend",
+			},
+			Permissions: ftpv1.UserPermissions{
+				Read:  true,
+				Write: true,
+			},
+		},
+	}
+
+	testContent := "test file content"
+	reader := strings.NewReader(testContent)
+
+	mockStorage := &MockStorage{}
+	mockStorage.On("PutFile", "/report.csv", reader, int64(0)).Return(int64(len(testContent)), nil)
+
+	recorder := events.NewFakeRecorder(10)
+	driver := &KubeDriver{
+		auth:              NewKubeAuth(nil),
+		authenticatedUser: "testuser",
+		user:              testUser,
+		storageImpl:       mockStorage,
+		recorder:          recorder,
+	}
+
+	_, err := driver.PutFile(nil, "/report.csv", reader, int64(0))
+	assert.NoError(t, err)
+
+	select {
+	case event := <-recorder.Events:
+		assert.Equal(t, "Normal UploadCompleted upload /report.csv (17 bytes)", event)
+	default:
+		t.Fatal("expected a transfer event to be recorded")
+	}
+
+	// Without a recorder no event is emitted and nothing panics
+	driver.recorder = nil
+	mockStorage.On("PutFile", "/other.csv", mock.Anything, int64(0)).Return(int64(1), nil)
+	_, err = driver.PutFile(nil, "/other.csv", strings.NewReader("x"), int64(0))
+	assert.NoError(t, err)
+	assert.Empty(t, recorder.Events)
+}
+
+func TestKubeDriver_GetFile_TransferEvent(t *testing.T) {
+	testUser := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testuser",
+			Namespace: "default",
+		},
+		Spec: ftpv1.UserSpec{
+			Username:      "testuser",
+			Enabled:       true,
+			HomeDirectory: "/test",
+			Backend: ftpv1.BackendReference{
+				Kind: "FilesystemBackend",
+				Name: "test-backend",
+			},
+			Permissions: ftpv1.UserPermissions{Read: true},
+		},
+	}
+
+	testContent := "test file content"
+	mockStorage := &MockStorage{}
+	mockStorage.On("GetFile", "/report.csv", int64(0)).Return(int64(len(testContent)), io.NopCloser(strings.NewReader(testContent)), nil).Once()
+	mockStorage.On("GetFile", "/aborted.csv", int64(0)).Return(int64(len(testContent)), io.NopCloser(strings.NewReader(testContent)), nil).Once()
+
+	recorder := events.NewFakeRecorder(10)
+	driver := &KubeDriver{
+		auth:              NewKubeAuth(nil),
+		authenticatedUser: "testuser",
+		user:              testUser,
+		storageImpl:       mockStorage,
+		recorder:          recorder,
+	}
+
+	_, reader, err := driver.GetFile(nil, "/report.csv", 0)
+	require.NoError(t, err)
+	assert.Empty(t, recorder.Events, "no event is recorded before the transfer")
+	_, err = io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+
+	select {
+	case event := <-recorder.Events:
+		assert.Equal(t, "Normal DownloadCompleted download /report.csv (17 bytes)", event)
+	default:
+		t.Fatal("expected a transfer event to be recorded")
+	}
+
+	// A download the client abandons part way records no event
+	_, reader, err = driver.GetFile(nil, "/aborted.csv", 0)
+	require.NoError(t, err)
+	_, err = reader.Read(make([]byte, 4))
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	assert.Empty(t, recorder.Events)
+	mockStorage.AssertExpectations(t)
+}
+
 func TestKubeDriver_OperationLogging(t *testing.T) {
 	scheme := runtime.NewScheme()
 	err := ftpv1.AddToScheme(scheme)
@@ -665,5 +1030,97 @@ func TestKubeLogger_PrintCommand_PasswordRedaction(t *testing.T) {
 		logger.PrintCommand("test-session", "PASS", "secretpassword")
 		logger.PrintCommand("test-session", "USER", "testuser")
 		logger.PrintCommand("test-session", "ACCT", "secretaccount")
+		logger.PrintCommand("test-session", "SITE", "PASSWD oldsecret newsecret")
+	})
+}
+
+func TestKubeDriver_GetOwnerGroup(t *testing.T) {
+	user := &ftpv1.User{Spec: ftpv1.UserSpec{Username: "testuser", HomeDirectory: "/"}}
+	stored := &MockFileInfo{name: "report.csv", owner: "alice", group: "finance"}
+
+	t.Run("backend tracks ownership", func(t *testing.T) {
+		mockStorage := &MockStorage{capabilities: storage.Capabilities{Ownership: true}}
+		mockStorage.On("Stat", "/report.csv").Return(stored, nil)
+		driver := &KubeDriver{authenticatedUser: "testuser", user: user, storageImpl: mockStorage}
+
+		owner, err := driver.GetOwner("/report.csv")
+		assert.NoError(t, err)
+		assert.Equal(t, "alice", owner)
+		group, err := driver.GetGroup("/report.csv")
+		assert.NoError(t, err)
+		assert.Equal(t, "finance", group)
+	})
+
+	t.Run("backend without ownership", func(t *testing.T) {
+		mockStorage := &MockStorage{}
+		driver := &KubeDriver{authenticatedUser: "testuser", user: user, storageImpl: mockStorage}
+
+		owner, err := driver.GetOwner("/report.csv")
+		assert.NoError(t, err)
+		assert.Equal(t, "testuser", owner)
+		group, err := driver.GetGroup("/report.csv")
+		assert.NoError(t, err)
+		assert.Equal(t, "ftp", group)
+		mockStorage.AssertNotCalled(t, "Stat", mock.Anything)
+	})
+
+	t.Run("chrooted user with a home below the root", func(t *testing.T) {
+		chrootUser := &ftpv1.User{Spec: ftpv1.UserSpec{Username: "testuser", HomeDirectory: "/home/testuser", Chroot: true}}
+		mockStorage := &MockStorage{capabilities: storage.Capabilities{Ownership: true}}
+		mockStorage.On("Stat", "/home/testuser/report.csv").Return(stored, nil)
+		driver := &KubeDriver{authenticatedUser: "testuser", user: chrootUser, storageImpl: mockStorage}
+
+		owner, err := driver.GetOwner("/report.csv")
+		assert.NoError(t, err)
+		assert.Equal(t, "alice", owner)
+		group, err := driver.GetGroup("/report.csv")
+		assert.NoError(t, err)
+		assert.Equal(t, "finance", group)
+		_, err = driver.GetMode("/report.csv")
+		assert.NoError(t, err)
+		mockStorage.AssertNotCalled(t, "Stat", "/report.csv")
 	})
 }
+
+func TestKubeDriver_BackendCacheMiss(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, ftpv1.AddToScheme(scheme))
+	backend := &ftpv1.FilesystemBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "cache-miss-backend", Namespace: "default"},
+		Spec:       ftpv1.FilesystemBackendSpec{BasePath: t.TempDir()},
+	}
+	testUser := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "scanner", Namespace: "default"},
+		Spec: ftpv1.UserSpec{
+			Username:      "scanner",
+			Enabled:       true,
+			HomeDirectory: "/",
+			Backend:       ftpv1.BackendReference{Kind: "FilesystemBackend", Name: "cache-miss-backend"},
+			Permissions:   ftpv1.UserPermissions{Read: true, List: true},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(testUser, backend).Build()
+	auth := NewKubeAuth(fakeClient)
+	auth.userCache.Store("scanner", testUser)
+
+	misses := metrics.BackendCacheMissesTotal.WithLabelValues("FilesystemBackend", "cache-miss-backend")
+	before := testutil.ToFloat64(misses)
+	driver := &KubeDriver{
+		client:            fakeClient,
+		auth:              auth,
+		authenticatedUser: "scanner",
+		sessionID:         "ftp-session-cache-miss",
+		sessionCtx:        context.Background(),
+	}
+
+	// The first operation constructs the storage
+	_, err := driver.Stat(nil, "/")
+	require.NoError(t, err)
+	assert.Equal(t, before+1, testutil.ToFloat64(misses))
+
+	// Later operations reuse it
+	_, err = driver.Stat(nil, "/")
+	require.NoError(t, err)
+	assert.Equal(t, before+1, testutil.ToFloat64(misses))
+	require.NoError(t, driver.Close())
+}
@@ -0,0 +1,113 @@
+package ftp
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// errorLogLimiter collapses runs of identical errors logged by a session. The
+// first error of a run is logged; repeats within interval of the last logged
+// line are counted and reported in one summary line when the run ends or the
+// interval has passed.
+type errorLogLimiter struct {
+	interval time.Duration
+	now      func() time.Time
+
+	mu         sync.Mutex
+	key        string       // message and error of the current run
+	msg        string       // message of the current run
+	errText    string       // error of the current run
+	logged     time.Time    // when the current run was last logged
+	suppressed int          // repeats not logged since then
+	sink       logr.LogSink // sink the current run was logged to
+}
+
+func newErrorLogLimiter(interval time.Duration) *errorLogLimiter {
+	return &errorLogLimiter{interval: interval, now: time.Now}
+}
+
+// observe decides whether an error is logged. It returns false when it should
+// be suppressed as a repeat.
+func (l *errorLogLimiter) observe(sink logr.LogSink, err error, msg string) bool {
+	errText := ""
+	if err != nil {
+		errText = err.Error()
+	}
+	key := msg + "\x00" + errText
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if key == l.key && now.Sub(l.logged) < l.interval {
+		l.suppressed++
+		return false
+	}
+	l.flushLocked()
+	l.key, l.msg, l.errText, l.logged, l.sink = key, msg, errText, now, sink
+	return true
+}
+
+// flush reports repeats suppressed in the current run, e.g. when the session ends
+func (l *errorLogLimiter) flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.flushLocked()
+	l.key = ""
+}
+
+func (l *errorLogLimiter) flushLocked() {
+	if l.suppressed == 0 {
+		return
+	}
+	l.sink.Info(0, "Suppressed repeated errors", "error_message", l.msg, "error", l.errText, "count", l.suppressed)
+	l.suppressed = 0
+}
+
+// errorLogSink passes log lines to its sink, collapsing repeated errors
+type errorLogSink struct {
+	logr.LogSink
+	limiter *errorLogLimiter
+}
+
+func (s *errorLogSink) Error(err error, msg string, keysAndValues ...any) {
+	if s.limiter.observe(s.LogSink, err, msg) {
+		s.LogSink.Error(err, msg, keysAndValues...)
+	}
+}
+
+func (s *errorLogSink) WithValues(keysAndValues ...any) logr.LogSink {
+	return &errorLogSink{LogSink: s.LogSink.WithValues(keysAndValues...), limiter: s.limiter}
+}
+
+func (s *errorLogSink) WithName(name string) logr.LogSink {
+	return &errorLogSink{LogSink: s.LogSink.WithName(name), limiter: s.limiter}
+}
+
+// sessionErrorLimiter returns the error log limiter of a session, or nil when
+// repeated errors are not collapsed
+func (auth *KubeAuth) sessionErrorLimiter(sessionID string) *errorLogLimiter {
+	if auth == nil || auth.ErrorLogInterval <= 0 || sessionID == "" {
+		return nil
+	}
+	value, _ := auth.sessionErrorLogs.LoadOrStore(sessionID, newErrorLogLimiter(auth.ErrorLogInterval))
+	return value.(*errorLogLimiter)
+}
+
+// clearSessionErrorLimiter reports any suppressed errors and forgets the
+// session's limiter when it ends
+func (auth *KubeAuth) clearSessionErrorLimiter(sessionID string) {
+	if value, ok := auth.sessionErrorLogs.LoadAndDelete(sessionID); ok {
+		value.(*errorLogLimiter).flush()
+	}
+}
+
+// withErrorLimit makes logger collapse the session's repeated errors
+func (driver *KubeDriver) withErrorLimit(logger logr.Logger) logr.Logger {
+	limiter := driver.auth.sessionErrorLimiter(driver.sessionID)
+	if limiter == nil {
+		return logger
+	}
+	return logger.WithSink(&errorLogSink{LogSink: logger.GetSink(), limiter: limiter})
+}
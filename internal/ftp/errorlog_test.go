@@ -0,0 +1,115 @@
+package ftp
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// limitedLogger returns a logger collapsing repeated errors, the lines it
+// wrote, and a function advancing its clock
+func limitedLogger(interval time.Duration) (logr.Logger, *[]string, *errorLogLimiter, func(time.Duration)) {
+	var lines []string
+	base := funcr.New(func(prefix, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{})
+	now := time.Unix(1700000000, 0)
+	limiter := newErrorLogLimiter(interval)
+	limiter.now = func() time.Time { return now }
+	logger := base.WithSink(&errorLogSink{LogSink: base.GetSink(), limiter: limiter})
+	return logger, &lines, limiter, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestErrorLogSink_CollapsesRepeats(t *testing.T) {
+	logger, lines, limiter, _ := limitedLogger(time.Minute)
+	failed := errors.New("backend unavailable")
+
+	for i := 0; i < 50; i++ {
+		logger.Error(failed, "Upload operation failed", "path", "/scan.pdf")
+	}
+	require.Len(t, *lines, 1, "identical errors should be logged once")
+	assert.Contains(t, (*lines)[0], `"msg"="Upload operation failed"`)
+
+	// A different error ends the run with a summary
+	logger.Error(errors.New("permission denied"), "Upload operation failed", "path", "/scan.pdf")
+	require.Len(t, *lines, 3)
+	assert.Contains(t, (*lines)[1], `"msg"="Suppressed repeated errors"`)
+	assert.Contains(t, (*lines)[1], `"count"=49`)
+	assert.Contains(t, (*lines)[1], `"error"="backend unavailable"`)
+	assert.Contains(t, (*lines)[2], `"error"="permission denied"`)
+
+	// Info lines are never collapsed
+	logger.Info("FTP Stat operation")
+	logger.Info("FTP Stat operation")
+	assert.Len(t, *lines, 5)
+
+	// Ending the session reports nothing when no repeats are pending
+	limiter.flush()
+	assert.Len(t, *lines, 5)
+}
+
+func TestErrorLogSink_IntervalAndFlush(t *testing.T) {
+	logger, lines, limiter, advance := limitedLogger(time.Minute)
+	failed := errors.New("backend unavailable")
+
+	for i := 0; i < 10; i++ {
+		logger.Error(failed, "Upload operation failed")
+		advance(time.Second)
+	}
+	require.Len(t, *lines, 1)
+
+	// Once the interval has passed a repeat is logged again after its summary
+	advance(time.Minute)
+	logger.Error(failed, "Upload operation failed")
+	require.Len(t, *lines, 3)
+	assert.Contains(t, (*lines)[1], `"count"=9`)
+	assert.Contains(t, (*lines)[2], `"msg"="Upload operation failed"`)
+
+	// Repeats pending when the session ends are summarised
+	logger.Error(failed, "Upload operation failed")
+	logger.Error(failed, "Upload operation failed")
+	limiter.flush()
+	require.Len(t, *lines, 4)
+	assert.Contains(t, (*lines)[3], `"count"=2`)
+}
+
+func TestKubeDriver_OperationLoggerErrorLimit(t *testing.T) {
+	auth := NewKubeAuth(nil)
+	driver := &KubeDriver{auth: auth, sessionID: "ftp-session-errors"}
+	_, limited := driver.operationLogger().GetSink().(*errorLogSink)
+	assert.False(t, limited, "errors are not collapsed by default")
+
+	auth.ErrorLogInterval = time.Minute
+	sink, limited := driver.operationLogger().GetSink().(*errorLogSink)
+	require.True(t, limited)
+	again := driver.operationLogger().GetSink().(*errorLogSink)
+	assert.Same(t, sink.limiter, again.limiter, "a session's loggers share one limiter")
+
+	other := (&KubeDriver{auth: auth, sessionID: "ftp-session-other"}).operationLogger().GetSink().(*errorLogSink)
+	assert.NotSame(t, sink.limiter, other.limiter, "sessions are limited separately")
+
+	serverConn, clientConn := net.Pipe()
+	defer func() { _ = clientConn.Close() }()
+	require.NoError(t, (&sessionConn{Conn: serverConn, auth: auth, sessionID: "ftp-session-errors"}).Close())
+	_, ok := auth.sessionErrorLogs.Load("ftp-session-errors")
+	assert.False(t, ok, "the limiter is forgotten when the session ends")
+}
+
+func TestErrorLogLimiter_KeysOnMessageAndError(t *testing.T) {
+	logger, lines, _, _ := limitedLogger(time.Minute)
+	logger.Error(nil, "ensureUserInitialized failed")
+	logger.Error(nil, "ensureUserInitialized failed")
+	logger.Error(nil, "LIST operation failed")
+	logger.Error(errors.New("a"), "LIST operation failed")
+	require.Len(t, *lines, 4, "the repeat is collapsed into one summary")
+	assert.Contains(t, (*lines)[0], `"msg"="ensureUserInitialized failed"`)
+	assert.Contains(t, (*lines)[1], `"count"=1`)
+	assert.Contains(t, (*lines)[2], `"msg"="LIST operation failed"`)
+	assert.Contains(t, (*lines)[3], `"error"="a"`)
+}
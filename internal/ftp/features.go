@@ -0,0 +1,37 @@
+package ftp
+
+import (
+	"strings"
+
+	"goftp.io/server/v2"
+)
+
+// commandUnadvertised hides an extension command from the FEAT reply while
+// leaving it usable. goftp builds FEAT from the commands reporting IsExtend.
+type commandUnadvertised struct {
+	server.Command
+}
+
+func (cmd commandUnadvertised) IsExtend() bool {
+	return false
+}
+
+// suppressFeatures removes the named tokens from the FEAT advertisement and
+// returns the tokens that are not advertised extension commands and so
+// could not be suppressed.
+func suppressFeatures(commands map[string]server.Command, features []string) []string {
+	var unknown []string
+	for _, feature := range features {
+		name := strings.ToUpper(strings.TrimSpace(feature))
+		if name == "" {
+			continue
+		}
+		cmd, ok := commands[name]
+		if !ok || !cmd.IsExtend() {
+			unknown = append(unknown, name)
+			continue
+		}
+		commands[name] = commandUnadvertised{Command: cmd}
+	}
+	return unknown
+}
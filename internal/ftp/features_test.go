@@ -0,0 +1,91 @@
+package ftp
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goftp.io/server/v2"
+)
+
+// featReply starts a goftp server with the given commands and returns the
+// lines of its FEAT reply
+func featReply(t *testing.T, commands map[string]server.Command) []string {
+	auth := NewKubeAuth(nil)
+	driver := &KubeDriver{auth: auth}
+	ftpServer, err := server.NewServer(&server.Options{
+		Driver:   driver,
+		Auth:     auth,
+		Perm:     driver,
+		Logger:   &KubeLogger{auth: auth},
+		Commands: commands,
+	})
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = ftpServer.Serve(listener) }()
+	t.Cleanup(func() { _ = ftpServer.Shutdown() })
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	reader := bufio.NewReader(conn)
+	banner, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(banner, "220"), "unexpected banner %q", banner)
+
+	_, err = conn.Write([]byte("FEAT\r\n"))
+	require.NoError(t, err)
+
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\r\n")
+		lines = append(lines, line)
+		if strings.HasPrefix(line, "211 ") {
+			return lines
+		}
+	}
+}
+
+// hasFeature reports whether a FEAT reply advertises the given token
+func hasFeature(lines []string, token string) bool {
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if strings.HasPrefix(line, " ") && len(fields) > 0 && fields[0] == token {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSuppressFeatures_RemovesMLSTFromFEAT(t *testing.T) {
+	commands := buildCommands(NewKubeAuth(nil), nil)
+	unknown := suppressFeatures(commands, []string{"mlst"})
+	assert.Empty(t, unknown)
+
+	lines := featReply(t, commands)
+	assert.False(t, hasFeature(lines, "MLST"), "MLST should not be advertised: %v", lines)
+	for _, token := range []string{"UTF8", "EPSV", "EPRT", "SITE"} {
+		assert.True(t, hasFeature(lines, token), "%s should still be advertised: %v", token, lines)
+	}
+
+	// The command itself keeps working
+	assert.False(t, commands["MLST"].IsExtend())
+	assert.True(t, commands["MLST"].RequireAuth())
+}
+
+func TestSuppressFeatures_UnknownTokens(t *testing.T) {
+	commands := buildCommands(NewKubeAuth(nil), nil)
+
+	unknown := suppressFeatures(commands, []string{"EPSV", " ", "RETR", "XYZZY"})
+	assert.Equal(t, []string{"RETR", "XYZZY"}, unknown)
+	assert.False(t, commands["EPSV"].IsExtend())
+	assert.True(t, commands["EPRT"].IsExtend())
+}
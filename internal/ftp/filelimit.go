@@ -0,0 +1,137 @@
+package ftp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"goftp.io/server/v2"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+// errFileLimitExceeded is returned for uploads and new directories once a user
+// stores MaxFiles entries
+var errFileLimitExceeded = errors.New("file limit exceeded")
+
+// fileCountTTL bounds how long a user's counted entries are trusted before the
+// home directory is walked again
+const fileCountTTL = 30 * time.Second
+
+// fileLimitCommands are the commands that create entries and are refused with
+// 552 while the user's cached entry count is at MaxFiles
+var fileLimitCommands = []string{"STOR", "APPE", "MKD", "XMKD"}
+
+// fileCounts caches the number of files and directories each user stores,
+// keyed by fileCountKey, so uploads don't walk the backend every time
+var fileCounts sync.Map
+
+// fileCount is a cached entry count
+type fileCount struct {
+	count     int64
+	countedAt time.Time
+}
+
+// fileCountKey identifies a user's home directory in fileCounts
+func fileCountKey(user *ftpv1.User) string {
+	return user.Namespace + "/" + user.Spec.Username + ":" + user.Spec.HomeDirectory
+}
+
+// cachedFileCount returns the user's cached entry count if it is still fresh
+func cachedFileCount(user *ftpv1.User) (int64, bool) {
+	value, ok := fileCounts.Load(fileCountKey(user))
+	if !ok {
+		return 0, false
+	}
+	cached := value.(fileCount)
+	if time.Since(cached.countedAt) >= fileCountTTL {
+		return 0, false
+	}
+	return cached.count, true
+}
+
+// fileCount returns the number of files and directories in the user's home,
+// walking the backend only when the cached count is missing or stale
+func (driver *KubeDriver) fileCount() (int64, error) {
+	if count, ok := cachedFileCount(driver.user); ok {
+		return count, nil
+	}
+	_, count, err := driver.usage()
+	if err != nil {
+		return 0, err
+	}
+	fileCounts.Store(fileCountKey(driver.user), fileCount{count: count, countedAt: time.Now()})
+	return count, nil
+}
+
+// checkFileLimit rejects new entries once the user stores MaxFiles of them
+func (driver *KubeDriver) checkFileLimit() error {
+	limit := driver.user.Spec.MaxFiles
+	if limit <= 0 {
+		return nil
+	}
+	count, err := driver.fileCount()
+	if err != nil {
+		return fmt.Errorf("failed to count files: %w", err)
+	}
+	if count >= limit {
+		return fmt.Errorf("%w: %d of %d files stored", errFileLimitExceeded, count, limit)
+	}
+	return nil
+}
+
+// recordFileCreated counts a new entry against the cached count, if any.
+// Overwritten files are counted too until the next recount.
+func (driver *KubeDriver) recordFileCreated() {
+	if driver.user == nil || driver.user.Spec.MaxFiles <= 0 {
+		return
+	}
+	key := fileCountKey(driver.user)
+	if value, ok := fileCounts.Load(key); ok {
+		cached := value.(fileCount)
+		cached.count++
+		fileCounts.Store(key, cached)
+	}
+}
+
+// forgetFileCount drops the cached count after entries are removed so the next
+// check recounts them
+func (driver *KubeDriver) forgetFileCount() {
+	if driver.user != nil {
+		fileCounts.Delete(fileCountKey(driver.user))
+	}
+}
+
+// commandFileLimit wraps a command that creates entries so it is refused with
+// 552 up front when the user's cached count has already reached MaxFiles. The
+// driver still enforces the limit when no count is cached.
+type commandFileLimit struct {
+	auth *KubeAuth
+	next server.Command
+}
+
+func (cmd commandFileLimit) IsExtend() bool {
+	return cmd.next.IsExtend()
+}
+
+func (cmd commandFileLimit) RequireParam() bool {
+	return cmd.next.RequireParam()
+}
+
+func (cmd commandFileLimit) RequireAuth() bool {
+	return cmd.next.RequireAuth()
+}
+
+func (cmd commandFileLimit) Execute(sess *server.Session, param string) {
+	user := cmd.auth.GetUser(context.Background(), sess.LoginUser())
+	if user != nil && user.Spec.MaxFiles > 0 {
+		if count, ok := cachedFileCount(user); ok && count >= user.Spec.MaxFiles {
+			getLogger().Info("Refusing new entry at file limit", "username", user.Spec.Username, "files", count, "max_files", user.Spec.MaxFiles)
+			sess.WriteMessage(552, fmt.Sprintf("%s: %d of %d files stored", errFileLimitExceeded, count, user.Spec.MaxFiles))
+			return
+		}
+	}
+	cmd.next.Execute(sess, param)
+}
@@ -0,0 +1,80 @@
+package ftp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newFileLimitTestDriver returns a quota test driver, whose home holds three
+// entries, limited to maxFiles entries
+func newFileLimitTestDriver(t *testing.T, maxFiles int64) (*KubeDriver, *MockStorage) {
+	driver, mockStorage := newQuotaTestDriver(0, false)
+	driver.user.Spec.MaxFiles = maxFiles
+	t.Cleanup(func() { fileCounts.Delete(fileCountKey(driver.user)) })
+	return driver, mockStorage
+}
+
+func TestKubeDriver_PutFile_FileLimitExceeded(t *testing.T) {
+	driver, mockStorage := newFileLimitTestDriver(t, 3)
+
+	_, err := driver.PutFile(nil, "/new.csv", strings.NewReader("data"), 0)
+	assert.ErrorIs(t, err, errFileLimitExceeded)
+	assert.ErrorContains(t, err, "3 of 3 files stored")
+	mockStorage.AssertNotCalled(t, "PutFile", mock.Anything, mock.Anything, mock.Anything)
+
+	assert.ErrorIs(t, driver.MakeDir(nil, "/newdir"), errFileLimitExceeded)
+	mockStorage.AssertNotCalled(t, "MakeDir", mock.Anything)
+}
+
+func TestKubeDriver_PutFile_FileLimitCountsUploads(t *testing.T) {
+	driver, mockStorage := newFileLimitTestDriver(t, 4)
+	reader := strings.NewReader("data")
+	mockStorage.On("PutFile", "/first.csv", reader, int64(0)).Return(int64(4), nil).Once()
+
+	_, err := driver.PutFile(nil, "/first.csv", reader, 0)
+	require.NoError(t, err)
+
+	count, ok := cachedFileCount(driver.user)
+	require.True(t, ok)
+	assert.Equal(t, int64(4), count)
+
+	// The cached count rejects the next upload without walking the home again
+	_, err = driver.PutFile(nil, "/second.csv", strings.NewReader("data"), 0)
+	assert.ErrorIs(t, err, errFileLimitExceeded)
+	mockStorage.AssertNumberOfCalls(t, "ListDir", 2)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestKubeDriver_DeleteFile_ForgetsFileCount(t *testing.T) {
+	driver, mockStorage := newFileLimitTestDriver(t, 3)
+	mockStorage.On("DeleteFile", "/report.csv").Return(nil)
+
+	require.Error(t, driver.checkFileLimit())
+	_, ok := cachedFileCount(driver.user)
+	require.True(t, ok)
+
+	require.NoError(t, driver.DeleteFile(nil, "/report.csv"))
+	_, ok = cachedFileCount(driver.user)
+	assert.False(t, ok)
+}
+
+func TestKubeDriver_FileLimit_Unlimited(t *testing.T) {
+	driver, mockStorage := newFileLimitTestDriver(t, 0)
+
+	assert.NoError(t, driver.checkFileLimit())
+	mockStorage.AssertNotCalled(t, "ListDir", mock.Anything, mock.Anything)
+}
+
+func TestCommandFileLimit_CoversAliases(t *testing.T) {
+	commands := buildCommands(NewKubeAuth(nil), nil)
+
+	// XMKD is goftp's alias of MKD, so it must not bypass the limit
+	for _, name := range []string{"MKD", "XMKD"} {
+		_, ok := commands[name].(commandFileLimit)
+		assert.True(t, ok, "%s should be wrapped by the file limit", name)
+	}
+}
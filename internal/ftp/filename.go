@@ -0,0 +1,52 @@
+package ftp
+
+import (
+	"fmt"
+	pathpkg "path"
+	"regexp"
+
+	"goftp.io/server/v2"
+)
+
+// filenameCommands name a file the client is creating
+var filenameCommands = []string{"STOR", "APPE", "RNTO"}
+
+// compileFilenameCharset builds a pattern matching file names made only of the
+// characters in charset, a regular expression character class body such as
+// "A-Za-z0-9._-"
+func compileFilenameCharset(charset string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile("^[" + charset + "]+$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid filename charset %q: %w", charset, err)
+	}
+	return re, nil
+}
+
+// commandFilenameCharset wraps a command creating a file so that names with
+// characters outside the allowed charset are refused with 553. Only the last
+// path element is checked, since the directories already exist.
+type commandFilenameCharset struct {
+	charset *regexp.Regexp
+	next    server.Command
+}
+
+func (cmd commandFilenameCharset) IsExtend() bool {
+	return cmd.next.IsExtend()
+}
+
+func (cmd commandFilenameCharset) RequireParam() bool {
+	return cmd.next.RequireParam()
+}
+
+func (cmd commandFilenameCharset) RequireAuth() bool {
+	return cmd.next.RequireAuth()
+}
+
+func (cmd commandFilenameCharset) Execute(sess *server.Session, param string) {
+	if name := pathpkg.Base(param); !cmd.charset.MatchString(name) {
+		getLogger().Info("Refusing file name outside the allowed charset", "username", sess.LoginUser(), "path", param)
+		sess.WriteMessage(553, "File name not allowed: "+name)
+		return
+	}
+	cmd.next.Execute(sess, param)
+}
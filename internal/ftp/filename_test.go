@@ -0,0 +1,66 @@
+package ftp
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+func TestCompileFilenameCharset(t *testing.T) {
+	charset, err := compileFilenameCharset("A-Za-z0-9._-")
+	require.NoError(t, err)
+	assert.True(t, charset.MatchString("scan_2024-01.pdf"))
+	assert.False(t, charset.MatchString("scan 2024.pdf"))
+	assert.False(t, charset.MatchString("scan#1.pdf"))
+	assert.False(t, charset.MatchString("résumé.pdf"))
+
+	_, err = compileFilenameCharset("a-")
+	assert.NoError(t, err)
+	_, err = compileFilenameCharset("z-a")
+	assert.Error(t, err)
+}
+
+func TestCommandFilenameCharset(t *testing.T) {
+	user := &ftpv1.User{Spec: ftpv1.UserSpec{
+		Username:    "guest",
+		Type:        "anonymous",
+		Enabled:     true,
+		Permissions: ftpv1.UserPermissions{Read: true, Write: true, List: true},
+	}}
+	auth := NewKubeAuth(nil)
+	charset, err := compileFilenameCharset("A-Za-z0-9._-")
+	require.NoError(t, err)
+	auth.FilenameCharset = charset
+	auth.userCache.Store("guest", user)
+	mockStorage := &MockStorage{}
+	mockStorage.On("PutFile", "/scan_2024-01.pdf", mock.Anything, int64(0)).Run(func(args mock.Arguments) {
+		_, _ = io.ReadAll(args.Get(1).(io.Reader))
+	}).Return(int64(4), nil)
+	send := anonymousSessionWithDriver(t, &KubeDriver{auth: auth, user: user, storageImpl: mockStorage})
+
+	// Names outside the charset are refused before a transfer starts
+	assert.True(t, strings.HasPrefix(send("STOR scan 2024.pdf"), "553 "))
+	assert.True(t, strings.HasPrefix(send("APPE incoming/scan#1.pdf"), "553 "))
+	assert.True(t, strings.HasPrefix(send("RNTO bad name.pdf"), "553 "))
+
+	// Compliant names upload
+	port := passivePort(t, send("PASV"))
+	data, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(send("STOR scan_2024-01.pdf"), "150 "))
+	_, err = data.Write([]byte("scan"))
+	require.NoError(t, err)
+	require.NoError(t, data.Close())
+	assert.True(t, strings.HasPrefix(send("NOOP"), "226 "))
+
+	mockStorage.AssertExpectations(t)
+	mockStorage.AssertNumberOfCalls(t, "PutFile", 1)
+}
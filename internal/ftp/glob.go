@@ -0,0 +1,34 @@
+package ftp
+
+import (
+	"path"
+
+	"goftp.io/server/v2"
+
+	"github.com/rossigee/kubeftpd/internal/storage"
+)
+
+// resolveListPath resolves the path of a LIST or NLST like validateChrootPath.
+// When its last element is a glob pattern naming no existing entry, e.g.
+// "/reports/*.csv", it returns the directory to list and the pattern to filter
+// it by instead, so files with glob characters in their names stay reachable.
+func (driver *KubeDriver) resolveListPath(ctx *server.Context, p string) (string, string, error) {
+	if driver.normalizeBackslashes {
+		p = normalizeBackslashes(p)
+	}
+	pattern := path.Base(p)
+	if ctx == nil || (ctx.Cmd != "LIST" && ctx.Cmd != "NLST") || !storage.HasGlobPattern(pattern) {
+		resolvedPath, err := driver.validateChrootPath(p)
+		return resolvedPath, "", err
+	}
+
+	resolvedDir, err := driver.validateChrootPath(path.Dir(p))
+	if err != nil {
+		return "", "", err
+	}
+	resolvedPath := path.Join(resolvedDir, pattern)
+	if _, err := driver.storageImpl.Stat(resolvedPath); err == nil {
+		return resolvedPath, "", nil
+	}
+	return resolvedDir, pattern, nil
+}
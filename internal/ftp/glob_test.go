@@ -0,0 +1,110 @@
+package ftp
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"goftp.io/server/v2"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+// newGlobTestDriver returns a driver for a chrooted user whose /reports
+// directory holds a few files and a file named with glob characters
+func newGlobTestDriver() (*KubeDriver, *MockStorage) {
+	mockStorage := &MockStorage{}
+	notFound := (*MockFileInfo)(nil)
+	mockStorage.On("Stat", "/home/alice/reports").Return(&MockFileInfo{name: "reports", isDir: true}, nil)
+	mockStorage.On("Stat", "/home/alice/reports/[draft].txt").Return(&MockFileInfo{name: "[draft].txt", size: 7}, nil)
+	mockStorage.On("Stat", mock.Anything).Return(notFound, os.ErrNotExist)
+	entries := []os.FileInfo{
+		&MockFileInfo{name: "jan.csv", size: 10},
+		&MockFileInfo{name: "feb.csv", size: 20},
+		&MockFileInfo{name: "notes.txt", size: 30},
+		&MockFileInfo{name: "[draft].txt", size: 7},
+	}
+	mockStorage.On("ListDir", "/home/alice/reports", mock.Anything).Run(func(args mock.Arguments) {
+		callback := args.Get(1).(func(os.FileInfo) error)
+		for _, entry := range entries {
+			if err := callback(entry); err != nil {
+				return
+			}
+		}
+	}).Return(nil)
+	mockStorage.On("ListDir", "/home/alice/reports/[draft].txt", mock.Anything).Run(func(args mock.Arguments) {
+		_ = args.Get(1).(func(os.FileInfo) error)(entries[3])
+	}).Return(nil)
+
+	user := &ftpv1.User{Spec: ftpv1.UserSpec{
+		Username:      "alice",
+		Enabled:       true,
+		HomeDirectory: "/home/alice",
+		Chroot:        true,
+		Permissions:   ftpv1.UserPermissions{Read: true, List: true},
+	}}
+	return &KubeDriver{auth: NewKubeAuth(nil), user: user, storageImpl: mockStorage}, mockStorage
+}
+
+// listNames runs Stat then ListDir for a listing command, as goftp does
+func listNames(t *testing.T, driver *KubeDriver, command, path string) []string {
+	t.Helper()
+	ctx := &server.Context{Cmd: command}
+	info, err := driver.Stat(ctx, path)
+	require.NoError(t, err)
+	if !info.IsDir() {
+		return []string{info.Name()}
+	}
+	var names []string
+	require.NoError(t, driver.ListDir(ctx, path, func(info os.FileInfo) error {
+		names = append(names, info.Name())
+		return nil
+	}))
+	return names
+}
+
+func TestKubeDriver_ListGlob(t *testing.T) {
+	for _, command := range []string{"LIST", "NLST"} {
+		t.Run(command, func(t *testing.T) {
+			driver, _ := newGlobTestDriver()
+
+			assert.Equal(t, []string{"jan.csv", "feb.csv"}, listNames(t, driver, command, "/reports/*.csv"))
+			assert.Equal(t, []string{"notes.txt"}, listNames(t, driver, command, "/reports/n?tes.*"))
+			assert.Empty(t, listNames(t, driver, command, "/reports/*.pdf"))
+			assert.Len(t, listNames(t, driver, command, "/reports"), 4, "paths without a pattern are listed unfiltered")
+		})
+	}
+}
+
+func TestKubeDriver_ListGlob_ExistingName(t *testing.T) {
+	driver, _ := newGlobTestDriver()
+
+	// A file named like a pattern is listed itself rather than matched
+	var names []string
+	ctx := &server.Context{Cmd: "NLST"}
+	require.NoError(t, driver.ListDir(ctx, "/reports/[draft].txt", func(info os.FileInfo) error {
+		names = append(names, info.Name())
+		return nil
+	}))
+	assert.Equal(t, []string{"[draft].txt"}, names)
+}
+
+func TestKubeDriver_ListGlob_OtherCommands(t *testing.T) {
+	driver, mockStorage := newGlobTestDriver()
+
+	// Only listings expand patterns; RETR and friends stat the literal name
+	_, err := driver.Stat(&server.Context{Cmd: "SIZE"}, "/reports/*.csv")
+	assert.Error(t, err)
+	mockStorage.AssertCalled(t, "Stat", "/home/alice/reports/*.csv")
+	mockStorage.AssertNotCalled(t, "Stat", "/home/alice/reports")
+}
+
+func TestKubeDriver_ListGlob_OutsideHome(t *testing.T) {
+	driver, mockStorage := newGlobTestDriver()
+
+	err := driver.ListDir(&server.Context{Cmd: "LIST"}, "../../etc/*.conf", func(os.FileInfo) error { return nil })
+	assert.Error(t, err)
+	mockStorage.AssertNotCalled(t, "ListDir", mock.Anything, mock.Anything)
+}
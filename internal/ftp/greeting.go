@@ -0,0 +1,47 @@
+package ftp
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+// greetingDelayListener holds back the welcome banner of each accepted
+// connection by delay. The wait happens on the connection's first write, in
+// the session's own goroutine, so accepting other connections isn't slowed.
+type greetingDelayListener struct {
+	net.Listener
+	delay time.Duration
+	// sleep waits for the delay; replaced in tests
+	sleep func(time.Duration)
+}
+
+func (l *greetingDelayListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil || l.delay <= 0 {
+		return conn, err
+	}
+	sleep := l.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	return &greetingDelayConn{Conn: conn, delay: l.delay, sleep: sleep}, nil
+}
+
+// greetingDelayConn sleeps before its first write, which carries the banner
+type greetingDelayConn struct {
+	net.Conn
+	delay   time.Duration
+	sleep   func(time.Duration)
+	delayed sync.Once
+}
+
+func (c *greetingDelayConn) Write(b []byte) (int, error) {
+	c.delayed.Do(func() {
+		metrics.RecordGreetingDelayed()
+		c.sleep(c.delay)
+	})
+	return c.Conn.Write(b)
+}
@@ -0,0 +1,62 @@
+package ftp
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+// acceptGreetingConn accepts one connection through a greeting delay listener
+// using a sleeper that records requested delays instead of waiting
+func acceptGreetingConn(t *testing.T, delay time.Duration) (net.Conn, *[]time.Duration) {
+	t.Helper()
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	var slept []time.Duration
+	listener := &greetingDelayListener{
+		Listener: inner,
+		delay:    delay,
+		sleep:    func(d time.Duration) { slept = append(slept, d) },
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	client, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn, &slept
+}
+
+func TestGreetingDelayListener_DelaysBanner(t *testing.T) {
+	before := testutil.ToFloat64(metrics.GreetingDelayedConnectionsTotal)
+	conn, slept := acceptGreetingConn(t, 2*time.Second)
+
+	_, err := conn.Write([]byte("220 Welcome\r\n"))
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("331 Password required\r\n"))
+	require.NoError(t, err)
+
+	// Only the banner is delayed
+	assert.Equal(t, []time.Duration{2 * time.Second}, *slept)
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.GreetingDelayedConnectionsTotal))
+}
+
+func TestGreetingDelayListener_ZeroDelay(t *testing.T) {
+	before := testutil.ToFloat64(metrics.GreetingDelayedConnectionsTotal)
+	conn, slept := acceptGreetingConn(t, 0)
+
+	_, err := conn.Write([]byte("220 Welcome\r\n"))
+	require.NoError(t, err)
+
+	assert.Empty(t, *slept)
+	assert.Equal(t, before, testutil.ToFloat64(metrics.GreetingDelayedConnectionsTotal))
+}
@@ -0,0 +1,50 @@
+package ftp
+
+import (
+	"net"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+// homeForClient returns the home directory of the first HomeByCIDR rule whose
+// CIDR contains the client address. Rules with invalid CIDRs are skipped; the
+// user reconciler reports them.
+func homeForClient(user *ftpv1.User, clientAddr string) (string, bool) {
+	if len(user.Spec.HomeByCIDR) == 0 {
+		return "", false
+	}
+
+	host := clientAddr
+	if h, _, err := net.SplitHostPort(clientAddr); err == nil {
+		host = h
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return "", false
+	}
+
+	for _, rule := range user.Spec.HomeByCIDR {
+		_, network, err := net.ParseCIDR(rule.CIDR)
+		if err != nil {
+			continue
+		}
+		if network.Contains(ip) {
+			return rule.HomeDirectory, true
+		}
+	}
+	return "", false
+}
+
+// applyHomeByCIDR returns a copy of the user with the home directory selected
+// by the client's address, or the user unchanged when no rule matches
+func (driver *KubeDriver) applyHomeByCIDR(user *ftpv1.User, clientAddr string) *ftpv1.User {
+	home, ok := homeForClient(user, clientAddr)
+	if !ok {
+		return user
+	}
+	getLogger().Info("Applying home directory for client subnet", "username", user.Spec.Username,
+		"client_ip", clientAddr, "home_directory", home)
+	user = user.DeepCopy()
+	user.Spec.HomeDirectory = home
+	return user
+}
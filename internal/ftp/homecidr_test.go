@@ -0,0 +1,85 @@
+package ftp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+func TestKubeDriver_HomeByCIDR(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, ftpv1.AddToScheme(scheme))
+
+	backend := &ftpv1.FilesystemBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "scans", Namespace: "default"},
+		Spec:       ftpv1.FilesystemBackendSpec{BasePath: t.TempDir()},
+	}
+	testUser := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "scanner", Namespace: "default"},
+		Spec: ftpv1.UserSpec{
+			Username:      "scanner",
+			Enabled:       true,
+			Chroot:        true,
+			Backend:       ftpv1.BackendReference{Kind: "FilesystemBackend", Name: "scans"},
+			HomeDirectory: "/scans/other",
+			HomeByCIDR: []ftpv1.HomeCIDRRule{
+				{CIDR: "10.1.0.0/16", HomeDirectory: "/scans/site-a"},
+				{CIDR: "10.2.0.0/16", HomeDirectory: "/scans/site-b"},
+				{CIDR: "2001:db8::/32", HomeDirectory: "/scans/site-v6"},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(testUser, backend).
+		Build()
+
+	auth := NewKubeAuth(fakeClient)
+	auth.userCache.Store("scanner", testUser)
+
+	tests := []struct {
+		name     string
+		clientIP string
+		wantHome string
+	}{
+		{name: "site A subnet", clientIP: "10.1.4.20:50123", wantHome: "/scans/site-a"},
+		{name: "site B subnet", clientIP: "10.2.9.7:50123", wantHome: "/scans/site-b"},
+		{name: "IPv6 subnet", clientIP: "[2001:db8::15]:50123", wantHome: "/scans/site-v6"},
+		{name: "unmatched subnet", clientIP: "192.168.1.5:50123", wantHome: "/scans/other"},
+		{name: "unknown address", clientIP: "unknown", wantHome: "/scans/other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := &KubeDriver{
+				client:            fakeClient,
+				auth:              auth,
+				authenticatedUser: "scanner",
+				clientIP:          tt.clientIP,
+				sessionID:         "ftp-session-" + tt.name,
+				sessionCtx:        context.Background(),
+			}
+			require.NoError(t, driver.ensureUserInitialized())
+			assert.Equal(t, tt.wantHome, driver.user.Spec.HomeDirectory)
+
+			// The selected home is the chroot
+			resolved, err := driver.validateChrootPath("/report.pdf")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantHome+"/report.pdf", resolved)
+
+			require.NoError(t, driver.Close())
+		})
+	}
+
+	// The cached user keeps its default home directory
+	cached := auth.GetUser(context.Background(), "scanner")
+	assert.Equal(t, "/scans/other", cached.Spec.HomeDirectory)
+}
@@ -0,0 +1,188 @@
+package ftp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http/httputil"
+	"net/textproto"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+const (
+	// icapDefaultPort is the ICAP port used when the server URL has none
+	icapDefaultPort = "1344"
+	// icapTimeout bounds each scan, from connecting to reading the verdict
+	icapTimeout = 2 * time.Minute
+)
+
+// errUploadInfected is returned for uploads the ICAP server found a threat in
+var errUploadInfected = errors.New("upload rejected: threat detected")
+
+// icapScanner sends uploads to an ICAP server (RFC 3507), such as c-icap with
+// ClamAV, as RESPMOD requests and reads back its verdict
+type icapScanner struct {
+	url     string // icap://host:port/service
+	host    string // Host header of each request
+	address string // host:port to dial
+	timeout time.Duration
+}
+
+// newICAPScanner parses an icap://host[:port]/service URL
+func newICAPScanner(rawURL string) (*icapScanner, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "icap" || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid ICAP server %q: expected icap://host[:port]/service", rawURL)
+	}
+	port := u.Port()
+	if port == "" {
+		port = icapDefaultPort
+	}
+	return &icapScanner{
+		url:     u.String(),
+		host:    u.Host,
+		address: net.JoinHostPort(u.Hostname(), port),
+		timeout: icapTimeout,
+	}, nil
+}
+
+// scan sends size bytes of body to the ICAP server. It returns the name of the
+// threat found, or "" when the upload is clean.
+func (s *icapScanner) scan(body io.Reader, size int64) (string, error) {
+	conn, err := net.DialTimeout("tcp", s.address, s.timeout)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(s.timeout))
+
+	// The upload is encapsulated as the body of an HTTP response, which the
+	// server answers with 204 when it would leave it unmodified
+	httpHeader := fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Type: application/octet-stream\r\nContent-Length: %d\r\n\r\n", size)
+	w := bufio.NewWriter(conn)
+	_, _ = fmt.Fprintf(w, "RESPMOD %s ICAP/1.0\r\nHost: %s\r\nAllow: 204\r\nEncapsulated: res-hdr=0, res-body=%d\r\n\r\n%s",
+		s.url, s.host, len(httpHeader), httpHeader)
+	chunked := httputil.NewChunkedWriter(w)
+	if _, err := io.Copy(chunked, body); err != nil {
+		return "", err
+	}
+	if err := chunked.Close(); err != nil {
+		return "", err
+	}
+	_, _ = w.WriteString("\r\n")
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+
+	reader := textproto.NewReader(bufio.NewReader(conn))
+	status, err := reader.ReadLine()
+	if err != nil {
+		return "", fmt.Errorf("failed to read ICAP response: %w", err)
+	}
+	header, err := reader.ReadMIMEHeader()
+	if err != nil {
+		return "", fmt.Errorf("failed to read ICAP response: %w", err)
+	}
+	fields := strings.Fields(status)
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "ICAP/") {
+		return "", fmt.Errorf("unexpected ICAP response %q", status)
+	}
+
+	switch fields[1] {
+	case "204":
+		return "", nil
+	case "200":
+		if threat := icapThreat(header); threat != "" {
+			return threat, nil
+		}
+		// Servers without threat headers replace an infected upload with an
+		// error page
+		if strings.Contains(header.Get("Encapsulated"), "res-hdr") {
+			line, err := reader.ReadLine()
+			if err != nil {
+				return "", fmt.Errorf("failed to read ICAP response: %w", err)
+			}
+			if httpFields := strings.Fields(line); len(httpFields) >= 2 {
+				if code, err := strconv.Atoi(httpFields[1]); err == nil && code >= 400 {
+					return "unknown threat", nil
+				}
+			}
+		}
+		return "", nil
+	default:
+		return "", fmt.Errorf("ICAP server replied %q", status)
+	}
+}
+
+// icapThreat returns the threat named by an ICAP response's headers, or ""
+func icapThreat(header textproto.MIMEHeader) string {
+	// X-Infection-Found: Type=0; Resolution=2; Threat=Eicar-Test-Signature;
+	if found := header.Get("X-Infection-Found"); found != "" {
+		for _, field := range strings.Split(found, ";") {
+			if name, ok := strings.CutPrefix(strings.TrimSpace(field), "Threat="); ok {
+				return name
+			}
+		}
+		return found
+	}
+	if id := header.Get("X-Virus-ID"); id != "" {
+		return id
+	}
+	if header.Get("X-Violations-Found") != "" {
+		return "policy violation"
+	}
+	return ""
+}
+
+// scanUpload has an upload scanned by the ICAP server before it reaches
+// storage. The upload is spooled to a temporary file, since it must be read
+// twice; the returned reader replays it and cleanup removes it. Infected
+// uploads and failed scans are rejected, so nothing unscanned is stored.
+func (driver *KubeDriver) scanUpload(reader io.Reader) (io.Reader, func(), error) {
+	if driver.auth == nil || driver.auth.ICAPScanner == nil {
+		return reader, func() {}, nil
+	}
+	scanner := driver.auth.ICAPScanner
+
+	spool, err := os.CreateTemp("", "kubeftpd-scan-*")
+	if err != nil {
+		metrics.RecordVirusScan("error")
+		return nil, nil, fmt.Errorf("virus scan failed: %w", err)
+	}
+	cleanup := func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}
+
+	var threat string
+	size, err := io.Copy(spool, reader)
+	if err == nil {
+		_, err = spool.Seek(0, io.SeekStart)
+	}
+	if err == nil {
+		threat, err = scanner.scan(spool, size)
+	}
+	if err == nil {
+		_, err = spool.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		cleanup()
+		metrics.RecordVirusScan("error")
+		return nil, nil, fmt.Errorf("virus scan failed: %w", err)
+	}
+	if threat != "" {
+		cleanup()
+		metrics.RecordVirusScan("infected")
+		return nil, nil, fmt.Errorf("%w (%s)", errUploadInfected, threat)
+	}
+	metrics.RecordVirusScan("clean")
+	return spool, cleanup, nil
+}
@@ -0,0 +1,172 @@
+package ftp
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http/httputil"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+// eicar is the standard antivirus test string
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// mockICAPServer answers RESPMOD requests, reporting a threat in bodies
+// containing the EICAR string. It returns the server's URL and a channel of
+// the bodies it received.
+func mockICAPServer(t *testing.T, status string) (string, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	bodies := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveICAP(conn, status, bodies)
+		}
+	}()
+	return "icap://" + listener.Addr().String() + "/avscan", bodies
+}
+
+func serveICAP(conn net.Conn, status string, bodies chan<- string) {
+	defer func() { _ = conn.Close() }()
+	buffered := bufio.NewReader(conn)
+	reader := textproto.NewReader(buffered)
+	if line, err := reader.ReadLine(); err != nil || !strings.HasPrefix(line, "RESPMOD icap://") {
+		return
+	}
+	if _, err := reader.ReadMIMEHeader(); err != nil {
+		return
+	}
+	// Encapsulated HTTP response status line and header, then the chunked body
+	if line, err := reader.ReadLine(); err != nil || !strings.HasPrefix(line, "HTTP/1.1 ") {
+		return
+	}
+	if _, err := reader.ReadMIMEHeader(); err != nil {
+		return
+	}
+	body, err := io.ReadAll(httputil.NewChunkedReader(buffered))
+	if err != nil {
+		return
+	}
+	bodies <- string(body)
+
+	switch {
+	case status != "":
+		_, _ = io.WriteString(conn, status+"\r\n\r\n")
+	case strings.Contains(string(body), "EICAR-STANDARD-ANTIVIRUS-TEST-FILE"):
+		_, _ = io.WriteString(conn, "ICAP/1.0 200 OK\r\n"+
+			"X-Infection-Found: Type=0; Resolution=2; Threat=Eicar-Test-Signature;\r\n"+
+			"Encapsulated: res-hdr=0, null-body=19\r\n\r\n"+
+			"HTTP/1.1 403 Forbidden\r\n\r\n")
+	default:
+		_, _ = io.WriteString(conn, "ICAP/1.0 204 No Content\r\nEncapsulated: null-body=0\r\n\r\n")
+	}
+}
+
+func newICAPTestDriver(t *testing.T, icapURL string) (*KubeDriver, *MockStorage) {
+	scanner, err := newICAPScanner(icapURL)
+	require.NoError(t, err)
+	auth := NewKubeAuth(nil)
+	auth.ICAPScanner = scanner
+
+	mockStorage := &MockStorage{}
+	driver := &KubeDriver{
+		authenticatedUser: "testuser",
+		user: &ftpv1.User{Spec: ftpv1.UserSpec{
+			Username:    "testuser",
+			Enabled:     true,
+			Permissions: ftpv1.UserPermissions{Write: true},
+		}},
+		storageImpl: mockStorage,
+		auth:        auth,
+	}
+	return driver, mockStorage
+}
+
+func TestNewICAPScanner(t *testing.T) {
+	scanner, err := newICAPScanner("icap://clamav-icap/avscan")
+	require.NoError(t, err)
+	assert.Equal(t, "clamav-icap:1344", scanner.address)
+	assert.Equal(t, "icap://clamav-icap/avscan", scanner.url)
+
+	scanner, err = newICAPScanner("icap://10.0.0.5:11344/srv_clamav")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.5:11344", scanner.address)
+
+	for _, invalid := range []string{"", "http://clamav/avscan", "icap:///avscan", "clamav:1344"} {
+		_, err := newICAPScanner(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestKubeDriver_PutFile_ICAPClean(t *testing.T) {
+	icapURL, bodies := mockICAPServer(t, "")
+	driver, mockStorage := newICAPTestDriver(t, icapURL)
+
+	var stored string
+	mockStorage.On("PutFile", "/report.csv", mock.Anything, int64(0)).Run(func(args mock.Arguments) {
+		data, _ := io.ReadAll(args.Get(1).(io.Reader))
+		stored = string(data)
+	}).Return(int64(14), nil)
+
+	size, err := driver.PutFile(nil, "/report.csv", strings.NewReader("id,total\n1,42\n"), 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(14), size)
+	assert.Equal(t, "id,total\n1,42\n", <-bodies, "the upload should be sent to the scanner")
+	assert.Equal(t, "id,total\n1,42\n", stored, "the scanned upload should be committed unchanged")
+	mockStorage.AssertExpectations(t)
+}
+
+func TestKubeDriver_PutFile_ICAPInfected(t *testing.T) {
+	icapURL, bodies := mockICAPServer(t, "")
+	driver, mockStorage := newICAPTestDriver(t, icapURL)
+
+	_, err := driver.PutFile(nil, "/invoice.exe", strings.NewReader(eicar), 0)
+	require.ErrorIs(t, err, errUploadInfected)
+	assert.Contains(t, err.Error(), "Eicar-Test-Signature")
+	assert.Equal(t, eicar, <-bodies)
+	mockStorage.AssertNotCalled(t, "PutFile", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestKubeDriver_PutFile_ICAPFailureRejects(t *testing.T) {
+	icapURL, _ := mockICAPServer(t, "ICAP/1.0 500 Server Error")
+	driver, mockStorage := newICAPTestDriver(t, icapURL)
+
+	_, err := driver.PutFile(nil, "/report.csv", strings.NewReader("id,total\n"), 0)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, errUploadInfected)
+	assert.Contains(t, err.Error(), "virus scan failed")
+	mockStorage.AssertNotCalled(t, "PutFile", mock.Anything, mock.Anything, mock.Anything)
+
+	// An unreachable scanner fails closed too
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	driver, mockStorage = newICAPTestDriver(t, "icap://"+address+"/avscan")
+	_, err = driver.PutFile(nil, "/report.csv", strings.NewReader("id,total\n"), 0)
+	assert.Error(t, err)
+	mockStorage.AssertNotCalled(t, "PutFile", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestICAPThreat(t *testing.T) {
+	assert.Equal(t, "Eicar-Test-Signature", icapThreat(textproto.MIMEHeader{
+		"X-Infection-Found": {"Type=0; Resolution=2; Threat=Eicar-Test-Signature;"},
+	}))
+	assert.Equal(t, "Win.Test.EICAR_HDB-1", icapThreat(textproto.MIMEHeader{"X-Virus-Id": {"Win.Test.EICAR_HDB-1"}}))
+	assert.Equal(t, "policy violation", icapThreat(textproto.MIMEHeader{"X-Violations-Found": {"1"}}))
+	assert.Empty(t, icapThreat(textproto.MIMEHeader{}))
+}
@@ -0,0 +1,54 @@
+package ftp
+
+import (
+	"goftp.io/server/v2"
+)
+
+const (
+	// defaultGreeting is the banner sent when no greeting is configured
+	defaultGreeting = "Welcome to KubeFTPd"
+	// genericGreeting replaces defaultGreeting when the server identity is hidden
+	genericGreeting = "FTP server ready"
+	// serverName is reported in the STAT reply unless the identity is hidden
+	serverName = "KubeFTPd"
+)
+
+// greeting returns the 220 banner text. A configured WelcomeMessage is sent
+// as-is; otherwise the default names the server unless HideServerIdentity is set.
+func (s *Server) greeting() string {
+	switch {
+	case s.WelcomeMessage != "":
+		return s.WelcomeMessage
+	case s.HideServerIdentity:
+		return genericGreeting
+	default:
+		return defaultGreeting
+	}
+}
+
+// commandStat replies to STAT without arguments with a status that leaves out
+// the server name and goftp version, so scanners cannot fingerprint the server.
+// STAT of a path is passed to next.
+type commandStat struct {
+	next server.Command
+}
+
+func (cmd commandStat) IsExtend() bool {
+	return cmd.next.IsExtend()
+}
+
+func (cmd commandStat) RequireParam() bool {
+	return cmd.next.RequireParam()
+}
+
+func (cmd commandStat) RequireAuth() bool {
+	return cmd.next.RequireAuth()
+}
+
+func (cmd commandStat) Execute(sess *server.Session, param string) {
+	if param != "" {
+		cmd.next.Execute(sess, param)
+		return
+	}
+	sess.WriteMessage(211, "Server status OK")
+}
@@ -0,0 +1,102 @@
+package ftp
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goftp.io/server/v2"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+func TestServerGreeting(t *testing.T) {
+	assert.Equal(t, defaultGreeting, (&Server{}).greeting())
+	assert.Equal(t, genericGreeting, (&Server{HideServerIdentity: true}).greeting())
+	assert.Equal(t, "Authorised use only", (&Server{WelcomeMessage: "Authorised use only"}).greeting())
+	assert.Equal(t, "Authorised use only",
+		(&Server{WelcomeMessage: "Authorised use only", HideServerIdentity: true}).greeting(),
+		"a configured greeting is used even when the identity is hidden")
+}
+
+// identitySession starts a server configured like Server.Start and returns its
+// banner and a function sending a command and reading all lines of the reply
+func identitySession(t *testing.T, s *Server) (string, func(command string) string) {
+	auth := NewKubeAuth(nil)
+	auth.HideServerIdentity = s.HideServerIdentity
+	auth.userCache.LoadOrStore("guest", &ftpv1.User{Spec: ftpv1.UserSpec{Username: "guest", Type: "anonymous", Enabled: true}})
+	driver := &KubeDriver{auth: auth}
+	ftpServer, err := server.NewServer(&server.Options{
+		Driver:         driver,
+		Auth:           auth,
+		Perm:           driver,
+		Logger:         &KubeLogger{auth: auth},
+		Name:           serverName,
+		WelcomeMessage: s.greeting(),
+		Commands:       buildCommands(auth, nil),
+	})
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = ftpServer.Serve(listener) }()
+	t.Cleanup(func() { _ = ftpServer.Shutdown() })
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	reader := bufio.NewReader(conn)
+	readReply := func() string {
+		var reply strings.Builder
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			reply.WriteString(line)
+			// The last line of a reply has a space after the code
+			if len(line) > 3 && line[3] == ' ' {
+				return reply.String()
+			}
+		}
+	}
+	send := func(command string) string {
+		_, err := conn.Write([]byte(command + "\r\n"))
+		require.NoError(t, err)
+		return readReply()
+	}
+
+	banner := readReply()
+	require.True(t, strings.HasPrefix(send("USER guest"), "331"))
+	require.True(t, strings.HasPrefix(send("PASS guest@example.com"), "230"))
+	return banner, send
+}
+
+func TestGreeting_Configured(t *testing.T) {
+	banner, _ := identitySession(t, &Server{WelcomeMessage: "Authorised use only"})
+	assert.Equal(t, "220 Authorised use only\r\n", banner)
+}
+
+func TestGreeting_Default(t *testing.T) {
+	banner, send := identitySession(t, &Server{})
+	assert.Equal(t, "220 "+defaultGreeting+"\r\n", banner)
+	assert.Contains(t, send("STAT"), serverName)
+}
+
+func TestHideServerIdentity(t *testing.T) {
+	banner, send := identitySession(t, &Server{HideServerIdentity: true})
+	assert.Equal(t, "220 "+genericGreeting+"\r\n", banner)
+
+	reply := send("STAT")
+	assert.True(t, strings.HasPrefix(reply, "211"), "unexpected STAT reply %q", reply)
+	for _, leak := range []string{serverName, "Go FTP", "Version", "2.0"} {
+		assert.NotContains(t, reply, leak)
+	}
+	for _, leak := range []string{serverName, "Go FTP", "2.0"} {
+		assert.NotContains(t, banner, leak)
+	}
+}
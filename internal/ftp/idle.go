@@ -0,0 +1,84 @@
+package ftp
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+// idleTimeoutListener wraps accepted control connections so that a session
+// waiting longer than its idle timeout for the client's next command is closed.
+// The timeout is the logged-in user's IdleTimeout if set, otherwise timeout;
+// zero for both leaves the session open.
+type idleTimeoutListener struct {
+	net.Listener
+	timeout time.Duration
+	auth    *KubeAuth
+}
+
+func (l *idleTimeoutListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &idleTimeoutConn{Conn: conn, timeout: l.timeoutFor, onIdle: l.reap}, nil
+}
+
+// sessionUsername returns the user logged in on conn, if any
+func (l *idleTimeoutListener) sessionUsername(conn net.Conn) string {
+	if l.auth == nil {
+		return ""
+	}
+	return l.auth.GetSessionUser(sessionIDForAddr(conn.RemoteAddr()))
+}
+
+// timeoutFor returns the idle timeout of conn's session, preferring the
+// logged-in user's IdleTimeout over the global one
+func (l *idleTimeoutListener) timeoutFor(conn net.Conn) time.Duration {
+	if username := l.sessionUsername(conn); username != "" {
+		if user := l.auth.cachedUser(username); user != nil && user.Spec.IdleTimeout.Duration > 0 {
+			return user.Spec.IdleTimeout.Duration
+		}
+	}
+	return l.timeout
+}
+
+// reap closes an idle control connection and records it
+func (l *idleTimeoutListener) reap(conn net.Conn, timeout time.Duration) {
+	getLogger().Info("Closing idle FTP session", "username", l.sessionUsername(conn),
+		"client_ip", conn.RemoteAddr().String(), "idle_timeout", timeout.String())
+	metrics.RecordIdleSessionClosed()
+	_ = conn.Close()
+}
+
+// idleTimeoutConn arms a read deadline before every read, so only time spent
+// waiting on the client counts as idle; long transfers on the data connection
+// don't trip it because the control connection isn't being read meanwhile.
+// The timeout is looked up on every read so that it follows the session's
+// user once they log in.
+type idleTimeoutConn struct {
+	net.Conn
+	timeout func(net.Conn) time.Duration
+	onIdle  func(net.Conn, time.Duration)
+	reaped  sync.Once
+}
+
+func (c *idleTimeoutConn) Read(b []byte) (int, error) {
+	timeout := c.timeout(c.Conn)
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	if err := c.Conn.SetReadDeadline(deadline); err != nil {
+		return 0, err
+	}
+	n, err := c.Conn.Read(b)
+	var netErr net.Error
+	if err != nil && errors.As(err, &netErr) && netErr.Timeout() {
+		c.reaped.Do(func() { c.onIdle(c.Conn, timeout) })
+	}
+	return n, err
+}
@@ -0,0 +1,108 @@
+package ftp
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+// acceptIdleConn starts an idle-reaping listener and returns the server side
+// of a fresh connection along with the client side.
+func acceptIdleConn(t *testing.T, timeout time.Duration) (net.Conn, net.Conn) {
+	t.Helper()
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	listener := &idleTimeoutListener{Listener: inner, timeout: timeout, auth: NewKubeAuth(nil)}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	client, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	return conn, client
+}
+
+func TestIdleTimeoutListener_ReapsIdleSession(t *testing.T) {
+	before := testutil.ToFloat64(metrics.IdleSessionsClosedTotal)
+	conn, client := acceptIdleConn(t, 50*time.Millisecond)
+
+	_, err := conn.Read(make([]byte, 16))
+	require.Error(t, err)
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.IdleSessionsClosedTotal))
+
+	// The client sees the connection closed
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(time.Second)))
+	_, err = client.Read(make([]byte, 16))
+	assert.Error(t, err)
+
+	// Further reads on a reaped connection are not counted again
+	_, _ = conn.Read(make([]byte, 16))
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.IdleSessionsClosedTotal))
+}
+
+func TestIdleTimeoutListener_ActiveSessionNotReaped(t *testing.T) {
+	before := testutil.ToFloat64(metrics.IdleSessionsClosedTotal)
+	conn, client := acceptIdleConn(t, 200*time.Millisecond)
+
+	// Each command arriving within the timeout re-arms the deadline
+	buf := make([]byte, 16)
+	for i := 0; i < 3; i++ {
+		time.Sleep(100 * time.Millisecond)
+		_, err := client.Write([]byte("NOOP\r\n"))
+		require.NoError(t, err)
+		n, err := conn.Read(buf)
+		require.NoError(t, err)
+		assert.Equal(t, "NOOP\r\n", string(buf[:n]))
+	}
+	assert.Equal(t, before, testutil.ToFloat64(metrics.IdleSessionsClosedTotal))
+}
+
+func TestIdleTimeoutListener_UserIdleTimeout(t *testing.T) {
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	auth := NewKubeAuth(nil)
+	auth.userCache.Store("scanner", &ftpv1.User{Spec: ftpv1.UserSpec{
+		Username:    "scanner",
+		IdleTimeout: metav1.Duration{Duration: 50 * time.Millisecond},
+	}})
+	auth.userCache.Store("alice", &ftpv1.User{Spec: ftpv1.UserSpec{Username: "alice"}})
+	listener := &idleTimeoutListener{Listener: inner, timeout: time.Hour, auth: auth}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	accept := func(username string) net.Conn {
+		client, err := net.Dial("tcp", listener.Addr().String())
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = client.Close() })
+		conn, err := listener.Accept()
+		require.NoError(t, err)
+		if username != "" {
+			auth.setSessionUser(sessionIDForAddr(conn.RemoteAddr()), username)
+		}
+		return conn
+	}
+
+	// The user's own timeout is honoured over the global one
+	start := time.Now()
+	_, err = accept("scanner").Read(make([]byte, 16))
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), time.Minute)
+
+	// Users without one, and sessions not yet logged in, get the global timeout
+	assert.Equal(t, time.Hour, listener.timeoutFor(accept("alice")))
+	assert.Equal(t, time.Hour, listener.timeoutFor(accept("")))
+
+	// A user's timeout applies even when the global idle reaper is disabled
+	listener.timeout = 0
+	assert.Equal(t, 50*time.Millisecond, listener.timeoutFor(accept("scanner")))
+	assert.Equal(t, time.Duration(0), listener.timeoutFor(accept("alice")))
+}
@@ -0,0 +1,88 @@
+package ftp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+// Keys read from a User's CIDRConfigMapRef. Values hold CIDRs separated by
+// commas, spaces or newlines.
+const (
+	allowedCIDRsKey = "allowedCIDRs"
+	deniedCIDRsKey  = "deniedCIDRs"
+)
+
+// isClientIPAllowed checks a client address against the user's allowed and denied
+// CIDR lists, including any shared lists from the referenced ConfigMap. The
+// ConfigMap is read through the client on every login, so with the manager's
+// watch-backed cache edits take effect without touching the User.
+func (auth *KubeAuth) isClientIPAllowed(ctx context.Context, user *ftpv1.User, clientAddr string) (bool, error) {
+	allowed := user.Spec.AllowedCIDRs
+	denied := user.Spec.DeniedCIDRs
+
+	if ref := user.Spec.CIDRConfigMapRef; ref != nil {
+		namespace := user.Namespace
+		if ref.Namespace != nil {
+			namespace = *ref.Namespace
+		}
+		configMap := &corev1.ConfigMap{}
+		if err := auth.client.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, configMap); err != nil {
+			return false, fmt.Errorf("failed to get CIDR ConfigMap %s/%s: %w", namespace, ref.Name, err)
+		}
+		allowed = append(append([]string{}, allowed...), splitCIDRList(configMap.Data[allowedCIDRsKey])...)
+		denied = append(append([]string{}, denied...), splitCIDRList(configMap.Data[deniedCIDRsKey])...)
+	}
+
+	if len(allowed) == 0 && len(denied) == 0 {
+		return true, nil
+	}
+
+	host := clientAddr
+	if h, _, err := net.SplitHostPort(clientAddr); err == nil {
+		host = h
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false, fmt.Errorf("cannot parse client address %q", clientAddr)
+	}
+
+	deniedMatch, err := cidrsContain(denied, ip)
+	if err != nil {
+		return false, err
+	}
+	if deniedMatch {
+		return false, nil
+	}
+	if len(allowed) == 0 {
+		return true, nil
+	}
+	return cidrsContain(allowed, ip)
+}
+
+// cidrsContain reports whether any of the CIDRs contains ip
+func cidrsContain(cidrs []string, ip net.IP) (bool, error) {
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return false, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+		}
+		if network.Contains(ip) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// splitCIDRList splits a ConfigMap value into individual CIDRs
+func splitCIDRList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\t' || r == '\r'
+	})
+}
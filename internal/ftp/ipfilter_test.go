@@ -0,0 +1,108 @@
+package ftp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+func TestKubeAuth_isClientIPAllowed_Inline(t *testing.T) {
+	tests := []struct {
+		name       string
+		allowed    []string
+		denied     []string
+		clientAddr string
+		want       bool
+		wantErr    bool
+	}{
+		{name: "no restrictions", clientAddr: "203.0.113.5:40000", want: true},
+		{name: "allowed match", allowed: []string{"10.0.0.0/8"}, clientAddr: "10.1.2.3:40000", want: true},
+		{name: "allowed miss", allowed: []string{"10.0.0.0/8"}, clientAddr: "192.168.1.1:40000", want: false},
+		{name: "denied match", denied: []string{"192.168.0.0/16"}, clientAddr: "192.168.1.1:40000", want: false},
+		{name: "denied wins over allowed", allowed: []string{"0.0.0.0/0"}, denied: []string{"192.168.1.0/24"}, clientAddr: "192.168.1.1:40000", want: false},
+		{name: "ipv6 allowed", allowed: []string{"2001:db8::/32"}, clientAddr: "[2001:db8::1]:40000", want: true},
+		{name: "invalid CIDR", allowed: []string{"not-a-cidr"}, clientAddr: "10.1.2.3:40000", wantErr: true},
+		{name: "unknown address", allowed: []string{"10.0.0.0/8"}, clientAddr: "unknown", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := NewKubeAuth(nil)
+			user := &ftpv1.User{
+				Spec: ftpv1.UserSpec{Username: "testuser", AllowedCIDRs: tt.allowed, DeniedCIDRs: tt.denied},
+			}
+			got, err := auth.isClientIPAllowed(context.Background(), user, tt.clientAddr)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.False(t, got)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestKubeAuth_isClientIPAllowed_ConfigMap(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, ftpv1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	sharedNamespace := "network"
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "office-networks", Namespace: sharedNamespace},
+		Data:       map[string]string{allowedCIDRsKey: "10.0.0.0/8\n172.16.0.0/12"},
+	}
+	user := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "testuser", Namespace: "default"},
+		Spec: ftpv1.UserSpec{
+			Username:         "testuser",
+			CIDRConfigMapRef: &ftpv1.ConfigMapReference{Name: "office-networks", Namespace: &sharedNamespace},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap, user).Build()
+	auth := NewKubeAuth(fakeClient)
+	ctx := context.Background()
+
+	allowed, err := auth.isClientIPAllowed(ctx, user, "10.1.2.3:40000")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, err = auth.isClientIPAllowed(ctx, user, "192.168.1.1:40000")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// Move the office to a new range and deny part of the old one, without editing the User
+	updated := &corev1.ConfigMap{}
+	require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(configMap), updated))
+	updated.Data = map[string]string{
+		allowedCIDRsKey: "10.0.0.0/8, 192.168.1.0/24",
+		deniedCIDRsKey:  "10.1.0.0/16",
+	}
+	require.NoError(t, fakeClient.Update(ctx, updated))
+
+	allowed, err = auth.isClientIPAllowed(ctx, user, "192.168.1.1:40000")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, err = auth.isClientIPAllowed(ctx, user, "10.1.2.3:40000")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	allowed, err = auth.isClientIPAllowed(ctx, user, "172.16.0.1:40000")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// A missing ConfigMap fails closed
+	require.NoError(t, fakeClient.Delete(ctx, updated))
+	allowed, err = auth.isClientIPAllowed(ctx, user, "192.168.1.1:40000")
+	assert.Error(t, err)
+	assert.False(t, allowed)
+}
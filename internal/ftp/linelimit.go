@@ -0,0 +1,124 @@
+package ftp
+
+import (
+	"bytes"
+	"errors"
+	"net"
+
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+// errCommandLineTooLong ends a session that sent an overlong command line
+// when DropLongCommandLines is enabled
+var errCommandLineTooLong = errors.New("command line too long")
+
+// tlsRecordHandshake starts every TLS handshake record. FTP commands never
+// begin with it, so seeing it at the start of a line means AUTH TLS succeeded.
+const tlsRecordHandshake = 0x16
+
+// lineLimitListener wraps accepted control connections so that command lines
+// longer than maxLength never reach goftp, which buffers lines without bound.
+type lineLimitListener struct {
+	net.Listener
+	maxLength int
+	drop      bool
+}
+
+func (l *lineLimitListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &lineLimitConn{Conn: conn, maxLength: l.maxLength, drop: l.drop}, nil
+}
+
+// lineLimitConn holds back each command line until it is complete. A line
+// growing past maxLength is answered with 500 and discarded, or closes the
+// connection when drop is set. Once the session upgrades to TLS the bytes are
+// encrypted and passed through unchecked.
+type lineLimitConn struct {
+	net.Conn
+	maxLength   int
+	drop        bool
+	pending     []byte // start of the current line
+	ready       []byte // complete lines not yet read by goftp
+	discarding  bool   // skipping the rest of a rejected line
+	passthrough bool
+	err         error // read error to report once ready is drained
+	buf         [4096]byte
+}
+
+func (c *lineLimitConn) Read(b []byte) (int, error) {
+	for {
+		if len(c.ready) > 0 {
+			n := copy(b, c.ready)
+			c.ready = c.ready[n:]
+			return n, nil
+		}
+		if c.err != nil {
+			return 0, c.err
+		}
+		if c.passthrough {
+			return c.Conn.Read(b)
+		}
+
+		n, err := c.Conn.Read(c.buf[:])
+		if rejectErr := c.scan(c.buf[:n]); rejectErr != nil {
+			return 0, rejectErr
+		}
+		c.err = err
+	}
+}
+
+// scan moves complete lines of data within the limit to ready
+func (c *lineLimitConn) scan(data []byte) error {
+	for len(data) > 0 {
+		if len(c.pending) == 0 && !c.discarding && data[0] == tlsRecordHandshake {
+			c.passthrough = true
+			c.ready = append(c.ready, data...)
+			return nil
+		}
+
+		end := bytes.IndexByte(data, '\n') + 1
+		complete := end > 0
+		if !complete {
+			end = len(data)
+		}
+		line := data[:end]
+		data = data[end:]
+
+		if c.discarding {
+			c.discarding = !complete
+			continue
+		}
+		c.pending = append(c.pending, line...)
+		if len(bytes.TrimRight(c.pending, "\r\n")) > c.maxLength {
+			c.pending = nil
+			c.discarding = !complete
+			if err := c.reject(); err != nil {
+				return err
+			}
+			continue
+		}
+		if complete {
+			c.ready = append(c.ready, c.pending...)
+			c.pending = nil
+		}
+	}
+	return nil
+}
+
+// reject answers an overlong line, closing the connection when drop is set
+func (c *lineLimitConn) reject() error {
+	metrics.RecordCommandLineTooLong()
+	getLogger().Info("Rejected overlong FTP command line", "client_ip", c.RemoteAddr().String(),
+		"max_length", c.maxLength, "dropped", c.drop)
+	if _, err := c.Write([]byte("500 Command line too long\r\n")); err != nil {
+		return err
+	}
+	if c.drop {
+		_ = c.Close()
+		return errCommandLineTooLong
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package ftp
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goftp.io/server/v2"
+
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+// lineLimitSession connects to a server limiting command lines to maxLength
+// bytes and returns the connection after the banner
+func lineLimitSession(t *testing.T, maxLength int, drop bool) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	auth := NewKubeAuth(nil)
+	driver := &KubeDriver{auth: auth}
+	ftpServer, err := server.NewServer(&server.Options{
+		Driver:   driver,
+		Auth:     auth,
+		Perm:     driver,
+		Logger:   &KubeLogger{auth: auth},
+		Commands: buildCommands(auth, nil),
+	})
+	require.NoError(t, err)
+
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	var listener net.Listener = &sessionConnListener{Listener: inner, auth: auth}
+	listener = &lineLimitListener{Listener: listener, maxLength: maxLength, drop: drop}
+	go func() { _ = ftpServer.Serve(listener) }()
+	t.Cleanup(func() { _ = ftpServer.Shutdown() })
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	reader := bufio.NewReader(conn)
+	banner, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(banner, "220"))
+	return conn, reader
+}
+
+func TestLineLimit_RejectsOverlongLine(t *testing.T) {
+	before := testutil.ToFloat64(metrics.CommandLinesTooLongTotal)
+	conn, reader := lineLimitSession(t, 32, false)
+
+	reply := func(command string) string {
+		_, err := conn.Write([]byte(command + "\r\n"))
+		require.NoError(t, err)
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		return line
+	}
+
+	assert.True(t, strings.HasPrefix(reply("USER "+strings.Repeat("a", 10000)), "500"),
+		"overlong line should be rejected")
+	assert.True(t, strings.HasPrefix(reply("NOOP"), "200"), "session should continue after the rejected line")
+	assert.True(t, strings.HasPrefix(reply("USER "+strings.Repeat("b", 27)), "331"),
+		"a line of exactly the limit should be accepted")
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.CommandLinesTooLongTotal))
+}
+
+func TestLineLimit_RejectsLineSentInPieces(t *testing.T) {
+	conn, reader := lineLimitSession(t, 32, false)
+
+	// The line never ends, so it must be rejected as it grows
+	for i := 0; i < 4; i++ {
+		_, err := conn.Write([]byte(strings.Repeat("x", 16)))
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+	}
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(line, "500"), "unexpected reply %q", line)
+
+	// The rest of the rejected line is skipped up to its newline
+	_, err = conn.Write([]byte(strings.Repeat("y", 100) + "\r\nNOOP\r\n"))
+	require.NoError(t, err)
+	line, err = reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(line, "200"), "unexpected reply %q", line)
+}
+
+func TestLineLimit_DropsConnection(t *testing.T) {
+	conn, reader := lineLimitSession(t, 32, true)
+
+	_, err := conn.Write([]byte("USER " + strings.Repeat("a", 100) + "\r\nNOOP\r\n"))
+	require.NoError(t, err)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(line, "500"), "unexpected reply %q", line)
+
+	_, err = reader.ReadString('\n')
+	assert.Error(t, err, "connection should be closed after the overlong line")
+}
@@ -0,0 +1,192 @@
+package ftp
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"goftp.io/server/v2"
+)
+
+// linkCounter is implemented by file infos that know their hard link count
+type linkCounter interface {
+	Nlink() uint64
+}
+
+// storedFileInfo returns the backend's file info underneath the wrappers a
+// listing adds for link targets, Latin-1 names and time zones
+func storedFileInfo(info os.FileInfo) os.FileInfo {
+	for {
+		wrapper, ok := info.(interface{ Unwrap() os.FileInfo })
+		if !ok {
+			return info
+		}
+		info = wrapper.Unwrap()
+	}
+}
+
+// formatListEntry formats a LIST line as ls -l does, taking the owner, group
+// and link count from the listed entry where the backend reports them.
+// Other entries are shown as owned by loginUser.
+func formatListEntry(info os.FileInfo, loginUser string) string {
+	mode := info.Mode()
+	if info.IsDir() {
+		mode |= os.ModeDir
+	}
+	owner, group := loginUser, "ftp"
+	var nlink uint64 = 1
+	stored := storedFileInfo(info)
+	if owned, ok := stored.(server.FileInfo); ok {
+		if owned.Owner() != "" {
+			owner = owned.Owner()
+		}
+		if owned.Group() != "" {
+			group = owned.Group()
+		}
+	}
+	if counted, ok := stored.(linkCounter); ok && counted.Nlink() > 0 {
+		nlink = counted.Nlink()
+	}
+
+	modified := info.ModTime().Format(" Jan _2 15:04 ")
+	if info.ModTime().Before(time.Now().AddDate(-1, 0, 0)) {
+		modified = info.ModTime().Format(" Jan _2  2006 ")
+	}
+	return fmt.Sprintf("%s %d %s %s %12s%s%s\r\n",
+		mode, nlink, owner, group, strconv.FormatInt(info.Size(), 10), modified, info.Name())
+}
+
+// listParamPath returns the path of a LIST or NLST parameter, skipping the
+// ls-style options such as -la that clients send before it
+func listParamPath(param string) string {
+	start := 0
+	for _, field := range strings.Fields(param) {
+		if !strings.HasPrefix(field, "-") {
+			break
+		}
+		start = strings.LastIndex(param, " "+field) + len(field) + 1
+	}
+	return strings.TrimLeft(param[start:], " ")
+}
+
+// commandList replaces goftp's LIST, which looks up every entry's mode, owner
+// and group again through the Perm methods and always reports one link
+type commandList struct {
+	auth *KubeAuth
+	next server.Command
+}
+
+func (cmd commandList) IsExtend() bool {
+	return cmd.next.IsExtend()
+}
+
+func (cmd commandList) RequireParam() bool {
+	return cmd.next.RequireParam()
+}
+
+func (cmd commandList) RequireAuth() bool {
+	return cmd.next.RequireAuth()
+}
+
+func (cmd commandList) Execute(sess *server.Session, param string) {
+	driver, ok := sess.Options().Driver.(*KubeDriver)
+	if !ok {
+		cmd.next.Execute(sess, param)
+		return
+	}
+	p := sess.BuildPath(listParamPath(param))
+	ctx := &server.Context{Sess: sess, Cmd: "LIST", Param: param}
+
+	info, err := driver.Stat(ctx, p)
+	if err != nil {
+		sess.WriteMessage(550, err.Error())
+		return
+	}
+	var listing bytes.Buffer
+	if info.IsDir() {
+		err = driver.ListDir(ctx, p, func(entry os.FileInfo) error {
+			listing.WriteString(formatListEntry(entry, sess.LoginUser()))
+			return nil
+		})
+	} else {
+		listing.WriteString(formatListEntry(info, sess.LoginUser()))
+	}
+	if err != nil {
+		sess.WriteMessage(550, err.Error())
+		return
+	}
+	cmd.auth.sendListing(sess, listing.Bytes())
+}
+
+// commandNlst replaces goftp's NLST, which looks up the directory's mode,
+// owner and group once for every entry even though only names are sent
+type commandNlst struct {
+	auth *KubeAuth
+	next server.Command
+}
+
+func (cmd commandNlst) IsExtend() bool {
+	return cmd.next.IsExtend()
+}
+
+func (cmd commandNlst) RequireParam() bool {
+	return cmd.next.RequireParam()
+}
+
+func (cmd commandNlst) RequireAuth() bool {
+	return cmd.next.RequireAuth()
+}
+
+func (cmd commandNlst) Execute(sess *server.Session, param string) {
+	driver, ok := sess.Options().Driver.(*KubeDriver)
+	if !ok {
+		cmd.next.Execute(sess, param)
+		return
+	}
+	p := sess.BuildPath(listParamPath(param))
+	ctx := &server.Context{Sess: sess, Cmd: "NLST", Param: param}
+
+	info, err := driver.Stat(ctx, p)
+	if err != nil {
+		sess.WriteMessage(550, err.Error())
+		return
+	}
+	if !info.IsDir() {
+		sess.WriteMessage(550, param+" is not a directory")
+		return
+	}
+	var listing bytes.Buffer
+	err = driver.ListDir(ctx, p, func(entry os.FileInfo) error {
+		listing.WriteString(entry.Name() + "\r\n")
+		return nil
+	})
+	if err != nil {
+		sess.WriteMessage(550, err.Error())
+		return
+	}
+	cmd.auth.sendListing(sess, listing.Bytes())
+}
+
+// sendListing writes a directory listing to the session's data connection
+// and closes it, replying as goftp does for its own listings
+func (auth *KubeAuth) sendListing(sess *server.Session, listing []byte) {
+	socket := sess.DataConn()
+	if socket == nil {
+		sess.WriteMessage(425, "Use PASV or PORT first")
+		return
+	}
+	sess.WriteMessage(150, "Opening ASCII mode data connection for file list")
+	_, err := socket.Write(listing)
+	_ = socket.Close()
+	// goftp only forgets a data connection it closed itself, so stop counting
+	// this one against the session's limit here
+	auth.releaseDataChannel(sessionIDForAddr(sess.RemoteAddr()), socket)
+	if err != nil {
+		sess.WriteMessage(426, "Connection closed; transfer aborted")
+		return
+	}
+	sess.WriteMessage(226, fmt.Sprintf("Closing data connection, sent %d bytes", len(listing)))
+}
@@ -0,0 +1,91 @@
+package ftp
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+func TestListParamPath(t *testing.T) {
+	tests := map[string]string{
+		"":                "",
+		"docs":            "docs",
+		"-la":             "",
+		"-la docs":        "docs",
+		"-l -a my docs":   "my docs",
+		"/reports/-draft": "/reports/-draft",
+	}
+	for param, want := range tests {
+		assert.Equal(t, want, listParamPath(param), param)
+	}
+}
+
+// listCommand runs a listing command in a chrooted guest session and returns
+// its output along with the session's storage
+func listCommand(t *testing.T, command string, entries ...os.FileInfo) (string, *MockStorage) {
+	user := &ftpv1.User{Spec: ftpv1.UserSpec{
+		Username:      "guest",
+		Type:          "anonymous",
+		Enabled:       true,
+		HomeDirectory: "/home/guest",
+		Chroot:        true,
+		Permissions:   ftpv1.UserPermissions{Read: true, List: true},
+	}}
+	auth := NewKubeAuth(nil)
+	auth.userCache.Store("guest", user)
+	mockStorage := &MockStorage{}
+	mockStorage.On("Stat", "/home/guest/docs").Return(&MockFileInfo{name: "docs", isDir: true}, nil)
+	mockStorage.On("ListDir", "/home/guest/docs", mock.Anything).Run(func(args mock.Arguments) {
+		for _, entry := range entries {
+			_ = args.Get(1).(func(os.FileInfo) error)(entry)
+		}
+	}).Return(nil)
+	send := anonymousSessionWithDriver(t, &KubeDriver{auth: auth, user: user, storageImpl: mockStorage})
+
+	port := passivePort(t, send("PASV"))
+	data, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	require.NoError(t, err)
+	defer func() { _ = data.Close() }()
+	require.True(t, strings.HasPrefix(send(command), "150 "))
+	require.NoError(t, data.SetReadDeadline(time.Now().Add(5*time.Second)))
+	output, err := io.ReadAll(data)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(send(""), "226 "))
+	return string(output), mockStorage
+}
+
+func TestCommandList_EntryDetails(t *testing.T) {
+	output, mockStorage := listCommand(t, "LIST -la docs",
+		&MockFileInfo{name: "report.csv", size: 42, mode: 0640, owner: "alice", group: "finance", nlink: 3},
+		&MockFileInfo{name: "notes.txt", size: 7, mode: 0644},
+	)
+
+	lines := strings.Split(strings.TrimSuffix(output, "\r\n"), "\r\n")
+	require.Len(t, lines, 2)
+	assert.Regexp(t, `^-rw-r----- 3 alice finance +42 \w{3} [ \d]\d +\S+ report\.csv$`, lines[0])
+	// Entries without stored ownership belong to the session's user with one link
+	assert.Regexp(t, `^-rw-r--r-- 1 guest ftp +7 \w{3} [ \d]\d +\S+ notes\.txt$`, lines[1])
+
+	// The listing's own entries are used rather than a Stat per entry
+	mockStorage.AssertNumberOfCalls(t, "Stat", 1)
+}
+
+func TestCommandNlst_Names(t *testing.T) {
+	output, mockStorage := listCommand(t, "NLST docs",
+		&MockFileInfo{name: "report.csv"},
+		&MockFileInfo{name: "notes.txt"},
+	)
+
+	assert.Equal(t, "report.csv\r\nnotes.txt\r\n", output)
+	mockStorage.AssertNumberOfCalls(t, "Stat", 1)
+}
@@ -0,0 +1,66 @@
+package ftp
+
+import (
+	"sync/atomic"
+
+	"goftp.io/server/v2"
+
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+// MaintenanceMode holds the message returned to clients while new logins are
+// refused. Sessions that are already logged in are unaffected.
+type MaintenanceMode struct {
+	message atomic.Pointer[string]
+}
+
+// Set enables maintenance mode with the given message, or disables it when empty
+func (m *MaintenanceMode) Set(message string) {
+	m.message.Store(&message)
+}
+
+// Message returns the current maintenance message, or "" when not in maintenance
+func (m *MaintenanceMode) Message() string {
+	if m == nil {
+		return ""
+	}
+	if message := m.message.Load(); message != nil {
+		return *message
+	}
+	return ""
+}
+
+// commandPass wraps the default PASS command so clients see the maintenance
+// message instead of a generic login failure while maintenance is active, and
+// locked-out addresses get a transient 421 telling them to back off.
+type commandPass struct {
+	auth *KubeAuth
+	next server.Command
+}
+
+func (cmd commandPass) IsExtend() bool {
+	return cmd.next.IsExtend()
+}
+
+func (cmd commandPass) RequireParam() bool {
+	return cmd.next.RequireParam()
+}
+
+func (cmd commandPass) RequireAuth() bool {
+	return cmd.next.RequireAuth()
+}
+
+func (cmd commandPass) Execute(sess *server.Session, param string) {
+	if message := cmd.auth.Maintenance.Message(); message != "" {
+		sess.WriteMessage(421, message)
+		return
+	}
+	// Tell locked-out clients to back off instead of reporting a bad password
+	if cmd.auth.bruteForce.IsIPLockedOut(sess.RemoteAddr().String()) {
+		recordAuthFailure("locked_out")
+		metrics.RecordUserLogin("locked_out")
+		cmd.auth.writeTransient(sess, replyLockedOut)
+		return
+	}
+	cmd.next.Execute(sess, param)
+}
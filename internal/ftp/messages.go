@@ -0,0 +1,102 @@
+package ftp
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Error categories whose reply text can be customised with ErrorMessages
+const (
+	MessagePermissionDenied = "permission-denied"
+	MessageNotFound         = "not-found"
+	MessageQuotaExceeded    = "quota-exceeded"
+)
+
+// DefaultErrorMessages are the reply templates used for categories without an
+// override. "{path}" is replaced with the path the client sent and "{error}"
+// with the underlying error.
+var DefaultErrorMessages = map[string]string{
+	MessagePermissionDenied: "Permission denied: {path}",
+	MessageNotFound:         "No such file or directory: {path}",
+	MessageQuotaExceeded:    "{error}",
+}
+
+// ParseErrorMessages parses semicolon-separated category=template overrides,
+// e.g. "permission-denied=Zugriff verweigert: {path}". An empty value returns nil.
+func ParseErrorMessages(value string) (map[string]string, error) {
+	var messages map[string]string
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		category, template, ok := strings.Cut(entry, "=")
+		category = strings.TrimSpace(category)
+		template = strings.TrimSpace(template)
+		if !ok || template == "" {
+			return nil, fmt.Errorf("invalid error message %q: expected category=template", entry)
+		}
+		if _, known := DefaultErrorMessages[category]; !known {
+			return nil, fmt.Errorf("unknown error message category %q (expected one of %s)", category, errorMessageCategories())
+		}
+		if messages == nil {
+			messages = make(map[string]string)
+		}
+		messages[category] = template
+	}
+	return messages, nil
+}
+
+// errorMessageCategories lists the known categories for error messages
+func errorMessageCategories() string {
+	categories := make([]string, 0, len(DefaultErrorMessages))
+	for category := range DefaultErrorMessages {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return strings.Join(categories, ", ")
+}
+
+// templatedError carries the text sent to the client while keeping the original
+// error available to errors.Is and errors.As
+type templatedError struct {
+	message string
+	err     error
+}
+
+func (e *templatedError) Error() string { return e.message }
+func (e *templatedError) Unwrap() error { return e.err }
+
+// errorCategory classifies a storage or driver error, returning "" for errors
+// that keep their own text
+func errorCategory(err error) string {
+	switch {
+	case errors.Is(err, errQuotaExceeded):
+		return MessageQuotaExceeded
+	case errors.Is(err, os.ErrPermission), strings.Contains(strings.ToLower(err.Error()), "permission denied"):
+		return MessagePermissionDenied
+	case errors.Is(err, os.ErrNotExist), isFileNotFoundError(err):
+		return MessageNotFound
+	}
+	return ""
+}
+
+// replyError replaces the text of permission, not-found and quota errors with
+// the configured template before the error is sent to the client
+func (driver *KubeDriver) replyError(err error, path string) error {
+	if err == nil {
+		return nil
+	}
+	category := errorCategory(err)
+	if category == "" {
+		return err
+	}
+	template, ok := driver.errorMessages[category]
+	if !ok {
+		template = DefaultErrorMessages[category]
+	}
+	message := strings.NewReplacer("{path}", path, "{error}", err.Error()).Replace(template)
+	return &templatedError{message: message, err: err}
+}
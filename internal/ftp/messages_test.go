@@ -0,0 +1,75 @@
+package ftp
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+func TestParseErrorMessages(t *testing.T) {
+	messages, err := ParseErrorMessages("permission-denied=Zugriff verweigert: {path}; not-found = Datei nicht gefunden")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		MessagePermissionDenied: "Zugriff verweigert: {path}",
+		MessageNotFound:         "Datei nicht gefunden",
+	}, messages)
+
+	messages, err = ParseErrorMessages("")
+	require.NoError(t, err)
+	assert.Nil(t, messages)
+
+	_, err = ParseErrorMessages("forbidden=No")
+	assert.ErrorContains(t, err, "unknown error message category")
+
+	_, err = ParseErrorMessages("permission-denied")
+	assert.ErrorContains(t, err, "expected category=template")
+}
+
+func TestKubeDriver_ErrorMessages(t *testing.T) {
+	newDriver := func(errorMessages map[string]string) (*KubeDriver, *MockStorage) {
+		mockStorage := &MockStorage{}
+		user := &ftpv1.User{Spec: ftpv1.UserSpec{Username: "testuser", HomeDirectory: "/", Enabled: true,
+			Permissions: ftpv1.UserPermissions{Read: true, Write: true, Delete: true, List: true}}}
+		return &KubeDriver{authenticatedUser: "testuser", user: user, storageImpl: mockStorage, errorMessages: errorMessages}, mockStorage
+	}
+	permissionDenied := errors.New("write permission denied")
+
+	t.Run("default permission denied template", func(t *testing.T) {
+		driver, mockStorage := newDriver(nil)
+		mockStorage.On("MakeDir", "/reports").Return(permissionDenied)
+
+		err := driver.MakeDir(nil, "/reports")
+		assert.EqualError(t, err, "Permission denied: /reports")
+		assert.ErrorIs(t, err, permissionDenied)
+	})
+
+	t.Run("overridden permission denied template", func(t *testing.T) {
+		driver, mockStorage := newDriver(map[string]string{MessagePermissionDenied: "Zugriff verweigert: {path}"})
+		mockStorage.On("MakeDir", "/reports").Return(permissionDenied)
+		mockStorage.On("DeleteFile", "/old.txt").Return(errors.New("delete permission denied"))
+
+		assert.EqualError(t, driver.MakeDir(nil, "/reports"), "Zugriff verweigert: /reports")
+		assert.EqualError(t, driver.DeleteFile(nil, "/old.txt"), "Zugriff verweigert: /old.txt")
+	})
+
+	t.Run("other categories keep their defaults", func(t *testing.T) {
+		driver, mockStorage := newDriver(map[string]string{MessagePermissionDenied: "Zugriff verweigert: {path}"})
+		mockStorage.On("DeleteFile", "/missing.txt").Return(os.ErrNotExist)
+
+		err := driver.DeleteFile(nil, "/missing.txt")
+		assert.EqualError(t, err, "No such file or directory: /missing.txt")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("uncategorised errors are unchanged", func(t *testing.T) {
+		driver, mockStorage := newDriver(map[string]string{MessagePermissionDenied: "Zugriff verweigert: {path}"})
+		mockStorage.On("MakeDir", "/reports").Return(errors.New("backend unavailable"))
+
+		assert.EqualError(t, driver.MakeDir(nil, "/reports"), "backend unavailable")
+	})
+}
@@ -0,0 +1,74 @@
+package ftp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/storage"
+)
+
+func TestKubeDriver_MakeDirExisting(t *testing.T) {
+	tests := []struct {
+		name            string
+		idempotentMkdir bool
+		wantErr         error
+	}{
+		{name: "idempotent mkdir accepts existing directory", idempotentMkdir: true},
+		{name: "default rejects existing directory", idempotentMkdir: false, wantErr: storage.ErrDirExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			basePath := t.TempDir()
+			require.NoError(t, os.Mkdir(filepath.Join(basePath, "reports"), 0o755))
+
+			scheme := runtime.NewScheme()
+			require.NoError(t, ftpv1.AddToScheme(scheme))
+			backend := &ftpv1.FilesystemBackend{
+				ObjectMeta: metav1.ObjectMeta{Name: "fs-backend", Namespace: "default"},
+				Spec:       ftpv1.FilesystemBackendSpec{BasePath: basePath},
+			}
+			kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(backend).Build()
+
+			user := &ftpv1.User{
+				ObjectMeta: metav1.ObjectMeta{Name: "testuser", Namespace: "default"},
+				Spec: ftpv1.UserSpec{
+					Username:      "testuser",
+					Enabled:       true,
+					HomeDirectory: "/",
+					Backend:       ftpv1.BackendReference{Kind: "FilesystemBackend", Name: "fs-backend"},
+					Permissions:   ftpv1.UserPermissions{Read: true, Write: true, List: true},
+				},
+			}
+			s, err := storage.NewStorage(context.Background(), user, kubeClient)
+			require.NoError(t, err)
+
+			driver := &KubeDriver{
+				authenticatedUser: "testuser",
+				user:              user,
+				storageImpl:       s,
+				idempotentMkdir:   tt.idempotentMkdir,
+			}
+
+			err = driver.MakeDir(nil, "/reports")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			// New directories are created either way
+			require.NoError(t, driver.MakeDir(nil, "/archive"))
+			assert.DirExists(t, filepath.Join(basePath, "archive"))
+		})
+	}
+}
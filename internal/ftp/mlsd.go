@@ -0,0 +1,111 @@
+package ftp
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"goftp.io/server/v2"
+)
+
+// mlsxPerm returns the RFC 3659 Perm fact of an entry: the operations the
+// session's user may perform on it given their permissions and whether the
+// server or backend is read-only
+func (driver *KubeDriver) mlsxPerm(isDir bool) string {
+	perms := driver.user.Spec.Permissions
+	caps := driver.storageImpl.Capabilities()
+	writable := !driver.readOnly.Enabled() && !caps.ReadOnly
+	canWrite := perms.Write && writable
+	canDelete := perms.Delete && writable
+
+	var perm strings.Builder
+	if isDir {
+		perm.WriteString("e")
+		if perms.List {
+			perm.WriteString("l")
+		}
+		if canWrite {
+			perm.WriteString("cfm")
+		}
+		if canDelete {
+			perm.WriteString("dp")
+		}
+		return perm.String()
+	}
+	if canWrite && caps.Append {
+		perm.WriteString("a")
+	}
+	if canDelete {
+		perm.WriteString("d")
+	}
+	if canWrite {
+		perm.WriteString("f")
+	}
+	if perms.Read {
+		perm.WriteString("r")
+	}
+	if canWrite {
+		perm.WriteString("w")
+	}
+	return perm.String()
+}
+
+// formatMLSDEntry formats an MLSD line with the facts goftp reports plus Perm
+func (driver *KubeDriver) formatMLSDEntry(info os.FileInfo) string {
+	fileType := "file"
+	if info.IsDir() {
+		fileType = "dir"
+	}
+	return fmt.Sprintf("Type=%s;Modify=%s;Size=%d;Perm=%s; %s\r\n",
+		fileType, info.ModTime().UTC().Format("20060102150405"), info.Size(), driver.mlsxPerm(info.IsDir()), info.Name())
+}
+
+// commandMLSD replaces goftp's MLSD, which has no Perm fact, so clients only
+// offer the operations the user is allowed to perform
+type commandMLSD struct {
+	auth *KubeAuth
+	next server.Command
+}
+
+func (cmd commandMLSD) IsExtend() bool {
+	return cmd.next.IsExtend()
+}
+
+func (cmd commandMLSD) RequireParam() bool {
+	return cmd.next.RequireParam()
+}
+
+func (cmd commandMLSD) RequireAuth() bool {
+	return cmd.next.RequireAuth()
+}
+
+func (cmd commandMLSD) Execute(sess *server.Session, param string) {
+	driver, ok := sess.Options().Driver.(*KubeDriver)
+	if !ok {
+		cmd.next.Execute(sess, param)
+		return
+	}
+	p := sess.BuildPath(param)
+	ctx := &server.Context{Sess: sess, Cmd: "MLSD", Param: param}
+
+	info, err := driver.Stat(ctx, p)
+	if err != nil {
+		sess.WriteMessage(550, err.Error())
+		return
+	}
+	var listing bytes.Buffer
+	if info.IsDir() {
+		err = driver.ListDir(ctx, p, func(entry os.FileInfo) error {
+			listing.WriteString(driver.formatMLSDEntry(entry))
+			return nil
+		})
+	} else {
+		listing.WriteString(driver.formatMLSDEntry(info))
+	}
+	if err != nil {
+		sess.WriteMessage(550, err.Error())
+		return
+	}
+	cmd.auth.sendListing(sess, listing.Bytes())
+}
@@ -0,0 +1,116 @@
+package ftp
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/storage"
+)
+
+func TestKubeDriver_MLSxPerm(t *testing.T) {
+	tests := []struct {
+		name     string
+		perms    ftpv1.UserPermissions
+		caps     storage.Capabilities
+		readOnly bool
+		wantFile string
+		wantDir  string
+	}{
+		{
+			name:     "read-only user",
+			perms:    ftpv1.UserPermissions{Read: true, List: true},
+			wantFile: "r",
+			wantDir:  "el",
+		},
+		{
+			name:     "full permissions",
+			perms:    ftpv1.UserPermissions{Read: true, Write: true, Delete: true, List: true},
+			wantFile: "dfrw",
+			wantDir:  "elcfmdp",
+		},
+		{
+			name:     "full permissions with appendable storage",
+			perms:    ftpv1.UserPermissions{Read: true, Write: true, Delete: true, List: true},
+			caps:     storage.Capabilities{Append: true},
+			wantFile: "adfrw",
+			wantDir:  "elcfmdp",
+		},
+		{
+			name:     "read-only backend",
+			perms:    ftpv1.UserPermissions{Read: true, Write: true, Delete: true, List: true},
+			caps:     storage.Capabilities{ReadOnly: true},
+			wantFile: "r",
+			wantDir:  "el",
+		},
+		{
+			name:     "server in read-only mode",
+			perms:    ftpv1.UserPermissions{Read: true, Write: true, Delete: true, List: true},
+			readOnly: true,
+			wantFile: "r",
+			wantDir:  "el",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readOnly := &ReadOnlyMode{}
+			readOnly.Set(tt.readOnly)
+			driver := &KubeDriver{
+				user:        &ftpv1.User{Spec: ftpv1.UserSpec{Username: "testuser", Permissions: tt.perms}},
+				storageImpl: &MockStorage{capabilities: tt.caps},
+				readOnly:    readOnly,
+			}
+			assert.Equal(t, tt.wantFile, driver.mlsxPerm(false))
+			assert.Equal(t, tt.wantDir, driver.mlsxPerm(true))
+		})
+	}
+}
+
+func TestCommandMLSD_PermFacts(t *testing.T) {
+	listMLSD := func(t *testing.T, perms ftpv1.UserPermissions) string {
+		user := &ftpv1.User{Spec: ftpv1.UserSpec{Username: "guest", Type: "anonymous", Enabled: true, Permissions: perms}}
+		auth := NewKubeAuth(nil)
+		auth.userCache.Store("guest", user)
+		mockStorage := &MockStorage{}
+		mockStorage.On("Stat", "/").Return(&MockFileInfo{name: "/", isDir: true}, nil)
+		mockStorage.On("ListDir", "/", mock.Anything).Run(func(args mock.Arguments) {
+			callback := args.Get(1).(func(os.FileInfo) error)
+			_ = callback(&MockFileInfo{name: "report.csv", size: 42})
+			_ = callback(&MockFileInfo{name: "archive", isDir: true})
+		}).Return(nil)
+		send := anonymousSessionWithDriver(t, &KubeDriver{auth: auth, user: user, storageImpl: mockStorage})
+
+		port := passivePort(t, send("PASV"))
+		data, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		require.NoError(t, err)
+		defer func() { _ = data.Close() }()
+		require.True(t, strings.HasPrefix(send("MLSD"), "150 "))
+		require.NoError(t, data.SetReadDeadline(time.Now().Add(5*time.Second)))
+		listing, err := io.ReadAll(data)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(send("NOOP"), "226 "))
+		return string(listing)
+	}
+
+	t.Run("read-only user", func(t *testing.T) {
+		listing := listMLSD(t, ftpv1.UserPermissions{Read: true, List: true})
+		assert.Contains(t, listing, "Type=file;Modify=20090213233130;Size=42;Perm=r; report.csv\r\n")
+		assert.Contains(t, listing, "Type=dir;Modify=20090213233130;Size=0;Perm=el; archive\r\n")
+	})
+
+	t.Run("full permissions", func(t *testing.T) {
+		listing := listMLSD(t, ftpv1.UserPermissions{Read: true, Write: true, Delete: true, List: true})
+		assert.Contains(t, listing, "Perm=dfrw; report.csv\r\n")
+		assert.Contains(t, listing, "Perm=elcfmdp; archive\r\n")
+	})
+}
@@ -0,0 +1,47 @@
+package ftp
+
+import (
+	"net"
+	"sync/atomic"
+
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+// replyListenerPaused is sent to connections refused while the listener is paused
+const replyListenerPaused = "421 Service not available, try again later\r\n"
+
+// PauseMode stops the FTP listener taking new connections without touching
+// sessions that are already connected
+type PauseMode struct {
+	paused atomic.Bool
+}
+
+// Set pauses or resumes accepting new connections
+func (m *PauseMode) Set(paused bool) {
+	m.paused.Store(paused)
+}
+
+// Paused reports whether new connections are being refused
+func (m *PauseMode) Paused() bool {
+	return m != nil && m.paused.Load()
+}
+
+// pausableListener answers connections accepted while paused with 421 and
+// closes them before goftp starts a session
+type pausableListener struct {
+	net.Listener
+	pause *PauseMode
+}
+
+func (l *pausableListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil || !l.pause.Paused() {
+			return conn, err
+		}
+		metrics.RecordPausedConnectionRefused()
+		getLogger().Info("Refused connection while listener is paused", "client_ip", conn.RemoteAddr().String())
+		_, _ = conn.Write([]byte(replyListenerPaused))
+		_ = conn.Close()
+	}
+}
@@ -0,0 +1,72 @@
+package ftp
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goftp.io/server/v2"
+
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+func TestPausableListener(t *testing.T) {
+	auth := NewKubeAuth(nil)
+	driver := &KubeDriver{auth: auth}
+	ftpServer, err := server.NewServer(&server.Options{
+		Driver:   driver,
+		Auth:     auth,
+		Perm:     driver,
+		Logger:   &KubeLogger{auth: auth},
+		Commands: buildCommands(auth, nil),
+	})
+	require.NoError(t, err)
+
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	pause := &PauseMode{}
+	var listener net.Listener = &pausableListener{Listener: inner, pause: pause}
+	listener = &sessionConnListener{Listener: listener, auth: auth}
+	go func() { _ = ftpServer.Serve(listener) }()
+	t.Cleanup(func() { _ = ftpServer.Shutdown() })
+
+	// connect returns the first reply of a new connection and a reader for more
+	connect := func() (string, net.Conn, *bufio.Reader) {
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		reader := bufio.NewReader(conn)
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		return line, conn, reader
+	}
+
+	banner, existing, existingReader := connect()
+	require.True(t, strings.HasPrefix(banner, "220"), "unexpected banner %q", banner)
+
+	before := testutil.ToFloat64(metrics.PausedConnectionsRefusedTotal)
+	pause.Set(true)
+	reply, refused, refusedReader := connect()
+	assert.True(t, strings.HasPrefix(reply, "421"), "paused listener should refuse with 421, got %q", reply)
+	_, err = refusedReader.ReadString('\n')
+	assert.Error(t, err, "refused connection should be closed")
+	_ = refused.Close()
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.PausedConnectionsRefusedTotal))
+
+	// Sessions connected before the pause carry on
+	_, err = existing.Write([]byte("NOOP\r\n"))
+	require.NoError(t, err)
+	line, err := existingReader.ReadString('\n')
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(line, "200"), "unexpected NOOP reply %q", line)
+
+	pause.Set(false)
+	banner, _, _ = connect()
+	assert.True(t, strings.HasPrefix(banner, "220"), "resumed listener should accept, got %q", banner)
+}
@@ -0,0 +1,78 @@
+package ftp
+
+import (
+	"path"
+	"strings"
+
+	"goftp.io/server/v2"
+)
+
+// commandPwd replies to PWD with the session's directory as the client should
+// use it: relative to the home root for chrooted users, and with the home
+// directory prefixed for other users when ShowHomePath is set. Quotes in the
+// directory name are doubled as RFC 959 requires.
+type commandPwd struct {
+	auth *KubeAuth
+}
+
+func (cmd commandPwd) IsExtend() bool {
+	return false
+}
+
+func (cmd commandPwd) RequireParam() bool {
+	return false
+}
+
+func (cmd commandPwd) RequireAuth() bool {
+	return true
+}
+
+func (cmd commandPwd) Execute(sess *server.Session, param string) {
+	dir := sess.BuildPath("")
+	if home, ok := cmd.shownHome(sess); ok {
+		dir = path.Join(home, stripHomePrefix(dir, home))
+	}
+	if !cmd.auth.sessionUTF8(sessionIDForAddr(sess.RemoteAddr())) {
+		dir = utf8ToLatin1(dir)
+	}
+	sess.WriteMessage(257, "\""+strings.ReplaceAll(dir, "\"", "\"\"")+"\" is the current directory")
+}
+
+// shownHome returns the home directory shown in the PWD replies of a session,
+// which is only the case for users without chroot when ShowHomePath is set.
+// It is the home in effect for the session, after HomeByCIDR and any virtual
+// host selected with HOST.
+func (cmd commandPwd) shownHome(sess *server.Session) (string, bool) {
+	if !cmd.auth.ShowHomePath {
+		return "", false
+	}
+	sessionID := sessionIDForAddr(sess.RemoteAddr())
+	user := cmd.auth.cachedUser(cmd.auth.GetSessionUser(sessionID))
+	if user == nil || user.Spec.Chroot {
+		return "", false
+	}
+	if driver, ok := sess.Options().Driver.(*KubeDriver); ok {
+		user = driver.sessionUser(sessionID, sess.RemoteAddr().String(), user)
+	}
+	if user.Spec.HomeDirectory == "" {
+		return "", false
+	}
+	return user.Spec.HomeDirectory, true
+}
+
+// stripHomePrefix maps a path the client built from a PWD reply showing the
+// home directory back to the path relative to the home root. Other paths are
+// returned unchanged.
+func stripHomePrefix(p, home string) string {
+	home = path.Clean(home)
+	if home == "/" {
+		return p
+	}
+	if p == home {
+		return "/"
+	}
+	if strings.HasPrefix(p, home+"/") {
+		return strings.TrimPrefix(p, home)
+	}
+	return p
+}
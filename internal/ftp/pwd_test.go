@@ -0,0 +1,130 @@
+package ftp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+// pwdSession logs in as a guest with the given chroot setting whose every
+// path is an existing directory, and returns the session and its storage
+func pwdSession(t *testing.T, chroot, showHomePath bool) (func(command string) string, *MockStorage) {
+	user := &ftpv1.User{Spec: ftpv1.UserSpec{
+		Username:      "guest",
+		Type:          "anonymous",
+		Enabled:       true,
+		HomeDirectory: "/home/guest",
+		Chroot:        chroot,
+	}}
+	auth := NewKubeAuth(nil)
+	auth.ShowHomePath = showHomePath
+	auth.userCache.Store("guest", user)
+	mockStorage := &MockStorage{}
+	mockStorage.On("Stat", mock.Anything).Return(&MockFileInfo{name: "dir", isDir: true}, nil)
+	return anonymousSessionWithDriver(t, &KubeDriver{auth: auth, user: user, storageImpl: mockStorage}), mockStorage
+}
+
+func TestCommandPwd_Chroot(t *testing.T) {
+	send, mockStorage := pwdSession(t, true, false)
+
+	assert.Equal(t, `257 "/" is the current directory`, send("PWD"))
+
+	assert.Equal(t, "250 Directory changed to /docs", send("CWD docs"))
+	assert.Equal(t, `257 "/docs" is the current directory`, send("PWD"))
+	mockStorage.AssertCalled(t, "Stat", "/home/guest/docs")
+
+	assert.Equal(t, "250 Directory changed to /docs/2024", send("CWD 2024"))
+	assert.Equal(t, `257 "/docs/2024" is the current directory`, send("PWD"))
+
+	assert.Equal(t, "250 Directory changed to /docs", send("CDUP"))
+	assert.Equal(t, `257 "/docs" is the current directory`, send("PWD"))
+
+	// Climbing above the home root stays at it
+	send("CWD /")
+	send("CDUP")
+	assert.Equal(t, `257 "/" is the current directory`, send("PWD"))
+}
+
+func TestCommandPwd_ShowHomePath(t *testing.T) {
+	t.Run("non-chroot user sees the home path", func(t *testing.T) {
+		send, mockStorage := pwdSession(t, false, true)
+
+		assert.Equal(t, `257 "/home/guest" is the current directory`, send("PWD"))
+
+		send("CWD docs")
+		assert.Equal(t, `257 "/home/guest/docs" is the current directory`, send("PWD"))
+
+		// A path built from the PWD reply resolves inside the home directory
+		send("CWD /home/guest/reports")
+		assert.Equal(t, `257 "/home/guest/reports" is the current directory`, send("PWD"))
+		mockStorage.AssertCalled(t, "Stat", "/reports")
+	})
+
+	t.Run("chrooted user still sees the home root", func(t *testing.T) {
+		send, _ := pwdSession(t, true, true)
+
+		assert.Equal(t, `257 "/" is the current directory`, send("PWD"))
+	})
+
+	t.Run("XPWD replies as PWD", func(t *testing.T) {
+		send, _ := pwdSession(t, false, true)
+
+		send("CWD docs")
+		assert.Equal(t, `257 "/home/guest/docs" is the current directory`, send("XPWD"))
+	})
+
+	t.Run("home selected by client subnet", func(t *testing.T) {
+		user := &ftpv1.User{Spec: ftpv1.UserSpec{
+			Username:      "guest",
+			Type:          "anonymous",
+			Enabled:       true,
+			HomeDirectory: "/home/guest",
+			HomeByCIDR:    []ftpv1.HomeCIDRRule{{CIDR: "127.0.0.0/8", HomeDirectory: "/sites/local"}},
+		}}
+		auth := NewKubeAuth(nil)
+		auth.ShowHomePath = true
+		auth.userCache.Store("guest", user)
+		send := anonymousSessionWithDriver(t, &KubeDriver{auth: auth})
+
+		assert.Equal(t, `257 "/sites/local" is the current directory`, send("PWD"))
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		send, _ := pwdSession(t, false, false)
+
+		send("CWD docs")
+		assert.Equal(t, `257 "/docs" is the current directory`, send("PWD"))
+	})
+}
+
+func TestCommandPwd_QuotesDirectoryName(t *testing.T) {
+	send, _ := pwdSession(t, true, false)
+
+	send(`CWD say "hi"`)
+	assert.Equal(t, `257 "/say ""hi""" is the current directory`, send("PWD"))
+}
+
+func TestStripHomePrefix(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		home string
+		want string
+	}{
+		{name: "home itself", path: "/home/alice", home: "/home/alice", want: "/"},
+		{name: "under home", path: "/home/alice/docs", home: "/home/alice", want: "/docs"},
+		{name: "similar prefix", path: "/home/alicia/docs", home: "/home/alice", want: "/home/alicia/docs"},
+		{name: "home relative", path: "/docs", home: "/home/alice", want: "/docs"},
+		{name: "root home", path: "/docs", home: "/", want: "/docs"},
+		{name: "trailing slash on home", path: "/home/alice/docs", home: "/home/alice/", want: "/docs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, stripHomePrefix(tt.path, tt.home))
+		})
+	}
+}
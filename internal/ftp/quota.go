@@ -0,0 +1,150 @@
+package ftp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rossigee/kubeftpd/internal/storage"
+)
+
+// quotaFileName is the synthetic file reporting quota usage at the home root
+const quotaFileName = ".quota"
+
+// errQuotaFileReadOnly is returned for any attempt to modify the .quota file
+var errQuotaFileReadOnly = fmt.Errorf("permission denied: %s is read-only", quotaFileName)
+
+// errQuotaExceeded is returned when an upload would exceed the user's QuotaBytes
+var errQuotaExceeded = errors.New("quota exceeded")
+
+// quotaFileInfo describes the synthetic .quota file
+type quotaFileInfo struct {
+	size    int64
+	modTime time.Time
+}
+
+func (fi *quotaFileInfo) Name() string       { return quotaFileName }
+func (fi *quotaFileInfo) Size() int64        { return fi.size }
+func (fi *quotaFileInfo) Mode() os.FileMode  { return 0444 }
+func (fi *quotaFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *quotaFileInfo) IsDir() bool        { return false }
+func (fi *quotaFileInfo) Sys() interface{}   { return nil }
+
+// homeRoot returns the resolved path of the user's home root, as passed to storage
+func (driver *KubeDriver) homeRoot() string {
+	root, err := driver.validateChrootPath("/")
+	if err != nil {
+		return "/"
+	}
+	return filepath.Clean(root)
+}
+
+// isQuotaFile reports whether a resolved path is the user's synthetic .quota file
+func (driver *KubeDriver) isQuotaFile(resolvedPath string) bool {
+	if driver.user == nil || !driver.user.Spec.ShowQuotaFile {
+		return false
+	}
+	return filepath.Clean(resolvedPath) == filepath.Join(driver.homeRoot(), quotaFileName)
+}
+
+// usedBytes sums the size of every file under the user's home root
+func (driver *KubeDriver) usedBytes() (int64, error) {
+	used, _, err := driver.usage()
+	return used, err
+}
+
+// usage walks the user's home root and returns the total size of its files
+// and the number of files and directories stored beneath it
+func (driver *KubeDriver) usage() (int64, int64, error) {
+	return walkUsage(driver.storageImpl, driver.homeRoot())
+}
+
+// walkUsage returns the total size of the files under root and the number of
+// files and directories stored beneath it
+func walkUsage(s storage.Storage, root string) (int64, int64, error) {
+	var walk func(dir string) (int64, int64, error)
+	walk = func(dir string) (int64, int64, error) {
+		var total, entries int64
+		var subdirs []string
+		err := s.ListDir(dir, func(info os.FileInfo) error {
+			entries++
+			if info.IsDir() {
+				subdirs = append(subdirs, filepath.Join(dir, info.Name()))
+			} else {
+				total += info.Size()
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, subdir := range subdirs {
+			size, count, err := walk(subdir)
+			if err != nil {
+				return 0, 0, err
+			}
+			total += size
+			entries += count
+		}
+		return total, entries, nil
+	}
+	return walk(root)
+}
+
+// quotaFileContent renders the .quota file from the user's current usage
+func (driver *KubeDriver) quotaFileContent() (string, error) {
+	used, err := driver.quotaUsage()
+	if err != nil {
+		return "", fmt.Errorf("failed to compute quota usage: %w", err)
+	}
+
+	quota, available := "unlimited", "unlimited"
+	if limit := driver.user.Spec.QuotaBytes; limit > 0 {
+		quota = strconv.FormatInt(limit, 10)
+		available = strconv.FormatInt(max(limit-used, 0), 10)
+	}
+	return fmt.Sprintf("used_bytes=%d\nquota_bytes=%s\navailable_bytes=%s\n", used, quota, available), nil
+}
+
+// quotaFileStat returns the file info of the .quota file
+func (driver *KubeDriver) quotaFileStat() (os.FileInfo, error) {
+	content, err := driver.quotaFileContent()
+	if err != nil {
+		return nil, err
+	}
+	return &quotaFileInfo{size: int64(len(content)), modTime: time.Now()}, nil
+}
+
+// openQuotaFile returns the .quota file's contents from offset
+func (driver *KubeDriver) openQuotaFile(offset int64) (int64, io.ReadCloser, error) {
+	content, err := driver.quotaFileContent()
+	if err != nil {
+		return 0, nil, err
+	}
+	reader := strings.NewReader(content)
+	if _, err := reader.Seek(offset, io.SeekStart); err != nil {
+		return 0, nil, err
+	}
+	return int64(len(content)), io.NopCloser(reader), nil
+}
+
+// checkQuota rejects uploads once the user's usage has reached QuotaBytes
+func (driver *KubeDriver) checkQuota() error {
+	limit := driver.user.Spec.QuotaBytes
+	if limit <= 0 {
+		return nil
+	}
+	used, err := driver.quotaUsage()
+	if err != nil {
+		return fmt.Errorf("failed to compute quota usage: %w", err)
+	}
+	if used >= limit {
+		return fmt.Errorf("%w: %d of %d bytes used", errQuotaExceeded, used, limit)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package ftp
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+// newQuotaTestDriver returns a driver whose home holds 150 bytes across a
+// top-level file and a subdirectory
+func newQuotaTestDriver(quotaBytes int64, showQuotaFile bool) (*KubeDriver, *MockStorage) {
+	mockStorage := &MockStorage{}
+	listing := map[string][]os.FileInfo{
+		"/":        {&MockFileInfo{name: "report.csv", size: 100}, &MockFileInfo{name: "archive", isDir: true}},
+		"/archive": {&MockFileInfo{name: "old.csv", size: 50}},
+	}
+	for dir, entries := range listing {
+		entries := entries
+		mockStorage.On("ListDir", dir, mock.Anything).Run(func(args mock.Arguments) {
+			callback := args.Get(1).(func(os.FileInfo) error)
+			for _, entry := range entries {
+				_ = callback(entry)
+			}
+		}).Return(nil)
+	}
+
+	user := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "testuser", Namespace: "default"},
+		Spec: ftpv1.UserSpec{
+			Username:      "testuser",
+			Enabled:       true,
+			HomeDirectory: "/test",
+			Permissions:   ftpv1.UserPermissions{Read: true, Write: true},
+			QuotaBytes:    quotaBytes,
+			ShowQuotaFile: showQuotaFile,
+		},
+	}
+	return &KubeDriver{authenticatedUser: "testuser", user: user, storageImpl: mockStorage}, mockStorage
+}
+
+func TestKubeDriver_QuotaFile(t *testing.T) {
+	driver, mockStorage := newQuotaTestDriver(1000, true)
+	want := "used_bytes=150\nquota_bytes=1000\navailable_bytes=850\n"
+
+	info, err := driver.Stat(nil, "/.quota")
+	require.NoError(t, err)
+	assert.Equal(t, ".quota", info.Name())
+	assert.Equal(t, int64(len(want)), info.Size())
+	assert.Equal(t, os.FileMode(0444), info.Mode())
+
+	size, reader, err := driver.GetFile(nil, "/.quota", 0)
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, want, string(content))
+	assert.Equal(t, int64(len(want)), size)
+
+	var names []string
+	require.NoError(t, driver.ListDir(nil, "/", func(info os.FileInfo) error {
+		names = append(names, info.Name())
+		return nil
+	}))
+	assert.Equal(t, []string{"report.csv", "archive", ".quota"}, names)
+
+	// The file is read-only and never reaches storage
+	assert.ErrorIs(t, driver.DeleteFile(nil, "/.quota"), errQuotaFileReadOnly)
+	assert.ErrorIs(t, driver.Rename(nil, "/report.csv", "/.quota"), errQuotaFileReadOnly)
+	_, err = driver.PutFile(nil, "/.quota", strings.NewReader("x"), 0)
+	assert.ErrorIs(t, err, errQuotaFileReadOnly)
+	mockStorage.AssertNotCalled(t, "Stat", mock.Anything)
+	mockStorage.AssertNotCalled(t, "GetFile", mock.Anything, mock.Anything)
+	mockStorage.AssertNotCalled(t, "DeleteFile", mock.Anything)
+	mockStorage.AssertNotCalled(t, "PutFile", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestKubeDriver_QuotaFile_Unlimited(t *testing.T) {
+	driver, _ := newQuotaTestDriver(0, true)
+
+	_, reader, err := driver.GetFile(nil, "/.quota", 0)
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "used_bytes=150\nquota_bytes=unlimited\navailable_bytes=unlimited\n", string(content))
+}
+
+func TestKubeDriver_QuotaFile_Disabled(t *testing.T) {
+	driver, mockStorage := newQuotaTestDriver(0, false)
+	mockStorage.On("Stat", "/.quota").Return((*MockFileInfo)(nil), os.ErrNotExist)
+
+	_, err := driver.Stat(nil, "/.quota")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	mockStorage.AssertCalled(t, "Stat", "/.quota")
+}
+
+func TestKubeDriver_PutFile_QuotaExceeded(t *testing.T) {
+	driver, mockStorage := newQuotaTestDriver(150, false)
+
+	_, err := driver.PutFile(nil, "/new.csv", strings.NewReader("data"), 0)
+	assert.ErrorContains(t, err, "quota exceeded")
+	mockStorage.AssertNotCalled(t, "PutFile", mock.Anything, mock.Anything, mock.Anything)
+
+	reader := strings.NewReader("data")
+	driver, mockStorage = newQuotaTestDriver(151, false)
+	mockStorage.On("PutFile", "/new.csv", reader, int64(0)).Return(int64(4), nil)
+	_, err = driver.PutFile(nil, "/new.csv", reader, 0)
+	assert.NoError(t, err)
+}
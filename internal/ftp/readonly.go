@@ -0,0 +1,36 @@
+package ftp
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// errServerReadOnly is returned for every write while the global read-only
+// switch is on
+var errServerReadOnly = errors.New("server is temporarily read-only: uploads, deletes, renames and new directories are disabled")
+
+// ReadOnlyMode is the emergency switch that rejects write operations for all
+// users. Downloads and listings keep working.
+type ReadOnlyMode struct {
+	enabled atomic.Bool
+}
+
+// Set turns global read-only mode on or off
+func (m *ReadOnlyMode) Set(enabled bool) {
+	m.enabled.Store(enabled)
+}
+
+// Enabled reports whether global read-only mode is on
+func (m *ReadOnlyMode) Enabled() bool {
+	return m != nil && m.enabled.Load()
+}
+
+// checkServerReadOnly rejects a write operation while global read-only mode is on
+func (driver *KubeDriver) checkServerReadOnly(operation, path string) error {
+	if !driver.readOnly.Enabled() {
+		return nil
+	}
+	driver.operationLogger().Info("Write rejected while server is read-only", "username", driver.getAuthenticatedUsername(),
+		"operation", operation, "path", path)
+	return errServerReadOnly
+}
@@ -0,0 +1,65 @@
+package ftp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+// writeOperations runs each write operation a driver supports and returns
+// their errors by name
+func writeOperations(driver *KubeDriver) map[string]error {
+	_, putErr := driver.PutFile(nil, "/upload.txt", strings.NewReader("data"), 0)
+	return map[string]error{
+		"upload": putErr,
+		"delete": driver.DeleteFile(nil, "/old.txt"),
+		"rename": driver.Rename(nil, "/a.txt", "/b.txt"),
+		"mkdir":  driver.MakeDir(nil, "/reports"),
+		"rmdir":  driver.DeleteDir(nil, "/archive"),
+	}
+}
+
+func TestKubeDriver_GlobalReadOnly(t *testing.T) {
+	readOnly := &ReadOnlyMode{}
+
+	var drivers []*KubeDriver
+	var storages []*MockStorage
+	for _, username := range []string{"alice", "bob"} {
+		mockStorage := &MockStorage{}
+		mockStorage.On("PutFile", "/upload.txt", mock.Anything, int64(0)).Return(int64(4), nil)
+		mockStorage.On("DeleteFile", "/old.txt").Return(nil)
+		mockStorage.On("Rename", "/a.txt", "/b.txt").Return(nil)
+		mockStorage.On("MakeDir", "/reports").Return(nil)
+		mockStorage.On("DeleteDir", "/archive").Return(nil)
+		user := &ftpv1.User{Spec: ftpv1.UserSpec{Username: username, HomeDirectory: "/", Enabled: true,
+			Permissions: ftpv1.UserPermissions{Read: true, Write: true, Delete: true}}}
+		drivers = append(drivers, &KubeDriver{authenticatedUser: username, user: user, storageImpl: mockStorage, readOnly: readOnly})
+		storages = append(storages, mockStorage)
+	}
+
+	readOnly.Set(true)
+	for i, driver := range drivers {
+		for operation, err := range writeOperations(driver) {
+			assert.ErrorIs(t, err, errServerReadOnly, "%s by %s should be rejected", operation, driver.authenticatedUser)
+		}
+		assert.Empty(t, storages[i].Calls, "read-only mode must not reach the backend")
+	}
+
+	readOnly.Set(false)
+	for _, driver := range drivers {
+		for operation, err := range writeOperations(driver) {
+			require.NoError(t, err, "%s by %s should be allowed again", operation, driver.authenticatedUser)
+		}
+	}
+}
+
+func TestReadOnlyMode_Nil(t *testing.T) {
+	var readOnly *ReadOnlyMode
+	assert.False(t, readOnly.Enabled())
+	assert.NoError(t, (&KubeDriver{}).checkServerReadOnly("upload", "/file"))
+}
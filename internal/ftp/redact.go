@@ -0,0 +1,50 @@
+package ftp
+
+import (
+	"regexp"
+	"strings"
+)
+
+// redactedParams replaces sensitive command parameters in logs
+const redactedParams = "[REDACTED]"
+
+// querySecretPattern matches query-like secrets in parameters, such as the
+// token in "report.csv?token=abc" or "SITE LOGIN user=bob password=x"
+var querySecretPattern = regexp.MustCompile(`(?i)\b(pass(?:word|wd)?|pwd|secret|token|access_token|api_?key|key|auth|signature|sig|credentials?)=[^&\s;]+`)
+
+// redactQuerySecrets hides the values of query-like secrets in params
+func redactQuerySecrets(params string) string {
+	return querySecretPattern.ReplaceAllString(params, "${1}="+redactedParams)
+}
+
+// redactParams returns params as they may be logged for command. PASS and
+// ACCT are always redacted, as are SITE PASSWD arguments, the commands listed
+// in RedactCommands and query-like secrets in any parameters.
+func (kubeLogger *KubeLogger) redactParams(command, params string) string {
+	if params == "" {
+		return params
+	}
+	command = strings.ToUpper(command)
+	switch command {
+	case "PASS", "ACCT":
+		// Passwords and account information should never be logged
+		return redactedParams
+	case "SITE":
+		// SITE PASSWD carries the old and new passwords
+		params = redactSiteParams(params)
+	}
+	subcommand, _, hasArgs := strings.Cut(params, " ")
+	for _, entry := range kubeLogger.RedactCommands {
+		name, sub, ok := strings.Cut(strings.TrimSpace(entry), " ")
+		if !strings.EqualFold(name, command) {
+			continue
+		}
+		if !ok {
+			return redactedParams
+		}
+		if hasArgs && strings.EqualFold(strings.TrimSpace(sub), subcommand) {
+			return subcommand + " " + redactedParams
+		}
+	}
+	return redactQuerySecrets(params)
+}
@@ -0,0 +1,42 @@
+package ftp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKubeLogger_RedactParams(t *testing.T) {
+	logger := &KubeLogger{RedactCommands: []string{"XAUTH", "site token"}}
+
+	tests := []struct {
+		name     string
+		command  string
+		params   string
+		expected string
+	}{
+		{name: "PASS", command: "PASS", params: "secret", expected: "[REDACTED]"},
+		{name: "ACCT", command: "acct", params: "account", expected: "[REDACTED]"},
+		{name: "empty PASS", command: "PASS", params: "", expected: ""},
+		{name: "SITE PASSWD", command: "SITE", params: "PASSWD old new", expected: "PASSWD [REDACTED]"},
+		{name: "configured command", command: "xauth", params: "bearer abc123", expected: "[REDACTED]"},
+		{name: "configured SITE subcommand", command: "SITE", params: "TOKEN abc123", expected: "TOKEN [REDACTED]"},
+		{name: "other SITE subcommand", command: "SITE", params: "CHMOD 644 file", expected: "CHMOD 644 file"},
+		{name: "unlisted command", command: "RETR", params: "report.csv", expected: "report.csv"},
+		{name: "query secret", command: "RETR", params: "report.csv?token=abc&v=2", expected: "report.csv?token=[REDACTED]&v=2"},
+		{name: "key value secret", command: "SITE", params: "LOGIN user=bob Password=hunter2", expected: "LOGIN user=bob Password=[REDACTED]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, logger.redactParams(tt.command, tt.params))
+		})
+	}
+}
+
+func TestKubeLogger_RedactParams_NoConfiguredCommands(t *testing.T) {
+	logger := &KubeLogger{}
+
+	assert.Equal(t, "bearer abc123", logger.redactParams("XAUTH", "bearer abc123"))
+	assert.Equal(t, "TOKEN abc123", logger.redactParams("SITE", "TOKEN abc123"))
+}
@@ -0,0 +1,41 @@
+package ftp
+
+import (
+	"errors"
+	"strings"
+
+	"goftp.io/server/v2"
+)
+
+// errRangeNotSupported rejects restarted downloads from storage that can only
+// serve files from the beginning.
+var errRangeNotSupported = errors.New("restarting downloads is not supported by this storage backend")
+
+// commandRest refuses REST with a non-zero offset once the session's storage is
+// known to support neither appends nor ranged downloads, instead of accepting
+// an offset that the following transfer would ignore.
+type commandRest struct {
+	auth *KubeAuth
+	next server.Command
+}
+
+func (cmd commandRest) IsExtend() bool {
+	return cmd.next.IsExtend()
+}
+
+func (cmd commandRest) RequireParam() bool {
+	return cmd.next.RequireParam()
+}
+
+func (cmd commandRest) RequireAuth() bool {
+	return cmd.next.RequireAuth()
+}
+
+func (cmd commandRest) Execute(sess *server.Session, param string) {
+	caps, ok := cmd.auth.GetSessionCapabilities(sessionIDForAddr(sess.RemoteAddr()))
+	if ok && !caps.Append && !caps.Range && strings.TrimSpace(param) != "0" {
+		sess.WriteMessage(502, "REST not supported by the storage backend")
+		return
+	}
+	cmd.next.Execute(sess, param)
+}
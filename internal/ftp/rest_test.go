@@ -0,0 +1,95 @@
+package ftp
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/storage"
+)
+
+func newCapabilityTestDriver(caps storage.Capabilities) (*KubeDriver, *MockStorage) {
+	testUser := &ftpv1.User{
+		Spec: ftpv1.UserSpec{
+			Username:      "testuser",
+			Enabled:       true,
+			Chroot:        true,
+			HomeDirectory: "/home/testuser",
+			Backend:       ftpv1.BackendReference{Kind: "WebDavBackend", Name: "test-backend"},
+			Permissions:   ftpv1.UserPermissions{Read: true, Write: true},
+		},
+	}
+	mockStorage := &MockStorage{capabilities: caps}
+	driver := &KubeDriver{
+		auth:              NewKubeAuth(nil),
+		user:              testUser,
+		storageImpl:       mockStorage,
+		authenticatedUser: "testuser",
+	}
+	return driver, mockStorage
+}
+
+func TestKubeDriver_GetFile_RangeCapability(t *testing.T) {
+	t.Run("ranged download passed to storage", func(t *testing.T) {
+		driver, mockStorage := newCapabilityTestDriver(storage.Capabilities{Range: true})
+		reader := io.NopCloser(strings.NewReader("content"))
+		mockStorage.On("GetFile", "/home/testuser/file.txt", int64(5)).Return(int64(12), reader, nil)
+
+		size, got, err := driver.GetFile(nil, "/file.txt", 5)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(12), size)
+		assert.NotNil(t, got)
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("ranged download refused without range support", func(t *testing.T) {
+		driver, mockStorage := newCapabilityTestDriver(storage.Capabilities{})
+
+		_, _, err := driver.GetFile(nil, "/file.txt", 5)
+		assert.ErrorIs(t, err, errRangeNotSupported)
+		mockStorage.AssertNotCalled(t, "GetFile", mock.Anything, mock.Anything)
+	})
+
+	t.Run("download from the start needs no range support", func(t *testing.T) {
+		driver, mockStorage := newCapabilityTestDriver(storage.Capabilities{})
+		reader := io.NopCloser(strings.NewReader("content"))
+		mockStorage.On("GetFile", "/home/testuser/file.txt", int64(0)).Return(int64(7), reader, nil)
+
+		_, _, err := driver.GetFile(nil, "/file.txt", 0)
+		assert.NoError(t, err)
+		mockStorage.AssertExpectations(t)
+	})
+}
+
+func TestKubeDriver_PutFile_AppendCapability(t *testing.T) {
+	driver, mockStorage := newCapabilityTestDriver(storage.Capabilities{Append: true})
+	reader := strings.NewReader("more")
+	mockStorage.On("Stat", "/home/testuser/file.txt").Return(&MockFileInfo{name: "file.txt", size: 100}, nil).Maybe()
+	mockStorage.On("PutFile", "/home/testuser/file.txt", reader, int64(100)).Return(int64(4), nil)
+
+	n, err := driver.PutFile(nil, "/file.txt", reader, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestKubeAuth_SessionCapabilities(t *testing.T) {
+	auth := NewKubeAuth(nil)
+
+	_, ok := auth.GetSessionCapabilities("session-1")
+	assert.False(t, ok)
+
+	auth.setSessionCapabilities("session-1", storage.Capabilities{Range: true})
+	caps, ok := auth.GetSessionCapabilities("session-1")
+	assert.True(t, ok)
+	assert.True(t, caps.Range)
+	assert.False(t, caps.Append)
+
+	auth.ClearSessionCapabilities("session-1")
+	_, ok = auth.GetSessionCapabilities("session-1")
+	assert.False(t, ok)
+}
@@ -3,6 +3,7 @@ package ftp
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -10,6 +11,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -17,6 +19,8 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"goftp.io/server/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -75,8 +79,126 @@ type Server struct {
 	TLSKeyFile  string
 	// ForceTLS requires clients to upgrade to TLS before issuing any command.
 	ForceTLS bool
+	// IdleTimeout closes control connections that wait this long for the next
+	// command. Zero disables the idle reaper except for users with their own
+	// IdleTimeout.
+	IdleTimeout time.Duration
+	// DataIdleTimeout closes passive data connections that no transfer uses
+	// within this long, keeping the control connection open. Zero disables it.
+	DataIdleTimeout time.Duration
+	// MaxCommandLength rejects control command lines longer than this many
+	// bytes with 500 before goftp buffers them. Zero leaves them unlimited.
+	MaxCommandLength int
+	// DropLongCommandLines closes the connection instead of answering 500
+	// when a command line exceeds MaxCommandLength.
+	DropLongCommandLines bool
+	// MaxDataConnsPerSession caps the passive data channels a single session
+	// may hold open at once. Zero leaves it unlimited.
+	MaxDataConnsPerSession int
+	// RetryHint is appended to 421/450 replies refusing work because of a
+	// lockout or a saturated backend, telling clients when to retry.
+	RetryHint string
+	// RequireUniqueUsernames refuses logins for usernames defined by more than
+	// one enabled User across namespaces.
+	RequireUniqueUsernames bool
+	// RequireUploadSize rejects uploads from users with a byte quota unless the
+	// client announced the file size with ALLO first.
+	RequireUploadSize bool
+	// RejectUploadSizeMismatch fails binary uploads whose byte count differs
+	// from the size the client announced with ALLO, deleting them unless they
+	// replaced an existing file.
+	RejectUploadSizeMismatch bool
+	// FailTruncatedDownloads fails whole-file downloads whose backend stream
+	// ends before the file's size instead of completing them short. Such
+	// downloads are always logged and counted.
+	FailTruncatedDownloads bool
+	// RedactCommands lists commands, or SITE subcommands such as "SITE TOKEN",
+	// whose parameters are redacted in command logs like PASS.
+	RedactCommands []string
+	// ForceChroot confines every user to their home directory, overriding
+	// Users that set chroot to false.
+	ForceChroot bool
+	// AutoDisableOnViolations disables a User, setting enabled to false, after
+	// this many attempts to access paths outside its home directory within an
+	// hour. Counts are kept in memory per replica. Zero never disables users.
+	AutoDisableOnViolations int
+	// ViolationEvents, when set, records a Warning Event on each User disabled
+	// by AutoDisableOnViolations
+	ViolationEvents events.EventRecorder
+	// ShowHomePath makes PWD replies to users without chroot include their
+	// home directory, and accepts paths carrying it. Ignored with ForceChroot.
+	ShowHomePath bool
+	// UsersFile, when set, is a YAML file of User manifests served instead of
+	// the User resources in the cluster. It is reloaded whenever it changes.
+	UsersFile string
+	// SystemType overrides the "UNIX Type: L8" reply to SYST for clients that
+	// choose their listing parser from it
+	SystemType string
+	// HideServerIdentity replaces the default banner with a generic one and
+	// leaves the server name and version out of STAT replies
+	HideServerIdentity bool
+	// AllowUTF8Off lets clients send OPTS UTF8 OFF to use Latin-1 file names
+	// for the rest of their session
+	AllowUTF8Off bool
+	// FilenameCharset restricts the names of uploaded and renamed files to
+	// these characters, given as a regular expression character class body
+	// such as "A-Za-z0-9._-". Empty allows any name.
+	FilenameCharset string
+	// ErrorLogInterval collapses identical consecutive operation errors in a
+	// session into one log line and a count per interval. Zero logs every error.
+	ErrorLogInterval time.Duration
+	// ICAPServer is an icap://host[:port]/service URL every upload is sent to
+	// for virus scanning before it is stored. Infected uploads and uploads
+	// that cannot be scanned are rejected. Empty disables scanning.
+	ICAPServer string
+	// IdempotentMkdir makes MKD on an existing directory succeed instead of
+	// failing with 550.
+	IdempotentMkdir bool
+	// ErrorMessages overrides the reply templates in DefaultErrorMessages by
+	// category, e.g. to translate them for clients.
+	ErrorMessages map[string]string
+	// SlowOperationThreshold logs a warning and counts any driver operation
+	// that takes at least this long. Zero disables the check.
+	SlowOperationThreshold time.Duration
+	// QuotaUsageRefreshInterval serves quota usage from a per-user cache that is
+	// recomputed in the background once older than this. Zero walks the
+	// user's home on every quota check.
+	QuotaUsageRefreshInterval time.Duration
+	// NormalizeBackslashes treats a backslash in client paths as a directory separator
+	// for Windows clients. Leave it off to allow backslashes in file names.
+	NormalizeBackslashes bool
+	// MetricTagKeys safelists the User tag keys exported as labels of
+	// kubeftpd_user_tag_info. Tags not listed only appear in logs.
+	MetricTagKeys []string
+	// GreetingDelay holds back the welcome banner on each new connection to
+	// slow down scanners. Zero sends it immediately.
+	GreetingDelay time.Duration
+	// ScheduledBanners add lines to the welcome banner of connections
+	// accepted during their daily window, e.g. a maintenance reminder.
+	ScheduledBanners []ScheduledBanner
+	// UserCacheMaxStaleness lets cached users keep authenticating for this long
+	// beyond the cache TTL while the Kubernetes API server is unreachable.
+	UserCacheMaxStaleness time.Duration
+	// TransferEvents, when set, records a Kubernetes Event on the User for each
+	// completed upload or download. Leave nil to avoid event spam.
+	TransferEvents events.EventRecorder
+	// VirtualHosts maps hostnames accepted by the HOST command to the profile
+	// applied to users logging in under them. HOST is only offered when set.
+	VirtualHosts map[string]VirtualHost
+	// DisabledFeatures lists FEAT tokens (e.g. MLST, EPSV) to leave out of the
+	// feature advertisement for clients that mishandle them
+	DisabledFeatures []string
+	// Maintenance controls maintenance mode; a non-empty message rejects new logins
+	Maintenance *MaintenanceMode
+	// Pause refuses new connections while set, keeping existing sessions
+	Pause *PauseMode
+	// ReadOnly, when enabled, rejects uploads, deletes, renames and new
+	// directories for every user
+	ReadOnly *ReadOnlyMode
 	client   client.Client
 	server   *server.Server
+	// auth is the running server's authenticator, nil until Start
+	auth atomic.Pointer[KubeAuth]
 }
 
 // NewServer creates a new FTP server instance
@@ -87,10 +209,35 @@ func NewServer(bindAddress string, port int, pasvPorts string, publicIP string,
 		PasvPorts:      pasvPorts,
 		PublicIP:       publicIP,
 		WelcomeMessage: welcomeMessage,
+		Maintenance:    &MaintenanceMode{},
+		Pause:          &PauseMode{},
+		ReadOnly:       &ReadOnlyMode{},
 		client:         kubeClient,
 	}
 }
 
+// errServerNotStarted is returned for operations on the running server's
+// state before Start has set it up
+var errServerNotStarted = errors.New("FTP server not started")
+
+// RefreshUserCache reloads the running server's user cache from Kubernetes
+func (s *Server) RefreshUserCache(ctx context.Context) error {
+	auth := s.auth.Load()
+	if auth == nil {
+		return errServerNotStarted
+	}
+	return auth.RefreshUserCache(ctx)
+}
+
+// CachedUserCount returns how many users the running server has cached
+func (s *Server) CachedUserCount() int {
+	auth := s.auth.Load()
+	if auth == nil {
+		return 0
+	}
+	return auth.CachedUserCount()
+}
+
 // Start initializes and starts the FTP server using a custom listener.
 // The server.Options.Port is intentionally set to 0 because we manage
 // the TCP listener directly below. This allows us to:
@@ -112,31 +259,97 @@ func (s *Server) Start(ctx context.Context) error {
 
 	// Create auth instance
 	auth := NewKubeAuth(s.client)
+	auth.MaxStaleness = s.UserCacheMaxStaleness
+	auth.Maintenance = s.Maintenance
+	auth.DataIdleTimeout = s.DataIdleTimeout
+	auth.MaxDataConnsPerSession = s.MaxDataConnsPerSession
+	auth.RetryHint = s.RetryHint
+	auth.RequireUniqueUsernames = s.RequireUniqueUsernames
+	auth.AutoDisableOnViolations = s.AutoDisableOnViolations
+	auth.ViolationEvents = s.ViolationEvents
+	auth.SystemType = s.SystemType
+	auth.HideServerIdentity = s.HideServerIdentity
+	auth.AllowUTF8Off = s.AllowUTF8Off
+	auth.ErrorLogInterval = s.ErrorLogInterval
+	if s.FilenameCharset != "" {
+		charset, err := compileFilenameCharset(s.FilenameCharset)
+		if err != nil {
+			return err
+		}
+		auth.FilenameCharset = charset
+	}
+	if s.ICAPServer != "" {
+		scanner, err := newICAPScanner(s.ICAPServer)
+		if err != nil {
+			return err
+		}
+		auth.ICAPScanner = scanner
+	}
+	auth.ShowHomePath = s.ShowHomePath && !s.ForceChroot
+	auth.UsersFile = s.UsersFile
+	// The version is taken before loading so an edit made during startup is reloaded
+	var usersFileSince usersFileVersion
+	if s.UsersFile != "" {
+		usersFileSince = statUsersFile(s.UsersFile)
+		if err := auth.LoadUsersFile(); err != nil {
+			return err
+		}
+	}
+	s.auth.Store(auth)
 
-	// Start user cache refresh every 5 minutes in a tracked goroutine
+	// Reload users from the users file when it changes, or refresh the user
+	// cache every 5 minutes, in a tracked goroutine
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
+		if s.UsersFile != "" {
+			auth.watchUsersFile(ctx, usersFilePollInterval, usersFileSince)
+			return
+		}
 		auth.StartCacheRefresh(ctx, 5*time.Minute)
 	}()
 
 	// Create FTP server configuration
 	driver := &KubeDriver{
-		client: s.client,
-		auth:   auth,
+		client:               s.client,
+		auth:                 auth,
+		recorder:             s.TransferEvents,
+		virtualHosts:         s.VirtualHosts,
+		requireUploadSize:    s.RequireUploadSize,
+		rejectSizeMismatch:   s.RejectUploadSizeMismatch,
+		failShortDownloads:   s.FailTruncatedDownloads,
+		idempotentMkdir:      s.IdempotentMkdir,
+		forceChroot:          s.ForceChroot,
+		errorMessages:        s.ErrorMessages,
+		slowOpThreshold:      s.SlowOperationThreshold,
+		usageRefreshInterval: s.QuotaUsageRefreshInterval,
+		normalizeBackslashes: s.NormalizeBackslashes,
+		metricTagKeys:        s.MetricTagKeys,
+		readOnly:             s.ReadOnly,
 	}
 
 	opts := &server.Options{
 		Driver:         driver,
 		Port:           0, // Don't set port when using custom listener
 		Hostname:       "",
+		Name:           serverName,
 		PublicIP:       s.PublicIP,
 		Auth:           auth,
-		Logger:         &KubeLogger{},
+		Logger:         &KubeLogger{auth: auth, RedactCommands: s.RedactCommands},
 		PassivePorts:   s.PasvPorts,
-		WelcomeMessage: s.WelcomeMessage,
+		WelcomeMessage: s.greeting(),
 		Perm:           driver, // KubeDriver implements the Perm interface
+		Commands:       buildCommands(auth, s.VirtualHosts),
+	}
+	if len(s.VirtualHosts) > 0 {
+		logger.Info("HOST command enabled", "virtual_hosts", len(s.VirtualHosts))
+	}
+	if len(s.DisabledFeatures) > 0 {
+		if unknown := suppressFeatures(opts.Commands, s.DisabledFeatures); len(unknown) > 0 {
+			logger.Info("WARNING: ignoring disabled features that are not advertised", "features", unknown)
+		}
+		logger.Info("Suppressing FEAT entries", "features", s.DisabledFeatures)
 	}
 
 	if s.TLSCertFile != "" && s.TLSKeyFile != "" {
@@ -174,6 +387,19 @@ func (s *Server) Start(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to create listener on %s: %w", bindAddr, err)
 	}
+	listener = &pausableListener{Listener: listener, pause: s.Pause}
+	listener = &sessionConnListener{Listener: listener, auth: auth}
+	// Always installed, since users may set their own IdleTimeout
+	listener = &idleTimeoutListener{Listener: listener, timeout: s.IdleTimeout, auth: auth}
+	if s.MaxCommandLength > 0 {
+		listener = &lineLimitListener{Listener: listener, maxLength: s.MaxCommandLength, drop: s.DropLongCommandLines}
+	}
+	if len(s.ScheduledBanners) > 0 {
+		listener = &scheduledBannerListener{Listener: listener, banners: s.ScheduledBanners}
+	}
+	if s.GreetingDelay > 0 {
+		listener = &greetingDelayListener{Listener: listener, delay: s.GreetingDelay}
+	}
 	defer func() {
 		_ = listener.Close()
 	}()
@@ -192,43 +418,47 @@ func (s *Server) Start(ctx context.Context) error {
 	return ftpServer.Serve(listener)
 }
 
-// KubeLogger implements logging for the FTP server
-type KubeLogger struct{}
+// KubeLogger implements logging for the FTP server. Commands and responses are
+// logged at debug verbosity, except for sessions traced via SITE DEBUG or the
+// DebugLoggingAnnotation, which are logged at info level.
+type KubeLogger struct {
+	auth *KubeAuth
+	// logger overrides the default FTP logger
+	logger logr.Logger
+	// sessions maps goftp session ids to their *sessionTrace
+	sessions sync.Map
+	// RedactCommands lists further commands whose parameters are never
+	// logged, e.g. "XAUTH", or "SITE TOKEN" to redact one SITE subcommand
+	RedactCommands []string
+}
 
 func (kubeLogger *KubeLogger) Print(sessionId string, message interface{}) {
-	logger := getLogger()
+	logger := kubeLogger.log()
 	logger.Info("FTP session message", "session_id", sessionId, "message", message)
+	if message == "Connection Terminated" {
+		kubeLogger.sessions.Delete(sessionId)
+	}
 }
 
 func (kubeLogger *KubeLogger) Printf(sessionId string, format string, v ...interface{}) {
-	logger := getLogger()
+	logger := kubeLogger.log()
 	message := fmt.Sprintf(format, v...)
 	logger.Info("FTP session message", "session_id", sessionId, "message", message)
 }
 
 func (kubeLogger *KubeLogger) PrintCommand(sessionId string, command string, params string) {
-	logger := getLogger()
+	kubeLogger.observeCommand(sessionId, command, params)
+	logger := kubeLogger.sessionLogger(sessionId)
 
 	// Redact sensitive information in FTP commands
-	logParams := params
-	switch strings.ToUpper(command) {
-	case "PASS":
-		// Password commands should never log the actual password
-		if params != "" {
-			logParams = "[REDACTED]"
-		}
-	case "ACCT":
-		// Account information may contain sensitive data
-		if params != "" {
-			logParams = "[REDACTED]"
-		}
-	}
+	logParams := kubeLogger.redactParams(command, params)
 
 	logger.Info("FTP command", "session_id", sessionId, "command", command, "params", logParams)
 }
 
 func (kubeLogger *KubeLogger) PrintResponse(sessionId string, code int, message string) {
-	logger := getLogger()
+	kubeLogger.observeResponse(sessionId, code)
+	logger := kubeLogger.sessionLogger(sessionId)
 	logger.Info("FTP response", "session_id", sessionId, "code", code, "message", message)
 }
 
@@ -249,16 +479,28 @@ func (factory *KubeDriverFactory) NewDriver() (server.Driver, error) {
 
 // KubeDriver implements the FTP driver interface using Kubernetes backends
 type KubeDriver struct {
-	client            client.Client
-	auth              *KubeAuth
-	user              *ftpv1.User
-	storageImpl       storage.Storage
-	authenticatedUser string             // Track the authenticated username
-	sessionStart      time.Time          // Track session start time
-	clientIP          string             // Track client IP
-	sessionID         string             // Track session ID for cleanup
-	sessionCtx        context.Context    // Per-session context; cancelled in Close
-	sessionCancel     context.CancelFunc // Cancels sessionCtx on connection close
+	client               client.Client
+	auth                 *KubeAuth
+	user                 *ftpv1.User
+	storageImpl          storage.Storage
+	recorder             events.EventRecorder
+	virtualHosts         map[string]VirtualHost
+	requireUploadSize    bool               // Reject unannounced uploads from quota-limited users
+	rejectSizeMismatch   bool               // Remove and reject uploads differing from the ALLO size
+	failShortDownloads   bool               // Fail downloads ending before the file's size
+	idempotentMkdir      bool               // MKD on an existing directory succeeds
+	forceChroot          bool               // Treat every user as chrooted
+	errorMessages        map[string]string  // Reply template overrides by error category
+	slowOpThreshold      time.Duration      // Operations at least this slow are logged and counted
+	usageRefreshInterval time.Duration      // Cached quota usage older than this is refreshed in the background
+	normalizeBackslashes bool               // Treat backslashes in client paths as "/"
+	metricTagKeys        []string           // User tag keys exported in kubeftpd_user_tag_info
+	readOnly             *ReadOnlyMode      // Global switch rejecting all writes
+	authenticatedUser    string             // Track the authenticated username
+	clientIP             string             // Track client IP
+	sessionID            string             // Track session ID for cleanup
+	sessionCtx           context.Context    // Per-session context; cancelled in Close
+	sessionCancel        context.CancelFunc // Cancels sessionCtx on connection close
 }
 
 func (driver *KubeDriver) Init(conn *server.Context) {
@@ -269,16 +511,8 @@ func (driver *KubeDriver) Init(conn *server.Context) {
 
 	// Store session ID for authenticated user lookup
 	driver.sessionID = driver.auth.getSessionID(conn)
-	driver.sessionStart = time.Now()
 
 	driver.clientIP = driver.auth.clientIPFromCtx(conn)
-
-	// Record connection metrics
-	username := driver.getAuthenticatedUsername()
-	if username != "" {
-		logger.Info("Recording connection metrics", "username", username, "client_ip", driver.clientIP)
-		metrics.RecordConnection(username, driver.clientIP)
-	}
 }
 
 // resolveChrootPath converts a user's path request to the actual filesystem path within their home directory
@@ -317,14 +551,28 @@ func isPathWithinHome(resolvedPath, homeDir string) bool {
 	return strings.HasPrefix(cleanResolved, cleanHome) || cleanResolved == strings.TrimSuffix(cleanHome, "/")
 }
 
+// normalizeBackslashes converts Windows-style separators in a client path to
+// slashes and cleans the result, so `dir\file.txt` names the same file as `dir/file.txt`
+func normalizeBackslashes(path string) string {
+	return filepath.Clean(strings.ReplaceAll(path, `\`, "/"))
+}
+
 // validateChrootPath checks if a path operation is allowed for a chroot user and returns the resolved path
 func (driver *KubeDriver) validateChrootPath(path string) (string, error) {
 	if driver.user == nil {
 		return "", fmt.Errorf("user not initialized")
 	}
 
-	// If chroot is disabled, use path as-is
-	if !driver.user.Spec.Chroot {
+	if driver.normalizeBackslashes {
+		path = normalizeBackslashes(path)
+	}
+
+	// If chroot is disabled and not forced, use path as-is, minus the home
+	// directory PWD showed the client
+	if !driver.user.Spec.Chroot && !driver.forceChroot {
+		if driver.auth != nil && driver.auth.ShowHomePath {
+			path = stripHomePrefix(path, driver.user.Spec.HomeDirectory)
+		}
 		return path, nil
 	}
 
@@ -332,14 +580,17 @@ func (driver *KubeDriver) validateChrootPath(path string) (string, error) {
 	resolvedPath := resolveChrootPath(path, homeDir)
 
 	if !isPathWithinHome(resolvedPath, homeDir) {
-		logger := getLogger()
+		logger := driver.operationLogger()
 		username := driver.getAuthenticatedUsername()
 		logger.Info("CHROOT VIOLATION: Attempted access outside home directory",
 			"username", username, "requested_path", path, "resolved_path", resolvedPath, "home_directory", homeDir)
+		if driver.auth != nil {
+			driver.auth.recordChrootViolation(context.Background(), driver.user)
+		}
 		return "", fmt.Errorf("access denied: path outside home directory")
 	}
 
-	logger := getLogger()
+	logger := driver.operationLogger()
 	username := driver.getAuthenticatedUsername()
 	logger.Info("Chroot path resolved", "username", username, "requested_path", path, "resolved_path", resolvedPath)
 
@@ -347,7 +598,9 @@ func (driver *KubeDriver) validateChrootPath(path string) (string, error) {
 }
 
 func (driver *KubeDriver) ChangeDir(ctx *server.Context, path string) error {
-	logger := getLogger()
+	defer driver.observeSlowOperation("chdir", path, time.Now())
+
+	logger := driver.operationLogger()
 	username := driver.getAuthenticatedUsername()
 	logger.Info("FTP ChangeDir operation", "username", username, "path", path)
 
@@ -369,11 +622,13 @@ func (driver *KubeDriver) ChangeDir(ctx *server.Context, path string) error {
 	} else {
 		logger.Info("ChangeDir operation successful", "username", username, "path", path, "resolved_path", resolvedPath)
 	}
-	return err
+	return driver.replyError(err, path)
 }
 
 func (driver *KubeDriver) Stat(ctx *server.Context, path string) (os.FileInfo, error) {
-	logger := getLogger()
+	defer driver.observeSlowOperation("stat", path, time.Now())
+
+	logger := driver.operationLogger()
 	username := driver.getAuthenticatedUsername()
 	logger.Info("FTP Stat operation", "username", username, "path", path)
 
@@ -382,13 +637,18 @@ func (driver *KubeDriver) Stat(ctx *server.Context, path string) (os.FileInfo, e
 		return nil, err
 	}
 
-	// Validate chroot restrictions and get resolved path
-	resolvedPath, err := driver.validateChrootPath(path)
+	// Validate chroot restrictions and get resolved path; a listing filtered
+	// by a glob stats the directory it lists
+	resolvedPath, _, err := driver.resolveListPath(ctx, path)
 	if err != nil {
 		logger.Info("Stat failed due to chroot restriction", "username", username, "path", path, "error", err)
 		return nil, err
 	}
 
+	if driver.isQuotaFile(resolvedPath) {
+		return driver.quotaFileStat()
+	}
+
 	stat, err := driver.storageImpl.Stat(resolvedPath)
 	if err != nil {
 		// File not found is a normal condition (e.g., for RNFR operations checking if file exists)
@@ -400,13 +660,16 @@ func (driver *KubeDriver) Stat(ctx *server.Context, path string) (os.FileInfo, e
 		}
 	} else {
 		logger.Info("Stat operation successful", "username", username, "path", path, "resolved_path", resolvedPath, "size", stat.Size())
+		stat = driver.localizeFileTime(ctx, stat)
 	}
-	return stat, err
+	return stat, driver.replyError(err, path)
 }
 
 func (driver *KubeDriver) ListDir(ctx *server.Context, path string, callback func(os.FileInfo) error) error {
+	defer driver.observeSlowOperation("list", path, time.Now())
+
 	username := driver.getAuthenticatedUsername()
-	logger := getLogger()
+	logger := driver.operationLogger()
 	logger.Info("FTP LIST operation", "username", username, "path", path)
 
 	if err := driver.ensureUserInitializedWithContext(ctx); err != nil {
@@ -415,25 +678,43 @@ func (driver *KubeDriver) ListDir(ctx *server.Context, path string, callback fun
 	}
 
 	// Validate chroot restrictions and get resolved path
-	resolvedPath, err := driver.validateChrootPath(path)
+	resolvedPath, pattern, err := driver.resolveListPath(ctx, path)
 	if err != nil {
 		return err
 	}
 
-	err = driver.storageImpl.ListDir(resolvedPath, callback)
+	// Glob patterns are matched here, so clients only receive matching entries
+	if pattern != "" {
+		callback = storage.GlobFilter(pattern, callback)
+	}
+	callback = driver.localizeFileTimes(ctx, callback)
+	callback = driver.encodeListNames(ctx, path, callback)
+	err = driver.storageImpl.ListDir(resolvedPath, showLinkTargets(ctx, callback))
+	if err == nil && driver.user.Spec.ShowQuotaFile && filepath.Clean(resolvedPath) == driver.homeRoot() {
+		var info os.FileInfo
+		if info, err = driver.quotaFileStat(); err == nil {
+			err = callback(info)
+		}
+	}
 	if err != nil {
 		logger.Error(err, "LIST operation failed", "username", username, "path", path)
 	} else {
 		logger.Info("LIST operation successful", "username", username, "path", path)
 	}
-	return err
+	return driver.replyError(err, path)
 }
 
 func (driver *KubeDriver) DeleteDir(ctx *server.Context, path string) error {
+	defer driver.observeSlowOperation("rmdir", path, time.Now())
+
 	username := driver.getAuthenticatedUsername()
-	logger := getLogger()
+	logger := driver.operationLogger()
 	logger.Info("FTP RMDIR operation", "username", username, "path", path)
 
+	if err := driver.checkServerReadOnly("rmdir", path); err != nil {
+		return err
+	}
+
 	if err := driver.ensureUserInitializedWithContext(ctx); err != nil {
 		logger.Error(err, "RMDIR failed during user initialization", "username", username, "path", path)
 		return err
@@ -450,15 +731,22 @@ func (driver *KubeDriver) DeleteDir(ctx *server.Context, path string) error {
 		logger.Error(err, "RMDIR operation failed", "username", username, "path", path)
 	} else {
 		logger.Info("RMDIR operation successful", "username", username, "path", path)
+		driver.forgetFileCount()
 	}
-	return err
+	return driver.replyError(err, path)
 }
 
 func (driver *KubeDriver) DeleteFile(ctx *server.Context, path string) error {
-	logger := getLogger()
+	defer driver.observeSlowOperation("delete", path, time.Now())
+
+	logger := driver.operationLogger()
 	username := driver.getAuthenticatedUsername()
 	logger.Info("FTP DELETE operation", "username", username, "path", path)
 
+	if err := driver.checkServerReadOnly("delete", path); err != nil {
+		return err
+	}
+
 	if err := driver.ensureUserInitializedWithContext(ctx); err != nil {
 		logger.Error(err, "DELETE failed during user initialization", "username", username, "path", path)
 		return err
@@ -471,6 +759,10 @@ func (driver *KubeDriver) DeleteFile(ctx *server.Context, path string) error {
 		return err
 	}
 
+	if driver.isQuotaFile(resolvedPath) {
+		return errQuotaFileReadOnly
+	}
+
 	err = driver.storageImpl.DeleteFile(resolvedPath)
 	if err != nil {
 		// File not found is a normal condition for DELETE operations
@@ -481,15 +773,22 @@ func (driver *KubeDriver) DeleteFile(ctx *server.Context, path string) error {
 		}
 	} else {
 		logger.Info("DELETE operation successful", "username", username, "path", path, "resolved_path", resolvedPath)
+		driver.forgetFileCount()
 	}
-	return err
+	return driver.replyError(err, path)
 }
 
 func (driver *KubeDriver) Rename(ctx *server.Context, fromPath, toPath string) error {
-	logger := getLogger()
+	defer driver.observeSlowOperation("rename", fromPath, time.Now())
+
+	logger := driver.operationLogger()
 	username := driver.getAuthenticatedUsername()
 	logger.Info("FTP RENAME operation", "username", username, "from_path", fromPath, "to_path", toPath)
 
+	if err := driver.checkServerReadOnly("rename", fromPath); err != nil {
+		return err
+	}
+
 	if err := driver.ensureUserInitializedWithContext(ctx); err != nil {
 		logger.Error(err, "RENAME failed during user initialization", "username", username, "from_path", fromPath, "to_path", toPath)
 		return err
@@ -507,6 +806,10 @@ func (driver *KubeDriver) Rename(ctx *server.Context, fromPath, toPath string) e
 		return err
 	}
 
+	if driver.isQuotaFile(resolvedFromPath) || driver.isQuotaFile(resolvedToPath) {
+		return errQuotaFileReadOnly
+	}
+
 	err = driver.storageImpl.Rename(resolvedFromPath, resolvedToPath)
 	if err != nil {
 		// File not found is expected for RENAME operations (RNFR checking if source exists)
@@ -518,13 +821,18 @@ func (driver *KubeDriver) Rename(ctx *server.Context, fromPath, toPath string) e
 	} else {
 		logger.Info("RENAME operation successful", "username", username, "from_path", fromPath, "to_path", toPath, "resolved_from", resolvedFromPath, "resolved_to", resolvedToPath)
 	}
-	return err
+	return driver.replyError(err, fromPath)
 }
 
 func (driver *KubeDriver) MakeDir(ctx *server.Context, path string) error {
-	logger := getLogger()
+	defer driver.observeSlowOperation("mkdir", path, time.Now())
+
+	logger := driver.operationLogger()
 	username := driver.getAuthenticatedUsername()
 	logger.Info("FTP MKDIR operation", "username", username, "path", path)
+	if err := driver.checkServerReadOnly("mkdir", path); err != nil {
+		return err
+	}
 	if err := driver.ensureUserInitializedWithContext(ctx); err != nil {
 		logger.Error(err, "MKDIR failed during user initialization", "username", username, "path", path)
 		return err
@@ -536,16 +844,32 @@ func (driver *KubeDriver) MakeDir(ctx *server.Context, path string) error {
 		return err
 	}
 
+	if driver.isQuotaFile(resolvedPath) {
+		return errQuotaFileReadOnly
+	}
+
+	if err := driver.checkFileLimit(); err != nil {
+		logger.Info("MKDIR rejected by file limit", "username", username, "path", path, "error", err)
+		return err
+	}
+
 	err = driver.storageImpl.MakeDir(resolvedPath)
+	if errors.Is(err, storage.ErrDirExists) && driver.idempotentMkdir {
+		logger.Info("MKDIR of existing directory accepted", "username", username, "path", path, "resolved_path", resolvedPath)
+		return nil
+	}
 	if err != nil {
 		logger.Error(err, "MKDIR operation failed", "username", username, "path", path, "resolved_path", resolvedPath)
 	} else {
 		logger.Info("MKDIR operation successful", "username", username, "path", path, "resolved_path", resolvedPath)
+		driver.recordFileCreated()
 	}
-	return err
+	return driver.replyError(err, path)
 }
 
 func (driver *KubeDriver) GetFile(ctx *server.Context, path string, offset int64) (int64, io.ReadCloser, error) {
+	defer driver.observeSlowOperation("download", path, time.Now())
+
 	traceCtx := context.Background()
 	var span trace.Span
 
@@ -560,7 +884,7 @@ func (driver *KubeDriver) GetFile(ctx *server.Context, path string, offset int64
 		defer span.End()
 	}
 
-	logger := getLogger()
+	logger := driver.operationLogger()
 	username := driver.getAuthenticatedUsername()
 	logger.Info("FTP DOWNLOAD operation", "username", username, "path", path, "offset", offset)
 	start := time.Now()
@@ -587,6 +911,16 @@ func (driver *KubeDriver) GetFile(ctx *server.Context, path string, offset int64
 		return 0, nil, err
 	}
 
+	if driver.isQuotaFile(resolvedPath) {
+		return driver.openQuotaFile(offset)
+	}
+
+	if offset > 0 && !driver.storageImpl.Capabilities().Range {
+		logger.Info("DOWNLOAD refused - backend doesn't support ranged downloads", "username", username, "path", path, "requested_offset", offset)
+		metrics.RecordFileOperation(driver.authenticatedUser, "download", driver.getBackendType(), "error")
+		return 0, nil, errRangeNotSupported
+	}
+
 	size, reader, err := driver.storageImpl.GetFile(resolvedPath, offset)
 	duration := time.Since(start)
 
@@ -597,7 +931,7 @@ func (driver *KubeDriver) GetFile(ctx *server.Context, path string, offset int64
 			span.SetAttributes(attribute.String("ftp.status", "error"))
 		}
 		metrics.RecordFileOperation(driver.authenticatedUser, "download", driver.getBackendType(), "error")
-		return 0, nil, err
+		return 0, nil, driver.replyError(err, path)
 	}
 
 	logger.Info("DOWNLOAD operation successful", "username", username, "path", path, "size_bytes", size, "duration_ms", duration.Milliseconds())
@@ -611,10 +945,19 @@ func (driver *KubeDriver) GetFile(ctx *server.Context, path string, offset int64
 	metrics.RecordFileOperation(driver.authenticatedUser, "download", driver.getBackendType(), "success")
 	metrics.RecordFileTransfer(driver.authenticatedUser, "download", driver.getBackendType(), size, duration)
 
-	return size, reader, nil
+	// Ranged downloads report the size of the whole file, not of the range
+	if offset == 0 {
+		reader = driver.checkDownloadSize(reader, path, size)
+	}
+	if driver.asciiTransfer(ctx) {
+		reader = newASCIIReader(reader)
+	}
+	return size, driver.downloadEventReader(reader, path), nil
 }
 
 func (driver *KubeDriver) PutFile(ctx *server.Context, path string, reader io.Reader, offset int64) (int64, error) {
+	defer driver.observeSlowOperation("upload", path, time.Now())
+
 	traceCtx := context.Background()
 	var span trace.Span
 
@@ -638,18 +981,18 @@ func (driver *KubeDriver) PutFile(ctx *server.Context, path string, reader io.Re
 		defer span.End()
 	}
 
-	logger := getLogger()
+	logger := driver.operationLogger()
 	username := driver.getAuthenticatedUsername()
 	logger.Info("FTP upload operation", "username", username, "operation", uploadType, "path", path, "offset", offset)
 
-	// Storage backends don't support offset mode, so force offset to 0 for complete uploads
-	// This ensures compatibility with FTP clients that may request resumable uploads
-	if offset != 0 {
-		logger.Info("Forcing offset to 0 - backends don't support resumable uploads", "username", username, "path", path, "requested_offset", offset)
-		offset = 0
-		uploadType = "UPLOAD" // Change from APPEND to UPLOAD
-	}
 	start := time.Now()
+	// Consume any ALLO announcement up front so it never leaks to a later upload
+	announcedSize := driver.announcedUploadSize(ctx)
+
+	if err := driver.checkServerReadOnly("upload", path); err != nil {
+		metrics.RecordFileOperation(driver.authenticatedUser, "upload", driver.getBackendType(), "error")
+		return 0, err
+	}
 
 	if err := driver.ensureUserInitializedWithContext(ctx); err != nil {
 		logger.Error(err, "Upload failed during user initialization", "username", username, "operation", uploadType, "path", path)
@@ -661,6 +1004,14 @@ func (driver *KubeDriver) PutFile(ctx *server.Context, path string, reader io.Re
 		return 0, err
 	}
 
+	// Most storage can't write at an offset, so force offset to 0 for complete uploads
+	// This ensures compatibility with FTP clients that may request resumable uploads
+	if offset != 0 && !storage.SupportsResume(driver.storageImpl) {
+		logger.Info("Forcing offset to 0 - backend doesn't support resumable uploads", "username", username, "path", path, "requested_offset", offset)
+		offset = 0
+		uploadType = "UPLOAD" // Change from APPEND to UPLOAD
+	}
+
 	// Validate chroot restrictions and get resolved path
 	resolvedPath, err := driver.validateChrootPath(path)
 	if err != nil {
@@ -673,9 +1024,98 @@ func (driver *KubeDriver) PutFile(ctx *server.Context, path string, reader io.Re
 		return 0, err
 	}
 
+	if isDirectoryPath(path) {
+		metrics.RecordFileOperation(driver.authenticatedUser, "upload", driver.getBackendType(), "error")
+		return 0, errDirectoryUploadPath
+	}
+
+	if driver.isQuotaFile(resolvedPath) {
+		metrics.RecordFileOperation(driver.authenticatedUser, "upload", driver.getBackendType(), "error")
+		return 0, errQuotaFileReadOnly
+	}
+
+	err = driver.checkQuota()
+	if err == nil {
+		err = driver.checkUploadSize(announcedSize)
+	}
+	if err == nil {
+		err = driver.checkFileLimit()
+	}
+	if err != nil {
+		logger.Info("Upload rejected by quota", "username", username, "operation", uploadType, "path", path, "error", err)
+		if span != nil {
+			span.RecordError(err)
+			span.SetAttributes(attribute.String("ftp.status", "error"))
+		}
+		metrics.RecordFileOperation(driver.authenticatedUser, "upload", driver.getBackendType(), "error")
+		return 0, driver.replyError(err, path)
+	}
+
+	// Date-partitioned backends store the upload under the upload date's
+	// directories, so the overwrite policy, size check, logs and events all
+	// see the path the file is stored at
+	path = storage.UploadPath(driver.storageImpl, path, start)
+	resolvedPath = storage.UploadPath(driver.storageImpl, resolvedPath, start)
+
+	// Resuming with REST+STOR or appending with APPE continues the existing file,
+	// so only uploads that replace it are subject to the overwrite policy
+	if !driver.continuesUpload(ctx, offset) {
+		resolvedPath, err = driver.applyOverwritePolicy(resolvedPath)
+	}
+	if err != nil {
+		logger.Info("Upload rejected by overwrite policy", "username", username, "operation", uploadType, "path", path, "policy", driver.user.Spec.OverwritePolicy, "error", err)
+		if span != nil {
+			span.RecordError(err)
+			span.SetAttributes(attribute.String("ftp.status", "error"))
+		}
+		metrics.RecordFileOperation(driver.authenticatedUser, "upload", driver.getBackendType(), "error")
+		return 0, err
+	}
+
+	ascii := driver.asciiTransfer(ctx)
+	if ascii {
+		reader = newASCIIUploadReader(reader)
+	}
+	reader, cleanup, err := driver.scanUpload(reader)
+	if err != nil {
+		if errors.Is(err, errUploadInfected) {
+			logger.Info("Upload rejected by virus scan", "username", username, "operation", uploadType, "path", path, "error", err)
+		} else {
+			logger.Error(err, "Upload virus scan failed", "username", username, "operation", uploadType, "path", path)
+		}
+		if span != nil {
+			span.RecordError(err)
+			span.SetAttributes(attribute.String("ftp.status", "error"))
+		}
+		metrics.RecordFileOperation(driver.authenticatedUser, "upload", driver.getBackendType(), "error")
+		return 0, err
+	}
+	defer cleanup()
+
+	// A size mismatch only removes the upload if it didn't replace a file
+	checkSize := driver.checksUploadSize(announcedSize, offset, ascii)
+	replacing := false
+	if checkSize {
+		exists, statErr := driver.fileExists(resolvedPath)
+		replacing = exists || statErr != nil
+	}
+
 	size, err := driver.storageImpl.PutFile(resolvedPath, reader, offset)
 	duration := time.Since(start)
 
+	if err == nil && checkSize {
+		if err = driver.checkUploadSizeMatch(resolvedPath, announcedSize, size, replacing); err != nil {
+			logger.Info("Upload rejected for size mismatch", "username", username, "operation", uploadType, "path", path,
+				"resolved_path", resolvedPath, "announced_bytes", announcedSize, "size_bytes", size)
+			if span != nil {
+				span.RecordError(err)
+				span.SetAttributes(attribute.String("ftp.status", "error"))
+			}
+			metrics.RecordFileOperation(driver.authenticatedUser, "upload", driver.getBackendType(), "error")
+			return 0, err
+		}
+	}
+
 	if err != nil {
 		logger.Error(err, "Upload operation failed", "username", username, "operation", uploadType, "path", path, "resolved_path", resolvedPath)
 		if span != nil {
@@ -683,7 +1123,7 @@ func (driver *KubeDriver) PutFile(ctx *server.Context, path string, reader io.Re
 			span.SetAttributes(attribute.String("ftp.status", "error"))
 		}
 		metrics.RecordFileOperation(driver.authenticatedUser, "upload", driver.getBackendType(), "error")
-		return 0, err
+		return 0, driver.replyError(err, path)
 	}
 
 	logger.Info("Upload operation successful", "username", username, "operation", uploadType, "path", path, "resolved_path", resolvedPath, "size_bytes", size, "duration_ms", duration.Milliseconds())
@@ -696,10 +1136,127 @@ func (driver *KubeDriver) PutFile(ctx *server.Context, path string, reader io.Re
 	}
 	metrics.RecordFileOperation(driver.authenticatedUser, "upload", driver.getBackendType(), "success")
 	metrics.RecordFileTransfer(driver.authenticatedUser, "upload", driver.getBackendType(), size, duration)
+	driver.recordTransferEvent("upload", path, size)
+	driver.recordFileCreated()
+	driver.recordUsage(size)
 
 	return size, nil
 }
 
+// continuesUpload reports whether an upload writes onto the end of an existing
+// file rather than replacing it. goftp passes -1 for uploads without REST, and
+// backends that can't resume rewrite the whole file even for APPE.
+func (driver *KubeDriver) continuesUpload(ctx *server.Context, offset int64) bool {
+	if offset > 0 {
+		return true
+	}
+	return ctx != nil && ctx.Cmd == "APPE" && storage.SupportsResume(driver.storageImpl)
+}
+
+// maxRenameAttempts bounds the search for a free name under the rename overwrite policy
+const maxRenameAttempts = 1000
+
+// applyOverwritePolicy checks an upload target against the user's OverwritePolicy and
+// returns the path the upload should be written to
+func (driver *KubeDriver) applyOverwritePolicy(resolvedPath string) (string, error) {
+	policy := driver.user.Spec.OverwritePolicy
+	if policy == "" || policy == "allow" {
+		return resolvedPath, nil
+	}
+
+	exists, err := driver.fileExists(resolvedPath)
+	if err != nil || !exists {
+		return resolvedPath, err
+	}
+
+	switch policy {
+	case "deny":
+		return "", fmt.Errorf("file already exists: overwriting is not permitted")
+	case "rename":
+		ext := filepath.Ext(resolvedPath)
+		base := strings.TrimSuffix(resolvedPath, ext)
+		for i := 1; i <= maxRenameAttempts; i++ {
+			candidate := fmt.Sprintf("%s.%d%s", base, i, ext)
+			exists, err := driver.fileExists(candidate)
+			if err != nil {
+				return "", err
+			}
+			if !exists {
+				driver.operationLogger().Info("Renaming upload to avoid overwrite", "username", driver.getAuthenticatedUsername(),
+					"path", resolvedPath, "renamed_path", candidate)
+				return candidate, nil
+			}
+		}
+		return "", fmt.Errorf("file already exists: no free name found after %d attempts", maxRenameAttempts)
+	default:
+		return "", fmt.Errorf("unknown overwrite policy %q", policy)
+	}
+}
+
+// fileExists reports whether a resolved path exists in the user's storage
+func (driver *KubeDriver) fileExists(resolvedPath string) (bool, error) {
+	_, err := driver.storageImpl.Stat(resolvedPath)
+	if err == nil {
+		return true, nil
+	}
+	if isFileNotFoundError(err) {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to check for existing file %s: %w", resolvedPath, err)
+}
+
+// recordTransferEvent emits a Kubernetes Event on the user summarizing a completed transfer
+func (driver *KubeDriver) recordTransferEvent(direction, path string, bytes int64) {
+	if driver.recorder == nil || driver.user == nil {
+		return
+	}
+	reason := "UploadCompleted"
+	if direction == "download" {
+		reason = "DownloadCompleted"
+	}
+	driver.recorder.Eventf(driver.user, nil, corev1.EventTypeNormal, reason, "Transfer",
+		"%s %s (%d bytes)", direction, path, bytes)
+}
+
+// downloadEventReader defers a download's transfer event until goftp closes
+// reader, so the event carries the bytes actually sent. Downloads that fail or
+// are aborted before the end of the file record no event.
+func (driver *KubeDriver) downloadEventReader(reader io.ReadCloser, path string) io.ReadCloser {
+	if driver.recorder == nil || driver.user == nil {
+		return reader
+	}
+	return &transferEventReader{ReadCloser: reader, record: func(sent int64) {
+		driver.recordTransferEvent("download", path, sent)
+	}}
+}
+
+// transferEventReader counts the bytes read from a download and records its
+// transfer event on Close if the reader was read to the end
+type transferEventReader struct {
+	io.ReadCloser
+	sent      int64
+	completed bool
+	record    func(sent int64)
+}
+
+func (r *transferEventReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.sent += int64(n)
+	if errors.Is(err, io.EOF) {
+		r.completed = true
+	}
+	return n, err
+}
+
+func (r *transferEventReader) Close() error {
+	err := r.ReadCloser.Close()
+	if r.completed {
+		r.completed = false
+		r.record(r.sent)
+	}
+	return err
+}
+
 // ensureUserInitialized ensures the driver has an authenticated user and storage configured
 func (driver *KubeDriver) ensureUserInitialized() error {
 	return driver.ensureUserInitializedWithContext(nil)
@@ -713,9 +1270,10 @@ func (driver *KubeDriver) ensureUserInitializedWithContext(ctx *server.Context)
 
 	// Get the authenticated username from the auth system
 	var username string
+	sessionID := driver.sessionID
 	if ctx != nil && driver.auth != nil {
 		// Try session-based lookup
-		sessionID := driver.auth.getSessionID(ctx)
+		sessionID = driver.auth.getSessionID(ctx)
 		username = driver.auth.GetSessionUser(sessionID)
 	}
 	if username == "" {
@@ -739,27 +1297,76 @@ func (driver *KubeDriver) ensureUserInitializedWithContext(ctx *server.Context)
 		logger.Error(nil, "ensureUserInitialized failed: user not found in auth cache", "username", username)
 		return fmt.Errorf("user %s not found in auth cache", username)
 	}
+	clientAddr := driver.clientIP
+	if ctx != nil && driver.auth != nil {
+		clientAddr = driver.auth.clientIPFromCtx(ctx)
+	}
+	user = driver.sessionUser(sessionID, clientAddr, user)
 
 	// Initialize storage if not already done
 	if driver.storageImpl == nil {
 		logger.Info("ensureUserInitialized: initializing storage",
 			"username", username, "backend_kind", user.Spec.Backend.Kind, "backend_name", user.Spec.Backend.Name)
+		metrics.RecordBackendCacheMiss(user.Spec.Backend.Kind, user.Spec.Backend.Name)
+
+		var span trace.Span
+		if isTracingEnabled() {
+			_, span = tracer.Start(context.Background(), "ftp.storage.init",
+				trace.WithAttributes(
+					attribute.String("ftp.user", username),
+					attribute.String("ftp.backend", user.Spec.Backend.Kind),
+					attribute.String("ftp.backend_name", user.Spec.Backend.Name),
+				))
+			defer span.End()
+		}
 
 		var err error
 		driver.storageImpl, err = storage.NewStorage(driver.sessionCtx, user, driver.client)
 		if err != nil {
 			logger.Error(err, "ensureUserInitialized failed: storage initialization error", "username", username)
+			if span != nil {
+				span.RecordError(err)
+				span.SetAttributes(attribute.String("ftp.status", "error"))
+			}
 			return fmt.Errorf("failed to initialize storage for user %s: %w", user.Spec.Username, err)
 		}
+		if span != nil {
+			span.SetAttributes(attribute.String("ftp.status", "success"))
+		}
 
 		driver.user = user
 		driver.authenticatedUser = username
+		driver.auth.setSessionCapabilities(sessionID, driver.storageImpl.Capabilities())
+		driver.publishUserTags(user)
 		logger.Info("User successfully configured with backend", "username", user.Spec.Username, "backend_kind", user.Spec.Backend.Kind)
 	}
 
 	return nil
 }
 
+// sessionUser returns the user as configured for a session: with the home
+// directory selected by HomeByCIDR for the client's address and the defaults
+// of the virtual host the session selected with HOST
+func (driver *KubeDriver) sessionUser(sessionID, clientAddr string, user *ftpv1.User) *ftpv1.User {
+	user = driver.applyHomeByCIDR(user, clientAddr)
+	return driver.applyVirtualHost(sessionID, user)
+}
+
+// applyVirtualHost applies the profile selected by the session's HOST command, if any
+func (driver *KubeDriver) applyVirtualHost(sessionID string, user *ftpv1.User) *ftpv1.User {
+	if driver.auth == nil || len(driver.virtualHosts) == 0 {
+		return user
+	}
+	host := driver.auth.GetSessionHost(sessionID)
+	vhost, ok := driver.virtualHosts[host]
+	if !ok {
+		return user
+	}
+	getLogger().Info("Applying virtual host profile", "username", user.Spec.Username, "host", host,
+		"backend_kind", vhost.Backend.Kind, "backend_name", vhost.Backend.Name)
+	return vhost.applyTo(user)
+}
+
 // getAuthenticatedUsername returns the authenticated username for this driver instance
 func (driver *KubeDriver) getAuthenticatedUsername() string {
 	// Get the authenticated username from the session-specific mapping
@@ -780,7 +1387,7 @@ func (driver *KubeDriver) getBackendType() string {
 	return "unknown"
 }
 
-// Close handles connection cleanup and metrics recording
+// Close handles connection cleanup
 func (driver *KubeDriver) Close() error {
 	if driver.sessionCancel != nil {
 		driver.sessionCancel()
@@ -789,6 +1396,8 @@ func (driver *KubeDriver) Close() error {
 	// Clean up session mapping to prevent memory leaks
 	if driver.auth != nil && driver.sessionID != "" {
 		driver.auth.ClearSessionUser(driver.sessionID)
+		driver.auth.setSessionUTF8(driver.sessionID, true)
+		driver.auth.stopSessionDeadline(driver.sessionID)
 	}
 
 	// Close storage implementation to free resources
@@ -796,12 +1405,6 @@ func (driver *KubeDriver) Close() error {
 		_ = driver.storageImpl.Close()
 	}
 
-	if driver.authenticatedUser != "" && !driver.sessionStart.IsZero() {
-		sessionDuration := time.Since(driver.sessionStart)
-		metrics.RecordConnectionClosed(driver.authenticatedUser, sessionDuration)
-		metrics.RecordUserSession(driver.authenticatedUser, sessionDuration)
-	}
-
 	return nil
 }
 
@@ -809,27 +1412,57 @@ func (driver *KubeDriver) Close() error {
 // These methods provide file ownership and permission information
 
 func (driver *KubeDriver) GetOwner(path string) (string, error) {
-	// Return the authenticated user as the owner of all files
+	// Report the stored owner where the backend tracks one, otherwise the authenticated user
 	if err := driver.ensureUserInitialized(); err != nil {
 		return "", err
 	}
+	if owner, _ := driver.storedOwnership(path); owner != "" {
+		return owner, nil
+	}
 	return driver.authenticatedUser, nil
 }
 
 func (driver *KubeDriver) GetGroup(path string) (string, error) {
-	// Return a default group - could be enhanced to use user groups
+	// Report the stored group where the backend tracks one, otherwise a default group
 	if err := driver.ensureUserInitialized(); err != nil {
 		return "", err
 	}
+	if _, group := driver.storedOwnership(path); group != "" {
+		return group, nil
+	}
 	return "ftp", nil
 }
 
+// storedOwnership returns the owner and group the backend records for path,
+// or empty strings when it doesn't track ownership
+func (driver *KubeDriver) storedOwnership(path string) (owner, group string) {
+	if !driver.storageImpl.Capabilities().Ownership {
+		return "", ""
+	}
+	resolvedPath, err := driver.validateChrootPath(driver.auth.storedListPath(path))
+	if err != nil {
+		return "", ""
+	}
+	stat, err := driver.storageImpl.Stat(resolvedPath)
+	if err != nil {
+		return "", ""
+	}
+	if info, ok := stat.(server.FileInfo); ok {
+		return info.Owner(), info.Group()
+	}
+	return "", ""
+}
+
 func (driver *KubeDriver) GetMode(path string) (os.FileMode, error) {
 	// Get file mode from the storage implementation
 	if err := driver.ensureUserInitialized(); err != nil {
 		return 0, err
 	}
-	stat, err := driver.storageImpl.Stat(path)
+	resolvedPath, err := driver.validateChrootPath(driver.auth.storedListPath(path))
+	if err != nil {
+		return 0, err
+	}
+	stat, err := driver.storageImpl.Stat(resolvedPath)
 	if err != nil {
 		return 0, err
 	}
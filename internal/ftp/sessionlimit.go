@@ -0,0 +1,127 @@
+package ftp
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+// singleSessionReject is the SingleSession policy refusing a second login.
+// The other policy, "replace", closes the existing session instead.
+const singleSessionReject = "reject"
+
+// sessionConnListener registers each accepted control connection with auth,
+// so a session can be closed from outside goftp's command loop, and counts it
+// in the connection metrics
+type sessionConnListener struct {
+	net.Listener
+	auth *KubeAuth
+}
+
+func (l *sessionConnListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	tracked := &sessionConn{Conn: conn, auth: l.auth, sessionID: sessionIDForAddr(conn.RemoteAddr()), opened: time.Now()}
+	l.auth.sessionConns.Store(tracked.sessionID, tracked)
+	metrics.RecordConnection()
+	return tracked, nil
+}
+
+// sessionConn forgets its session's state when closed. goftp never calls the
+// driver's Close, so this is where a session's entries in auth's per-session
+// maps are removed and its timers stopped.
+type sessionConn struct {
+	net.Conn
+	auth      *KubeAuth
+	sessionID string
+	opened    time.Time
+	closed    sync.Once
+}
+
+func (c *sessionConn) Close() error {
+	c.closed.Do(func() {
+		c.auth.sessionConns.CompareAndDelete(c.sessionID, c)
+		username := c.auth.GetSessionUser(c.sessionID)
+		duration := time.Since(c.opened)
+		metrics.RecordConnectionClosed(username, duration)
+		if username != "" {
+			metrics.RecordUserSession(username, duration)
+		}
+		c.auth.ClearSessionUser(c.sessionID)
+		c.auth.stopSessionDeadline(c.sessionID)
+		c.auth.setSessionUTF8(c.sessionID, true)
+		c.auth.clearSessionTransferType(c.sessionID)
+		c.auth.ClearSessionCapabilities(c.sessionID)
+		c.auth.clearDataChannels(c.sessionID)
+		c.auth.stopDataIdleTimer(c.sessionID)
+		c.auth.takeSessionUploadSize(c.sessionID)
+		c.auth.clearSessionErrorLimiter(c.sessionID)
+		c.auth.ClearSessionHost(c.sessionID)
+	})
+	return c.Conn.Close()
+}
+
+// startSessionDeadline closes the session's control connection once maxDuration
+// has passed, regardless of activity. Closing the connection ends goftp's
+// command loop, which closes any data connection and the driver with it.
+func (auth *KubeAuth) startSessionDeadline(sessionID, username string, maxDuration time.Duration) {
+	if sessionID == "" || maxDuration <= 0 {
+		return
+	}
+	auth.stopSessionDeadline(sessionID)
+	deadline := &sessionTimer{}
+	deadline.timer = time.AfterFunc(maxDuration, func() {
+		auth.sessionDeadlines.CompareAndDelete(sessionID, deadline)
+		value, ok := auth.sessionConns.Load(sessionID)
+		if !ok {
+			return
+		}
+		getLogger().Info("Closing FTP session that reached its maximum duration", "username", username,
+			"session_id", sessionID, "max_session_duration", maxDuration.String())
+		metrics.RecordMaxSessionDurationClosed()
+		_ = value.(net.Conn).Close()
+	})
+	auth.sessionDeadlines.Store(sessionID, deadline)
+}
+
+// stopSessionDeadline cancels a session's pending maximum duration timer, if any
+func (auth *KubeAuth) stopSessionDeadline(sessionID string) {
+	if value, ok := auth.sessionDeadlines.LoadAndDelete(sessionID); ok {
+		value.(*sessionTimer).timer.Stop()
+	}
+}
+
+// claimSingleSession records sessionID as the only session of a user with
+// SingleSession set, looking for the user's other sessions among the open
+// control connections. Under the reject policy it returns false, leaving the
+// existing session alone, when the user is already logged in elsewhere. Under
+// replace the existing sessions' control connections are closed.
+func (auth *KubeAuth) claimSingleSession(sessionID string, user *ftpv1.User) bool {
+	auth.singleSessionMu.Lock()
+	defer auth.singleSessionMu.Unlock()
+
+	username := user.Spec.Username
+	var existing []*sessionConn
+	auth.sessionConns.Range(func(key, value any) bool {
+		if other := key.(string); other != sessionID && auth.GetSessionUser(other) == username {
+			existing = append(existing, value.(*sessionConn))
+		}
+		return true
+	})
+	if len(existing) > 0 && user.Spec.SingleSession == singleSessionReject {
+		return false
+	}
+
+	for _, conn := range existing {
+		getLogger().Info("Closing FTP session replaced by a new login", "username", username,
+			"session_id", conn.sessionID, "new_session_id", sessionID)
+		_ = conn.Close()
+	}
+	auth.setSessionUser(sessionID, username)
+	return true
+}
@@ -0,0 +1,342 @@
+package ftp
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goftp.io/server/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+// maxDurationSession logs in as an anonymous user whose sessions last at most
+// maxDuration, through a listener tracking control connections like Start's
+func maxDurationSession(t *testing.T, maxDuration time.Duration) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	auth := NewKubeAuth(nil)
+	auth.userCache.Store("guest", &ftpv1.User{Spec: ftpv1.UserSpec{
+		Username:           "guest",
+		Type:               "anonymous",
+		Enabled:            true,
+		MaxSessionDuration: metav1.Duration{Duration: maxDuration},
+	}})
+	driver := &KubeDriver{auth: auth}
+	ftpServer, err := server.NewServer(&server.Options{
+		Driver:   driver,
+		Auth:     auth,
+		Perm:     driver,
+		Logger:   &KubeLogger{auth: auth},
+		Commands: buildCommands(auth, nil),
+	})
+	require.NoError(t, err)
+
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	listener := &sessionConnListener{Listener: inner, auth: auth}
+	go func() { _ = ftpServer.Serve(listener) }()
+	t.Cleanup(func() { _ = ftpServer.Shutdown() })
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	reader := bufio.NewReader(conn)
+	send := func(command, wantCode string) {
+		_, err := conn.Write([]byte(command + "\r\n"))
+		require.NoError(t, err)
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(line, wantCode), "unexpected reply to %s: %q", command, line)
+	}
+	banner, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(banner, "220"))
+	send("USER guest", "331")
+	send("PASS guest@example.com", "230")
+	return conn, reader
+}
+
+func TestMaxSessionDuration_ClosesActiveSession(t *testing.T) {
+	before := testutil.ToFloat64(metrics.MaxSessionDurationClosedTotal)
+	conn, reader := maxDurationSession(t, 300*time.Millisecond)
+
+	// Commands keep flowing until the deadline; activity does not extend it
+	_, err := conn.Write([]byte("NOOP\r\n"))
+	require.NoError(t, err)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(line, "200"), "unexpected NOOP reply %q", line)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = reader.ReadString('\n')
+	require.Error(t, err, "session should be closed once its maximum duration passes")
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		assert.False(t, netErr.Timeout(), "session was not closed before the read deadline")
+	}
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.MaxSessionDurationClosedTotal))
+}
+
+func TestMaxSessionDuration_ZeroNeverCloses(t *testing.T) {
+	before := testutil.ToFloat64(metrics.MaxSessionDurationClosedTotal)
+	conn, reader := maxDurationSession(t, 0)
+
+	time.Sleep(300 * time.Millisecond)
+	_, err := conn.Write([]byte("NOOP\r\n"))
+	require.NoError(t, err)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(line, "200"), "unexpected NOOP reply %q", line)
+	assert.Equal(t, before, testutil.ToFloat64(metrics.MaxSessionDurationClosedTotal))
+}
+
+// singleSessionClient is a control connection to a singleSessionServer
+type singleSessionClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func (c *singleSessionClient) send(t *testing.T, command string) (string, error) {
+	t.Helper()
+	if _, err := c.conn.Write([]byte(command + "\r\n")); err != nil {
+		return "", err
+	}
+	require.NoError(t, c.conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	return c.reader.ReadString('\n')
+}
+
+// singleSessionServer serves an anonymous user with the given SingleSession
+// policy and returns a function connecting a client that logs in, along with
+// the reply to its PASS
+func singleSessionServer(t *testing.T, policy string) func() (*singleSessionClient, string) {
+	t.Helper()
+	auth := NewKubeAuth(nil)
+	auth.userCache.Store("guest", &ftpv1.User{Spec: ftpv1.UserSpec{
+		Username:      "guest",
+		Type:          "anonymous",
+		Enabled:       true,
+		SingleSession: policy,
+	}})
+	driver := &KubeDriver{auth: auth}
+	ftpServer, err := server.NewServer(&server.Options{
+		Driver:   driver,
+		Auth:     auth,
+		Perm:     driver,
+		Logger:   &KubeLogger{auth: auth},
+		Commands: buildCommands(auth, nil),
+	})
+	require.NoError(t, err)
+
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	listener := &sessionConnListener{Listener: inner, auth: auth}
+	go func() { _ = ftpServer.Serve(listener) }()
+	t.Cleanup(func() { _ = ftpServer.Shutdown() })
+
+	return func() (*singleSessionClient, string) {
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+		client := &singleSessionClient{conn: conn, reader: bufio.NewReader(conn)}
+
+		banner, err := client.reader.ReadString('\n')
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(banner, "220"))
+		reply, err := client.send(t, "USER guest")
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(reply, "331"), "unexpected USER reply %q", reply)
+		reply, err = client.send(t, "PASS guest@example.com")
+		require.NoError(t, err)
+		return client, reply
+	}
+}
+
+func TestSingleSession_RejectNew(t *testing.T) {
+	login := singleSessionServer(t, "reject")
+
+	first, reply := login()
+	require.True(t, strings.HasPrefix(reply, "230"), "unexpected PASS reply %q", reply)
+
+	_, reply = login()
+	assert.True(t, strings.HasPrefix(reply, "530"), "a second login should be refused, got %q", reply)
+
+	// The existing session is untouched
+	reply, err := first.send(t, "NOOP")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(reply, "200"), "unexpected NOOP reply %q", reply)
+
+	// Once it ends the user can log in again
+	reply, err = first.send(t, "QUIT")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(reply, "221"), "unexpected QUIT reply %q", reply)
+	_, err = first.reader.ReadString('\n')
+	require.Error(t, err)
+	require.Eventually(t, func() bool {
+		_, reply = login()
+		return strings.HasPrefix(reply, "230")
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestSingleSession_ReplaceOld(t *testing.T) {
+	login := singleSessionServer(t, "replace")
+
+	first, reply := login()
+	require.True(t, strings.HasPrefix(reply, "230"), "unexpected PASS reply %q", reply)
+
+	second, reply := login()
+	require.True(t, strings.HasPrefix(reply, "230"), "the new login should be let in, got %q", reply)
+
+	// The earlier session has been closed
+	_, err := first.send(t, "NOOP")
+	if err == nil {
+		_, err = first.reader.ReadString('\n')
+	}
+	require.Error(t, err, "the replaced session should be closed")
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		assert.False(t, netErr.Timeout(), "the replaced session was not closed before the read deadline")
+	}
+
+	reply, err = second.send(t, "NOOP")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(reply, "200"), "unexpected NOOP reply %q", reply)
+}
+
+func TestSingleSession_Unset(t *testing.T) {
+	login := singleSessionServer(t, "")
+
+	_, reply := login()
+	require.True(t, strings.HasPrefix(reply, "230"), "unexpected PASS reply %q", reply)
+	_, reply = login()
+	assert.True(t, strings.HasPrefix(reply, "230"), "users without SingleSession may log in repeatedly, got %q", reply)
+}
+
+func TestSessionConn_ClearsSessionStateOnQuit(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, ftpv1.AddToScheme(scheme))
+	user := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "testuser", Namespace: "default"},
+		Spec: ftpv1.UserSpec{
+			Username:      "testuser",
+			Password:      "secret",
+			Enabled:       true,
+			Backend:       ftpv1.BackendReference{Kind: "FilesystemBackend", Name: "fs"},
+			HomeDirectory: "/",
+		},
+	}
+	backend := &ftpv1.FilesystemBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "fs", Namespace: "default"},
+		Spec:       ftpv1.FilesystemBackendSpec{BasePath: t.TempDir()},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(user, backend).Build()
+
+	auth := NewKubeAuth(fakeClient)
+	auth.userCache.Store("testuser", user)
+	auth.DataIdleTimeout = time.Minute
+	auth.MaxDataConnsPerSession = 2
+	hosts, err := ParseVirtualHosts("files.example.com=FilesystemBackend/fs")
+	require.NoError(t, err)
+	driver := &KubeDriver{client: fakeClient, auth: auth, virtualHosts: hosts}
+	ftpServer, err := server.NewServer(&server.Options{
+		Driver:   driver,
+		Auth:     auth,
+		Perm:     driver,
+		Logger:   &KubeLogger{auth: auth},
+		Commands: buildCommands(auth, hosts),
+	})
+	require.NoError(t, err)
+
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	listener := &sessionConnListener{Listener: inner, auth: auth}
+	go func() { _ = ftpServer.Serve(listener) }()
+	t.Cleanup(func() { _ = ftpServer.Shutdown() })
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	client := &singleSessionClient{conn: conn, reader: bufio.NewReader(conn)}
+	banner, err := client.reader.ReadString('\n')
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(banner, "220"))
+
+	for _, step := range []struct{ command, wantCode string }{
+		{"HOST files.example.com", "220"},
+		{"USER testuser", "331"},
+		{"PASS secret", "230"},
+		{"TYPE A", "200"},
+		{"ALLO 10", "200"},
+		{"SIZE missing.txt", "450"},
+		{"PASV", "227"},
+	} {
+		reply, err := client.send(t, step.command)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(reply, step.wantCode), "unexpected reply to %s: %q", step.command, reply)
+	}
+
+	// Every per-session map holds an entry for the session while it is open
+	maps := map[string]*sync.Map{
+		"sessionConns":     &auth.sessionConns,
+		"sessionUserMap":   &auth.sessionUserMap,
+		"sessionTypes":     &auth.sessionTypes,
+		"sessionCaps":      &auth.sessionCaps,
+		"sessionDataConns": &auth.sessionDataConns,
+		"sessionDataIdle":  &auth.sessionDataIdle,
+		"sessionAllo":      &auth.sessionAllo,
+		"sessionHostMap":   &auth.sessionHostMap,
+	}
+	sessionID := sessionIDForAddr(conn.LocalAddr())
+	for name, m := range maps {
+		_, ok := m.Load(sessionID)
+		assert.True(t, ok, "%s has no entry for the open session", name)
+	}
+
+	reply, err := client.send(t, "QUIT")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(reply, "221"), "unexpected QUIT reply %q", reply)
+	_, err = client.reader.ReadString('\n')
+	require.Error(t, err)
+
+	for name, m := range maps {
+		assert.Eventually(t, func() bool {
+			empty := true
+			m.Range(func(any, any) bool {
+				empty = false
+				return false
+			})
+			return empty
+		}, 5*time.Second, 10*time.Millisecond, "%s still holds entries after QUIT", name)
+	}
+}
+
+func TestSessionConnListener_RecordsConnections(t *testing.T) {
+	before := metrics.GetServerStats()
+	login := singleSessionServer(t, "")
+
+	client, reply := login()
+	require.True(t, strings.HasPrefix(reply, "230"), "unexpected PASS reply %q", reply)
+	stats := metrics.GetServerStats()
+	assert.Equal(t, before.TotalConnections+1, stats.TotalConnections)
+	assert.Equal(t, before.ActiveSessions+1, stats.ActiveSessions)
+
+	reply, err := client.send(t, "QUIT")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(reply, "221"), "unexpected QUIT reply %q", reply)
+	assert.Eventually(t, func() bool {
+		return metrics.GetServerStats().ActiveSessions == before.ActiveSessions
+	}, 5*time.Second, 10*time.Millisecond, "the closed session is still counted as active")
+	assert.Equal(t, before.TotalConnections+1, metrics.GetServerStats().TotalConnections)
+}
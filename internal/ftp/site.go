@@ -0,0 +1,165 @@
+package ftp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+
+	"goftp.io/server/v2"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+// Password change failures that the SITE PASSWD command reports distinctly
+var (
+	errPasswordChangeNotAllowed = errors.New("password change is not permitted for this user")
+	errPasswordChangeNoSecret   = errors.New("password change requires a password stored in a Secret")
+	errPasswordChangeMismatch   = errors.New("current password is incorrect")
+	errPasswordChangeTooWeak    = errors.New("new password rejected")
+)
+
+// changePassword verifies the user's current password and writes the new one
+// to the user's password Secret after checking its strength.
+func (auth *KubeAuth) changePassword(ctx context.Context, user *ftpv1.User, oldPassword, newPassword string) error {
+	if !user.Spec.AllowPasswordChange {
+		return errPasswordChangeNotAllowed
+	}
+	secretRef := user.Spec.PasswordSecret
+	if secretRef == nil {
+		return errPasswordChangeNoSecret
+	}
+
+	current, err := auth.getPasswordFromSecret(ctx, secretRef, user.Namespace)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(current), []byte(oldPassword)) != 1 {
+		return errPasswordChangeMismatch
+	}
+	if err := ftpv1.ValidatePasswordStrength(newPassword); err != nil {
+		return fmt.Errorf("%w: %v", errPasswordChangeTooWeak, err)
+	}
+
+	secretNamespace := user.Namespace
+	if secretRef.Namespace != nil && *secretRef.Namespace != "" {
+		secretNamespace = *secretRef.Namespace
+	}
+	passwordKey := secretRef.Key
+	if passwordKey == "" {
+		passwordKey = "password"
+	}
+
+	secret := &corev1.Secret{}
+	if err := auth.client.Get(ctx, client.ObjectKey{Name: secretRef.Name, Namespace: secretNamespace}, secret); err != nil {
+		return fmt.Errorf("failed to get secret %s/%s: %w", secretNamespace, secretRef.Name, err)
+	}
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	secret.Data[passwordKey] = []byte(newPassword)
+	if err := auth.client.Update(ctx, secret); err != nil {
+		return fmt.Errorf("failed to update secret %s/%s: %w", secretNamespace, secretRef.Name, err)
+	}
+	return nil
+}
+
+// commandSite handles the SITE command. Supported subcommands are
+// SITE PASSWD old new and, for admin users, SITE DEBUG ON|OFF.
+type commandSite struct {
+	auth *KubeAuth
+}
+
+func (cmd commandSite) IsExtend() bool {
+	return true
+}
+
+func (cmd commandSite) RequireParam() bool {
+	return true
+}
+
+func (cmd commandSite) RequireAuth() bool {
+	return true
+}
+
+func (cmd commandSite) Execute(sess *server.Session, param string) {
+	fields := strings.Fields(param)
+	if len(fields) == 0 {
+		// goftp passes through a parameter of only spaces
+		sess.WriteMessage(501, "Usage: SITE <command> [arguments]")
+		return
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "PASSWD":
+		cmd.executePasswd(sess, fields[1:])
+	case "DEBUG":
+		cmd.executeDebug(sess, fields[1:])
+	default:
+		sess.WriteMessage(504, fmt.Sprintf("SITE %s not supported", fields[0]))
+	}
+}
+
+func (cmd commandSite) executePasswd(sess *server.Session, args []string) {
+	if len(args) != 2 {
+		sess.WriteMessage(501, "Usage: SITE PASSWD <old> <new>")
+		return
+	}
+
+	ctx := context.Background()
+	username := sess.LoginUser()
+	user := cmd.auth.GetUser(ctx, username)
+	if user == nil {
+		sess.WriteMessage(550, "User not found")
+		return
+	}
+
+	err := cmd.auth.changePassword(ctx, user, args[0], args[1])
+	switch {
+	case err == nil:
+		getLogger().Info("User changed password", "username", username)
+		sess.WriteMessage(200, "Password changed")
+	case errors.Is(err, errPasswordChangeMismatch):
+		sess.WriteMessage(530, err.Error())
+	case errors.Is(err, errPasswordChangeNotAllowed), errors.Is(err, errPasswordChangeNoSecret),
+		errors.Is(err, errPasswordChangeTooWeak):
+		sess.WriteMessage(550, err.Error())
+	default:
+		getLogger().Error(err, "Failed to change password", "username", username)
+		sess.WriteMessage(451, "Password change failed")
+	}
+}
+
+// executeDebug toggles command/response tracing for the current session. The
+// 200 reply is what KubeLogger acts on, so every refusal must use another code.
+func (cmd commandSite) executeDebug(sess *server.Session, args []string) {
+	if len(args) != 1 || (!strings.EqualFold(args[0], "ON") && !strings.EqualFold(args[0], "OFF")) {
+		sess.WriteMessage(501, "Usage: SITE DEBUG ON|OFF")
+		return
+	}
+
+	username := sess.LoginUser()
+	user := cmd.auth.GetUser(context.Background(), username)
+	if user == nil || user.Spec.Type != "admin" {
+		sess.WriteMessage(550, "SITE DEBUG is restricted to admin users")
+		return
+	}
+
+	enabled := strings.EqualFold(args[0], "ON")
+	getLogger().Info("Session debug logging toggled", "username", username, "enabled", enabled)
+	if enabled {
+		sess.WriteMessage(200, "Session debug logging enabled")
+	} else {
+		sess.WriteMessage(200, "Session debug logging disabled")
+	}
+}
+
+// redactSiteParams hides the passwords in SITE PASSWD arguments
+func redactSiteParams(params string) string {
+	if subcommand, _, ok := strings.Cut(params, " "); ok && strings.EqualFold(subcommand, "PASSWD") {
+		return subcommand + " [REDACTED]"
+	}
+	return params
+}
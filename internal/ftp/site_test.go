@@ -0,0 +1,122 @@
+package ftp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+func TestKubeAuth_changePassword(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, ftpv1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	const oldPassword = "Old-Pa55w0rd!"
+
+	tests := []struct {
+		name         string
+		allowChange  bool
+		plaintext    bool
+		oldPassword  string
+		newPassword  string
+		wantErr      error
+		wantPassword string
+	}{
+		{
+			name:         "successful change",
+			allowChange:  true,
+			oldPassword:  oldPassword,
+			newPassword:  "N3w-Str0ng!Key",
+			wantPassword: "N3w-Str0ng!Key",
+		},
+		{
+			name:         "weak new password rejected",
+			allowChange:  true,
+			oldPassword:  oldPassword,
+			newPassword:  "short",
+			wantErr:      errPasswordChangeTooWeak,
+			wantPassword: oldPassword,
+		},
+		{
+			name:         "wrong current password",
+			allowChange:  true,
+			oldPassword:  "Wr0ng-Guess!",
+			newPassword:  "N3w-Str0ng!Key",
+			wantErr:      errPasswordChangeMismatch,
+			wantPassword: oldPassword,
+		},
+		{
+			name:         "change not allowed",
+			oldPassword:  oldPassword,
+			newPassword:  "N3w-Str0ng!Key",
+			wantErr:      errPasswordChangeNotAllowed,
+			wantPassword: oldPassword,
+		},
+		{
+			name:        "plaintext password cannot be changed",
+			allowChange: true,
+			plaintext:   true,
+			oldPassword: oldPassword,
+			newPassword: "N3w-Str0ng!Key",
+			wantErr:     errPasswordChangeNoSecret,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "alice-ftp-password", Namespace: "default"},
+				Data:       map[string][]byte{"password": []byte(oldPassword)},
+			}
+			user := &ftpv1.User{
+				ObjectMeta: metav1.ObjectMeta{Name: "alice", Namespace: "default"},
+				Spec: ftpv1.UserSpec{
+					Username:            "alice",
+					AllowPasswordChange: tt.allowChange,
+					PasswordSecret:      &ftpv1.UserSecretRef{Name: "alice-ftp-password"},
+				},
+			}
+			if tt.plaintext {
+				user.Spec.PasswordSecret = nil
+				user.Spec.Password = oldPassword
+			}
+
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret, user).Build()
+			auth := NewKubeAuth(fakeClient)
+
+			err := auth.changePassword(context.Background(), user, tt.oldPassword, tt.newPassword)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			if tt.wantPassword != "" {
+				stored := &corev1.Secret{}
+				require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(secret), stored))
+				assert.Equal(t, tt.wantPassword, string(stored.Data["password"]))
+			}
+		})
+	}
+}
+
+func TestRedactSiteParams(t *testing.T) {
+	assert.Equal(t, "PASSWD [REDACTED]", redactSiteParams("PASSWD old new"))
+	assert.Equal(t, "passwd [REDACTED]", redactSiteParams("passwd old new"))
+	assert.Equal(t, "CHMOD 644 file", redactSiteParams("CHMOD 644 file"))
+}
+
+func TestCommandSite_BlankParam(t *testing.T) {
+	send := anonymousSession(t)
+	assert.Equal(t, "501 Usage: SITE <command> [arguments]", send("SITE    "))
+	assert.Equal(t, "504 SITE CHMOD not supported", send("SITE CHMOD 644 file"))
+}
@@ -0,0 +1,27 @@
+package ftp
+
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+// observeSlowOperation flags a driver operation started at start if it ran
+// longer than the slow operation threshold. Call it with defer at the top of
+// each operation.
+func (driver *KubeDriver) observeSlowOperation(operation, path string, start time.Time) {
+	recordSlowOperation(driver.operationLogger(), driver.slowOpThreshold, operation, driver.getAuthenticatedUsername(), path, time.Since(start))
+}
+
+// recordSlowOperation logs a warning and counts the operation when duration
+// reaches threshold. A zero threshold disables the check.
+func recordSlowOperation(logger logr.Logger, threshold time.Duration, operation, username, path string, duration time.Duration) {
+	if threshold <= 0 || duration < threshold {
+		return
+	}
+	logger.Info("WARNING: slow FTP operation", "operation", operation, "username", username, "path", path,
+		"duration_ms", duration.Milliseconds(), "threshold_ms", threshold.Milliseconds())
+	metrics.RecordSlowOperation(operation)
+}
@@ -0,0 +1,74 @@
+package ftp
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+func TestRecordSlowOperation(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		duration  time.Duration
+		wantSlow  bool
+	}{
+		{name: "exceeds threshold", threshold: time.Second, duration: 2 * time.Second, wantSlow: true},
+		{name: "equals threshold", threshold: time.Second, duration: time.Second, wantSlow: true},
+		{name: "below threshold", threshold: time.Second, duration: 500 * time.Millisecond},
+		{name: "disabled", threshold: 0, duration: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lines []string
+			logger := funcr.New(func(prefix, args string) { lines = append(lines, args) }, funcr.Options{})
+			counter := metrics.SlowOperationsTotal.WithLabelValues("stat")
+			before := testutil.ToFloat64(counter)
+
+			recordSlowOperation(logger, tt.threshold, "stat", "testuser", "/report.csv", tt.duration)
+
+			if tt.wantSlow {
+				require.Len(t, lines, 1)
+				assert.Contains(t, lines[0], "WARNING: slow FTP operation")
+				assert.Contains(t, lines[0], `"operation"="stat"`)
+				assert.Contains(t, lines[0], `"duration_ms"=`+strconv.FormatInt(tt.duration.Milliseconds(), 10))
+				assert.Equal(t, before+1, testutil.ToFloat64(counter))
+			} else {
+				assert.Empty(t, lines)
+				assert.Equal(t, before, testutil.ToFloat64(counter))
+			}
+		})
+	}
+}
+
+func TestKubeDriver_SlowOperationMetric(t *testing.T) {
+	mockStorage := &MockStorage{}
+	mockStorage.On("Stat", "/slow.csv").After(20*time.Millisecond).Return(&MockFileInfo{name: "slow.csv"}, nil)
+	mockStorage.On("Stat", "/fast.csv").Return(&MockFileInfo{name: "fast.csv"}, nil)
+
+	driver := &KubeDriver{
+		authenticatedUser: "testuser",
+		user:              &ftpv1.User{Spec: ftpv1.UserSpec{Username: "testuser"}},
+		storageImpl:       mockStorage,
+		slowOpThreshold:   10 * time.Millisecond,
+	}
+	counter := metrics.SlowOperationsTotal.WithLabelValues("stat")
+	before := testutil.ToFloat64(counter)
+
+	_, err := driver.Stat(nil, "/slow.csv")
+	require.NoError(t, err)
+	assert.Equal(t, before+1, testutil.ToFloat64(counter))
+
+	_, err = driver.Stat(nil, "/fast.csv")
+	require.NoError(t, err)
+	assert.Equal(t, before+1, testutil.ToFloat64(counter), "fast operations are not counted")
+}
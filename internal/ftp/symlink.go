@@ -0,0 +1,41 @@
+package ftp
+
+import (
+	"os"
+
+	"goftp.io/server/v2"
+)
+
+// linkTargeter is implemented by file infos that can describe symbolic links
+type linkTargeter interface {
+	LinkTarget() string
+}
+
+// symlinkListEntry presents a symbolic link as "name -> target", as ls -l does
+type symlinkListEntry struct {
+	os.FileInfo
+	target string
+}
+
+func (e *symlinkListEntry) Name() string {
+	return e.FileInfo.Name() + " -> " + e.target
+}
+
+func (e *symlinkListEntry) Unwrap() os.FileInfo {
+	return e.FileInfo
+}
+
+// showLinkTargets wraps a directory listing callback so that LIST output
+// shows symbolic link targets. NLST and MLSD keep bare names since clients
+// use them as paths.
+func showLinkTargets(ctx *server.Context, callback func(os.FileInfo) error) func(os.FileInfo) error {
+	if ctx == nil || ctx.Cmd != "LIST" {
+		return callback
+	}
+	return func(info os.FileInfo) error {
+		if link, ok := info.(linkTargeter); ok && info.Mode()&os.ModeSymlink != 0 && link.LinkTarget() != "" {
+			return callback(&symlinkListEntry{FileInfo: info, target: link.LinkTarget()})
+		}
+		return callback(info)
+	}
+}
@@ -0,0 +1,50 @@
+package ftp
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goftp.io/server/v2"
+)
+
+// linkFileInfo is a file info for a symbolic link exposing its target
+type linkFileInfo struct {
+	MockFileInfo
+	target string
+}
+
+func (fi *linkFileInfo) LinkTarget() string { return fi.target }
+
+func TestShowLinkTargets(t *testing.T) {
+	entries := []os.FileInfo{
+		&MockFileInfo{name: "report.csv", size: 10},
+		&linkFileInfo{MockFileInfo: MockFileInfo{name: "latest.csv", mode: os.ModeSymlink | 0777}, target: "report.csv"},
+	}
+
+	tests := []struct {
+		name string
+		ctx  *server.Context
+		want []string
+	}{
+		{name: "LIST shows targets", ctx: &server.Context{Cmd: "LIST"}, want: []string{"report.csv", "latest.csv -> report.csv"}},
+		{name: "MLSD keeps names", ctx: &server.Context{Cmd: "MLSD"}, want: []string{"report.csv", "latest.csv"}},
+		{name: "NLST keeps names", ctx: &server.Context{Cmd: "NLST"}, want: []string{"report.csv", "latest.csv"}},
+		{name: "no context", ctx: nil, want: []string{"report.csv", "latest.csv"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			callback := showLinkTargets(tt.ctx, func(info os.FileInfo) error {
+				names = append(names, info.Name())
+				return nil
+			})
+			for _, entry := range entries {
+				require.NoError(t, callback(entry))
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package ftp
+
+import (
+	"goftp.io/server/v2"
+)
+
+// defaultSystemType is the SYST reply most clients parse as a Unix-style
+// LIST format
+const defaultSystemType = "UNIX Type: L8"
+
+// commandSyst replies to SYST with a configurable system type, since clients
+// pick their listing parser from it
+type commandSyst struct {
+	systemType string
+}
+
+func (cmd commandSyst) IsExtend() bool {
+	return false
+}
+
+func (cmd commandSyst) RequireParam() bool {
+	return false
+}
+
+func (cmd commandSyst) RequireAuth() bool {
+	return true
+}
+
+func (cmd commandSyst) Execute(sess *server.Session, param string) {
+	systemType := cmd.systemType
+	if systemType == "" {
+		systemType = defaultSystemType
+	}
+	sess.WriteMessage(215, systemType)
+}
@@ -0,0 +1,21 @@
+package ftp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandSyst_DefaultReply(t *testing.T) {
+	send := anonymousSessionWithAuth(t, NewKubeAuth(nil))
+
+	assert.Equal(t, "215 UNIX Type: L8", send("SYST"))
+}
+
+func TestCommandSyst_OverrideReply(t *testing.T) {
+	auth := NewKubeAuth(nil)
+	auth.SystemType = "Windows_NT"
+	send := anonymousSessionWithAuth(t, auth)
+
+	assert.Equal(t, "215 Windows_NT", send("SYST"))
+}
@@ -0,0 +1,81 @@
+package ftp
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"goftp.io/server/v2"
+)
+
+// locations caches the time zones named by Users' Timezone: name -> *time.Location
+var locations sync.Map
+
+// localTimeFileInfo reports a file's modification time in another location
+type localTimeFileInfo struct {
+	os.FileInfo
+	loc *time.Location
+}
+
+func (f *localTimeFileInfo) ModTime() time.Time {
+	return f.FileInfo.ModTime().In(f.loc)
+}
+
+func (f *localTimeFileInfo) Unwrap() os.FileInfo {
+	return f.FileInfo
+}
+
+// fileTimeLocation returns the location a command shows file times in:
+// UTC for MDTM and the MLST/MLSD facts, which RFC 3659 defines as UTC, and
+// the user's Timezone for LIST. Nil leaves times as the backend reports them.
+func (driver *KubeDriver) fileTimeLocation(ctx *server.Context) *time.Location {
+	if ctx == nil {
+		return nil
+	}
+	switch ctx.Cmd {
+	case "MDTM", "MLST", "MLSD":
+		return time.UTC
+	case "LIST":
+		if driver.user == nil || driver.user.Spec.Timezone == "" {
+			return nil
+		}
+		return userLocation(driver.user.Spec.Timezone)
+	}
+	return nil
+}
+
+// userLocation loads a User's Timezone, or returns nil after logging when the
+// name is unknown so listings fall back to the backend's times
+func userLocation(name string) *time.Location {
+	if cached, ok := locations.Load(name); ok {
+		return cached.(*time.Location)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		getLogger().Error(err, "Ignoring unknown user timezone", "timezone", name)
+		return nil
+	}
+	locations.Store(name, loc)
+	return loc
+}
+
+// localizeFileTime reports info's modification time in the location the
+// command shows file times in
+func (driver *KubeDriver) localizeFileTime(ctx *server.Context, info os.FileInfo) os.FileInfo {
+	loc := driver.fileTimeLocation(ctx)
+	if loc == nil || info == nil {
+		return info
+	}
+	return &localTimeFileInfo{FileInfo: info, loc: loc}
+}
+
+// localizeFileTimes wraps a ListDir callback with localizeFileTime
+func (driver *KubeDriver) localizeFileTimes(ctx *server.Context, callback func(os.FileInfo) error) func(os.FileInfo) error {
+	loc := driver.fileTimeLocation(ctx)
+	if loc == nil {
+		return callback
+	}
+	return func(info os.FileInfo) error {
+		return callback(&localTimeFileInfo{FileInfo: info, loc: loc})
+	}
+}
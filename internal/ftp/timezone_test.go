@@ -0,0 +1,94 @@
+package ftp
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"goftp.io/server/v2"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+// zonedFileInfo is a file whose backend reports its time outside UTC
+type zonedFileInfo struct {
+	MockFileInfo
+	modTime time.Time
+}
+
+func (f *zonedFileInfo) ModTime() time.Time { return f.modTime }
+
+// newTimezoneTestDriver returns a driver for a user in timezone whose home
+// holds report.csv, modified at 02:04:05 UTC on 2 January 2024
+func newTimezoneTestDriver(timezone string) (*KubeDriver, *MockStorage) {
+	report := &zonedFileInfo{
+		MockFileInfo: MockFileInfo{name: "report.csv", size: 42},
+		modTime:      time.Date(2024, time.January, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600)),
+	}
+	mockStorage := &MockStorage{}
+	mockStorage.On("Stat", "/report.csv").Return(report, nil)
+	mockStorage.On("ListDir", "/", mock.Anything).Run(func(args mock.Arguments) {
+		_ = args.Get(1).(func(os.FileInfo) error)(report)
+	}).Return(nil)
+
+	user := &ftpv1.User{Spec: ftpv1.UserSpec{
+		Username:      "guest",
+		Type:          "anonymous",
+		Enabled:       true,
+		HomeDirectory: "/",
+		Timezone:      timezone,
+		Permissions:   ftpv1.UserPermissions{Read: true, List: true},
+	}}
+	auth := NewKubeAuth(nil)
+	auth.userCache.Store("guest", user)
+	return &KubeDriver{auth: auth, user: user, storageImpl: mockStorage}, mockStorage
+}
+
+// listedModTime returns the time LIST shows for report.csv
+func listedModTime(t *testing.T, driver *KubeDriver) time.Time {
+	t.Helper()
+	var modTime time.Time
+	require.NoError(t, driver.ListDir(&server.Context{Cmd: "LIST"}, "/", func(info os.FileInfo) error {
+		modTime = info.ModTime()
+		return nil
+	}))
+	return modTime
+}
+
+func TestTimezone_ListShowsUserTime(t *testing.T) {
+	driver, _ := newTimezoneTestDriver("Asia/Tokyo")
+
+	modTime := listedModTime(t, driver)
+	assert.Equal(t, "Asia/Tokyo", modTime.Location().String())
+	assert.Equal(t, "Jan  2 11:04", modTime.Format("Jan _2 15:04"))
+
+	// A single file listed by name is shown in the same zone
+	info, err := driver.Stat(&server.Context{Cmd: "LIST"}, "/report.csv")
+	require.NoError(t, err)
+	assert.Equal(t, "Jan  2 11:04", info.ModTime().Format("Jan _2 15:04"))
+}
+
+func TestTimezone_MachineReadableTimesStayUTC(t *testing.T) {
+	driver, _ := newTimezoneTestDriver("Asia/Tokyo")
+
+	// MDTM is answered on the control connection
+	send := anonymousSessionWithDriver(t, driver)
+	assert.Equal(t, "213 20240102020405", send("MDTM report.csv"))
+
+	require.NoError(t, driver.ListDir(&server.Context{Cmd: "MLSD"}, "/", func(info os.FileInfo) error {
+		assert.Equal(t, time.UTC, info.ModTime().Location())
+		assert.Equal(t, "20240102020405", info.ModTime().Format("20060102150405"))
+		return nil
+	}))
+}
+
+func TestTimezone_UnsetKeepsBackendTime(t *testing.T) {
+	driver, _ := newTimezoneTestDriver("")
+	assert.Equal(t, "Jan  2 03:04", listedModTime(t, driver).Format("Jan _2 15:04"))
+
+	driver, _ = newTimezoneTestDriver("Not/AZone")
+	assert.Equal(t, "Jan  2 03:04", listedModTime(t, driver).Format("Jan _2 15:04"), "unknown zones fall back to the backend's time")
+}
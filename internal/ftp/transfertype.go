@@ -0,0 +1,161 @@
+package ftp
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"goftp.io/server/v2"
+)
+
+// Transfer types set with TYPE or a User's DefaultTransferType
+const (
+	transferTypeASCII  = "ascii"
+	transferTypeBinary = "binary"
+)
+
+// setSessionTransferType records the transfer type of a session's next transfers
+func (auth *KubeAuth) setSessionTransferType(sessionID, transferType string) {
+	if sessionID != "" {
+		auth.sessionTypes.Store(sessionID, transferType)
+	}
+}
+
+// sessionTransferType returns the session's transfer type, binary unless set
+func (auth *KubeAuth) sessionTransferType(sessionID string) string {
+	if sessionID == "" {
+		return transferTypeBinary
+	}
+	if transferType, ok := auth.sessionTypes.Load(sessionID); ok {
+		return transferType.(string)
+	}
+	return transferTypeBinary
+}
+
+// clearSessionTransferType forgets a session's transfer type when it ends
+func (auth *KubeAuth) clearSessionTransferType(sessionID string) {
+	if sessionID != "" {
+		auth.sessionTypes.Delete(sessionID)
+	}
+}
+
+// asciiTransfer reports whether the session behind ctx transfers in ASCII mode
+func (driver *KubeDriver) asciiTransfer(ctx *server.Context) bool {
+	if driver.auth == nil {
+		return false
+	}
+	sessionID := driver.auth.getSessionID(ctx)
+	if sessionID == "" {
+		sessionID = driver.sessionID
+	}
+	return driver.auth.sessionTransferType(sessionID) == transferTypeASCII
+}
+
+// commandType wraps TYPE so the session's transfer type is tracked for line
+// ending translation. goftp only accepts A and I.
+type commandType struct {
+	auth *KubeAuth
+	next server.Command
+}
+
+func (cmd commandType) IsExtend() bool {
+	return cmd.next.IsExtend()
+}
+
+func (cmd commandType) RequireParam() bool {
+	return cmd.next.RequireParam()
+}
+
+func (cmd commandType) RequireAuth() bool {
+	return cmd.next.RequireAuth()
+}
+
+func (cmd commandType) Execute(sess *server.Session, param string) {
+	sessionID := sessionIDForAddr(sess.RemoteAddr())
+	switch strings.ToUpper(param) {
+	case "A":
+		cmd.auth.setSessionTransferType(sessionID, transferTypeASCII)
+	case "I":
+		cmd.auth.setSessionTransferType(sessionID, transferTypeBinary)
+	}
+	cmd.next.Execute(sess, param)
+}
+
+// asciiReader converts bare LF line endings to the CRLF sent on the wire
+type asciiReader struct {
+	io.ReadCloser
+	src *bufio.Reader
+	// pendingLF is the LF still owed after a CR was emitted for it
+	pendingLF bool
+	// lastCR is set when the previous byte read was a CR, so an LF following
+	// it is already part of a CRLF
+	lastCR bool
+}
+
+// newASCIIReader translates a download to ASCII line endings
+func newASCIIReader(r io.ReadCloser) *asciiReader {
+	return &asciiReader{ReadCloser: r, src: bufio.NewReader(r)}
+}
+
+func (r *asciiReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if r.pendingLF {
+			p[n] = '\n'
+			n++
+			r.pendingLF = false
+			continue
+		}
+		// Only block for more input when nothing has been returned yet
+		if n > 0 && r.src.Buffered() == 0 {
+			break
+		}
+		b, err := r.src.ReadByte()
+		if err != nil {
+			return n, err
+		}
+		if b == '\n' && !r.lastCR {
+			p[n] = '\r'
+			n++
+			r.pendingLF = true
+			r.lastCR = false
+			continue
+		}
+		p[n] = b
+		n++
+		r.lastCR = b == '\r'
+	}
+	return n, nil
+}
+
+// asciiUploadReader converts the CRLF line endings of an ASCII upload to LF
+type asciiUploadReader struct {
+	src *bufio.Reader
+}
+
+// newASCIIUploadReader translates an ASCII upload to stored LF line endings
+func newASCIIUploadReader(r io.Reader) *asciiUploadReader {
+	return &asciiUploadReader{src: bufio.NewReader(r)}
+}
+
+func (r *asciiUploadReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if n > 0 && r.src.Buffered() == 0 {
+			break
+		}
+		b, err := r.src.ReadByte()
+		if err != nil {
+			return n, err
+		}
+		if b == '\r' {
+			next, err := r.src.Peek(1)
+			if err == nil && next[0] == '\n' {
+				continue
+			}
+		}
+		p[n] = b
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,91 @@
+package ftp
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+func TestASCIIReader(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "bare LF", in: "one\ntwo\n", want: "one\r\ntwo\r\n"},
+		{name: "CRLF kept", in: "one\r\ntwo\r\n", want: "one\r\ntwo\r\n"},
+		{name: "no line endings", in: "data", want: "data"},
+		{name: "leading LF", in: "\n\n", want: "\r\n\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// One byte at a time exercises the LF owed across reads
+			r := newASCIIReader(io.NopCloser(iotest.OneByteReader(strings.NewReader(tt.in))))
+			got, err := io.ReadAll(iotest.OneByteReader(r))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestASCIIUploadReader(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "CRLF", in: "one\r\ntwo\r\n", want: "one\ntwo\n"},
+		{name: "bare LF kept", in: "one\ntwo\n", want: "one\ntwo\n"},
+		{name: "bare CR kept", in: "one\rtwo\r", want: "one\rtwo\r"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := io.ReadAll(newASCIIUploadReader(iotest.OneByteReader(strings.NewReader(tt.in))))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestDefaultTransferType_ASCII(t *testing.T) {
+	// retrieve starts a session as a guest defaulting to ASCII, sends the
+	// given commands and returns the content of a RETR
+	retrieve := func(t *testing.T, commands ...string) string {
+		user := &ftpv1.User{Spec: ftpv1.UserSpec{Username: "guest", Type: "anonymous", Enabled: true, DefaultTransferType: transferTypeASCII}}
+		auth := NewKubeAuth(nil)
+		auth.userCache.Store("guest", user)
+		mockStorage := &MockStorage{}
+		mockStorage.On("GetFile", "/notes.txt", int64(0)).Return(int64(8), io.NopCloser(strings.NewReader("one\ntwo\n")), nil)
+		send := anonymousSessionWithDriver(t, &KubeDriver{auth: auth, user: user, storageImpl: mockStorage})
+
+		for _, command := range commands {
+			require.True(t, strings.HasPrefix(send(command), "200 "), command)
+		}
+		port := passivePort(t, send("PASV"))
+		data, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		require.NoError(t, err)
+		defer func() { _ = data.Close() }()
+		require.True(t, strings.HasPrefix(send("RETR notes.txt"), "150 "))
+		require.NoError(t, data.SetReadDeadline(time.Now().Add(5*time.Second)))
+		content, err := io.ReadAll(data)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	t.Run("default applies without TYPE A", func(t *testing.T) {
+		assert.Equal(t, "one\r\ntwo\r\n", retrieve(t))
+	})
+
+	t.Run("TYPE I overrides the default", func(t *testing.T) {
+		assert.Equal(t, "one\ntwo\n", retrieve(t, "TYPE I"))
+	})
+}
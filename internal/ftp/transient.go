@@ -0,0 +1,34 @@
+package ftp
+
+import (
+	"goftp.io/server/v2"
+
+	"github.com/rossigee/kubeftpd/internal/storage"
+)
+
+// transientReply is a 4xx rejection that well-behaved clients retry after
+// backing off
+type transientReply struct {
+	code    int
+	message string
+}
+
+// Transient rejections caused by concurrency caps and rate limits. Replies
+// built from these carry the configured retry hint.
+var (
+	replyBackendBusy = transientReply{code: 450, message: storage.ErrBackendBusy.Error()}
+	replyLockedOut   = transientReply{code: 421, message: "Too many failed login attempts from this address, try again later"}
+)
+
+// transientMessage appends the configured retry hint to a transient reply
+func (auth *KubeAuth) transientMessage(reply transientReply) string {
+	if auth.RetryHint == "" {
+		return reply.message
+	}
+	return reply.message + "; " + auth.RetryHint
+}
+
+// writeTransient sends a transient rejection to the client
+func (auth *KubeAuth) writeTransient(sess *server.Session, reply transientReply) {
+	sess.WriteMessage(reply.code, auth.transientMessage(reply))
+}
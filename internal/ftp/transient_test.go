@@ -0,0 +1,85 @@
+package ftp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/storage"
+)
+
+const testRetryHint = "retry in 30 seconds"
+
+func TestTransientMessage(t *testing.T) {
+	auth := NewKubeAuth(nil)
+	assert.Equal(t, storage.ErrBackendBusy.Error(), auth.transientMessage(replyBackendBusy))
+
+	auth.RetryHint = testRetryHint
+	assert.Equal(t, storage.ErrBackendBusy.Error()+"; "+testRetryHint, auth.transientMessage(replyBackendBusy))
+}
+
+func TestCommandPass_LockedOutAddressGetsRetryHint(t *testing.T) {
+	auth := NewKubeAuth(nil)
+	auth.RetryHint = testRetryHint
+	send := anonymousSessionWithAuth(t, auth)
+
+	for i := 0; i < bruteForceMaxFails; i++ {
+		auth.bruteForce.RecordFailure("someone", "127.0.0.1:1234")
+	}
+
+	require.True(t, strings.HasPrefix(send("USER guest"), "331"))
+	reply := send("PASS guest@example.com")
+	assert.True(t, strings.HasPrefix(reply, "421 "), reply)
+	assert.Contains(t, reply, testRetryHint)
+}
+
+func TestCommandBackpressure_SaturatedBackendGetsRetryHint(t *testing.T) {
+	basePath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(basePath, "held.txt"), []byte("data"), 0o644))
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, ftpv1.AddToScheme(scheme))
+	backend := &ftpv1.FilesystemBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "capped-backend", Namespace: "default"},
+		Spec:       ftpv1.FilesystemBackendSpec{BasePath: basePath, MaxConcurrentOperations: 1},
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(backend).Build()
+
+	user := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "guest", Namespace: "default"},
+		Spec: ftpv1.UserSpec{
+			Username:      "guest",
+			Type:          "anonymous",
+			Enabled:       true,
+			HomeDirectory: "/",
+			Backend:       ftpv1.BackendReference{Kind: "FilesystemBackend", Name: "capped-backend"},
+			Permissions:   ftpv1.UserPermissions{Read: true, List: true},
+		},
+	}
+
+	// An open download holds the backend's only slot
+	s, err := storage.NewStorage(context.Background(), user, kubeClient)
+	require.NoError(t, err)
+	_, reader, err := s.GetFile("/held.txt", 0)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+	require.True(t, storage.BackendSaturated(user))
+
+	auth := NewKubeAuth(nil)
+	auth.RetryHint = testRetryHint
+	send := anonymousSessionWithAuth(t, auth)
+	auth.userCache.Store("guest", user)
+
+	reply := send("LIST")
+	assert.True(t, strings.HasPrefix(reply, "450 "), reply)
+	assert.Contains(t, reply, testRetryHint)
+}
@@ -0,0 +1,105 @@
+package ftp
+
+import (
+	"errors"
+	"fmt"
+	pathpkg "path"
+	"strings"
+
+	"goftp.io/server/v2"
+)
+
+// errDirectoryUploadPath rejects uploads to a path naming a directory, which
+// would otherwise be cleaned into a file named after the directory
+var errDirectoryUploadPath = errors.New("file name must not end with /")
+
+// errParentNotFound rejects uploads into a missing directory on backends that
+// don't create parent directories
+var errParentNotFound = errors.New("parent directory does not exist")
+
+// uploadCommands take the target file name as their parameter
+var uploadCommands = []string{"STOR", "APPE"}
+
+// isDirectoryPath reports whether an upload target ends in a path separator
+func isDirectoryPath(path string) bool {
+	return strings.HasSuffix(path, "/")
+}
+
+// commandUploadPath wraps an upload command so targets ending in "/" are
+// refused with 553. goftp cleans the parameter before the driver sees it, so
+// the check has to happen on the raw command.
+type commandUploadPath struct {
+	next server.Command
+}
+
+func (cmd commandUploadPath) IsExtend() bool {
+	return cmd.next.IsExtend()
+}
+
+func (cmd commandUploadPath) RequireParam() bool {
+	return cmd.next.RequireParam()
+}
+
+func (cmd commandUploadPath) RequireAuth() bool {
+	return cmd.next.RequireAuth()
+}
+
+func (cmd commandUploadPath) Execute(sess *server.Session, param string) {
+	if isDirectoryPath(param) {
+		sess.WriteMessage(553, "Invalid file name: "+errDirectoryUploadPath.Error())
+		return
+	}
+	cmd.next.Execute(sess, param)
+}
+
+// checkUploadParent rejects an upload to path when the user's storage requires
+// an existing parent directory and it is missing. Chroot violations are left
+// for PutFile to report.
+func (driver *KubeDriver) checkUploadParent(ctx *server.Context, path string) error {
+	if err := driver.ensureUserInitializedWithContext(ctx); err != nil {
+		return nil
+	}
+	if !driver.storageImpl.Capabilities().RequireParents {
+		return nil
+	}
+	parent := pathpkg.Dir(path)
+	resolvedParent, err := driver.validateChrootPath(parent)
+	if err != nil {
+		return nil
+	}
+	if info, err := driver.storageImpl.Stat(resolvedParent); err != nil || !info.IsDir() {
+		return fmt.Errorf("%w: %s", errParentNotFound, parent)
+	}
+	return nil
+}
+
+// commandUploadParent wraps an upload command so uploads into a missing
+// directory are refused with 550 before the transfer starts. goftp replies 450
+// to any error PutFile returns, after the data connection is already open.
+type commandUploadParent struct {
+	next server.Command
+}
+
+func (cmd commandUploadParent) IsExtend() bool {
+	return cmd.next.IsExtend()
+}
+
+func (cmd commandUploadParent) RequireParam() bool {
+	return cmd.next.RequireParam()
+}
+
+func (cmd commandUploadParent) RequireAuth() bool {
+	return cmd.next.RequireAuth()
+}
+
+func (cmd commandUploadParent) Execute(sess *server.Session, param string) {
+	if driver, ok := sess.Options().Driver.(*KubeDriver); ok {
+		ctx := &server.Context{Sess: sess, Param: param}
+		if err := driver.checkUploadParent(ctx, sess.BuildPath(param)); err != nil {
+			getLogger().Info("Refusing upload into missing directory", "username", sess.LoginUser(), "path", param)
+			sess.WriteMessage(550, err.Error())
+			return
+		}
+	}
+	cmd.next.Execute(sess, param)
+}
@@ -0,0 +1,146 @@
+package ftp
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"goftp.io/server/v2"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/storage"
+)
+
+// anonymousSession starts a goftp server with KubeFTPd's commands, logs in as
+// an anonymous user and returns a function sending a command and returning
+// its reply line
+func anonymousSession(t *testing.T) func(command string) string {
+	return anonymousSessionWithAuth(t, NewKubeAuth(nil))
+}
+
+// anonymousSessionWithAuth is anonymousSession using the given auth settings
+func anonymousSessionWithAuth(t *testing.T, auth *KubeAuth) func(command string) string {
+	return anonymousSessionWithDriver(t, &KubeDriver{auth: auth})
+}
+
+// anonymousSessionWithDriver is anonymousSession serving the given driver
+func anonymousSessionWithDriver(t *testing.T, driver *KubeDriver) func(command string) string {
+	auth := driver.auth
+	auth.userCache.LoadOrStore("guest", &ftpv1.User{Spec: ftpv1.UserSpec{Username: "guest", Type: "anonymous", Enabled: true}})
+	ftpServer, err := server.NewServer(&server.Options{
+		Driver:   driver,
+		Auth:     auth,
+		Perm:     driver,
+		Logger:   &KubeLogger{auth: auth},
+		Commands: buildCommands(auth, nil),
+	})
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = ftpServer.Serve(listener) }()
+	t.Cleanup(func() { _ = ftpServer.Shutdown() })
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	reader := bufio.NewReader(conn)
+	readReply := func() string {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		return strings.TrimRight(line, "\r\n")
+	}
+	// An empty command only reads the next reply, such as the 226 after a transfer
+	send := func(command string) string {
+		if command != "" {
+			_, err := conn.Write([]byte(command + "\r\n"))
+			require.NoError(t, err)
+		}
+		return readReply()
+	}
+
+	require.True(t, strings.HasPrefix(readReply(), "220"))
+	require.True(t, strings.HasPrefix(send("USER guest"), "331"))
+	require.True(t, strings.HasPrefix(send("PASS guest@example.com"), "230"))
+	return send
+}
+
+func TestCommandUploadPath_RejectsDirectoryPath(t *testing.T) {
+	send := anonymousSession(t)
+
+	assert.True(t, strings.HasPrefix(send("STOR foo/"), "553 "))
+	assert.True(t, strings.HasPrefix(send("APPE /"), "553 "))
+}
+
+func TestCommandUploadParent_RejectsMissingParent(t *testing.T) {
+	mockStorage := &MockStorage{capabilities: storage.Capabilities{RequireParents: true}}
+	mockStorage.On("Stat", "/missing").Return((*MockFileInfo)(nil), os.ErrNotExist)
+	driver := &KubeDriver{
+		auth:        NewKubeAuth(nil),
+		user:        &ftpv1.User{Spec: ftpv1.UserSpec{Username: "guest", Type: "anonymous", Enabled: true}},
+		storageImpl: mockStorage,
+	}
+	send := anonymousSessionWithDriver(t, driver)
+
+	assert.True(t, strings.HasPrefix(send("STOR /missing/report.csv"), "550 "))
+	assert.True(t, strings.HasPrefix(send("APPE missing/report.csv"), "550 "))
+	mockStorage.AssertNotCalled(t, "PutFile", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestKubeDriver_CheckUploadParent(t *testing.T) {
+	mockStorage := &MockStorage{}
+	driver := &KubeDriver{
+		user:        &ftpv1.User{Spec: ftpv1.UserSpec{Username: "testuser", Enabled: true}},
+		storageImpl: mockStorage,
+	}
+
+	// Storage that creates parents is never asked
+	assert.NoError(t, driver.checkUploadParent(nil, "/missing/report.csv"))
+	mockStorage.AssertNotCalled(t, "Stat", mock.Anything)
+
+	mockStorage.capabilities.RequireParents = true
+	mockStorage.On("Stat", "/missing").Return((*MockFileInfo)(nil), os.ErrNotExist)
+	mockStorage.On("Stat", "/incoming").Return(&MockFileInfo{name: "incoming", isDir: true}, nil)
+	mockStorage.On("Stat", "/report.csv").Return(&MockFileInfo{name: "report.csv"}, nil)
+
+	assert.ErrorIs(t, driver.checkUploadParent(nil, "/missing/report.csv"), errParentNotFound)
+	assert.ErrorIs(t, driver.checkUploadParent(nil, "/report.csv/data.csv"), errParentNotFound)
+	assert.NoError(t, driver.checkUploadParent(nil, "/incoming/report.csv"))
+}
+
+func TestIsDirectoryPath(t *testing.T) {
+	assert.True(t, isDirectoryPath("foo/"))
+	assert.True(t, isDirectoryPath("/"))
+	assert.False(t, isDirectoryPath("foo/bar"))
+	assert.False(t, isDirectoryPath("foo"))
+}
+
+func TestKubeDriver_PutFile_DirectoryPath(t *testing.T) {
+	mockStorage := &MockStorage{}
+	driver := &KubeDriver{
+		authenticatedUser: "testuser",
+		user: &ftpv1.User{Spec: ftpv1.UserSpec{
+			Username:    "testuser",
+			Enabled:     true,
+			Permissions: ftpv1.UserPermissions{Write: true},
+		}},
+		storageImpl: mockStorage,
+	}
+
+	_, err := driver.PutFile(nil, "/foo/", strings.NewReader("data"), 0)
+	assert.ErrorIs(t, err, errDirectoryUploadPath)
+	mockStorage.AssertNotCalled(t, "PutFile", mock.Anything, mock.Anything, mock.Anything)
+
+	reader := strings.NewReader("data")
+	mockStorage.On("PutFile", "/foo/bar", reader, int64(0)).Return(int64(4), nil)
+	n, err := driver.PutFile(nil, "/foo/bar", reader, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+	mockStorage.AssertExpectations(t)
+}
@@ -0,0 +1,105 @@
+package ftp
+
+import (
+	"sync"
+	"time"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/metrics"
+	"github.com/rossigee/kubeftpd/internal/storage"
+)
+
+// usageTrackers holds the cached quota usage of each user's home, keyed by
+// fileCountKey, shared by all of the user's sessions
+var usageTrackers sync.Map
+
+// usageTracker caches a user's used bytes between background refreshes
+type usageTracker struct {
+	mu         sync.Mutex
+	used       int64
+	computedAt time.Time
+	refreshing bool
+}
+
+// usageTrackerFor returns the tracker for the user's home
+func usageTrackerFor(user *ftpv1.User) *usageTracker {
+	value, _ := usageTrackers.LoadOrStore(fileCountKey(user), &usageTracker{})
+	return value.(*usageTracker)
+}
+
+// quotaUsage returns the bytes the user stores. Without a refresh interval the
+// home is walked on every call. Otherwise the first call walks it and later
+// calls are served from the cache, which is refreshed in the background once
+// it is older than the interval.
+func (driver *KubeDriver) quotaUsage() (int64, error) {
+	username := driver.user.Spec.Username
+	if driver.usageRefreshInterval <= 0 {
+		used, err := driver.usedBytes()
+		if err == nil {
+			metrics.SetUserStorageUsed(username, used)
+		}
+		return used, err
+	}
+
+	tracker := usageTrackerFor(driver.user)
+	tracker.mu.Lock()
+	if tracker.computedAt.IsZero() {
+		tracker.mu.Unlock()
+		used, err := driver.usedBytes()
+		if err != nil {
+			return 0, err
+		}
+		tracker.store(username, used)
+		return used, nil
+	}
+
+	used := tracker.used
+	if time.Since(tracker.computedAt) >= driver.usageRefreshInterval && !tracker.refreshing {
+		tracker.refreshing = true
+		go tracker.refresh(username, driver.storageImpl, driver.homeRoot())
+	}
+	tracker.mu.Unlock()
+	return used, nil
+}
+
+// recordUsage adds bytes written by an upload to the cached usage so the cache
+// stays close to the truth between refreshes
+func (driver *KubeDriver) recordUsage(bytes int64) {
+	if driver.usageRefreshInterval <= 0 || driver.user == nil {
+		return
+	}
+	value, ok := usageTrackers.Load(fileCountKey(driver.user))
+	if !ok {
+		return
+	}
+	tracker := value.(*usageTracker)
+	tracker.mu.Lock()
+	if !tracker.computedAt.IsZero() {
+		tracker.used += bytes
+	}
+	tracker.mu.Unlock()
+}
+
+// refresh walks the home root and replaces the cached usage. The previous
+// value is kept if the walk fails.
+func (t *usageTracker) refresh(username string, s storage.Storage, root string) {
+	used, _, err := walkUsage(s, root)
+	if err != nil {
+		getLogger().Error(err, "Failed to refresh quota usage", "username", username)
+		t.mu.Lock()
+		t.refreshing = false
+		t.mu.Unlock()
+		return
+	}
+	t.store(username, used)
+}
+
+// store records freshly computed usage and publishes it as a metric
+func (t *usageTracker) store(username string, used int64) {
+	metrics.SetUserStorageUsed(username, used)
+	t.mu.Lock()
+	t.used = used
+	t.computedAt = time.Now()
+	t.refreshing = false
+	t.mu.Unlock()
+}
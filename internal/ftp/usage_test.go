@@ -0,0 +1,92 @@
+package ftp
+
+import (
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+// newUsageTestDriver returns a quota test driver whose home holds a single
+// file of the size currently stored in size
+func newUsageTestDriver(t *testing.T, quotaBytes int64, interval time.Duration, size *atomic.Int64) (*KubeDriver, *MockStorage) {
+	mockStorage := &MockStorage{}
+	mockStorage.On("ListDir", "/", mock.Anything).Run(func(args mock.Arguments) {
+		callback := args.Get(1).(func(os.FileInfo) error)
+		_ = callback(&MockFileInfo{name: "data.bin", size: size.Load()})
+	}).Return(nil)
+
+	driver, _ := newQuotaTestDriver(quotaBytes, true)
+	driver.user.Spec.Username = t.Name()
+	driver.storageImpl = mockStorage
+	driver.usageRefreshInterval = interval
+	t.Cleanup(func() { usageTrackers.Delete(fileCountKey(driver.user)) })
+	return driver, mockStorage
+}
+
+func TestKubeDriver_QuotaUsage_RefreshesInBackground(t *testing.T) {
+	var size atomic.Int64
+	size.Store(100)
+	driver, mockStorage := newUsageTestDriver(t, 0, 50*time.Millisecond, &size)
+
+	used, err := driver.quotaUsage()
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), used)
+	assert.Equal(t, float64(100), testutil.ToFloat64(metrics.UserStorageUsedBytes.WithLabelValues(t.Name())))
+
+	// Changes are not seen until the refresh interval has passed
+	size.Store(300)
+	used, err = driver.quotaUsage()
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), used)
+	mockStorage.AssertNumberOfCalls(t, "ListDir", 1)
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Eventually(t, func() bool {
+		used, err := driver.quotaUsage()
+		return err == nil && used == 300
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, float64(300), testutil.ToFloat64(metrics.UserStorageUsedBytes.WithLabelValues(t.Name())))
+}
+
+func TestKubeDriver_QuotaUsage_CheckQuotaUsesCache(t *testing.T) {
+	var size atomic.Int64
+	size.Store(100)
+	driver, mockStorage := newUsageTestDriver(t, 200, time.Hour, &size)
+
+	require.NoError(t, driver.checkQuota())
+
+	// The cached 100 bytes still leave room even though the backend now holds more
+	size.Store(250)
+	assert.NoError(t, driver.checkQuota())
+
+	// Uploads are added to the cached usage
+	driver.recordUsage(150)
+	assert.ErrorContains(t, driver.checkQuota(), "quota exceeded")
+
+	content, err := driver.quotaFileContent()
+	require.NoError(t, err)
+	assert.Equal(t, "used_bytes=250\nquota_bytes=200\navailable_bytes=0\n", content)
+	mockStorage.AssertNumberOfCalls(t, "ListDir", 1)
+}
+
+func TestKubeDriver_QuotaUsage_NoInterval(t *testing.T) {
+	var size atomic.Int64
+	size.Store(100)
+	driver, mockStorage := newUsageTestDriver(t, 0, 0, &size)
+
+	_, err := driver.quotaUsage()
+	require.NoError(t, err)
+	size.Store(200)
+	used, err := driver.quotaUsage()
+	require.NoError(t, err)
+	assert.Equal(t, int64(200), used)
+	mockStorage.AssertNumberOfCalls(t, "ListDir", 2)
+}
@@ -0,0 +1,104 @@
+package ftp
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+// userLogWriters holds one shared writer per LogDestination, since a
+// destination is usually written by many sessions
+var userLogWriters sync.Map
+
+// userLogWriter serialises log lines written to a destination
+type userLogWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *userLogWriter) writeLine(line string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, _ = fmt.Fprintln(w.w, line)
+}
+
+// openUserLogDestination opens the writer for a User's LogDestination
+func openUserLogDestination(destination string) (io.Writer, error) {
+	switch {
+	case destination == "syslog":
+		return syslog.New(syslog.LOG_INFO|syslog.LOG_DAEMON, "kubeftpd")
+	case strings.HasPrefix(destination, "syslog://"):
+		return syslog.Dial("udp", strings.TrimPrefix(destination, "syslog://"), syslog.LOG_INFO|syslog.LOG_DAEMON, "kubeftpd")
+	case strings.HasPrefix(destination, "/"):
+		return os.OpenFile(destination, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	default:
+		return nil, fmt.Errorf("unsupported log destination %q", destination)
+	}
+}
+
+// userLogger returns a JSON logger writing to destination. If the destination
+// cannot be opened the default logger is used so operations are still logged.
+func userLogger(destination string) logr.Logger {
+	value, ok := userLogWriters.Load(destination)
+	if !ok {
+		w, err := openUserLogDestination(destination)
+		if err != nil {
+			getLogger().Error(err, "Failed to open user log destination, using the server log", "destination", destination)
+			return getLogger()
+		}
+		var loaded bool
+		if value, loaded = userLogWriters.LoadOrStore(destination, &userLogWriter{w: w}); loaded {
+			if closer, ok := w.(io.Closer); ok {
+				_ = closer.Close()
+			}
+		}
+	}
+	writer := value.(*userLogWriter)
+	return funcr.NewJSON(writer.writeLine, funcr.Options{LogTimestamp: true}).WithName("ftp")
+}
+
+// operationLogger returns the logger for the session user's file operations:
+// the user's LogDestination when one is set, otherwise the server log. The
+// user's Tags are attached to every entry, and repeated errors are collapsed
+// when ErrorLogInterval is set.
+func (driver *KubeDriver) operationLogger() logr.Logger {
+	user := driver.user
+	if username := driver.getAuthenticatedUsername(); driver.auth != nil && username != "" && (user == nil || user.Spec.Username != username) {
+		user = driver.auth.cachedUser(username)
+	}
+	if user == nil {
+		return driver.withErrorLimit(getLogger())
+	}
+	logger := getLogger()
+	if user.Spec.LogDestination != "" {
+		logger = userLogger(user.Spec.LogDestination)
+	}
+	if len(user.Spec.Tags) > 0 {
+		logger = logger.WithValues("tags", user.Spec.Tags)
+	}
+	return driver.withErrorLimit(logger)
+}
+
+// publishUserTags exports the user's tags whose keys are in the server's
+// metricTagKeys safelist, keeping unbounded tags out of Prometheus
+func (driver *KubeDriver) publishUserTags(user *ftpv1.User) {
+	if len(driver.metricTagKeys) == 0 {
+		return
+	}
+	tags := make(map[string]string)
+	for _, key := range driver.metricTagKeys {
+		if value, ok := user.Spec.Tags[key]; ok {
+			tags[key] = value
+		}
+	}
+	metrics.SetUserTags(user.Spec.Username, tags)
+}
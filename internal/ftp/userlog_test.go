@@ -0,0 +1,113 @@
+package ftp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+// isDedicatedLogger reports whether a logger writes to a user log destination
+// rather than the server log
+func isDedicatedLogger(logger logr.Logger) bool {
+	_, ok := logger.GetSink().(funcr.Underlier)
+	return ok
+}
+
+func TestKubeDriver_UserLogDestination(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "alice.log")
+	alice := &ftpv1.User{Spec: ftpv1.UserSpec{Username: "alice", HomeDirectory: "/", LogDestination: logPath}}
+	bob := &ftpv1.User{Spec: ftpv1.UserSpec{Username: "bob", HomeDirectory: "/"}}
+
+	auth := NewKubeAuth(nil)
+	auth.cacheUser(alice)
+	auth.cacheUser(bob)
+	auth.setSessionUser("ftp-session-alice", "alice")
+	auth.setSessionUser("ftp-session-bob", "bob")
+
+	mockStorage := &MockStorage{}
+	mockStorage.On("Stat", "/report.csv").Return(&MockFileInfo{name: "report.csv", size: 4}, nil)
+
+	aliceDriver := &KubeDriver{auth: auth, sessionID: "ftp-session-alice", user: alice, storageImpl: mockStorage}
+	_, err := aliceDriver.Stat(nil, "/report.csv")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"msg":"FTP Stat operation"`)
+	assert.Contains(t, string(data), `"msg":"Stat operation successful"`)
+	assert.Contains(t, string(data), `"username":"alice"`)
+	assert.True(t, isDedicatedLogger(aliceDriver.operationLogger()), "alice's operations must not go to the server log")
+
+	// The destination applies before the driver has initialised the user
+	assert.True(t, isDedicatedLogger((&KubeDriver{auth: auth, sessionID: "ftp-session-alice"}).operationLogger()))
+
+	bobDriver := &KubeDriver{auth: auth, sessionID: "ftp-session-bob", user: bob, storageImpl: mockStorage}
+	_, err = bobDriver.Stat(nil, "/report.csv")
+	require.NoError(t, err)
+	assert.False(t, isDedicatedLogger(bobDriver.operationLogger()), "users without a destination use the server log")
+
+	data, err = os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), `"username":"bob"`)
+}
+
+func TestUserLogger_UnavailableDestination(t *testing.T) {
+	logger := userLogger(filepath.Join(t.TempDir(), "missing", "alice.log"))
+	assert.False(t, isDedicatedLogger(logger), "an unopenable destination falls back to the server log")
+
+	_, err := openUserLogDestination("relative/alice.log")
+	assert.ErrorContains(t, err, "unsupported log destination")
+}
+
+func TestKubeDriver_OperationLogTags(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "scanner.log")
+	user := &ftpv1.User{Spec: ftpv1.UserSpec{Username: "scanner", HomeDirectory: "/", LogDestination: logPath,
+		Tags: map[string]string{"department": "finance", "device": "scanner-3"}}}
+	auth := NewKubeAuth(nil)
+	auth.cacheUser(user)
+	auth.setSessionUser("ftp-session-scanner", "scanner")
+
+	mockStorage := &MockStorage{}
+	mockStorage.On("Stat", "/report.csv").Return(&MockFileInfo{name: "report.csv", size: 4}, nil)
+
+	driver := &KubeDriver{auth: auth, sessionID: "ftp-session-scanner", user: user, storageImpl: mockStorage}
+	_, err := driver.Stat(nil, "/report.csv")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry struct {
+			Tags map[string]string `json:"tags"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		assert.Equal(t, user.Spec.Tags, entry.Tags)
+	}
+}
+
+func TestKubeDriver_PublishUserTags(t *testing.T) {
+	user := &ftpv1.User{Spec: ftpv1.UserSpec{Username: "tagged",
+		Tags: map[string]string{"department": "finance", "serial": "SN-0042"}}}
+
+	driver := &KubeDriver{metricTagKeys: []string{"department", "site"}}
+	driver.publishUserTags(user)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.UserTagInfo.WithLabelValues("tagged", "department", "finance")))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.UserTagInfo), "tags outside the safelist are not exported")
+
+	user.Spec.Tags["department"] = "sales"
+	driver.publishUserTags(user)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.UserTagInfo.WithLabelValues("tagged", "department", "sales")))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.UserTagInfo), "a changed tag replaces its old value")
+}
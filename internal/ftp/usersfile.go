@@ -0,0 +1,112 @@
+package ftp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+// usersFilePollInterval is how often the users file is checked for changes
+const usersFilePollInterval = 2 * time.Second
+
+// LoadUsersFile replaces the cached users with those defined in UsersFile. A
+// file that cannot be read or parsed leaves the current users in place.
+func (auth *KubeAuth) LoadUsersFile() error {
+	users, err := readUsersFile(auth.UsersFile)
+	if err != nil {
+		return err
+	}
+	auth.replaceCachedUsers(users)
+	getLogger().Info("Loaded users from file", "path", auth.UsersFile, "user_count", len(users))
+	return nil
+}
+
+// readUsersFile parses a YAML stream of User manifests separated by "---"
+func readUsersFile(path string) ([]ftpv1.User, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open users file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var users []ftpv1.User
+	decoder := utilyaml.NewYAMLOrJSONDecoder(file, 4096)
+	for document := 1; ; document++ {
+		var user ftpv1.User
+		if err := decoder.Decode(&user); err != nil {
+			if errors.Is(err, io.EOF) {
+				return users, nil
+			}
+			return nil, fmt.Errorf("%s: document %d: %w", path, document, err)
+		}
+		if user.Kind == "" && user.Spec.Username == "" {
+			// Empty document, e.g. a trailing "---"
+			continue
+		}
+		if user.Kind != "User" {
+			return nil, fmt.Errorf("%s: document %d: kind %q is not User", path, document, user.Kind)
+		}
+		if user.Spec.Username == "" {
+			return nil, fmt.Errorf("%s: document %d: spec.username is required", path, document)
+		}
+		users = append(users, user)
+	}
+}
+
+// usersFileVersion identifies the contents of the users file by modification
+// time and size
+type usersFileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+// statUsersFile returns the current version of the users file, or the zero
+// version if it can't be statted
+func statUsersFile(path string) usersFileVersion {
+	info, err := os.Stat(path)
+	if err != nil {
+		return usersFileVersion{}
+	}
+	return usersFileVersion{modTime: info.ModTime(), size: info.Size()}
+}
+
+// watchUsersFile reloads UsersFile whenever its modification time or size
+// differs from the last version seen, starting from since and checking every
+// interval until ctx is cancelled. Mounted ConfigMaps and Secrets are followed
+// through their symlinks. since must be taken before the caller's goroutine
+// starts, or an edit made while it starts becomes the baseline.
+func (auth *KubeAuth) watchUsersFile(ctx context.Context, interval time.Duration, since usersFileVersion) {
+	logger := getLogger()
+	last := since
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Stopping users file watch")
+			return
+		case <-ticker.C:
+			info, err := os.Stat(auth.UsersFile)
+			if err != nil {
+				continue
+			}
+			current := usersFileVersion{modTime: info.ModTime(), size: info.Size()}
+			if current.modTime.Equal(last.modTime) && current.size == last.size {
+				continue
+			}
+			last = current
+			if err := auth.LoadUsersFile(); err != nil {
+				logger.Error(err, "Failed to reload users file, keeping the previous users", "path", auth.UsersFile)
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package ftp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testUsersFile = `apiVersion: ftp.golder.org/v1
+kind: User
+metadata:
+  name: scanner
+spec:
+  username: scanner
+  password: scan-pass
+  enabled: true
+  homeDirectory: /scans
+  loginAliases:
+    - copier
+  backend:
+    kind: FilesystemBackend
+    name: local-storage
+---
+apiVersion: ftp.golder.org/v1
+kind: User
+metadata:
+  name: retired
+spec:
+  username: retired
+  password: old-pass
+  enabled: false
+  homeDirectory: /retired
+  backend:
+    kind: FilesystemBackend
+    name: local-storage
+---
+`
+
+// usersFileAuth writes contents to a users file and returns an authenticator
+// serving it without a Kubernetes client
+func usersFileAuth(t *testing.T, contents string) (*KubeAuth, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "users.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	auth := NewKubeAuth(nil)
+	auth.UsersFile = path
+	return auth, path
+}
+
+func TestLoadUsersFile_Authenticates(t *testing.T) {
+	auth, _ := usersFileAuth(t, testUsersFile)
+	require.NoError(t, auth.LoadUsersFile())
+	assert.Equal(t, 2, auth.CachedUserCount())
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		want     bool
+	}{
+		{name: "valid password", username: "scanner", password: "scan-pass", want: true},
+		{name: "login alias", username: "copier", password: "scan-pass", want: true},
+		{name: "wrong password", username: "scanner", password: "guess", want: false},
+		{name: "disabled user", username: "retired", password: "old-pass", want: false},
+		{name: "user not in the file", username: "stranger", password: "scan-pass", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticated, err := auth.CheckPasswd(nil, tt.username, tt.password)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, authenticated)
+		})
+	}
+
+	user := auth.GetUser(context.Background(), "scanner")
+	require.NotNil(t, user)
+	assert.Equal(t, "/scans", user.Spec.HomeDirectory)
+	assert.Equal(t, "FilesystemBackend", user.Spec.Backend.Kind)
+}
+
+func TestLoadUsersFile_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		wantErr  string
+	}{
+		{name: "not YAML", contents: "spec: [unterminated", wantErr: "document 1"},
+		{name: "wrong kind", contents: "apiVersion: v1\nkind: Secret\nmetadata:\n  name: creds\n", wantErr: `kind "Secret" is not User`},
+		{name: "missing username", contents: "apiVersion: ftp.golder.org/v1\nkind: User\nmetadata:\n  name: nameless\nspec:\n  password: x\n", wantErr: "spec.username is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth, _ := usersFileAuth(t, tt.contents)
+			err := auth.LoadUsersFile()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		auth := NewKubeAuth(nil)
+		auth.UsersFile = filepath.Join(t.TempDir(), "absent.yaml")
+		assert.Error(t, auth.LoadUsersFile())
+	})
+}
+
+func TestWatchUsersFile_ReloadsOnChange(t *testing.T) {
+	auth, path := usersFileAuth(t, testUsersFile)
+	since := statUsersFile(path)
+	require.NoError(t, auth.LoadUsersFile())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		auth.watchUsersFile(ctx, 10*time.Millisecond, since)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	// Change a password, enable a user and drop the login alias
+	edited := `apiVersion: ftp.golder.org/v1
+kind: User
+metadata:
+  name: scanner
+spec:
+  username: scanner
+  password: rotated-pass
+  enabled: true
+  homeDirectory: /scans
+---
+apiVersion: ftp.golder.org/v1
+kind: User
+metadata:
+  name: retired
+spec:
+  username: retired
+  password: old-pass
+  enabled: true
+  homeDirectory: /retired
+`
+	require.NoError(t, os.WriteFile(path, []byte(edited), 0o600))
+
+	require.Eventually(t, func() bool {
+		user := auth.GetUser(context.Background(), "scanner")
+		return user != nil && user.Spec.Password == "rotated-pass"
+	}, 5*time.Second, 10*time.Millisecond, "edited file should be reloaded")
+
+	authenticated, err := auth.CheckPasswd(nil, "scanner", "rotated-pass")
+	require.NoError(t, err)
+	assert.True(t, authenticated, "edited password should be accepted")
+	authenticated, err = auth.CheckPasswd(nil, "scanner", "scan-pass")
+	require.NoError(t, err)
+	assert.False(t, authenticated, "previous password should be rejected")
+	authenticated, err = auth.CheckPasswd(nil, "retired", "old-pass")
+	require.NoError(t, err)
+	assert.True(t, authenticated, "enabled user should log in")
+	assert.Nil(t, auth.GetUser(context.Background(), "copier"), "removed alias should not resolve")
+
+	// A broken edit keeps the users from the last good file
+	require.NoError(t, os.WriteFile(path, []byte("spec: [unterminated and longer than before"), 0o600))
+	time.Sleep(100 * time.Millisecond)
+	authenticated, err = auth.CheckPasswd(nil, "scanner", "rotated-pass")
+	require.NoError(t, err)
+	assert.True(t, authenticated)
+}
@@ -0,0 +1,178 @@
+package ftp
+
+import (
+	"os"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"goftp.io/server/v2"
+)
+
+// legacyPathCommands take a path that is decoded as Latin-1 in sessions that
+// sent OPTS UTF8 OFF
+var legacyPathCommands = []string{
+	"CWD", "XCWD", "MKD", "XMKD", "RMD", "XRMD", "DELE", "RNFR", "RNTO",
+	"RETR", "STOR", "APPE", "SIZE", "MDTM", "LIST", "NLST", "MLSD", "MLST", "STAT",
+}
+
+// setSessionUTF8 records whether a session's paths are UTF-8, the default, or
+// Latin-1 after OPTS UTF8 OFF
+func (auth *KubeAuth) setSessionUTF8(sessionID string, enabled bool) {
+	if sessionID == "" {
+		return
+	}
+	if enabled {
+		auth.sessionLegacyNames.Delete(sessionID)
+	} else {
+		auth.sessionLegacyNames.Store(sessionID, struct{}{})
+	}
+}
+
+// sessionUTF8 reports whether the session's paths are UTF-8
+func (auth *KubeAuth) sessionUTF8(sessionID string) bool {
+	if sessionID == "" {
+		return true
+	}
+	_, legacy := auth.sessionLegacyNames.Load(sessionID)
+	return !legacy
+}
+
+// latin1ToUTF8 decodes s, whose bytes are Latin-1 characters
+func latin1ToUTF8(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		b.WriteRune(rune(s[i]))
+	}
+	return b.String()
+}
+
+// utf8ToLatin1 encodes s as Latin-1, replacing characters it cannot represent
+// with '?'
+func utf8ToLatin1(s string) string {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r > 0xff || r == utf8.RuneError {
+			r = '?'
+		}
+		b = append(b, byte(r))
+	}
+	return string(b)
+}
+
+// latin1FileInfo reports its name encoded as Latin-1
+type latin1FileInfo struct {
+	os.FileInfo
+}
+
+func (info *latin1FileInfo) Name() string {
+	return utf8ToLatin1(info.FileInfo.Name())
+}
+
+func (info *latin1FileInfo) Unwrap() os.FileInfo {
+	return info.FileInfo
+}
+
+// encodeListNames makes listings of dir by sessions that sent OPTS UTF8 OFF
+// show names in Latin-1. goftp's STAT looks up each entry's mode, owner and
+// group by the name it was given, so the entry's UTF-8 path is recorded for
+// the Perm methods while the callback runs.
+func (driver *KubeDriver) encodeListNames(ctx *server.Context, dir string, callback func(os.FileInfo) error) func(os.FileInfo) error {
+	if driver.auth == nil {
+		return callback
+	}
+	sessionID := driver.auth.getSessionID(ctx)
+	if sessionID == "" {
+		sessionID = driver.sessionID
+	}
+	if driver.auth.sessionUTF8(sessionID) {
+		return callback
+	}
+	return func(info os.FileInfo) error {
+		encoded := &latin1FileInfo{FileInfo: info}
+		if encoded.Name() == info.Name() {
+			return callback(info)
+		}
+		listedPath := path.Join(dir, encoded.Name())
+		driver.auth.legacyListNames.Store(listedPath, path.Join(dir, info.Name()))
+		defer driver.auth.legacyListNames.Delete(listedPath)
+		return callback(encoded)
+	}
+}
+
+// storedListPath returns the UTF-8 path of an entry goftp is listing under its
+// Latin-1 name, or p unchanged
+func (auth *KubeAuth) storedListPath(p string) string {
+	if auth == nil {
+		return p
+	}
+	if stored, ok := auth.legacyListNames.Load(p); ok {
+		return stored.(string)
+	}
+	return p
+}
+
+// commandOpts answers OPTS UTF8 ON and OFF, tracking the choice per session.
+// Other options are passed to next.
+type commandOpts struct {
+	auth *KubeAuth
+	next server.Command
+}
+
+func (cmd commandOpts) IsExtend() bool {
+	return cmd.next.IsExtend()
+}
+
+func (cmd commandOpts) RequireParam() bool {
+	return cmd.next.RequireParam()
+}
+
+func (cmd commandOpts) RequireAuth() bool {
+	return cmd.next.RequireAuth()
+}
+
+func (cmd commandOpts) Execute(sess *server.Session, param string) {
+	parts := strings.Fields(param)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "UTF8") {
+		cmd.next.Execute(sess, param)
+		return
+	}
+	sessionID := sessionIDForAddr(sess.RemoteAddr())
+	switch strings.ToUpper(parts[1]) {
+	case "ON":
+		cmd.auth.setSessionUTF8(sessionID, true)
+		sess.WriteMessage(200, "UTF8 mode enabled")
+	case "OFF":
+		cmd.auth.setSessionUTF8(sessionID, false)
+		sess.WriteMessage(200, "UTF8 mode disabled")
+	default:
+		sess.WriteMessage(501, "OPTS UTF8 takes ON or OFF")
+	}
+}
+
+// commandLegacyPath decodes the path of a command from Latin-1 in sessions
+// that sent OPTS UTF8 OFF, so names are stored as UTF-8 either way
+type commandLegacyPath struct {
+	auth *KubeAuth
+	next server.Command
+}
+
+func (cmd commandLegacyPath) IsExtend() bool {
+	return cmd.next.IsExtend()
+}
+
+func (cmd commandLegacyPath) RequireParam() bool {
+	return cmd.next.RequireParam()
+}
+
+func (cmd commandLegacyPath) RequireAuth() bool {
+	return cmd.next.RequireAuth()
+}
+
+func (cmd commandLegacyPath) Execute(sess *server.Session, param string) {
+	if !cmd.auth.sessionUTF8(sessionIDForAddr(sess.RemoteAddr())) {
+		param = latin1ToUTF8(param)
+	}
+	cmd.next.Execute(sess, param)
+}
@@ -0,0 +1,90 @@
+package ftp
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+func TestLatin1Conversion(t *testing.T) {
+	assert.Equal(t, "café.txt", latin1ToUTF8("caf\xe9.txt"))
+	assert.Equal(t, "caf\xe9.txt", utf8ToLatin1("café.txt"))
+	assert.Equal(t, "plain.txt", latin1ToUTF8("plain.txt"))
+	assert.Equal(t, "?.txt", utf8ToLatin1("日.txt"), "characters outside Latin-1 are replaced")
+}
+
+// utf8Session logs in to a session with AllowUTF8Off whose storage has one
+// file named café.txt
+func utf8Session(t *testing.T) (func(command string) string, *MockStorage) {
+	user := &ftpv1.User{Spec: ftpv1.UserSpec{
+		Username:    "guest",
+		Type:        "anonymous",
+		Enabled:     true,
+		Permissions: ftpv1.UserPermissions{Read: true, List: true},
+	}}
+	auth := NewKubeAuth(nil)
+	auth.AllowUTF8Off = true
+	auth.userCache.Store("guest", user)
+	mockStorage := &MockStorage{}
+	mockStorage.On("Stat", "/café.txt").Return(&MockFileInfo{name: "café.txt", size: 5}, nil)
+	mockStorage.On("Stat", "/").Return(&MockFileInfo{name: "/", isDir: true}, nil)
+	mockStorage.On("Stat", mock.Anything).Return((*MockFileInfo)(nil), os.ErrNotExist)
+	mockStorage.On("ListDir", "/", mock.Anything).Run(func(args mock.Arguments) {
+		_ = args.Get(1).(func(os.FileInfo) error)(&MockFileInfo{name: "café.txt", size: 5})
+	}).Return(nil)
+	return anonymousSessionWithDriver(t, &KubeDriver{auth: auth, user: user, storageImpl: mockStorage}), mockStorage
+}
+
+// listing returns the LIST output of the session's home directory
+func listing(t *testing.T, send func(string) string) string {
+	port := passivePort(t, send("PASV"))
+	data, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	require.NoError(t, err)
+	defer func() { _ = data.Close() }()
+	require.True(t, strings.HasPrefix(send("LIST"), "150 "))
+	require.NoError(t, data.SetReadDeadline(time.Now().Add(5*time.Second)))
+	output, err := io.ReadAll(data)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(send(""), "226 "))
+	return string(output)
+}
+
+func TestOptsUTF8_Default(t *testing.T) {
+	send, _ := utf8Session(t)
+	assert.Equal(t, "200 UTF8 mode enabled", send("OPTS UTF8 ON"))
+
+	assert.Equal(t, "213 5", send("SIZE café.txt"))
+	assert.True(t, strings.HasPrefix(send("SIZE caf\xe9.txt"), "450"),
+		"Latin-1 bytes are not a UTF-8 name")
+	assert.Contains(t, listing(t, send), "café.txt")
+}
+
+func TestOptsUTF8_Off(t *testing.T) {
+	send, _ := utf8Session(t)
+	assert.Equal(t, "200 UTF8 mode disabled", send("OPTS UTF8 OFF"))
+
+	// Paths are decoded from Latin-1 and listings encoded to it
+	assert.Equal(t, "213 5", send("SIZE caf\xe9.txt"))
+	output := listing(t, send)
+	assert.Contains(t, output, "caf\xe9.txt")
+	assert.NotContains(t, output, "café.txt")
+
+	// Turning UTF8 back on restores UTF-8 names
+	assert.Equal(t, "200 UTF8 mode enabled", send("OPTS UTF8 ON"))
+	assert.Equal(t, "213 5", send("SIZE café.txt"))
+}
+
+func TestOptsUTF8_OffRefusedByDefault(t *testing.T) {
+	send := anonymousSession(t)
+	assert.True(t, strings.HasPrefix(send("OPTS UTF8 OFF"), "550"))
+}
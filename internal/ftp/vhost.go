@@ -0,0 +1,124 @@
+package ftp
+
+import (
+	"fmt"
+	"strings"
+
+	"goftp.io/server/v2"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+// VirtualHost is a profile selected by the FTP HOST command (RFC 7151). It
+// provides a default backend and/or home directory for users logging in under
+// it. A user's own backend and home directory are never replaced, so HOST
+// cannot reach another tenant's storage.
+type VirtualHost struct {
+	// Backend is used for users without a backend of their own
+	Backend ftpv1.BackendReference
+	// HomeDirectory is used for users without a home directory of their own
+	HomeDirectory string
+}
+
+// ParseVirtualHosts parses a comma-separated list of virtual host profiles of
+// the form host=Kind/[namespace/]name[:/home]. An empty value returns nil.
+func ParseVirtualHosts(value string) (map[string]VirtualHost, error) {
+	var hosts map[string]VirtualHost
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, profile, ok := strings.Cut(entry, "=")
+		host = strings.ToLower(strings.TrimSpace(host))
+		if !ok || host == "" {
+			return nil, fmt.Errorf("invalid virtual host %q: expected host=Kind/name[:/home]", entry)
+		}
+
+		var vhost VirtualHost
+		backendRef, home, hasHome := strings.Cut(strings.TrimSpace(profile), ":")
+		if hasHome {
+			if !strings.HasPrefix(home, "/") {
+				return nil, fmt.Errorf("invalid virtual host %q: home directory must be absolute", entry)
+			}
+			vhost.HomeDirectory = home
+		}
+
+		parts := strings.Split(backendRef, "/")
+		switch len(parts) {
+		case 2:
+			vhost.Backend = ftpv1.BackendReference{Kind: parts[0], Name: parts[1]}
+		case 3:
+			namespace := parts[1]
+			vhost.Backend = ftpv1.BackendReference{Kind: parts[0], Namespace: &namespace, Name: parts[2]}
+		default:
+			return nil, fmt.Errorf("invalid virtual host %q: backend must be Kind/name or Kind/namespace/name", entry)
+		}
+		if vhost.Backend.Kind == "" || vhost.Backend.Name == "" {
+			return nil, fmt.Errorf("invalid virtual host %q: backend kind and name are required", entry)
+		}
+
+		if hosts == nil {
+			hosts = make(map[string]VirtualHost)
+		}
+		if _, exists := hosts[host]; exists {
+			return nil, fmt.Errorf("duplicate virtual host %q", host)
+		}
+		hosts[host] = vhost
+	}
+	return hosts, nil
+}
+
+// applyTo returns a copy of the user with the virtual host's defaults filling
+// the backend and home directory the user leaves unset
+func (vhost VirtualHost) applyTo(user *ftpv1.User) *ftpv1.User {
+	user = user.DeepCopy()
+	if user.Spec.Backend.Kind == "" && user.Spec.Backend.Name == "" {
+		user.Spec.Backend = *vhost.Backend.DeepCopy()
+	}
+	if user.Spec.HomeDirectory == "" {
+		user.Spec.HomeDirectory = vhost.HomeDirectory
+	}
+	return user
+}
+
+// commandHost responds to the HOST FTP command by selecting a virtual host
+// profile for the session. It must be sent before USER.
+type commandHost struct {
+	auth  *KubeAuth
+	hosts map[string]VirtualHost
+}
+
+func (cmd commandHost) IsExtend() bool {
+	return true
+}
+
+func (cmd commandHost) RequireParam() bool {
+	return true
+}
+
+func (cmd commandHost) RequireAuth() bool {
+	return false
+}
+
+func (cmd commandHost) Execute(sess *server.Session, param string) {
+	if sess.IsLogin() {
+		sess.WriteMessage(503, "HOST not allowed after login")
+		return
+	}
+
+	host := normalizeHostParam(param)
+	if _, ok := cmd.hosts[host]; !ok {
+		sess.WriteMessage(504, fmt.Sprintf("Unknown virtual host %s", host))
+		return
+	}
+
+	cmd.auth.setSessionHost(sessionIDForAddr(sess.RemoteAddr()), host)
+	sess.WriteMessage(220, fmt.Sprintf("Host %s accepted", host))
+}
+
+// normalizeHostParam lowercases a HOST argument and strips IP literal brackets
+func normalizeHostParam(param string) string {
+	host := strings.ToLower(strings.TrimSpace(param))
+	return strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+}
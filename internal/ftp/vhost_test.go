@@ -0,0 +1,152 @@
+package ftp
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+func TestParseVirtualHosts(t *testing.T) {
+	shared := "shared"
+	tests := []struct {
+		name    string
+		value   string
+		want    map[string]VirtualHost
+		wantErr bool
+	}{
+		{
+			name:  "empty",
+			value: "",
+			want:  nil,
+		},
+		{
+			name:  "backend only",
+			value: "Files.Example.com=MinioBackend/archive",
+			want: map[string]VirtualHost{
+				"files.example.com": {Backend: ftpv1.BackendReference{Kind: "MinioBackend", Name: "archive"}},
+			},
+		},
+		{
+			name:  "namespaced backend with home",
+			value: "docs.example.com=WebDavBackend/shared/docs:/docs, files.example.com=FilesystemBackend/local",
+			want: map[string]VirtualHost{
+				"docs.example.com": {
+					Backend:       ftpv1.BackendReference{Kind: "WebDavBackend", Namespace: &shared, Name: "docs"},
+					HomeDirectory: "/docs",
+				},
+				"files.example.com": {Backend: ftpv1.BackendReference{Kind: "FilesystemBackend", Name: "local"}},
+			},
+		},
+		{name: "missing separator", value: "files.example.com", wantErr: true},
+		{name: "missing backend name", value: "files.example.com=MinioBackend", wantErr: true},
+		{name: "relative home", value: "files.example.com=MinioBackend/archive:archive", wantErr: true},
+		{name: "duplicate host", value: "a.example.com=MinioBackend/x,A.example.com=MinioBackend/y", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseVirtualHosts(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNormalizeHostParam(t *testing.T) {
+	assert.Equal(t, "files.example.com", normalizeHostParam(" Files.Example.COM "))
+	assert.Equal(t, "::1", normalizeHostParam("[::1]"))
+}
+
+func TestKubeDriver_VirtualHostRouting(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, ftpv1.AddToScheme(scheme))
+
+	newBackend := func(name string) *ftpv1.FilesystemBackend {
+		return &ftpv1.FilesystemBackend{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       ftpv1.FilesystemBackendSpec{BasePath: t.TempDir()},
+		}
+	}
+
+	// A users-file user may leave its backend and home to the virtual host
+	defaultsUser := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "defaults", Namespace: "default"},
+		Spec:       ftpv1.UserSpec{Username: "defaults", Enabled: true},
+	}
+	tenantUser := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant", Namespace: "default"},
+		Spec: ftpv1.UserSpec{
+			Username:      "tenant",
+			Enabled:       true,
+			Backend:       ftpv1.BackendReference{Kind: "FilesystemBackend", Name: "tenant-fs"},
+			HomeDirectory: "/home/tenant",
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(newBackend("tenant-fs"), newBackend("fs-a"), newBackend("fs-b")).
+		Build()
+
+	auth := NewKubeAuth(fakeClient)
+	auth.userCache.Store("defaults", defaultsUser)
+	auth.userCache.Store("tenant", tenantUser)
+
+	hosts, err := ParseVirtualHosts("a.example.com=FilesystemBackend/fs-a:/a,b.example.com=FilesystemBackend/fs-b:/b")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		username    string
+		host        string
+		wantBackend string
+		wantHome    string
+	}{
+		{name: "no HOST uses user backend", username: "tenant", host: "", wantBackend: "tenant-fs", wantHome: "/home/tenant"},
+		{name: "first host", username: "defaults", host: "a.example.com", wantBackend: "fs-a", wantHome: "/a"},
+		{name: "second host", username: "defaults", host: "b.example.com", wantBackend: "fs-b", wantHome: "/b"},
+		{name: "HOST keeps user backend", username: "tenant", host: "a.example.com", wantBackend: "tenant-fs", wantHome: "/home/tenant"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessionID := "ftp-session-" + tt.name
+			if tt.host != "" {
+				auth.setSessionHost(sessionID, tt.host)
+			}
+
+			driver := &KubeDriver{
+				client:            fakeClient,
+				auth:              auth,
+				virtualHosts:      hosts,
+				authenticatedUser: tt.username,
+				sessionID:         sessionID,
+				sessionCtx:        context.Background(),
+			}
+			require.NoError(t, driver.ensureUserInitialized())
+			assert.Equal(t, tt.wantBackend, driver.user.Spec.Backend.Name)
+			assert.Equal(t, tt.wantHome, driver.user.Spec.HomeDirectory)
+
+			serverConn, clientConn := net.Pipe()
+			defer func() { _ = clientConn.Close() }()
+			require.NoError(t, (&sessionConn{Conn: serverConn, auth: auth, sessionID: sessionID}).Close())
+			assert.Empty(t, auth.GetSessionHost(sessionID))
+		})
+	}
+
+	// The cached user must not be modified by a virtual host profile
+	cached := auth.GetUser(context.Background(), "defaults")
+	assert.Empty(t, cached.Spec.Backend.Name)
+}
@@ -0,0 +1,86 @@
+package ftp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+// chrootViolationWindow is how long a chroot violation counts toward
+// AutoDisableOnViolations. The count starts over once the first violation in
+// it is older, so occasional mistakes spread over months never add up.
+var chrootViolationWindow = time.Hour
+
+// violationCount is a user's chroot violations since the window started
+type violationCount struct {
+	mu    sync.Mutex
+	count int
+	since time.Time
+}
+
+// recordChrootViolation counts a chroot violation by user and disables the
+// User once AutoDisableOnViolations is reached within chrootViolationWindow.
+// It reports whether the user was disabled.
+func (auth *KubeAuth) recordChrootViolation(ctx context.Context, user *ftpv1.User) bool {
+	if auth.AutoDisableOnViolations <= 0 || user == nil {
+		return false
+	}
+	key := user.Namespace + "/" + user.Name
+	if auth.countChrootViolation(key, time.Now()) != auth.AutoDisableOnViolations {
+		return false
+	}
+	// Start over so a re-enabled user gets the full threshold again
+	auth.chrootViolations.Delete(key)
+
+	logger := getLogger()
+	if err := auth.disableUser(ctx, user); err != nil {
+		logger.Error(err, "Failed to disable user after repeated chroot violations",
+			"username", user.Spec.Username, "user", key, "violations", auth.AutoDisableOnViolations)
+		return false
+	}
+	logger.Info("Disabled user after repeated chroot violations",
+		"username", user.Spec.Username, "user", key, "violations", auth.AutoDisableOnViolations)
+	if auth.ViolationEvents != nil {
+		auth.ViolationEvents.Eventf(user, nil, corev1.EventTypeWarning, "UserAutoDisabled", "Disable",
+			"Disabled after %d attempts to access paths outside the home directory", auth.AutoDisableOnViolations)
+	}
+	return true
+}
+
+// countChrootViolation adds a violation at now to the user's count, starting
+// a new window when the current one has expired, and returns the count
+func (auth *KubeAuth) countChrootViolation(key string, now time.Time) int {
+	value, _ := auth.chrootViolations.LoadOrStore(key, &violationCount{since: now})
+	counter := value.(*violationCount)
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+	if now.Sub(counter.since) > chrootViolationWindow {
+		counter.count = 0
+		counter.since = now
+	}
+	counter.count++
+	return counter.count
+}
+
+// disableUser sets enabled to false on the User and drops it from the cache
+// so new logins see the change without waiting for the cache refresh
+func (auth *KubeAuth) disableUser(ctx context.Context, user *ftpv1.User) error {
+	current := &ftpv1.User{}
+	if err := auth.client.Get(ctx, client.ObjectKey{Namespace: user.Namespace, Name: user.Name}, current); err != nil {
+		return fmt.Errorf("failed to get user %s/%s: %w", user.Namespace, user.Name, err)
+	}
+	if current.Spec.Enabled {
+		current.Spec.Enabled = false
+		if err := auth.client.Update(ctx, current); err != nil {
+			return fmt.Errorf("failed to update user %s/%s: %w", user.Namespace, user.Name, err)
+		}
+	}
+	auth.evictUser(user.Spec.Username)
+	return nil
+}
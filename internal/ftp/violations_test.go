@@ -0,0 +1,117 @@
+package ftp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+func newViolationTestDriver(t *testing.T, threshold int) (*KubeDriver, client.Client, *events.FakeRecorder) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, ftpv1.AddToScheme(scheme))
+
+	user := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "prober", Namespace: "default"},
+		Spec: ftpv1.UserSpec{
+			Username:      "prober",
+			Enabled:       true,
+			HomeDirectory: "/home/prober",
+			Chroot:        true,
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(user).Build()
+
+	recorder := events.NewFakeRecorder(10)
+	auth := NewKubeAuth(fakeClient)
+	auth.AutoDisableOnViolations = threshold
+	auth.ViolationEvents = recorder
+	auth.cacheUser(user.DeepCopy())
+
+	driver := &KubeDriver{
+		auth:              auth,
+		authenticatedUser: "prober",
+		user:              user.DeepCopy(),
+	}
+	return driver, fakeClient, recorder
+}
+
+func storedUserEnabled(t *testing.T, c client.Client) bool {
+	user := &ftpv1.User{}
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "prober"}, user))
+	return user.Spec.Enabled
+}
+
+func TestChrootViolations_DisableUserAtThreshold(t *testing.T) {
+	driver, fakeClient, recorder := newViolationTestDriver(t, 3)
+
+	for i := 0; i < 2; i++ {
+		_, err := driver.validateChrootPath("../other/secret.txt")
+		require.Error(t, err)
+	}
+	assert.True(t, storedUserEnabled(t, fakeClient), "user should stay enabled below the threshold")
+	assert.Empty(t, recorder.Events)
+
+	// Paths inside the home directory are not violations
+	_, err := driver.validateChrootPath("/reports/q1.csv")
+	require.NoError(t, err)
+	assert.True(t, storedUserEnabled(t, fakeClient))
+
+	_, err = driver.validateChrootPath("../../etc/passwd")
+	require.Error(t, err)
+	assert.False(t, storedUserEnabled(t, fakeClient), "user should be disabled at the threshold")
+
+	select {
+	case event := <-recorder.Events:
+		assert.Equal(t, "Warning UserAutoDisabled Disabled after 3 attempts to access paths outside the home directory", event)
+	default:
+		t.Fatal("expected a UserAutoDisabled event to be recorded")
+	}
+
+	// The disabled user is dropped from the cache so new logins reload it
+	_, cached := driver.auth.userCache.Load("prober")
+	assert.False(t, cached)
+}
+
+func TestChrootViolations_ZeroThresholdNeverDisables(t *testing.T) {
+	driver, fakeClient, recorder := newViolationTestDriver(t, 0)
+
+	for i := 0; i < 20; i++ {
+		_, err := driver.validateChrootPath("../other/secret.txt")
+		require.Error(t, err)
+	}
+	assert.True(t, storedUserEnabled(t, fakeClient))
+	assert.Empty(t, recorder.Events)
+}
+
+func TestChrootViolations_CountsPerUser(t *testing.T) {
+	driver, fakeClient, _ := newViolationTestDriver(t, 2)
+
+	other := &ftpv1.User{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}}
+	assert.False(t, driver.auth.recordChrootViolation(context.Background(), other))
+
+	_, err := driver.validateChrootPath("../other/secret.txt")
+	require.Error(t, err)
+	assert.True(t, storedUserEnabled(t, fakeClient), "another user's violation must not count")
+}
+
+func TestChrootViolations_CountStartsOverAfterWindow(t *testing.T) {
+	auth := NewKubeAuth(nil)
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 1, auth.countChrootViolation("default/prober", start))
+	assert.Equal(t, 2, auth.countChrootViolation("default/prober", start.Add(30*time.Minute)))
+
+	// Violations long after the first are counted as a fresh start
+	assert.Equal(t, 1, auth.countChrootViolation("default/prober", start.Add(chrootViolationWindow+time.Minute)))
+	assert.Equal(t, 2, auth.countChrootViolation("default/prober", start.Add(chrootViolationWindow+2*time.Minute)))
+}
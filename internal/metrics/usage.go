@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -33,6 +35,64 @@ var (
 		[]string{"username"},
 	)
 
+	IdleSessionsClosedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kubeftpd_idle_sessions_closed_total",
+			Help: "Total FTP sessions closed by the idle timeout reaper",
+		},
+	)
+
+	CommandLinesTooLongTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kubeftpd_command_lines_too_long_total",
+			Help: "Total FTP command lines rejected for exceeding the maximum command length",
+		},
+	)
+
+	PausedConnectionsRefusedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kubeftpd_paused_connections_refused_total",
+			Help: "Total FTP connections refused while the listener was paused",
+		},
+	)
+
+	MaxSessionDurationClosedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kubeftpd_max_session_duration_closed_total",
+			Help: "Total FTP sessions closed for exceeding their user's maximum session duration",
+		},
+	)
+
+	IdleDataConnectionsClosedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kubeftpd_idle_data_connections_closed_total",
+			Help: "Total passive data connections closed by the data idle timeout",
+		},
+	)
+
+	GreetingDelayedConnectionsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kubeftpd_greeting_delayed_connections_total",
+			Help: "Total FTP connections whose welcome banner was delayed",
+		},
+	)
+
+	DownloadSizeMismatchesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeftpd_download_size_mismatches_total",
+			Help: "Total whole-file downloads whose streamed bytes differed from the file's size",
+		},
+		[]string{"backend_type"},
+	)
+
+	SlowOperationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeftpd_slow_operations_total",
+			Help: "Total FTP driver operations that exceeded the slow operation threshold",
+		},
+		[]string{"operation"},
+	)
+
 	// File operation metrics
 	FileOperationsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -77,6 +137,22 @@ var (
 		[]string{"username"},
 	)
 
+	UserStorageUsedBytes = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubeftpd_user_storage_used_bytes",
+			Help: "Bytes stored by each user as last computed for quota enforcement",
+		},
+		[]string{"username"},
+	)
+
+	UserTagInfo = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubeftpd_user_tag_info",
+			Help: "Safelisted tags of each user, always 1; join on username to group other metrics by tag",
+		},
+		[]string{"username", "tag", "value"},
+	)
+
 	// Backend metrics
 	BackendOperationsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -86,6 +162,54 @@ var (
 		[]string{"backend_name", "backend_type", "operation", "result"},
 	)
 
+	BackendErrorsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeftpd_backend_errors_total",
+			Help: "Storage operations that failed in the backend, excluding not-found and permission rejections",
+		},
+		[]string{"backend_kind", "backend_name", "operation"},
+	)
+
+	BackendCacheMissesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeftpd_backend_cache_misses_total",
+			Help: "Sessions that had to construct their user's storage backend on first use",
+		},
+		[]string{"backend_kind", "backend_name"},
+	)
+
+	MirrorUploadsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeftpd_mirror_uploads_total",
+			Help: "Uploads copied to a mirror backend, by result (success, failed or dropped)",
+		},
+		[]string{"backend_kind", "backend_name", "result"},
+	)
+
+	UploadVerifyRetriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeftpd_upload_verify_retries_total",
+			Help: "Uploads sent again after the stored object failed size verification",
+		},
+		[]string{"backend_name"},
+	)
+
+	VirusScansTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeftpd_virus_scans_total",
+			Help: "Uploads scanned by the ICAP server by verdict",
+		},
+		[]string{"result"},
+	)
+
+	BackendInflight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubeftpd_backend_inflight",
+			Help: "Storage operations currently in progress per backend",
+		},
+		[]string{"backend_name"},
+	)
+
 	BackendResponseTime = promauto.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    "kubeftpd_backend_response_time_seconds",
@@ -113,16 +237,100 @@ var (
 	)
 )
 
-// RecordConnection records a new connection
-func RecordConnection(username, clientIP string) {
+// ServerStats is an aggregate summary of server activity since process start,
+// suitable for inclusion in the HTTP status response
+type ServerStats struct {
+	UptimeSeconds    int64 `json:"uptime_seconds"`
+	TotalConnections int64 `json:"total_connections"`
+	BytesTransferred int64 `json:"bytes_transferred"`
+	ActiveSessions   int64 `json:"active_sessions"`
+}
+
+// Process-wide totals backing GetServerStats. These are kept alongside the
+// Prometheus collectors because reading aggregates back out of labelled vectors
+// would require walking every series.
+var (
+	startTime        = time.Now()
+	totalConnections atomic.Int64
+	bytesTransferred atomic.Int64
+	activeSessions   atomic.Int64
+)
+
+// GetServerStats returns a snapshot of the aggregate server statistics
+func GetServerStats() ServerStats {
+	return ServerStats{
+		UptimeSeconds:    int64(time.Since(startTime).Seconds()),
+		TotalConnections: totalConnections.Load(),
+		BytesTransferred: bytesTransferred.Load(),
+		ActiveSessions:   activeSessions.Load(),
+	}
+}
+
+// RecordConnection records a new control connection
+func RecordConnection() {
 	ActiveConnections.Inc()
+	totalConnections.Add(1)
+	activeSessions.Add(1)
+}
+
+// RecordConnectionLogin records a user logging in on a connection
+func RecordConnectionLogin(username, clientIP string) {
 	ConnectionsTotal.WithLabelValues(username, clientIP).Inc()
 }
 
-// RecordConnectionClosed records a connection closure
+// RecordConnectionClosed records a connection closure. The duration of a
+// connection that never logged in, with an empty username, is not observed.
 func RecordConnectionClosed(username string, duration time.Duration) {
 	ActiveConnections.Dec()
-	ConnectionDuration.WithLabelValues(username).Observe(duration.Seconds())
+	activeSessions.Add(-1)
+	if username != "" {
+		ConnectionDuration.WithLabelValues(username).Observe(duration.Seconds())
+	}
+}
+
+// RecordIdleSessionClosed records a session closed for exceeding the idle timeout
+func RecordIdleSessionClosed() {
+	IdleSessionsClosedTotal.Inc()
+}
+
+// RecordCommandLineTooLong records a command line rejected for exceeding the
+// maximum command length
+func RecordCommandLineTooLong() {
+	CommandLinesTooLongTotal.Inc()
+}
+
+// RecordPausedConnectionRefused records a connection refused while the
+// listener was paused
+func RecordPausedConnectionRefused() {
+	PausedConnectionsRefusedTotal.Inc()
+}
+
+// RecordMaxSessionDurationClosed records a session closed for exceeding its
+// user's maximum session duration
+func RecordMaxSessionDurationClosed() {
+	MaxSessionDurationClosedTotal.Inc()
+}
+
+// RecordIdleDataConnectionClosed records a passive data connection closed
+// before any transfer used it
+func RecordIdleDataConnectionClosed() {
+	IdleDataConnectionsClosedTotal.Inc()
+}
+
+// RecordGreetingDelayed records a connection whose banner was held back
+func RecordGreetingDelayed() {
+	GreetingDelayedConnectionsTotal.Inc()
+}
+
+// RecordDownloadSizeMismatch records a download whose backend streamed a
+// different number of bytes than the file's size
+func RecordDownloadSizeMismatch(backendType string) {
+	DownloadSizeMismatchesTotal.WithLabelValues(backendType).Inc()
+}
+
+// RecordSlowOperation records a driver operation that exceeded the slow threshold
+func RecordSlowOperation(operation string) {
+	SlowOperationsTotal.WithLabelValues(operation).Inc()
 }
 
 // RecordFileOperation records a file operation
@@ -134,6 +342,7 @@ func RecordFileOperation(username, operation, backendType, result string) {
 func RecordFileTransfer(username, direction, backendType string, bytes int64, duration time.Duration) {
 	FileTransferBytes.WithLabelValues(username, direction, backendType).Add(float64(bytes))
 	FileTransferDuration.WithLabelValues(username, direction, backendType).Observe(duration.Seconds())
+	bytesTransferred.Add(bytes)
 }
 
 // RecordUserLogin records a user login attempt
@@ -146,10 +355,111 @@ func RecordUserSession(username string, duration time.Duration) {
 	UserSessionDuration.WithLabelValues(username).Observe(duration.Seconds())
 }
 
+// SetUserStorageUsed publishes the bytes a user stores
+func SetUserStorageUsed(username string, bytes int64) {
+	UserStorageUsedBytes.WithLabelValues(username).Set(float64(bytes))
+}
+
+// SetUserTags replaces the tags published for a user
+func SetUserTags(username string, tags map[string]string) {
+	UserTagInfo.DeletePartialMatch(prometheus.Labels{"username": username})
+	for tag, value := range tags {
+		UserTagInfo.WithLabelValues(username, tag, value).Set(1)
+	}
+}
+
 // RecordBackendOperation records backend operation metrics
 func RecordBackendOperation(backendName, backendType, operation, result string, duration time.Duration) {
 	BackendOperationsTotal.WithLabelValues(backendName, backendType, operation, result).Inc()
 	BackendResponseTime.WithLabelValues(backendName, backendType, operation).Observe(duration.Seconds())
+	recentBackendOps.record(result == "error", time.Now())
+}
+
+// RecordBackendError counts a storage operation that failed in the backend
+func RecordBackendError(backendKind, backendName, operation string) {
+	BackendErrorsTotal.WithLabelValues(backendKind, backendName, operation).Inc()
+}
+
+// RecordBackendCacheMiss counts a session constructing its user's storage
+func RecordBackendCacheMiss(backendKind, backendName string) {
+	BackendCacheMissesTotal.WithLabelValues(backendKind, backendName).Inc()
+}
+
+// RecordMirrorUpload records the outcome of copying an upload to a mirror backend
+func RecordMirrorUpload(backendKind, backendName, result string) {
+	MirrorUploadsTotal.WithLabelValues(backendKind, backendName, result).Inc()
+}
+
+// RecordUploadVerifyRetry counts an upload resent after failing verification
+func RecordUploadVerifyRetry(backendName string) {
+	UploadVerifyRetriesTotal.WithLabelValues(backendName).Inc()
+}
+
+// RecordVirusScan counts an upload scan: clean, infected or error
+func RecordVirusScan(result string) {
+	VirusScansTotal.WithLabelValues(result).Inc()
+}
+
+// backendErrorWindow is the span of recent backend operations summarized by
+// GetBackendErrorRate
+const backendErrorWindow = time.Minute
+
+// recentBackendOps counts backend operations in the current and previous
+// window, so the error rate reflects the last one to two minutes of traffic
+var recentBackendOps = &operationWindow{}
+
+// operationWindow counts operations and errors in fixed time windows
+type operationWindow struct {
+	mu                       sync.Mutex
+	start                    time.Time
+	operations, errors       int64
+	prevOperations, prevErrs int64
+}
+
+// roll moves to the window containing now, keeping the one before it
+func (w *operationWindow) roll(now time.Time) {
+	switch elapsed := now.Sub(w.start); {
+	case elapsed < backendErrorWindow:
+		return
+	case elapsed < 2*backendErrorWindow:
+		w.prevOperations, w.prevErrs = w.operations, w.errors
+	default:
+		w.prevOperations, w.prevErrs = 0, 0
+	}
+	w.start = now
+	w.operations, w.errors = 0, 0
+}
+
+func (w *operationWindow) record(failed bool, now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.roll(now)
+	w.operations++
+	if failed {
+		w.errors++
+	}
+}
+
+func (w *operationWindow) rate(now time.Time) (float64, int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.roll(now)
+	operations := w.operations + w.prevOperations
+	if operations == 0 {
+		return 0, 0
+	}
+	return float64(w.errors+w.prevErrs) / float64(operations), operations
+}
+
+// GetBackendErrorRate returns the fraction of recent backend operations that
+// failed, and how many operations it is based on
+func GetBackendErrorRate() (float64, int64) {
+	return recentBackendOps.rate(time.Now())
+}
+
+// SetBackendInflight publishes the number of in-progress operations on a backend
+func SetBackendInflight(backendName string, inflight int) {
+	BackendInflight.WithLabelValues(backendName).Set(float64(inflight))
 }
 
 // RecordError records an error
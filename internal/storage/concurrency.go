@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+// ErrBackendBusy is returned when a backend's MaxConcurrentOperations limit is
+// reached. It is temporary; clients should retry.
+var ErrBackendBusy = errors.New("backend is busy, try again later")
+
+// backendLimiters holds the shared concurrency state of each backend, keyed by
+// backendKey, since storage is created per session.
+var backendLimiters sync.Map
+
+// backendLimiter counts in-flight operations against one backend
+type backendLimiter struct {
+	name     string
+	mu       sync.Mutex
+	limit    int
+	inflight int
+}
+
+// backendKey identifies the backend a user is bound to
+func backendKey(user *ftpv1.User) string {
+	namespace := user.Spec.Backend.ResolveNamespace(user.Namespace)
+	return user.Spec.Backend.Kind + "/" + namespace + "/" + user.Spec.Backend.Name
+}
+
+// limiterFor returns the user's backend limiter, applying the current limit
+func limiterFor(user *ftpv1.User, limit int32) *backendLimiter {
+	value, _ := backendLimiters.LoadOrStore(backendKey(user), &backendLimiter{name: user.Spec.Backend.Name})
+	limiter := value.(*backendLimiter)
+	limiter.mu.Lock()
+	limiter.limit = int(limit)
+	limiter.mu.Unlock()
+	return limiter
+}
+
+// acquire reserves a slot for an operation or returns ErrBackendBusy
+func (l *backendLimiter) acquire() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limit > 0 && l.inflight >= l.limit {
+		return ErrBackendBusy
+	}
+	l.inflight++
+	metrics.SetBackendInflight(l.name, l.inflight)
+	return nil
+}
+
+// release frees a slot reserved by acquire
+func (l *backendLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inflight--
+	metrics.SetBackendInflight(l.name, l.inflight)
+}
+
+// saturated reports whether new operations would currently be refused
+func (l *backendLimiter) saturated() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit > 0 && l.inflight >= l.limit
+}
+
+// BackendSaturated reports whether the user's backend is at its
+// MaxConcurrentOperations limit, so callers can refuse work up front
+func BackendSaturated(user *ftpv1.User) bool {
+	value, ok := backendLimiters.Load(backendKey(user))
+	return ok && value.(*backendLimiter).saturated()
+}
+
+// withConcurrencyLimit wraps storage so every operation holds a slot on the
+// user's backend limiter. Downloads hold their slot until the reader is closed.
+func withConcurrencyLimit(s Storage, user *ftpv1.User, limit int32) Storage {
+	return &limitedStorage{Storage: s, limiter: limiterFor(user, limit)}
+}
+
+// limitedStorage enforces a backend's concurrency limit around a Storage
+type limitedStorage struct {
+	Storage
+	limiter *backendLimiter
+}
+
+func (s *limitedStorage) ChangeDir(path string) error {
+	if err := s.limiter.acquire(); err != nil {
+		return err
+	}
+	defer s.limiter.release()
+	return s.Storage.ChangeDir(path)
+}
+
+func (s *limitedStorage) Stat(path string) (os.FileInfo, error) {
+	if err := s.limiter.acquire(); err != nil {
+		return nil, err
+	}
+	defer s.limiter.release()
+	return s.Storage.Stat(path)
+}
+
+func (s *limitedStorage) ListDir(path string, callback func(os.FileInfo) error) error {
+	if err := s.limiter.acquire(); err != nil {
+		return err
+	}
+	defer s.limiter.release()
+	return s.Storage.ListDir(path, callback)
+}
+
+func (s *limitedStorage) DeleteDir(path string) error {
+	if err := s.limiter.acquire(); err != nil {
+		return err
+	}
+	defer s.limiter.release()
+	return s.Storage.DeleteDir(path)
+}
+
+func (s *limitedStorage) DeleteFile(path string) error {
+	if err := s.limiter.acquire(); err != nil {
+		return err
+	}
+	defer s.limiter.release()
+	return s.Storage.DeleteFile(path)
+}
+
+func (s *limitedStorage) Rename(fromPath, toPath string) error {
+	if err := s.limiter.acquire(); err != nil {
+		return err
+	}
+	defer s.limiter.release()
+	return s.Storage.Rename(fromPath, toPath)
+}
+
+func (s *limitedStorage) MakeDir(path string) error {
+	if err := s.limiter.acquire(); err != nil {
+		return err
+	}
+	defer s.limiter.release()
+	return s.Storage.MakeDir(path)
+}
+
+func (s *limitedStorage) GetFile(path string, offset int64) (int64, io.ReadCloser, error) {
+	if err := s.limiter.acquire(); err != nil {
+		return 0, nil, err
+	}
+	size, reader, err := s.Storage.GetFile(path, offset)
+	if err != nil {
+		s.limiter.release()
+		return 0, nil, err
+	}
+	return size, &releasingReadCloser{ReadCloser: reader, release: s.limiter.release}, nil
+}
+
+func (s *limitedStorage) PutFile(path string, reader io.Reader, offset int64) (int64, error) {
+	if err := s.limiter.acquire(); err != nil {
+		return 0, err
+	}
+	defer s.limiter.release()
+	return s.Storage.PutFile(path, reader, offset)
+}
+
+// releasingReadCloser frees a limiter slot once a download is closed
+type releasingReadCloser struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (r *releasingReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
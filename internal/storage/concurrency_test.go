@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/backends"
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+// newLimitedTestStorage returns filesystem storage for a user bound to
+// backendName, wrapped with the given concurrency limit
+func newLimitedTestStorage(backendName string, limit int32) (Storage, *MockFilesystemBackend, *ftpv1.User) {
+	user := createTestUser()
+	user.Namespace = "default"
+	user.Spec.Backend = ftpv1.BackendReference{Kind: "FilesystemBackend", Name: backendName}
+	mockBackend := &MockFilesystemBackend{}
+	s := &filesystemStorage{
+		user:       user,
+		backend:    mockBackend,
+		basePath:   "/home/testuser",
+		currentDir: "/home/testuser",
+	}
+	return withConcurrencyLimit(s, user, limit), mockBackend, user
+}
+
+func TestLimitedStorage_SaturationReturnsBusy(t *testing.T) {
+	s, mockBackend, user := newLimitedTestStorage("limited-backend", 1)
+	gauge := metrics.BackendInflight.WithLabelValues("limited-backend")
+	mockBackend.On("GetFile", mock.Anything, int64(0), int64(4)).
+		Return(io.NopCloser(strings.NewReader("data")), nil)
+	mockBackend.On("StatFile", mock.Anything).Return(&backends.FileInfo{Name: "report.csv", Size: 4}, nil)
+
+	// An open download holds the only slot
+	_, reader, err := s.GetFile("report.csv", 0)
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(gauge))
+	assert.True(t, BackendSaturated(user))
+
+	_, err = s.Stat("report.csv")
+	assert.ErrorIs(t, err, ErrBackendBusy)
+	_, err = s.PutFile("other.csv", strings.NewReader("x"), 0)
+	assert.ErrorIs(t, err, ErrBackendBusy)
+
+	// Closing the download frees the slot
+	require.NoError(t, reader.Close())
+	require.NoError(t, reader.Close(), "closing twice must not release twice")
+	assert.Equal(t, float64(0), testutil.ToFloat64(gauge))
+	assert.False(t, BackendSaturated(user))
+
+	_, err = s.Stat("report.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), testutil.ToFloat64(gauge))
+}
+
+func TestLimitedStorage_Unlimited(t *testing.T) {
+	s, mockBackend, user := newLimitedTestStorage("unlimited-backend", 0)
+	gauge := metrics.BackendInflight.WithLabelValues("unlimited-backend")
+	mockBackend.On("GetFile", mock.Anything, int64(0), int64(4)).
+		Return(io.NopCloser(strings.NewReader("data")), nil)
+	mockBackend.On("StatFile", mock.Anything).Return(&backends.FileInfo{Name: "report.csv", Size: 4}, nil)
+
+	var readers []io.ReadCloser
+	for i := 0; i < 3; i++ {
+		_, reader, err := s.GetFile("report.csv", 0)
+		require.NoError(t, err)
+		readers = append(readers, reader)
+	}
+
+	// In-flight operations are still published without a limit
+	assert.Equal(t, float64(3), testutil.ToFloat64(gauge))
+	assert.False(t, BackendSaturated(user))
+
+	for _, reader := range readers {
+		require.NoError(t, reader.Close())
+	}
+	assert.Equal(t, float64(0), testutil.ToFloat64(gauge))
+}
+
+func TestLimitedStorage_SupportsResume(t *testing.T) {
+	s, _, _ := newLimitedTestStorage("resume-backend", 0)
+	assert.False(t, SupportsResume(s))
+
+	user := createTestUser()
+	wrapped := withConcurrencyLimit(&minioStorage{resumableUploads: true}, user, 0)
+	assert.True(t, SupportsResume(wrapped))
+}
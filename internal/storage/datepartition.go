@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"path"
+	"time"
+)
+
+// datePartitionLayout names the directories an upload is partitioned into
+const datePartitionLayout = "2006/01/02"
+
+// withDatePartition marks s as storing uploads under YYYY/MM/DD directories of
+// the upload date when the backend's DatePartition is set. Otherwise s is
+// returned unchanged.
+func withDatePartition(s Storage, enabled bool) Storage {
+	if !enabled {
+		return s
+	}
+	return &datePartitionStorage{Storage: s}
+}
+
+// datePartitionStorage reports the DatePartition capability, so callers
+// upload to UploadPath and see the stored path in their overwrite checks,
+// logs and events. Every other operation sees the stored layout, so the date
+// directories show up in listings and downloads use the partitioned paths.
+type datePartitionStorage struct {
+	Storage
+}
+
+// UploadPath returns the path an upload to filePath starting at t is stored
+// at: inside the upload date's directories when s partitions uploads by date,
+// otherwise filePath itself
+func UploadPath(s Storage, filePath string, t time.Time) string {
+	if !s.Capabilities().DatePartition {
+		return filePath
+	}
+	return datePartitionPath(filePath, t)
+}
+
+// datePartitionPath inserts the date of t between the directory and name of filePath
+func datePartitionPath(filePath string, t time.Time) string {
+	dir, name := path.Split(filePath)
+	return path.Join(dir, t.Format(datePartitionLayout), name)
+}
+
+// Capabilities reports the wrapped storage's capabilities plus DatePartition
+func (s *datePartitionStorage) Capabilities() Capabilities {
+	caps := s.Storage.Capabilities()
+	caps.DatePartition = true
+	return caps
+}
+
+// PutFile stores an upload at filePath, which callers have already placed
+// under its date directories with UploadPath
+func (s *datePartitionStorage) PutFile(filePath string, reader io.Reader, offset int64) (int64, error) {
+	if dayDir := path.Dir(filePath); s.Storage.Capabilities().RequireParents && isDatePartitionDir(dayDir) {
+		if err := s.makeDateDirs(dayDir); err != nil {
+			return 0, err
+		}
+	}
+	return s.Storage.PutFile(filePath, reader, offset)
+}
+
+// isDatePartitionDir reports whether dir ends in YYYY/MM/DD date directories
+func isDatePartitionDir(dir string) bool {
+	if len(dir) < len(datePartitionLayout) {
+		return false
+	}
+	_, err := time.Parse(datePartitionLayout, dir[len(dir)-len(datePartitionLayout):])
+	return err == nil
+}
+
+// makeDateDirs creates the year, month and day directories ending at dayDir
+// for backends that do not create missing parents on upload
+func (s *datePartitionStorage) makeDateDirs(dayDir string) error {
+	monthDir := path.Dir(dayDir)
+	for _, dir := range []string{path.Dir(monthDir), monthDir, dayDir} {
+		if err := s.MakeDir(dir); err != nil && !errors.Is(err, ErrDirExists) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newDatePartitionTestStorage(requireParents bool) (Storage, *MockFilesystemBackend) {
+	mockBackend := &MockFilesystemBackend{}
+	mockBackend.On("IsReadOnly").Return(false)
+	s := withDatePartition(&filesystemStorage{
+		user:           createTestUser(),
+		backend:        mockBackend,
+		basePath:       "/home/testuser",
+		currentDir:     "/home/testuser",
+		requireParents: requireParents,
+	}, true)
+	return s, mockBackend
+}
+
+func TestDatePartitionPath(t *testing.T) {
+	day := time.Date(2026, time.March, 7, 23, 59, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "absolute", path: "/inbox/scan.pdf", want: "/inbox/2026/03/07/scan.pdf"},
+		{name: "home root", path: "/scan.pdf", want: "/2026/03/07/scan.pdf"},
+		{name: "relative", path: "scan.pdf", want: "2026/03/07/scan.pdf"},
+		{name: "relative subdirectory", path: "inbox/scan.pdf", want: "inbox/2026/03/07/scan.pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, datePartitionPath(tt.path, day))
+		})
+	}
+}
+
+func TestUploadPath(t *testing.T) {
+	day := time.Date(2026, time.October, 16, 9, 30, 0, 0, time.UTC)
+
+	s, _ := newDatePartitionTestStorage(false)
+	assert.True(t, s.Capabilities().DatePartition)
+	assert.Equal(t, "/inbox/2026/10/16/scan.pdf", UploadPath(s, "/inbox/scan.pdf", day))
+	assert.Equal(t, "/inbox/2027/01/02/scan.pdf", UploadPath(s, "/inbox/scan.pdf", time.Date(2027, time.January, 2, 0, 0, 0, 0, time.UTC)),
+		"partition follows the upload time")
+
+	// Wrappers pass the capability through
+	assert.Equal(t, "/inbox/2026/10/16/scan.pdf", UploadPath(withErrorMetrics(s, "FilesystemBackend", "test"), "/inbox/scan.pdf", day))
+
+	unpartitioned := &filesystemStorage{user: createTestUser()}
+	assert.False(t, unpartitioned.Capabilities().DatePartition)
+	assert.Equal(t, "/inbox/scan.pdf", UploadPath(unpartitioned, "/inbox/scan.pdf", day))
+}
+
+func TestDatePartitionStorage_PutFile(t *testing.T) {
+	t.Run("upload is stored at the partitioned path it is given", func(t *testing.T) {
+		s, mockBackend := newDatePartitionTestStorage(false)
+		mockBackend.On("PutFile", "/home/testuser/inbox/2026/10/16/scan.pdf", mock.Anything, int64(-1)).Return(nil)
+
+		n, err := s.PutFile("/inbox/2026/10/16/scan.pdf", strings.NewReader("scanned"), 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), n)
+		mockBackend.AssertExpectations(t)
+	})
+
+	t.Run("date directories are created when the backend requires parents", func(t *testing.T) {
+		s, mockBackend := newDatePartitionTestStorage(true)
+		mockBackend.On("StatFile", "/home/testuser/inbox/2026").Return(nil, errors.New("no such file or directory"))
+		mockBackend.On("StatFile", "/home/testuser/inbox/2026/10").Return(nil, errors.New("no such file or directory"))
+		mockBackend.On("StatFile", "/home/testuser/inbox/2026/10/16").Return(nil, errors.New("no such file or directory"))
+		mockBackend.On("MakeDir", "/home/testuser/inbox/2026").Return(nil)
+		mockBackend.On("MakeDir", "/home/testuser/inbox/2026/10").Return(nil)
+		mockBackend.On("MakeDir", "/home/testuser/inbox/2026/10/16").Return(nil)
+		mockBackend.On("PutFile", "/home/testuser/inbox/2026/10/16/scan.pdf", mock.Anything, int64(-1)).Return(nil)
+
+		_, err := s.PutFile("/inbox/2026/10/16/scan.pdf", strings.NewReader("scanned"), 0)
+		require.NoError(t, err)
+		mockBackend.AssertExpectations(t)
+	})
+
+	t.Run("paths outside a date directory are left to the backend", func(t *testing.T) {
+		s, mockBackend := newDatePartitionTestStorage(true)
+		mockBackend.On("PutFile", "/home/testuser/inbox/scan.pdf", mock.Anything, int64(-1)).Return(nil)
+
+		_, err := s.PutFile("/inbox/scan.pdf", strings.NewReader("scanned"), 0)
+		require.NoError(t, err)
+		mockBackend.AssertNotCalled(t, "MakeDir", mock.Anything)
+	})
+}
+
+func TestWithDatePartition(t *testing.T) {
+	inner := &filesystemStorage{user: createTestUser()}
+	assert.Same(t, Storage(inner), withDatePartition(inner, false), "disabled partitioning leaves storage unwrapped")
+
+	_, ok := withDatePartition(inner, true).(*datePartitionStorage)
+	assert.True(t, ok)
+}
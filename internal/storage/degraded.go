@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+// ErrBackendDegraded is returned for writes to a backend serving in read-only
+// degrade mode while its health check is failing
+var ErrBackendDegraded = errors.New("backend is degraded")
+
+// withDegradeMode makes storage read-only when the backend's DegradeMode is
+// readonly and its health check is failing. Otherwise s is returned unchanged.
+func withDegradeMode(s Storage, mode string, healthCheckFailing bool, backendName string) Storage {
+	if mode != ftpv1.DegradeModeReadOnly || !healthCheckFailing {
+		return s
+	}
+	return &readOnlyStorage{Storage: s, backendName: backendName}
+}
+
+// readOnlyStorage serves reads from a degraded backend and rejects writes
+type readOnlyStorage struct {
+	Storage
+	backendName string
+}
+
+// writeRejected explains why a write to the degraded backend was refused
+func (s *readOnlyStorage) writeRejected() error {
+	return fmt.Errorf("%w: %s is read-only until its health check recovers", ErrBackendDegraded, s.backendName)
+}
+
+func (s *readOnlyStorage) DeleteDir(path string) error {
+	return s.writeRejected()
+}
+
+func (s *readOnlyStorage) DeleteFile(path string) error {
+	return s.writeRejected()
+}
+
+func (s *readOnlyStorage) Rename(fromPath, toPath string) error {
+	return s.writeRejected()
+}
+
+func (s *readOnlyStorage) MakeDir(path string) error {
+	return s.writeRejected()
+}
+
+func (s *readOnlyStorage) PutFile(path string, reader io.Reader, offset int64) (int64, error) {
+	return 0, s.writeRejected()
+}
+
+// Capabilities reports the wrapped storage's capabilities minus those that write
+func (s *readOnlyStorage) Capabilities() Capabilities {
+	caps := s.Storage.Capabilities()
+	caps.Append = false
+	caps.Chmod = false
+	caps.ReadOnly = true
+	return caps
+}
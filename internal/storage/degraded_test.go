@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+func TestNewStorage_DegradeMode(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, ftpv1.AddToScheme(scheme))
+
+	now := metav1.Now()
+	failing := ftpv1.FilesystemBackendStatus{Ready: false, Message: "Base path is not writable", LastChecked: &now}
+	healthy := ftpv1.FilesystemBackendStatus{Ready: true, LastChecked: &now}
+
+	tests := []struct {
+		name         string
+		degradeMode  string
+		status       ftpv1.FilesystemBackendStatus
+		writeAllowed bool
+	}{
+		{name: "readonly while failing", degradeMode: ftpv1.DegradeModeReadOnly, status: failing, writeAllowed: false},
+		{name: "readonly while healthy", degradeMode: ftpv1.DegradeModeReadOnly, status: healthy, writeAllowed: true},
+		{name: "readonly before first check", degradeMode: ftpv1.DegradeModeReadOnly, writeAllowed: true},
+		{name: "none while failing", degradeMode: ftpv1.DegradeModeNone, status: failing, writeAllowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			basePath := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(basePath, "existing.txt"), []byte("hello"), 0644))
+
+			backend := &ftpv1.FilesystemBackend{
+				ObjectMeta: metav1.ObjectMeta{Name: "fs-backend", Namespace: "default"},
+				Spec:       ftpv1.FilesystemBackendSpec{BasePath: basePath, DegradeMode: tt.degradeMode},
+				Status:     tt.status,
+			}
+			kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(backend).Build()
+			user := createTestUser()
+			user.Namespace = "default"
+			user.Spec.HomeDirectory = "/"
+			user.Spec.Backend = ftpv1.BackendReference{Kind: "FilesystemBackend", Name: "fs-backend"}
+
+			s, err := NewStorage(context.Background(), user, kubeClient)
+			require.NoError(t, err)
+
+			// Reads are always served
+			_, reader, err := s.GetFile("/existing.txt", 0)
+			require.NoError(t, err)
+			content, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			require.NoError(t, reader.Close())
+			assert.Equal(t, "hello", string(content))
+
+			_, err = s.PutFile("/new.txt", strings.NewReader("data"), 0)
+			mkdirErr := s.MakeDir("/newdir")
+			deleteErr := s.DeleteFile("/existing.txt")
+			if tt.writeAllowed {
+				assert.NoError(t, err)
+				assert.NoError(t, mkdirErr)
+				assert.NoError(t, deleteErr)
+				return
+			}
+			for _, err := range []error{err, mkdirErr, deleteErr} {
+				assert.ErrorIs(t, err, ErrBackendDegraded)
+				assert.EqualError(t, err, "backend is degraded: fs-backend is read-only until its health check recovers")
+			}
+			assert.FileExists(t, filepath.Join(basePath, "existing.txt"))
+			assert.NoFileExists(t, filepath.Join(basePath, "new.txt"))
+		})
+	}
+}
+
+func TestReadOnlyStorage_Capabilities(t *testing.T) {
+	user := createTestUser()
+	s := withDegradeMode(&minioStorage{user: user, resumableUploads: true}, ftpv1.DegradeModeReadOnly, true, "minio")
+	assert.Equal(t, Capabilities{Range: true, ReadOnly: true}, s.Capabilities())
+	assert.False(t, SupportsResume(s))
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+// withErrorMetrics counts the operations of s that fail in the backend in
+// kubeftpd_backend_errors_total
+func withErrorMetrics(s Storage, backendKind, backendName string) Storage {
+	return &errorMetricsStorage{Storage: s, backendKind: backendKind, backendName: backendName}
+}
+
+// errorMetricsStorage records backend failures of the Storage it wraps
+type errorMetricsStorage struct {
+	Storage
+	backendKind string
+	backendName string
+}
+
+// isBackendFailure reports whether err means the backend failed rather than
+// the request being rejected: missing paths, permission checks, existing
+// directories, read-only backends and degraded-mode refusals are answers, not
+// failures
+func isBackendFailure(err error) bool {
+	if err == nil || errors.Is(err, os.ErrNotExist) || errors.Is(err, os.ErrPermission) ||
+		errors.Is(err, ErrDirExists) || errors.Is(err, ErrBackendDegraded) {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	for _, rejection := range []string{"not found", "no such file", "does not exist", "permission denied", "read-only"} {
+		if strings.Contains(message, rejection) {
+			return false
+		}
+	}
+	return true
+}
+
+// observe counts err against operation if it is a backend failure
+func (s *errorMetricsStorage) observe(operation string, err error) error {
+	if isBackendFailure(err) {
+		metrics.RecordBackendError(s.backendKind, s.backendName, operation)
+	}
+	return err
+}
+
+func (s *errorMetricsStorage) ChangeDir(path string) error {
+	return s.observe("chdir", s.Storage.ChangeDir(path))
+}
+
+func (s *errorMetricsStorage) Stat(path string) (os.FileInfo, error) {
+	info, err := s.Storage.Stat(path)
+	return info, s.observe("stat", err)
+}
+
+func (s *errorMetricsStorage) ListDir(path string, callback func(os.FileInfo) error) error {
+	// Errors returned by the callback come from the FTP side, not the backend
+	var callbackErr error
+	err := s.Storage.ListDir(path, func(info os.FileInfo) error {
+		callbackErr = callback(info)
+		return callbackErr
+	})
+	if callbackErr != nil {
+		return err
+	}
+	return s.observe("list", err)
+}
+
+func (s *errorMetricsStorage) DeleteDir(path string) error {
+	return s.observe("delete_dir", s.Storage.DeleteDir(path))
+}
+
+func (s *errorMetricsStorage) DeleteFile(path string) error {
+	return s.observe("delete_file", s.Storage.DeleteFile(path))
+}
+
+func (s *errorMetricsStorage) Rename(fromPath, toPath string) error {
+	return s.observe("rename", s.Storage.Rename(fromPath, toPath))
+}
+
+func (s *errorMetricsStorage) MakeDir(path string) error {
+	return s.observe("mkdir", s.Storage.MakeDir(path))
+}
+
+func (s *errorMetricsStorage) GetFile(path string, offset int64) (int64, io.ReadCloser, error) {
+	size, reader, err := s.Storage.GetFile(path, offset)
+	return size, reader, s.observe("get", err)
+}
+
+func (s *errorMetricsStorage) PutFile(path string, reader io.Reader, offset int64) (int64, error) {
+	// An upload the client aborts fails reading its data, which is not the
+	// backend's fault
+	upload := &uploadReader{Reader: reader}
+	size, err := s.Storage.PutFile(path, upload, offset)
+	if err != nil && upload.err != nil {
+		return size, err
+	}
+	return size, s.observe("put", err)
+}
+
+// uploadReader remembers the first error reading an upload's data
+type uploadReader struct {
+	io.Reader
+	err error
+}
+
+func (r *uploadReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err != nil && err != io.EOF && r.err == nil {
+		r.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/rossigee/kubeftpd/internal/backends"
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+func TestErrorMetricsStorage(t *testing.T) {
+	backendErrors := func(operation string) float64 {
+		return testutil.ToFloat64(metrics.BackendErrorsTotal.WithLabelValues("FilesystemBackend", "errors-test", operation))
+	}
+
+	user := createTestUser()
+	mockBackend := &MockFilesystemBackend{}
+	mockBackend.On("IsReadOnly").Return(false)
+	s := withErrorMetrics(&filesystemStorage{
+		user:       user,
+		backend:    mockBackend,
+		basePath:   "/home/testuser",
+		currentDir: "/home/testuser",
+	}, "FilesystemBackend", "errors-test")
+
+	t.Run("backend failure is counted", func(t *testing.T) {
+		before := backendErrors("list")
+		mockBackend.On("ListFiles", "/home/testuser/broken", false).Return([]backends.FileInfo(nil), errors.New("input/output error"))
+		mockBackend.On("StatFile", "/home/testuser/broken").Return(nil, errors.New("input/output error"))
+
+		err := s.ListDir("broken", func(os.FileInfo) error { return nil })
+		assert.Error(t, err)
+		assert.Equal(t, before+1, backendErrors("list"))
+	})
+
+	t.Run("permission denied is not counted", func(t *testing.T) {
+		user.Spec.Permissions.Delete = false
+		defer func() { user.Spec.Permissions.Delete = true }()
+		before := backendErrors("delete_file")
+
+		err := s.DeleteFile("report.csv")
+		assert.ErrorContains(t, err, "permission denied")
+		assert.Equal(t, before, backendErrors("delete_file"))
+		mockBackend.AssertNotCalled(t, "RemoveFile", mock.Anything)
+	})
+
+	t.Run("not found is not counted", func(t *testing.T) {
+		before := backendErrors("stat")
+		mockBackend.On("StatFile", "/home/testuser/missing.csv").Return(nil, os.ErrNotExist)
+
+		_, err := s.Stat("missing.csv")
+		assert.Error(t, err)
+		assert.Equal(t, before, backendErrors("stat"))
+	})
+
+	t.Run("aborted upload is not counted", func(t *testing.T) {
+		before := backendErrors("put")
+		// The mock drains the reader like a real backend would
+		mockBackend.On("PutFile", "/home/testuser/upload.bin", mock.Anything, int64(-1)).Return(errors.New("failed to write file: connection reset")).Once()
+
+		_, err := s.PutFile("upload.bin", iotest.ErrReader(errors.New("connection reset")), 0)
+		assert.Error(t, err)
+		assert.Equal(t, before, backendErrors("put"))
+	})
+
+	t.Run("failed upload is counted", func(t *testing.T) {
+		before := backendErrors("put")
+		mockBackend.On("PutFile", "/home/testuser/upload.bin", mock.Anything, int64(-1)).Return(errors.New("disk quota exceeded")).Once()
+
+		_, err := s.PutFile("upload.bin", strings.NewReader("data"), 0)
+		assert.Error(t, err)
+		assert.Equal(t, before+1, backendErrors("put"))
+	})
+}
@@ -21,6 +21,10 @@ type filesystemStorage struct {
 	backend    backends.FilesystemBackend
 	basePath   string
 	currentDir string
+	// requireParents is set when the backend refuses uploads into missing directories
+	requireParents bool
+	// readOnly mirrors the backend's read-only setting for Capabilities
+	readOnly bool
 }
 
 // ChangeDir changes the current working directory
@@ -51,13 +55,22 @@ func (s *filesystemStorage) Stat(filePath string) (os.FileInfo, error) {
 		return nil, fmt.Errorf("file not found: %s", filePath)
 	}
 
+	return s.newFileInfo(path.Base(filePath), fileInfo), nil
+}
+
+// newFileInfo converts a backend file info into the one reported to clients
+func (s *filesystemStorage) newFileInfo(name string, file *backends.FileInfo) *filesystemFileInfo {
 	return &filesystemFileInfo{
-		name:    path.Base(filePath),
-		size:    fileInfo.Size,
-		mode:    s.getModeFromInfo(fileInfo),
-		modTime: fileInfo.ModTime,
-		isDir:   fileInfo.IsDir,
-	}, nil
+		name:       name,
+		size:       file.Size,
+		mode:       s.getModeFromInfo(file),
+		modTime:    file.ModTime,
+		isDir:      file.IsDir,
+		linkTarget: file.LinkTarget,
+		owner:      file.Owner,
+		group:      file.Group,
+		nlink:      file.Nlink,
+	}
 }
 
 // ListDir lists directory contents
@@ -70,19 +83,15 @@ func (s *filesystemStorage) ListDir(dirPath string, callback func(os.FileInfo) e
 
 	files, err := s.backend.ListFiles(fullPath, false)
 	if err != nil {
+		// Listing a file yields that file alone, as ls does
+		if file, statErr := s.backend.StatFile(fullPath); statErr == nil && !file.IsDir {
+			return callback(s.newFileInfo(path.Base(fullPath), file))
+		}
 		return fmt.Errorf("failed to list directory: %w", err)
 	}
 
 	for _, file := range files {
-		fileInfo := &filesystemFileInfo{
-			name:    file.Name,
-			size:    file.Size,
-			mode:    s.getModeFromInfo(&file),
-			modTime: file.ModTime,
-			isDir:   file.IsDir,
-		}
-
-		if err := callback(fileInfo); err != nil {
+		if err := callback(s.newFileInfo(file.Name, &file)); err != nil {
 			return err
 		}
 	}
@@ -146,6 +155,9 @@ func (s *filesystemStorage) MakeDir(dirPath string) error {
 	}
 
 	fullPath := s.resolvePath(dirPath)
+	if info, err := s.backend.StatFile(fullPath); err == nil && info.IsDir {
+		return ErrDirExists
+	}
 	return s.backend.MakeDir(fullPath)
 }
 
@@ -251,6 +263,9 @@ func (s *filesystemStorage) resolvePath(relativePath string) string {
 
 // getModeFromInfo returns appropriate file mode based on file info
 func (s *filesystemStorage) getModeFromInfo(fileInfo *backends.FileInfo) fs.FileMode {
+	if fileInfo.IsSymlink {
+		return fs.ModeSymlink | 0777
+	}
 	if fileInfo.IsDir {
 		return fs.ModeDir | 0755
 	}
@@ -259,11 +274,15 @@ func (s *filesystemStorage) getModeFromInfo(fileInfo *backends.FileInfo) fs.File
 
 // filesystemFileInfo implements server.FileInfo interface
 type filesystemFileInfo struct {
-	name    string
-	size    int64
-	mode    fs.FileMode
-	modTime time.Time
-	isDir   bool
+	name       string
+	size       int64
+	mode       fs.FileMode
+	modTime    time.Time
+	isDir      bool
+	linkTarget string
+	owner      string
+	group      string
+	nlink      uint64
 }
 
 func (fi *filesystemFileInfo) Name() string       { return fi.name }
@@ -271,10 +290,29 @@ func (fi *filesystemFileInfo) Size() int64        { return fi.size }
 func (fi *filesystemFileInfo) Mode() fs.FileMode  { return fi.mode }
 func (fi *filesystemFileInfo) ModTime() time.Time { return fi.modTime }
 func (fi *filesystemFileInfo) IsDir() bool        { return fi.isDir }
-func (fi *filesystemFileInfo) Owner() string      { return "" }
-func (fi *filesystemFileInfo) Group() string      { return "" }
+func (fi *filesystemFileInfo) Owner() string      { return fi.owner }
+func (fi *filesystemFileInfo) Group() string      { return fi.group }
 func (fi *filesystemFileInfo) Sys() interface{}   { return nil }
 
+// Nlink returns the file's hard link count, or 0 when it isn't known
+func (fi *filesystemFileInfo) Nlink() uint64 { return fi.nlink }
+
+// LinkTarget returns the target of a symbolic link, or "" for other entries
+func (fi *filesystemFileInfo) LinkTarget() string { return fi.linkTarget }
+
+// Capabilities reports ranged downloads, symbolic link listings, on-disk
+// ownership, whether uploads need an existing parent directory and whether
+// the backend is read-only
+func (s *filesystemStorage) Capabilities() Capabilities {
+	return Capabilities{
+		Symlink:        true,
+		Range:          true,
+		Ownership:      true,
+		RequireParents: s.requireParents,
+		ReadOnly:       s.readOnly,
+	}
+}
+
 // Close cleans up resources
 func (s *filesystemStorage) Close() error {
 	// Filesystem backend does not require explicit closing
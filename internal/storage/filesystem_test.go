@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"errors"
 	"io"
 	"os"
 	"strings"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
@@ -231,6 +233,63 @@ func TestFilesystemStorage_ListDir(t *testing.T) {
 	mockBackend.AssertExpectations(t)
 }
 
+func TestFilesystemStorage_ListDir_File(t *testing.T) {
+	user := createTestUser()
+	mockBackend := &MockFilesystemBackend{}
+
+	storage := &filesystemStorage{
+		user:       user,
+		backend:    mockBackend,
+		basePath:   "/home/testuser",
+		currentDir: "/home/testuser",
+	}
+
+	modTime := metav1.Now().Time
+	mockBackend.On("ListFiles", "/home/testuser/report.csv", false).Return([]backends.FileInfo(nil), errors.New("not a directory"))
+	mockBackend.On("StatFile", "/home/testuser/report.csv").Return(&backends.FileInfo{Name: "report.csv", Size: 1024, ModTime: modTime}, nil)
+	mockBackend.On("ListFiles", "/home/testuser/missing", false).Return([]backends.FileInfo(nil), errors.New("no such file or directory"))
+	mockBackend.On("StatFile", "/home/testuser/missing").Return(nil, errors.New("no such file or directory"))
+
+	var entries []os.FileInfo
+	err := storage.ListDir("report.csv", func(info os.FileInfo) error {
+		entries = append(entries, info)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "report.csv", entries[0].Name())
+	assert.Equal(t, int64(1024), entries[0].Size())
+	assert.Equal(t, modTime, entries[0].ModTime())
+	assert.False(t, entries[0].IsDir())
+
+	err = storage.ListDir("missing", func(os.FileInfo) error { return nil })
+	assert.ErrorContains(t, err, "failed to list directory")
+
+	mockBackend.AssertExpectations(t)
+}
+
+func TestFilesystemStorage_ListDir_EmptyDirectory(t *testing.T) {
+	user := createTestUser()
+	mockBackend := &MockFilesystemBackend{}
+	mockBackend.On("ListFiles", "/home/testuser/empty", false).Return([]backends.FileInfo{}, nil)
+
+	storage := &filesystemStorage{
+		user:       user,
+		backend:    mockBackend,
+		basePath:   "/home/testuser",
+		currentDir: "/home/testuser",
+	}
+
+	calls := 0
+	err := storage.ListDir("empty", func(os.FileInfo) error {
+		calls++
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, calls)
+	mockBackend.AssertExpectations(t)
+}
+
 func TestFilesystemStorage_ListDir_PermissionDenied(t *testing.T) {
 	user := createTestUser()
 	user.Spec.Permissions.List = false // Disable list permission
@@ -459,6 +518,7 @@ func TestFilesystemStorage_MakeDir(t *testing.T) {
 	}
 
 	mockBackend.On("IsReadOnly").Return(false)
+	mockBackend.On("StatFile", "/home/testuser/newdir").Return(nil, os.ErrNotExist)
 	mockBackend.On("MakeDir", "/home/testuser/newdir").Return(nil)
 
 	err := storage.MakeDir("newdir")
@@ -467,6 +527,25 @@ func TestFilesystemStorage_MakeDir(t *testing.T) {
 	mockBackend.AssertExpectations(t)
 }
 
+func TestFilesystemStorage_MakeDir_Exists(t *testing.T) {
+	user := createTestUser()
+	mockBackend := &MockFilesystemBackend{}
+
+	storage := &filesystemStorage{
+		user:       user,
+		backend:    mockBackend,
+		basePath:   "/home/testuser",
+		currentDir: "/home/testuser",
+	}
+
+	mockBackend.On("IsReadOnly").Return(false)
+	mockBackend.On("StatFile", "/home/testuser/existing").Return(&backends.FileInfo{Name: "existing", IsDir: true}, nil)
+
+	err := storage.MakeDir("existing")
+	assert.ErrorIs(t, err, ErrDirExists)
+	mockBackend.AssertNotCalled(t, "MakeDir", mock.Anything)
+}
+
 func TestFilesystemStorage_MakeDir_PermissionDenied(t *testing.T) {
 	user := createTestUser()
 	user.Spec.Permissions.Write = false // Disable write permission
@@ -0,0 +1,206 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"time"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/backends"
+)
+
+// ftpStorage implements Storage by proxying to a remote FTP server
+type ftpStorage struct {
+	user       *ftpv1.User
+	backend    backends.FtpBackend
+	basePath   string
+	currentDir string
+}
+
+// ChangeDir changes the current working directory
+func (s *ftpStorage) ChangeDir(dir string) error {
+	newPath := s.resolvePath(dir)
+
+	info, err := s.backend.Stat(newPath)
+	if err != nil {
+		return fmt.Errorf("directory not found: %s", dir)
+	}
+	if !info.IsDir {
+		return fmt.Errorf("not a directory: %s", dir)
+	}
+
+	s.currentDir = newPath
+	return nil
+}
+
+// Stat returns file information for the given path
+func (s *ftpStorage) Stat(filePath string) (os.FileInfo, error) {
+	info, err := s.backend.Stat(s.resolvePath(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("file not found: %s", filePath)
+	}
+
+	return &ftpFileInfo{
+		name:    path.Base(filePath),
+		size:    info.Size,
+		mode:    info.Mode,
+		modTime: info.ModTime,
+		isDir:   info.IsDir,
+	}, nil
+}
+
+// ListDir lists directory contents
+func (s *ftpStorage) ListDir(dirPath string, callback func(os.FileInfo) error) error {
+	if !s.user.Spec.Permissions.List {
+		return fmt.Errorf("list permission denied")
+	}
+
+	entries, err := s.backend.ReadDir(s.resolvePath(dirPath))
+	if err != nil {
+		return fmt.Errorf("failed to list directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		fileInfo := &ftpFileInfo{
+			name:    entry.Name,
+			size:    entry.Size,
+			mode:    entry.Mode,
+			modTime: entry.ModTime,
+			isDir:   entry.IsDir,
+		}
+		if err := callback(fileInfo); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteDir deletes a directory; like RMD, the remote server refuses
+// directories that are not empty
+func (s *ftpStorage) DeleteDir(dirPath string) error {
+	if !s.user.Spec.Permissions.Delete {
+		return fmt.Errorf("delete permission denied")
+	}
+
+	return s.backend.RemoveDir(s.resolvePath(dirPath))
+}
+
+// DeleteFile deletes a file
+func (s *ftpStorage) DeleteFile(filePath string) error {
+	if !s.user.Spec.Permissions.Delete {
+		return fmt.Errorf("delete permission denied")
+	}
+
+	return s.backend.Remove(s.resolvePath(filePath))
+}
+
+// Rename renames/moves a file or directory
+func (s *ftpStorage) Rename(fromPath, toPath string) error {
+	if !s.user.Spec.Permissions.Write {
+		return fmt.Errorf("write permission denied")
+	}
+
+	return s.backend.Rename(s.resolvePath(fromPath), s.resolvePath(toPath))
+}
+
+// MakeDir creates a directory
+func (s *ftpStorage) MakeDir(dirPath string) error {
+	if !s.user.Spec.Permissions.Write {
+		return fmt.Errorf("write permission denied")
+	}
+
+	fullPath := s.resolvePath(dirPath)
+	if info, err := s.backend.Stat(fullPath); err == nil && info.IsDir {
+		return ErrDirExists
+	}
+	return s.backend.Mkdir(fullPath)
+}
+
+// GetFile downloads a file
+func (s *ftpStorage) GetFile(filePath string, offset int64) (int64, io.ReadCloser, error) {
+	if !s.user.Spec.Permissions.Read {
+		return 0, nil, fmt.Errorf("read permission denied")
+	}
+	if offset != 0 {
+		return 0, nil, fmt.Errorf("offset mode not supported")
+	}
+
+	fullPath := s.resolvePath(filePath)
+
+	info, err := s.backend.Stat(fullPath)
+	if err != nil {
+		return 0, nil, fmt.Errorf("file not found: %s", filePath)
+	}
+
+	reader, err := s.backend.Open(fullPath)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	return info.Size, reader, nil
+}
+
+// PutFile uploads a file
+func (s *ftpStorage) PutFile(filePath string, reader io.Reader, offset int64) (int64, error) {
+	if !s.user.Spec.Permissions.Write {
+		return 0, fmt.Errorf("write permission denied")
+	}
+	if offset != 0 {
+		return 0, fmt.Errorf("offset mode not supported")
+	}
+
+	size, err := s.backend.WriteFile(s.resolvePath(filePath), reader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return size, nil
+}
+
+// resolvePath resolves a relative path to an absolute path within the user's home directory
+func (s *ftpStorage) resolvePath(relativePath string) string {
+	if relativePath == "" || relativePath == "." {
+		return s.currentDir
+	}
+
+	if path.IsAbs(relativePath) {
+		// Absolute path relative to home directory
+		return path.Join(s.basePath, relativePath)
+	}
+
+	// Relative path from current directory
+	return path.Join(s.currentDir, relativePath)
+}
+
+// ftpFileInfo implements server.FileInfo interface
+type ftpFileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+	isDir   bool
+}
+
+func (fi *ftpFileInfo) Name() string       { return fi.name }
+func (fi *ftpFileInfo) Size() int64        { return fi.size }
+func (fi *ftpFileInfo) Mode() fs.FileMode  { return fi.mode }
+func (fi *ftpFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *ftpFileInfo) IsDir() bool        { return fi.isDir }
+func (fi *ftpFileInfo) Owner() string      { return "" }
+func (fi *ftpFileInfo) Group() string      { return "" }
+func (fi *ftpFileInfo) Sys() interface{}   { return nil }
+
+// Capabilities reports no optional features: transfers always cover the whole
+// file, since the proxy does not forward REST to the remote server
+func (s *ftpStorage) Capabilities() Capabilities {
+	return Capabilities{}
+}
+
+// Close logs out of the remote server
+func (s *ftpStorage) Close() error {
+	return s.backend.Close()
+}
@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/backends"
+)
+
+// MockFtpBackend for testing
+type MockFtpBackend struct {
+	mock.Mock
+}
+
+func (m *MockFtpBackend) Stat(filePath string) (*backends.FileInfo, error) {
+	args := m.Called(filePath)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*backends.FileInfo), args.Error(1)
+}
+
+func (m *MockFtpBackend) Open(filePath string) (io.ReadCloser, error) {
+	args := m.Called(filePath)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockFtpBackend) WriteFile(filePath string, reader io.Reader) (int64, error) {
+	data, _ := io.ReadAll(reader)
+	args := m.Called(filePath, string(data))
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockFtpBackend) Remove(filePath string) error {
+	return m.Called(filePath).Error(0)
+}
+
+func (m *MockFtpBackend) Rename(oldPath, newPath string) error {
+	return m.Called(oldPath, newPath).Error(0)
+}
+
+func (m *MockFtpBackend) Mkdir(dirPath string) error {
+	return m.Called(dirPath).Error(0)
+}
+
+func (m *MockFtpBackend) RemoveDir(dirPath string) error {
+	return m.Called(dirPath).Error(0)
+}
+
+func (m *MockFtpBackend) ReadDir(dirPath string) ([]*backends.FileInfo, error) {
+	args := m.Called(dirPath)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*backends.FileInfo), args.Error(1)
+}
+
+func (m *MockFtpBackend) Close() error {
+	return m.Called().Error(0)
+}
+
+func createTestFtpStorage(user *ftpv1.User, backend backends.FtpBackend) *ftpStorage {
+	return &ftpStorage{
+		user:       user,
+		backend:    backend,
+		basePath:   "/home/testuser",
+		currentDir: "/home/testuser",
+	}
+}
+
+func TestFtpStorage_Operations(t *testing.T) {
+	mockBackend := &MockFtpBackend{}
+	s := createTestFtpStorage(createTestUser(), mockBackend)
+
+	// list
+	mockBackend.On("ReadDir", "/home/testuser/reports").Return([]*backends.FileInfo{
+		{Name: "q1.csv", Size: 10},
+		{Name: "old", IsDir: true},
+	}, nil).Once()
+	var listed []string
+	require.NoError(t, s.ListDir("reports", func(info os.FileInfo) error {
+		listed = append(listed, info.Name())
+		return nil
+	}))
+	assert.Equal(t, []string{"q1.csv", "old"}, listed)
+
+	// stat
+	mockBackend.On("Stat", "/home/testuser/reports/q1.csv").Return(&backends.FileInfo{Name: "q1.csv", Size: 10}, nil)
+	info, err := s.Stat("/reports/q1.csv")
+	require.NoError(t, err)
+	assert.Equal(t, "q1.csv", info.Name())
+	assert.Equal(t, int64(10), info.Size())
+
+	// get
+	mockBackend.On("Open", "/home/testuser/reports/q1.csv").Return(io.NopCloser(strings.NewReader("0123456789")), nil).Once()
+	size, reader, err := s.GetFile("/reports/q1.csv", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), size)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789", string(data))
+
+	// put
+	mockBackend.On("WriteFile", "/home/testuser/upload.txt", "hello").Return(int64(5), nil).Once()
+	written, err := s.PutFile("upload.txt", strings.NewReader("hello"), 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), written)
+
+	// rename
+	mockBackend.On("Rename", "/home/testuser/upload.txt", "/home/testuser/reports/upload.txt").Return(nil).Once()
+	require.NoError(t, s.Rename("upload.txt", "/reports/upload.txt"))
+
+	// delete
+	mockBackend.On("Remove", "/home/testuser/reports/upload.txt").Return(nil).Once()
+	require.NoError(t, s.DeleteFile("/reports/upload.txt"))
+	mockBackend.On("RemoveDir", "/home/testuser/reports/old").Return(nil).Once()
+	require.NoError(t, s.DeleteDir("/reports/old"))
+
+	mockBackend.On("Close").Return(nil).Once()
+	require.NoError(t, s.Close())
+
+	mockBackend.AssertExpectations(t)
+}
+
+func TestFtpStorage_RejectsOffsets(t *testing.T) {
+	mockBackend := &MockFtpBackend{}
+	s := createTestFtpStorage(createTestUser(), mockBackend)
+
+	_, _, err := s.GetFile("file.txt", 5)
+	assert.Error(t, err)
+	_, err = s.PutFile("file.txt", strings.NewReader("x"), 5)
+	assert.Error(t, err)
+	assert.False(t, SupportsResume(s))
+
+	mockBackend.AssertNotCalled(t, "Open", mock.Anything)
+	mockBackend.AssertNotCalled(t, "WriteFile", mock.Anything, mock.Anything)
+}
+
+func TestFtpStorage_EnforcesPermissions(t *testing.T) {
+	user := createTestUser()
+	user.Spec.Permissions = ftpv1.UserPermissions{}
+	mockBackend := &MockFtpBackend{}
+	s := createTestFtpStorage(user, mockBackend)
+
+	assert.Error(t, s.ListDir("/", func(os.FileInfo) error { return nil }))
+	_, _, err := s.GetFile("file.txt", 0)
+	assert.Error(t, err)
+	_, err = s.PutFile("file.txt", strings.NewReader("x"), 0)
+	assert.Error(t, err)
+	assert.Error(t, s.Rename("a", "b"))
+	assert.Error(t, s.DeleteFile("file.txt"))
+	assert.Error(t, s.DeleteDir("dir"))
+
+	mockBackend.AssertExpectations(t)
+}
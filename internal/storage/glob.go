@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"os"
+	"path"
+	"strings"
+)
+
+// HasGlobPattern reports whether name contains shell glob metacharacters
+func HasGlobPattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// GlobFilter wraps a ListDir callback so that only entries whose names match
+// pattern, as interpreted by path.Match, reach it. A malformed pattern fails
+// the listing with path.ErrBadPattern.
+func GlobFilter(pattern string, callback func(os.FileInfo) error) func(os.FileInfo) error {
+	return func(info os.FileInfo) error {
+		matched, err := path.Match(pattern, info.Name())
+		if err != nil {
+			return err
+		}
+		if !matched {
+			return nil
+		}
+		return callback(info)
+	}
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rossigee/kubeftpd/internal/backends"
+)
+
+func TestHasGlobPattern(t *testing.T) {
+	assert.True(t, HasGlobPattern("*.csv"))
+	assert.True(t, HasGlobPattern("report-?.txt"))
+	assert.True(t, HasGlobPattern("[ab].log"))
+	assert.False(t, HasGlobPattern("report.csv"))
+	assert.False(t, HasGlobPattern(""))
+}
+
+// globListing lists the home directory of s through GlobFilter
+func globListing(t *testing.T, s Storage, pattern string) []string {
+	t.Helper()
+	var names []string
+	err := s.ListDir("", GlobFilter(pattern, func(info os.FileInfo) error {
+		names = append(names, info.Name())
+		return nil
+	}))
+	require.NoError(t, err)
+	return names
+}
+
+func TestGlobFilter_Backends(t *testing.T) {
+	fsBackend := &MockFilesystemBackend{}
+	fsBackend.On("ListFiles", "/home/testuser", false).Return([]backends.FileInfo{
+		{Name: "a.csv", Size: 10},
+		{Name: "b.txt", Size: 20},
+		{Name: "c.csv", Size: 30},
+		{Name: "archive", IsDir: true},
+	}, nil)
+	filesystem := &filesystemStorage{
+		user:       createTestUser(),
+		backend:    fsBackend,
+		basePath:   "/home/testuser",
+		currentDir: "/home/testuser",
+	}
+
+	minioBackend := &MockMinioBackend{}
+	minioBackend.On("ListObjects", "/home/testuser", false).Return([]*backends.ObjectInfo{
+		{Key: "a.csv", Size: 10},
+		{Key: "b.txt", Size: 20},
+		{Key: "c.csv", Size: 30},
+		{Key: "archive/old.csv", Size: 40},
+	}, nil)
+	minio := &minioStorage{
+		user:       createTestUser(),
+		backend:    minioBackend,
+		basePath:   "/home/testuser",
+		currentDir: "/home/testuser",
+	}
+
+	for name, s := range map[string]Storage{"filesystem": filesystem, "minio": minio} {
+		t.Run(name, func(t *testing.T) {
+			assert.ElementsMatch(t, []string{"a.csv", "c.csv"}, globListing(t, s, "*.csv"))
+			assert.ElementsMatch(t, []string{"b.txt"}, globListing(t, s, "?.txt"))
+			assert.ElementsMatch(t, []string{"archive"}, globListing(t, s, "arch*"))
+			assert.ElementsMatch(t, []string{"a.csv", "b.txt"}, globListing(t, s, "[ab].*"))
+			assert.Empty(t, globListing(t, s, "*.pdf"))
+		})
+	}
+}
+
+func TestGlobFilter_BadPattern(t *testing.T) {
+	callback := GlobFilter("[", func(os.FileInfo) error { return nil })
+	assert.ErrorIs(t, callback(&filesystemFileInfo{name: "a.csv"}), path.ErrBadPattern)
+}
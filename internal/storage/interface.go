@@ -2,9 +2,13 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strings"
+	"sync"
 	"sync/atomic"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -13,6 +17,9 @@ import (
 	"github.com/rossigee/kubeftpd/internal/backends"
 )
 
+// ErrDirExists is returned by MakeDir when the directory already exists
+var ErrDirExists = errors.New("directory already exists")
+
 // Storage interface defines the operations supported by storage backends
 type Storage interface {
 	ChangeDir(path string) error
@@ -24,9 +31,38 @@ type Storage interface {
 	MakeDir(path string) error
 	GetFile(path string, offset int64) (int64, io.ReadCloser, error)
 	PutFile(path string, reader io.Reader, offset int64) (int64, error)
+	Capabilities() Capabilities
 	Close() error
 }
 
+// Capabilities describes the optional features a storage backend supports
+type Capabilities struct {
+	// Append allows uploads to continue at a non-zero offset
+	Append bool
+	// Chmod allows file modes to be changed
+	Chmod bool
+	// Symlink means listings report symbolic links and their targets
+	Symlink bool
+	// Checksum allows file digests to be computed by the backend
+	Checksum bool
+	// Range allows downloads to start at a non-zero offset
+	Range bool
+	// Ownership means file infos report the owner and group stored by the backend
+	Ownership bool
+	// RequireParents means uploads fail unless the parent directory exists
+	RequireParents bool
+	// ReadOnly means the backend rejects every write
+	ReadOnly bool
+	// DatePartition means uploads are stored under YYYY/MM/DD directories of
+	// the upload date; see UploadPath
+	DatePartition bool
+}
+
+// SupportsResume reports whether s can continue uploads at a non-zero offset
+func SupportsResume(s Storage) bool {
+	return s.Capabilities().Append
+}
+
 // countingReader counts bytes read from the underlying reader
 type countingReader struct {
 	reader    io.Reader
@@ -39,8 +75,64 @@ func (cr *countingReader) Read(p []byte) (int, error) {
 	return n, err
 }
 
-// NewStorage creates a new storage implementation based on the user's backend configuration
+// enabledBackendKinds restricts which backend kinds NewStorage will construct.
+// A nil map means every supported kind is enabled.
+var (
+	enabledBackendKindsMu sync.RWMutex
+	enabledBackendKinds   map[string]bool
+)
+
+// SetEnabledBackendKinds restricts NewStorage to the given backend kinds.
+// Passing an empty list re-enables all kinds.
+func SetEnabledBackendKinds(kinds []string) {
+	enabledBackendKindsMu.Lock()
+	defer enabledBackendKindsMu.Unlock()
+
+	if len(kinds) == 0 {
+		enabledBackendKinds = nil
+		return
+	}
+	enabledBackendKinds = make(map[string]bool, len(kinds))
+	for _, kind := range kinds {
+		enabledBackendKinds[kind] = true
+	}
+}
+
+// IsBackendKindEnabled reports whether the given backend kind may be served
+func IsBackendKindEnabled(kind string) bool {
+	enabledBackendKindsMu.RLock()
+	defer enabledBackendKindsMu.RUnlock()
+	return enabledBackendKinds == nil || enabledBackendKinds[kind]
+}
+
+// enabledBackendKindList returns the configured allowlist for error messages
+func enabledBackendKindList() string {
+	enabledBackendKindsMu.RLock()
+	defer enabledBackendKindsMu.RUnlock()
+	kinds := make([]string, 0, len(enabledBackendKinds))
+	for kind := range enabledBackendKinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return strings.Join(kinds, ", ")
+}
+
+// NewStorage creates a new storage implementation based on the user's backend
+// configuration. Uploads are copied to the user's MirrorBackend when one is set.
 func NewStorage(ctx context.Context, user *ftpv1.User, kubeClient client.Client) (Storage, error) {
+	s, err := newBackendStorage(ctx, user, kubeClient)
+	if err != nil || user.Spec.MirrorBackend == nil {
+		return s, err
+	}
+	return withMirror(ctx, s, user, kubeClient), nil
+}
+
+// newBackendStorage creates the storage for the backend user.Spec.Backend refers to
+func newBackendStorage(ctx context.Context, user *ftpv1.User, kubeClient client.Client) (Storage, error) {
+	if !IsBackendKindEnabled(user.Spec.Backend.Kind) {
+		return nil, fmt.Errorf("backend kind %s is disabled (enabled kinds: %s)", user.Spec.Backend.Kind, enabledBackendKindList())
+	}
+
 	switch user.Spec.Backend.Kind {
 	case "MinioBackend":
 		return newMinioStorage(ctx, user, kubeClient)
@@ -48,6 +140,8 @@ func NewStorage(ctx context.Context, user *ftpv1.User, kubeClient client.Client)
 		return newWebDavStorage(ctx, user, kubeClient)
 	case "FilesystemBackend":
 		return newFilesystemStorage(ctx, user, kubeClient)
+	case "FtpBackend":
+		return newFtpStorage(ctx, user, kubeClient)
 	default:
 		return nil, fmt.Errorf("unsupported backend kind: %s", user.Spec.Backend.Kind)
 	}
@@ -58,10 +152,7 @@ func newMinioStorage(ctx context.Context, user *ftpv1.User, kubeClient client.Cl
 	// Get the MinioBackend CRD
 	backend := &ftpv1.MinioBackend{}
 	backendName := user.Spec.Backend.Name
-	backendNamespace := user.Namespace
-	if user.Spec.Backend.Namespace != nil {
-		backendNamespace = *user.Spec.Backend.Namespace
-	}
+	backendNamespace := user.Spec.Backend.ResolveNamespace(user.Namespace)
 
 	err := kubeClient.Get(ctx, client.ObjectKey{
 		Name:      backendName,
@@ -77,13 +168,24 @@ func newMinioStorage(ctx context.Context, user *ftpv1.User, kubeClient client.Cl
 		return nil, fmt.Errorf("failed to create MinIO backend: %w", err)
 	}
 
-	return &minioStorage{
-		user:        user,
-		backend:     minioBackend,
-		basePath:    user.Spec.HomeDirectory,
-		currentDir:  user.Spec.HomeDirectory,
-		backendName: backendName,
-	}, nil
+	objectTagKey, objectTagValue, _ := backend.Spec.ObjectTag()
+	s := withDegradeMode(&minioStorage{
+		user:                  user,
+		backend:               minioBackend,
+		basePath:              user.Spec.HomeDirectory,
+		currentDir:            user.Spec.HomeDirectory,
+		backendName:           backendName,
+		keyNormalization:      backend.Spec.KeyNormalization,
+		resumableUploads:      backend.Spec.ResumableUploads,
+		partSize:              backend.Spec.PartSize,
+		caseInsensitiveLookup: backend.Spec.CaseInsensitiveLookup,
+		stripLeadingSlash:     backend.Spec.StripLeadingSlash,
+		uploadScope:           backendNamespace + "/" + backendName,
+		objectTagKey:          objectTagKey,
+		objectTagValue:        objectTagValue,
+		uploadVerifyRetries:   int(backend.Spec.UploadVerifyRetries),
+	}, backend.Spec.DegradeMode, backend.HealthCheckFailing(), backendName)
+	return withConcurrencyLimit(withErrorMetrics(withDatePartition(s, backend.Spec.DatePartition), "MinioBackend", backendName), user, backend.Spec.MaxConcurrentOperations), nil
 }
 
 // newWebDavStorage creates a WebDAV-backed storage implementation
@@ -91,10 +193,7 @@ func newWebDavStorage(ctx context.Context, user *ftpv1.User, kubeClient client.C
 	// Get the WebDavBackend CRD
 	backend := &ftpv1.WebDavBackend{}
 	backendName := user.Spec.Backend.Name
-	backendNamespace := user.Namespace
-	if user.Spec.Backend.Namespace != nil {
-		backendNamespace = *user.Spec.Backend.Namespace
-	}
+	backendNamespace := user.Spec.Backend.ResolveNamespace(user.Namespace)
 
 	err := kubeClient.Get(ctx, client.ObjectKey{
 		Name:      backendName,
@@ -110,12 +209,13 @@ func newWebDavStorage(ctx context.Context, user *ftpv1.User, kubeClient client.C
 		return nil, fmt.Errorf("failed to create WebDAV backend: %w", err)
 	}
 
-	return &webdavStorage{
+	s := withDegradeMode(&webdavStorage{
 		user:       user,
 		backend:    webdavBackend,
 		basePath:   user.Spec.HomeDirectory,
 		currentDir: user.Spec.HomeDirectory,
-	}, nil
+	}, backend.Spec.DegradeMode, backend.HealthCheckFailing(), backendName)
+	return withConcurrencyLimit(withErrorMetrics(s, "WebDavBackend", backendName), user, backend.Spec.MaxConcurrentOperations), nil
 }
 
 // newFilesystemStorage creates a filesystem-backed storage implementation
@@ -123,10 +223,7 @@ func newFilesystemStorage(ctx context.Context, user *ftpv1.User, kubeClient clie
 	// Get the FilesystemBackend CRD
 	backend := &ftpv1.FilesystemBackend{}
 	backendName := user.Spec.Backend.Name
-	backendNamespace := user.Namespace
-	if user.Spec.Backend.Namespace != nil {
-		backendNamespace = *user.Spec.Backend.Namespace
-	}
+	backendNamespace := user.Spec.Backend.ResolveNamespace(user.Namespace)
 
 	err := kubeClient.Get(ctx, client.ObjectKey{
 		Name:      backendName,
@@ -142,10 +239,43 @@ func newFilesystemStorage(ctx context.Context, user *ftpv1.User, kubeClient clie
 		return nil, fmt.Errorf("failed to create filesystem backend: %w", err)
 	}
 
-	return &filesystemStorage{
+	s := withDegradeMode(&filesystemStorage{
+		user:           user,
+		backend:        filesystemBackend,
+		basePath:       user.Spec.HomeDirectory,
+		currentDir:     user.Spec.HomeDirectory,
+		requireParents: !backend.Spec.CreatesParents(),
+		readOnly:       filesystemBackend.IsReadOnly(),
+	}, backend.Spec.DegradeMode, backend.HealthCheckFailing(), backendName)
+	return withConcurrencyLimit(withErrorMetrics(withDatePartition(s, backend.Spec.DatePartition), "FilesystemBackend", backendName), user, backend.Spec.MaxConcurrentOperations), nil
+}
+
+// newFtpStorage creates storage that proxies to a remote FTP server
+func newFtpStorage(ctx context.Context, user *ftpv1.User, kubeClient client.Client) (Storage, error) {
+	// Get the FtpBackend CRD
+	backend := &ftpv1.FtpBackend{}
+	backendName := user.Spec.Backend.Name
+	backendNamespace := user.Spec.Backend.ResolveNamespace(user.Namespace)
+
+	err := kubeClient.Get(ctx, client.ObjectKey{
+		Name:      backendName,
+		Namespace: backendNamespace,
+	}, backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get FtpBackend %s/%s: %w", backendNamespace, backendName, err)
+	}
+
+	// Log in to the remote server
+	ftpBackend, err := backends.NewFtpBackend(ctx, backend, kubeClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create FTP backend: %w", err)
+	}
+
+	s := &ftpStorage{
 		user:       user,
-		backend:    filesystemBackend,
+		backend:    ftpBackend,
 		basePath:   user.Spec.HomeDirectory,
 		currentDir: user.Spec.HomeDirectory,
-	}, nil
+	}
+	return withConcurrencyLimit(withErrorMetrics(s, "FtpBackend", backendName), user, backend.Spec.MaxConcurrentOperations), nil
 }
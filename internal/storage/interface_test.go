@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+func TestNewStorage_EnabledBackendKinds(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, ftpv1.AddToScheme(scheme))
+
+	backend := &ftpv1.FilesystemBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "fs-backend", Namespace: "default"},
+		Spec:       ftpv1.FilesystemBackendSpec{BasePath: t.TempDir()},
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(backend).Build()
+
+	user := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "testuser", Namespace: "default"},
+		Spec: ftpv1.UserSpec{
+			Username:      "testuser",
+			HomeDirectory: "/",
+			Backend: ftpv1.BackendReference{
+				Kind: "FilesystemBackend",
+				Name: "fs-backend",
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		kinds       []string
+		expectError string
+	}{
+		{
+			name:  "all kinds enabled by default",
+			kinds: nil,
+		},
+		{
+			name:  "kind in allowlist",
+			kinds: []string{"FilesystemBackend", "MinioBackend"},
+		},
+		{
+			name:        "kind not in allowlist",
+			kinds:       []string{"MinioBackend", "WebDavBackend"},
+			expectError: "backend kind FilesystemBackend is disabled (enabled kinds: MinioBackend, WebDavBackend)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetEnabledBackendKinds(tt.kinds)
+			defer SetEnabledBackendKinds(nil)
+
+			s, err := NewStorage(context.Background(), user, kubeClient)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				assert.Nil(t, s)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, s)
+		})
+	}
+}
+
+func TestNewStorage_CrossNamespaceBackend(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, ftpv1.AddToScheme(scheme))
+
+	backend := &ftpv1.FilesystemBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "shared-backend", Namespace: "shared"},
+		Spec:       ftpv1.FilesystemBackendSpec{BasePath: t.TempDir()},
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(backend).Build()
+
+	newUser := func(namespace *string) *ftpv1.User {
+		return &ftpv1.User{
+			ObjectMeta: metav1.ObjectMeta{Name: "testuser", Namespace: "team-a"},
+			Spec: ftpv1.UserSpec{
+				Username:      "testuser",
+				HomeDirectory: "/",
+				Backend: ftpv1.BackendReference{
+					Kind:      "FilesystemBackend",
+					Name:      "shared-backend",
+					Namespace: namespace,
+				},
+			},
+		}
+	}
+	shared := "shared"
+	other := "other"
+
+	s, err := NewStorage(context.Background(), newUser(&shared), kubeClient)
+	require.NoError(t, err)
+	assert.NotNil(t, s)
+
+	_, err = NewStorage(context.Background(), newUser(nil), kubeClient)
+	assert.ErrorContains(t, err, "failed to get FilesystemBackend team-a/shared-backend")
+
+	_, err = NewStorage(context.Background(), newUser(&other), kubeClient)
+	assert.ErrorContains(t, err, "failed to get FilesystemBackend other/shared-backend")
+}
+
+func TestStorage_Capabilities(t *testing.T) {
+	user := createTestUser()
+
+	tests := []struct {
+		name     string
+		storage  Storage
+		expected Capabilities
+	}{
+		{
+			name:     "filesystem",
+			storage:  &filesystemStorage{user: user},
+			expected: Capabilities{Symlink: true, Range: true, Ownership: true},
+		},
+		{
+			name:     "filesystem requiring parents",
+			storage:  &filesystemStorage{user: user, requireParents: true},
+			expected: Capabilities{Symlink: true, Range: true, Ownership: true, RequireParents: true},
+		},
+		{
+			name:     "read-only filesystem",
+			storage:  &filesystemStorage{user: user, readOnly: true},
+			expected: Capabilities{Symlink: true, Range: true, Ownership: true, ReadOnly: true},
+		},
+		{
+			name:     "minio",
+			storage:  &minioStorage{user: user},
+			expected: Capabilities{Range: true},
+		},
+		{
+			name:     "minio with resumable uploads",
+			storage:  &minioStorage{user: user, resumableUploads: true},
+			expected: Capabilities{Append: true, Range: true},
+		},
+		{
+			name:     "webdav",
+			storage:  &webdavStorage{user: user},
+			expected: Capabilities{},
+		},
+		{
+			name:     "concurrency limit passes through",
+			storage:  withConcurrencyLimit(&minioStorage{user: user, resumableUploads: true}, user, 1),
+			expected: Capabilities{Append: true, Range: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.storage.Capabilities())
+			assert.Equal(t, tt.expected.Append, SupportsResume(tt.storage))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -9,6 +10,9 @@ import (
 	"strings"
 	"sync/atomic"
 	"time"
+	"unicode"
+
+	ctrl "sigs.k8s.io/controller-runtime"
 
 	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
 	"github.com/rossigee/kubeftpd/internal/backends"
@@ -22,15 +26,37 @@ type minioStorage struct {
 	basePath    string
 	currentDir  string
 	backendName string
+	// keyNormalization mirrors MinioBackendSpec.KeyNormalization; empty means "none"
+	keyNormalization string
+	// resumableUploads mirrors MinioBackendSpec.ResumableUploads
+	resumableUploads bool
+	// caseInsensitiveLookup mirrors MinioBackendSpec.CaseInsensitiveLookup
+	caseInsensitiveLookup bool
+	// stripLeadingSlash mirrors MinioBackendSpec.StripLeadingSlash
+	stripLeadingSlash bool
+	// uploadScope identifies the backend ("namespace/name") in pendingUploads
+	uploadScope string
+	// partSize mirrors MinioBackendSpec.PartSize, overriding
+	// defaultMultipartPartSize for resumable uploads
+	partSize int64
+	// objectTagKey and objectTagValue are MinioBackendSpec.RequiredObjectTag;
+	// an empty key means objects need no tag
+	objectTagKey   string
+	objectTagValue string
+	// uploadVerifyRetries mirrors MinioBackendSpec.UploadVerifyRetries
+	uploadVerifyRetries int
 }
 
 // ChangeDir changes the current working directory
 func (s *minioStorage) ChangeDir(dir string) error {
 	// Normalize the path
-	newPath := s.resolvePath(dir)
+	newPath, err := s.resolvePath(dir)
+	if err != nil {
+		return err
+	}
 
 	// Check if the directory exists by trying to list it
-	_, err := s.backend.ListObjects(newPath, false)
+	_, err = s.backend.ListObjects(newPath, false)
 	if err != nil {
 		return fmt.Errorf("directory not found: %s", dir)
 	}
@@ -42,10 +68,28 @@ func (s *minioStorage) ChangeDir(dir string) error {
 // Stat returns file information for the given path
 func (s *minioStorage) Stat(filePath string) (os.FileInfo, error) {
 	start := time.Now()
-	fullPath := s.resolvePath(filePath)
+	fullPath, err := s.resolvePath(filePath)
+	if err != nil {
+		return nil, err
+	}
 
 	// Try to get object info
+	name := path.Base(filePath)
 	objInfo, err := s.backend.StatObject(fullPath)
+	if err != nil {
+		if foldedPath, folded, ok := s.lookupFolded(fullPath); ok {
+			fullPath, objInfo, err = foldedPath, folded, nil
+			name = path.Base(foldedPath)
+		}
+	}
+	if err != nil && s.resumableUploads {
+		// An interrupted upload is reported at its committed size, so a client
+		// asking SIZE before REST+STOR resumes from what is stored
+		if size, updated, ok := s.pendingUploadSize(fullPath); ok {
+			metrics.RecordBackendOperation(s.backendName, "MinioBackend", "stat", "success", time.Since(start))
+			return &minioFileInfo{name: name, size: size, mode: 0644, modTime: updated}, nil
+		}
+	}
 	if err != nil {
 		// Only treat as directory if the path ends with / or doesn't have a file extension
 		if strings.HasSuffix(filePath, "/") || path.Ext(filePath) == "" {
@@ -77,12 +121,16 @@ func (s *minioStorage) Stat(filePath string) (os.FileInfo, error) {
 		metrics.RecordBackendOperation(s.backendName, "MinioBackend", "stat", "error", duration)
 		return nil, fmt.Errorf("file not found: %s", filePath)
 	}
+	if !s.visible(objInfo) {
+		metrics.RecordBackendOperation(s.backendName, "MinioBackend", "stat", "error", time.Since(start))
+		return nil, fmt.Errorf("file not found: %s", filePath)
+	}
 
 	duration := time.Since(start)
 	metrics.RecordBackendOperation(s.backendName, "MinioBackend", "stat", "success", duration)
 
 	return &minioFileInfo{
-		name:    path.Base(filePath),
+		name:    name,
 		size:    objInfo.Size,
 		mode:    0644,
 		modTime: objInfo.LastModified,
@@ -96,32 +144,60 @@ func (s *minioStorage) ListDir(dirPath string, callback func(os.FileInfo) error)
 		return fmt.Errorf("list permission denied")
 	}
 
-	fullPath := s.resolvePath(dirPath)
+	fullPath, err := s.resolvePath(dirPath)
+	if err != nil {
+		return err
+	}
 
 	objects, err := s.backend.ListObjects(fullPath, false)
 	if err != nil {
 		return fmt.Errorf("failed to list directory: %w", err)
 	}
 
+	// Listing a file yields that file alone, as ls does, rather than every
+	// object sharing its name as a prefix
+	if file := findObject(objects, fullPath); file != nil {
+		if !s.visible(file) {
+			return nil
+		}
+		return callback(&minioFileInfo{
+			name:    path.Base(fullPath),
+			size:    file.Size,
+			mode:    0644,
+			modTime: file.LastModified,
+			isDir:   false,
+		})
+	}
+
 	// Track directories we've seen to avoid duplicates
 	seenDirs := make(map[string]bool)
 
+	// Keys are reported without a leading slash
+	prefix := strings.TrimPrefix(fullPath, "/")
 	for _, obj := range objects {
 		// Get relative path from the listing prefix
-		relativePath := strings.TrimPrefix(obj.Key, fullPath)
-		if relativePath == "" {
-			continue
+		relativePath := strings.TrimPrefix(obj.Key, "/")
+		if prefix != "" && strings.HasPrefix(relativePath, prefix) {
+			relativePath = strings.TrimPrefix(relativePath, prefix)
+			if relativePath != "" && !strings.HasPrefix(relativePath, "/") {
+				// A sibling sharing the prefix, e.g. "reports2/" when listing "reports"
+				continue
+			}
 		}
 
-		// Remove leading slash
+		// Remove leading slash. The directory's own marker or prefix leaves
+		// nothing, so an empty directory lists no entries.
 		relativePath = strings.TrimPrefix(relativePath, "/")
+		if relativePath == "" {
+			continue
+		}
 
 		// Check if this is a subdirectory
 		parts := strings.Split(relativePath, "/")
 		if len(parts) > 1 {
 			// This is a file in a subdirectory, add the directory entry
 			dirName := parts[0]
-			if !seenDirs[dirName] {
+			if dirName != "" && !seenDirs[dirName] {
 				seenDirs[dirName] = true
 				dirInfo := &minioFileInfo{
 					name:    dirName,
@@ -134,7 +210,7 @@ func (s *minioStorage) ListDir(dirPath string, callback func(os.FileInfo) error)
 					return err
 				}
 			}
-		} else {
+		} else if s.visible(obj) {
 			// This is a file in the current directory
 			fileInfo := &minioFileInfo{
 				name:    parts[0],
@@ -152,13 +228,31 @@ func (s *minioStorage) ListDir(dirPath string, callback func(os.FileInfo) error)
 	return nil
 }
 
+// findObject returns the object stored under key itself, if listing it as a
+// prefix found one. Keys ending in "/" name directories and never match.
+func findObject(objects []*backends.ObjectInfo, key string) *backends.ObjectInfo {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" || strings.HasSuffix(key, "/") {
+		return nil
+	}
+	for _, obj := range objects {
+		if strings.TrimPrefix(obj.Key, "/") == key {
+			return obj
+		}
+	}
+	return nil
+}
+
 // DeleteDir deletes a directory
 func (s *minioStorage) DeleteDir(dirPath string) error {
 	if !s.user.Spec.Permissions.Delete {
 		return fmt.Errorf("delete permission denied")
 	}
 
-	fullPath := s.resolvePath(dirPath)
+	fullPath, err := s.resolvePath(dirPath)
+	if err != nil {
+		return err
+	}
 	return s.backend.RemoveObjects(fullPath, true) // recursive delete
 }
 
@@ -168,7 +262,13 @@ func (s *minioStorage) DeleteFile(filePath string) error {
 		return fmt.Errorf("delete permission denied")
 	}
 
-	fullPath := s.resolvePath(filePath)
+	fullPath, err := s.resolvePath(filePath)
+	if err != nil {
+		return err
+	}
+	if s.hidden(fullPath) {
+		return fmt.Errorf("file not found: %s", filePath)
+	}
 	return s.backend.RemoveObject(fullPath)
 }
 
@@ -178,8 +278,20 @@ func (s *minioStorage) Rename(fromPath, toPath string) error {
 		return fmt.Errorf("write permission denied")
 	}
 
-	fullFromPath := s.resolvePath(fromPath)
-	fullToPath := s.resolvePath(toPath)
+	fullFromPath, err := s.resolvePath(fromPath)
+	if err != nil {
+		return err
+	}
+	fullToPath, err := s.resolvePath(toPath)
+	if err != nil {
+		return err
+	}
+	if s.hidden(fullFromPath) {
+		return fmt.Errorf("file not found: %s", fromPath)
+	}
+	if s.hidden(fullToPath) {
+		return fmt.Errorf("%s: %w", toPath, os.ErrPermission)
+	}
 
 	// MinIO doesn't have native rename, so we copy and delete
 	return s.backend.CopyObject(fullFromPath, fullToPath, true) // deleteSource = true
@@ -191,7 +303,14 @@ func (s *minioStorage) MakeDir(dirPath string) error {
 		return fmt.Errorf("write permission denied")
 	}
 
-	fullPath := s.resolvePath(dirPath)
+	fullPath, err := s.resolvePath(dirPath)
+	if err != nil {
+		return err
+	}
+	// A directory exists once any object, including its marker, has its prefix
+	if objects, err := s.backend.ListObjects(fullPath+"/", false); err == nil && len(objects) > 0 {
+		return ErrDirExists
+	}
 	// Create an empty object with trailing slash to represent directory
 	return s.backend.PutObject(fullPath+"/", strings.NewReader(""), 0)
 }
@@ -202,15 +321,33 @@ func (s *minioStorage) GetFile(filePath string, offset int64) (int64, io.ReadClo
 		return 0, nil, fmt.Errorf("read permission denied")
 	}
 
-	fullPath := s.resolvePath(filePath)
+	fullPath, err := s.resolvePath(filePath)
+	if err != nil {
+		return 0, nil, err
+	}
 
 	// Get object info for size
 	objInfo, err := s.backend.StatObject(fullPath)
 	if err != nil {
+		foldedPath, folded, ok := s.lookupFolded(fullPath)
+		if !ok {
+			return 0, nil, fmt.Errorf("file not found: %s", filePath)
+		}
+		fullPath, objInfo = foldedPath, folded
+	}
+	if !s.visible(objInfo) {
 		return 0, nil, fmt.Errorf("file not found: %s", filePath)
 	}
 
-	// Get object data
+	if offset > objInfo.Size {
+		return 0, nil, fmt.Errorf("offset %d is beyond the end of %s (%d bytes)", offset, filePath, objInfo.Size)
+	}
+	if offset > 0 && offset == objInfo.Size {
+		// Nothing left to send; a range starting at the end is unsatisfiable
+		return objInfo.Size, io.NopCloser(strings.NewReader("")), nil
+	}
+
+	// Get object data, fetching only the bytes from offset onwards
 	reader, err := s.backend.GetObject(fullPath, offset, objInfo.Size-offset)
 	if err != nil {
 		return 0, nil, fmt.Errorf("failed to get file: %w", err)
@@ -225,19 +362,33 @@ func (s *minioStorage) PutFile(filePath string, reader io.Reader, offset int64)
 		return 0, fmt.Errorf("write permission denied")
 	}
 
-	fullPath := s.resolvePath(filePath)
+	fullPath, err := s.resolvePath(filePath)
+	if err != nil {
+		return 0, err
+	}
+	if s.hidden(fullPath) {
+		return 0, fmt.Errorf("%s: %w", filePath, os.ErrPermission)
+	}
+
+	if s.resumableUploads {
+		return s.putFileResumable(fullPath, reader, offset)
+	}
 
-	// For simplicity, we don't support offset mode for now
+	// Without resumable uploads, offset mode isn't supported
 	if offset != 0 {
 		return 0, fmt.Errorf("offset mode not supported")
 	}
 
+	if s.uploadVerifyRetries > 0 {
+		return s.putFileVerified(fullPath, reader)
+	}
+
 	// Create a counting reader to track bytes uploaded
 	countingReader := &countingReader{reader: reader}
 
 	// Upload directly to MinIO with unknown size (-1 for streaming)
 	// MinIO will handle the upload efficiently without buffering entire file
-	err := s.backend.PutObject(fullPath, countingReader, -1)
+	err = s.backend.PutObject(fullPath, countingReader, -1)
 	if err != nil {
 		return 0, fmt.Errorf("failed to put file: %w", err)
 	}
@@ -245,19 +396,160 @@ func (s *minioStorage) PutFile(filePath string, reader io.Reader, offset int64)
 	return atomic.LoadInt64(&countingReader.bytesRead), nil
 }
 
-// resolvePath resolves a relative path to an absolute path within the user's home directory
-func (s *minioStorage) resolvePath(relativePath string) string {
+// uploadVerifySpoolLimit is the largest upload spooled to a temporary file
+// so it can be resent after failing verification. Larger uploads are sent
+// once, streamed, without retries.
+var uploadVerifySpoolLimit int64 = 256 << 20
+
+// putFileVerified spools an upload to a temporary file and sends it to the
+// backend, sending it again up to uploadVerifyRetries times if the stored
+// object fails size verification. Uploads over uploadVerifySpoolLimit are
+// streamed once instead.
+func (s *minioStorage) putFileVerified(fullPath string, reader io.Reader) (int64, error) {
+	spool, err := os.CreateTemp("", "kubeftpd-upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to spool upload: %w", err)
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+	size, err := io.CopyN(spool, reader, uploadVerifySpoolLimit+1)
+	if err != nil && err != io.EOF {
+		return 0, fmt.Errorf("failed to spool upload: %w", err)
+	}
+	if size > uploadVerifySpoolLimit {
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			return 0, fmt.Errorf("failed to rewind spooled upload: %w", err)
+		}
+		ctrl.Log.WithName("minio").Info("Upload too large to spool for verification retries, sending it once",
+			"path", fullPath, "backend", s.backendName, "spool_limit_bytes", uploadVerifySpoolLimit)
+		countingReader := &countingReader{reader: io.MultiReader(spool, reader)}
+		if err := s.backend.PutObject(fullPath, countingReader, -1); err != nil {
+			return 0, fmt.Errorf("failed to put file: %w", err)
+		}
+		return atomic.LoadInt64(&countingReader.bytesRead), nil
+	}
+
+	for attempt := 0; ; attempt++ {
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			return 0, fmt.Errorf("failed to rewind spooled upload: %w", err)
+		}
+		err = s.backend.PutObject(fullPath, spool, size)
+		if err == nil {
+			return size, nil
+		}
+		if !errors.Is(err, backends.ErrUploadVerification) || attempt == s.uploadVerifyRetries {
+			return 0, fmt.Errorf("failed to put file: %w", err)
+		}
+		metrics.RecordUploadVerifyRetry(s.backendName)
+		ctrl.Log.WithName("minio").Info("Upload failed verification, retrying",
+			"path", fullPath, "backend", s.backendName, "attempt", attempt+1, "error", err.Error())
+	}
+}
+
+// lookupFolded finds an object in fullPath's directory whose name matches it
+// ignoring case, for backends with CaseInsensitiveLookup. It returns the
+// object's path and info, and false when folding is off or nothing matches.
+func (s *minioStorage) lookupFolded(fullPath string) (string, *backends.ObjectInfo, bool) {
+	if !s.caseInsensitiveLookup {
+		return "", nil, false
+	}
+	dir, name := path.Split(fullPath)
+	objects, err := s.backend.ListObjects(dir, false)
+	if err != nil {
+		return "", nil, false
+	}
+	for _, obj := range objects {
+		if strings.HasSuffix(obj.Key, "/") || !s.visible(obj) {
+			continue
+		}
+		if candidate := path.Base(obj.Key); strings.EqualFold(candidate, name) {
+			return dir + candidate, obj, true
+		}
+	}
+	return "", nil, false
+}
+
+// visible reports whether obj carries the backend's required object tag, if any
+func (s *minioStorage) visible(obj *backends.ObjectInfo) bool {
+	if s.objectTagKey == "" {
+		return true
+	}
+	value, ok := obj.Tags[s.objectTagKey]
+	return ok && value == s.objectTagValue
+}
+
+// hidden reports whether an object exists at fullPath without the required
+// object tag, so it must not be touched
+func (s *minioStorage) hidden(fullPath string) bool {
+	if s.objectTagKey == "" {
+		return false
+	}
+	objInfo, err := s.backend.StatObject(fullPath)
+	return err == nil && !s.visible(objInfo)
+}
+
+// Capabilities reports ranged downloads, and appends when resumable uploads are enabled
+func (s *minioStorage) Capabilities() Capabilities {
+	return Capabilities{Append: s.resumableUploads, Range: true}
+}
+
+// resolvePath resolves a relative path to an object key within the user's home directory
+func (s *minioStorage) resolvePath(relativePath string) (string, error) {
 	if relativePath == "" || relativePath == "." {
-		return s.currentDir
+		return s.objectKey(s.currentDir), nil
+	}
+
+	if s.keyNormalization == "strict" {
+		normalized, err := normalizeObjectPath(relativePath)
+		if err != nil {
+			return "", err
+		}
+		relativePath = normalized
 	}
 
 	if strings.HasPrefix(relativePath, "/") {
 		// Absolute path relative to home directory
-		return path.Join(s.basePath, relativePath)
+		return s.objectKey(path.Join(s.basePath, relativePath)), nil
 	}
 
 	// Relative path from current directory
-	return path.Join(s.currentDir, relativePath)
+	return s.objectKey(path.Join(s.currentDir, relativePath)), nil
+}
+
+// objectKey drops the leading slash from a resolved path when the backend
+// has StripLeadingSlash set
+func (s *minioStorage) objectKey(p string) string {
+	if s.stripLeadingSlash {
+		return strings.TrimPrefix(p, "/")
+	}
+	return p
+}
+
+// normalizeObjectPath rejects names that cannot map to a sensible object key and
+// strips trailing dots from each segment. Duplicate slashes are collapsed by the
+// subsequent path.Join.
+func normalizeObjectPath(p string) (string, error) {
+	for _, r := range p {
+		if unicode.IsControl(r) {
+			return "", fmt.Errorf("invalid file name: contains control characters")
+		}
+	}
+
+	segments := strings.Split(p, "/")
+	for i, seg := range segments {
+		if seg == "" || seg == "." || seg == ".." {
+			continue
+		}
+		trimmed := strings.TrimRight(seg, ".")
+		if trimmed == "" {
+			return "", fmt.Errorf("invalid file name: %q", seg)
+		}
+		segments[i] = trimmed
+	}
+
+	return strings.Join(segments, "/"), nil
 }
 
 // minioFileInfo implements server.FileInfo interface
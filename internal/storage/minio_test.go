@@ -2,18 +2,22 @@ package storage
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
 	"github.com/rossigee/kubeftpd/internal/backends"
+	"github.com/rossigee/kubeftpd/internal/metrics"
 )
 
 // MockMinioBackend for testing
@@ -55,6 +59,28 @@ func (m *MockMinioBackend) CopyObject(srcObject, dstObject string, deleteSource
 	return args.Error(0)
 }
 
+func (m *MockMinioBackend) NewMultipartUpload(objectName string) (string, error) {
+	args := m.Called(objectName)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockMinioBackend) UploadPart(objectName, uploadID string, partNumber int, reader io.Reader, size int64) (backends.UploadedPart, error) {
+	// Read the part so expectations can match on its content
+	data, _ := io.ReadAll(reader)
+	args := m.Called(objectName, uploadID, partNumber, data, size)
+	return args.Get(0).(backends.UploadedPart), args.Error(1)
+}
+
+func (m *MockMinioBackend) CompleteMultipartUpload(objectName, uploadID string, parts []backends.UploadedPart) error {
+	args := m.Called(objectName, uploadID, parts)
+	return args.Error(0)
+}
+
+func (m *MockMinioBackend) AbortMultipartUpload(objectName, uploadID string) error {
+	args := m.Called(objectName, uploadID)
+	return args.Error(0)
+}
+
 func (m *MockMinioBackend) ListObjects(prefix string, recursive bool) ([]*backends.ObjectInfo, error) {
 	args := m.Called(prefix, recursive)
 	return args.Get(0).([]*backends.ObjectInfo), args.Error(1)
@@ -180,6 +206,91 @@ func TestMinioStorage_ListDir(t *testing.T) {
 	mockBackend.AssertExpectations(t)
 }
 
+func TestMinioStorage_ListDir_File(t *testing.T) {
+	user := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "testuser"},
+		Spec: ftpv1.UserSpec{
+			Username:      "testuser",
+			HomeDirectory: "/home/testuser",
+			Permissions:   ftpv1.UserPermissions{List: true},
+		},
+	}
+
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mockBackend := &MockMinioBackend{}
+	// Listing the key as a prefix also finds siblings that start with it
+	mockBackend.On("ListObjects", "/home/testuser/report.csv", false).Return([]*backends.ObjectInfo{
+		{Key: "home/testuser/report.csv", Size: 1024, LastModified: modTime},
+		{Key: "home/testuser/report.csv.bak", Size: 512, LastModified: modTime},
+	}, nil)
+
+	storage := &minioStorage{
+		user:       user,
+		backend:    mockBackend,
+		basePath:   "/home/testuser",
+		currentDir: "/home/testuser",
+	}
+
+	var entries []os.FileInfo
+	err := storage.ListDir("report.csv", func(info os.FileInfo) error {
+		entries = append(entries, info)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "report.csv", entries[0].Name())
+	assert.Equal(t, int64(1024), entries[0].Size())
+	assert.Equal(t, modTime, entries[0].ModTime())
+	assert.False(t, entries[0].IsDir())
+
+	mockBackend.AssertExpectations(t)
+}
+
+func TestMinioStorage_ListDir_EmptyDirectory(t *testing.T) {
+	user := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "testuser"},
+		Spec: ftpv1.UserSpec{
+			Username:      "testuser",
+			HomeDirectory: "/home/testuser",
+			Permissions:   ftpv1.UserPermissions{List: true},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		objects []*backends.ObjectInfo
+	}{
+		{name: "no objects", objects: []*backends.ObjectInfo{}},
+		{name: "directory marker", objects: []*backends.ObjectInfo{{Key: "home/testuser/empty/"}}},
+		{name: "sibling sharing the prefix", objects: []*backends.ObjectInfo{
+			{Key: "home/testuser/empty/"},
+			{Key: "home/testuser/empty2/"},
+			{Key: "home/testuser/empty.txt", Size: 4},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBackend := &MockMinioBackend{}
+			mockBackend.On("ListObjects", "/home/testuser/empty", false).Return(tt.objects, nil)
+			storage := &minioStorage{
+				user:       user,
+				backend:    mockBackend,
+				basePath:   "/home/testuser",
+				currentDir: "/home/testuser",
+			}
+
+			calls := 0
+			err := storage.ListDir("empty", func(os.FileInfo) error {
+				calls++
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, 0, calls)
+		})
+	}
+}
+
 func TestMinioStorage_DeleteFile(t *testing.T) {
 	user := &ftpv1.User{
 		ObjectMeta: metav1.ObjectMeta{
@@ -354,6 +465,61 @@ func TestMinioStorage_PutFile(t *testing.T) {
 	mockBackend.AssertExpectations(t)
 }
 
+func TestMinioStorage_StripLeadingSlash(t *testing.T) {
+	user := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testuser",
+		},
+		Spec: ftpv1.UserSpec{
+			Username:      "testuser",
+			HomeDirectory: "/home/testuser",
+			Permissions: ftpv1.UserPermissions{
+				Read:  true,
+				Write: true,
+				List:  true,
+			},
+		},
+	}
+
+	mockBackend := &MockMinioBackend{}
+	mockBackend.On("PutObject", "home/testuser/file.txt", mock.Anything, int64(-1)).Return(nil)
+	mockBackend.On("ListObjects", "home/testuser", false).Return([]*backends.ObjectInfo{
+		{Key: "home/testuser/file.txt", Size: 17},
+		{Key: "home/testuser/reports/q1.pdf", Size: 2048},
+	}, nil)
+	mockBackend.On("ListObjects", "home/testuser/reports", false).Return([]*backends.ObjectInfo{
+		{Key: "home/testuser/reports/q1.pdf", Size: 2048},
+	}, nil)
+
+	storage := &minioStorage{
+		user:              user,
+		backend:           mockBackend,
+		basePath:          "/home/testuser",
+		currentDir:        "/home/testuser",
+		stripLeadingSlash: true,
+	}
+
+	size, err := storage.PutFile("/file.txt", strings.NewReader("test file content"), 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(17), size)
+
+	listNames := func(dir string) []string {
+		var names []string
+		require.NoError(t, storage.ListDir(dir, func(info os.FileInfo) error {
+			names = append(names, info.Name())
+			return nil
+		}))
+		return names
+	}
+	assert.ElementsMatch(t, []string{"file.txt", "reports"}, listNames("/"))
+
+	// Relative paths resolve against the stripped working directory
+	require.NoError(t, storage.ChangeDir("reports"))
+	assert.ElementsMatch(t, []string{"q1.pdf"}, listNames(""))
+
+	mockBackend.AssertExpectations(t)
+}
+
 // NOTE: MinIO storage layer write verification is tested through the backend layer.
 // The enhanced verification includes:
 // 1. StatObject verification after PutObject to confirm upload completion
@@ -364,14 +530,16 @@ func TestMinioStorage_PutFile(t *testing.T) {
 
 func TestMinioStorage_resolvePath(t *testing.T) {
 	storage := &minioStorage{
-		basePath:   "/home/testuser",
-		currentDir: "/home/testuser/subdir",
+		basePath:         "/home/testuser",
+		currentDir:       "/home/testuser/subdir",
+		keyNormalization: "strict",
 	}
 
 	tests := []struct {
 		name         string
 		relativePath string
 		expected     string
+		wantErr      bool
 	}{
 		{
 			name:         "empty path",
@@ -403,16 +571,66 @@ func TestMinioStorage_resolvePath(t *testing.T) {
 			relativePath: "../file.txt",
 			expected:     "/home/testuser/file.txt",
 		},
+		{
+			name:         "double slashes",
+			relativePath: "//documents//file.txt",
+			expected:     "/home/testuser/documents/file.txt",
+		},
+		{
+			name:         "trailing dots",
+			relativePath: "reports./file.txt..",
+			expected:     "/home/testuser/subdir/reports/file.txt",
+		},
+		{
+			name:         "embedded null",
+			relativePath: "file\x00.txt",
+			wantErr:      true,
+		},
+		{
+			name:         "control character",
+			relativePath: "file\n.txt",
+			wantErr:      true,
+		},
+		{
+			name:         "dots only segment",
+			relativePath: "docs/.../file.txt",
+			wantErr:      true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := storage.resolvePath(tt.relativePath)
+			result, err := storage.resolvePath(tt.relativePath)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
 
+func TestMinioStorage_resolvePath_NoNormalization(t *testing.T) {
+	// Keys pass through unchanged by default, so existing objects whose keys
+	// end in a dot stay reachable
+	for _, mode := range []string{"", "none"} {
+		storage := &minioStorage{
+			basePath:         "/home/testuser",
+			currentDir:       "/home/testuser",
+			keyNormalization: mode,
+		}
+
+		result, err := storage.resolvePath("file.txt.")
+		assert.NoError(t, err)
+		assert.Equal(t, "/home/testuser/file.txt.", result)
+
+		result, err = storage.resolvePath("file\x00.txt")
+		assert.NoError(t, err)
+		assert.Equal(t, "/home/testuser/file\x00.txt", result)
+	}
+}
+
 // Regression test for empty directory handling
 func TestMinioStorage_Stat_EmptyDirectory(t *testing.T) {
 	user := &ftpv1.User{
@@ -654,10 +872,86 @@ func TestMinioStorage_MakeDir(t *testing.T) {
 	}
 
 	// MakeDir should create an empty object with trailing slash to represent directory
+	mockBackend.On("ListObjects", "/home/testuser/newdir/", false).Return([]*backends.ObjectInfo{}, nil)
 	mockBackend.On("PutObject", "/home/testuser/newdir/", mock.Anything, int64(0)).Return(nil)
 
 	err := storage.MakeDir("newdir")
-	assert.NoError(t, err, "MakeDir should always succeed in object storage")
+	assert.NoError(t, err)
+
+	// A prefix that already holds objects is an existing directory
+	mockBackend.On("ListObjects", "/home/testuser/existing/", false).Return([]*backends.ObjectInfo{
+		{Key: "/home/testuser/existing/file.txt", Size: 10},
+	}, nil)
+	err = storage.MakeDir("existing")
+	assert.ErrorIs(t, err, ErrDirExists)
+	mockBackend.AssertNotCalled(t, "PutObject", "/home/testuser/existing/", mock.Anything, int64(0))
+}
+
+func TestMinioStorage_GetFile_Offset(t *testing.T) {
+	user := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testuser",
+		},
+		Spec: ftpv1.UserSpec{
+			Username:      "testuser",
+			HomeDirectory: "/home/testuser",
+			Permissions: ftpv1.UserPermissions{
+				Read: true,
+			},
+		},
+	}
+	objectInfo := &backends.ObjectInfo{
+		Key:  "large.bin",
+		Size: 1024,
+	}
+
+	tests := []struct {
+		name        string
+		offset      int64
+		wantRange   bool
+		wantContent string
+		wantErr     string
+	}{
+		{name: "nonzero offset requests only the remaining bytes", offset: 100, wantRange: true, wantContent: "tail"},
+		{name: "offset at end returns no data", offset: 1024, wantContent: ""},
+		{name: "offset beyond end", offset: 2048, wantErr: "offset 2048 is beyond the end of large.bin (1024 bytes)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBackend := &MockMinioBackend{}
+			mockBackend.On("StatObject", "/home/testuser/large.bin").Return(objectInfo, nil)
+			if tt.wantRange {
+				mockBackend.On("GetObject", "/home/testuser/large.bin", tt.offset, objectInfo.Size-tt.offset).
+					Return(io.NopCloser(strings.NewReader(tt.wantContent)), nil)
+			}
+
+			storage := &minioStorage{
+				user:       user,
+				backend:    mockBackend,
+				basePath:   "/home/testuser",
+				currentDir: "/home/testuser",
+			}
+
+			size, reader, err := storage.GetFile("large.bin", tt.offset)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				mockBackend.AssertNotCalled(t, "GetObject", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(1024), size)
+			defer func() { _ = reader.Close() }()
+
+			data, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantContent, string(data))
+			if !tt.wantRange {
+				mockBackend.AssertNotCalled(t, "GetObject", mock.Anything, mock.Anything, mock.Anything)
+			}
+			mockBackend.AssertExpectations(t)
+		})
+	}
 }
 
 func TestMinioStorage_GetFile_PermissionDenied(t *testing.T) {
@@ -793,3 +1087,217 @@ func TestMinioFileInfo_Methods(t *testing.T) {
 	assert.Equal(t, "testdir", dirInfo.Name())
 	assert.Equal(t, int64(0), dirInfo.Size())
 }
+
+func TestMinioStorage_CaseInsensitiveLookup(t *testing.T) {
+	user := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testuser",
+		},
+		Spec: ftpv1.UserSpec{
+			Username:      "testuser",
+			HomeDirectory: "/home/testuser",
+			Permissions: ftpv1.UserPermissions{
+				Read: true,
+			},
+		},
+	}
+
+	newStorage := func(folding bool) (*minioStorage, *MockMinioBackend) {
+		mockBackend := &MockMinioBackend{}
+		mockBackend.On("StatObject", "/home/testuser/File.TXT").Return((*backends.ObjectInfo)(nil), errors.New("object not found"))
+		mockBackend.On("ListObjects", "/home/testuser/", false).Return([]*backends.ObjectInfo{
+			{Key: "home/testuser/reports/"},
+			{Key: "home/testuser/file.txt", Size: 12},
+		}, nil)
+		mockBackend.On("GetObject", "/home/testuser/file.txt", int64(0), int64(12)).Return(io.NopCloser(strings.NewReader("hello, world")), nil)
+		return &minioStorage{
+			user:                  user,
+			backend:               mockBackend,
+			basePath:              "/home/testuser",
+			currentDir:            "/home/testuser",
+			backendName:           "test-backend",
+			caseInsensitiveLookup: folding,
+		}, mockBackend
+	}
+
+	t.Run("folding on", func(t *testing.T) {
+		storage, _ := newStorage(true)
+
+		info, err := storage.Stat("File.TXT")
+		require.NoError(t, err)
+		assert.Equal(t, "file.txt", info.Name())
+		assert.Equal(t, int64(12), info.Size())
+
+		size, reader, err := storage.GetFile("File.TXT", 0)
+		require.NoError(t, err)
+		defer func() { _ = reader.Close() }()
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, int64(12), size)
+		assert.Equal(t, "hello, world", string(data))
+	})
+
+	t.Run("folding off", func(t *testing.T) {
+		storage, mockBackend := newStorage(false)
+
+		_, err := storage.Stat("File.TXT")
+		assert.ErrorContains(t, err, "file not found")
+
+		_, _, err = storage.GetFile("File.TXT", 0)
+		assert.ErrorContains(t, err, "file not found")
+		mockBackend.AssertNotCalled(t, "ListObjects", mock.Anything, mock.Anything)
+		mockBackend.AssertNotCalled(t, "GetObject", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestMinioStorage_RequiredObjectTag(t *testing.T) {
+	user := &ftpv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "testuser"},
+		Spec: ftpv1.UserSpec{
+			Username:      "testuser",
+			HomeDirectory: "/home/testuser",
+			Permissions:   ftpv1.UserPermissions{Read: true, Write: true, Delete: true, List: true},
+		},
+	}
+	mine := &backends.ObjectInfo{Key: "home/testuser/mine.txt", Size: 4, Tags: map[string]string{"tenant": "acme"}}
+	untagged := &backends.ObjectInfo{Key: "home/testuser/untagged.txt", Size: 5}
+	theirs := &backends.ObjectInfo{Key: "home/testuser/theirs.txt", Size: 6, Tags: map[string]string{"tenant": "globex"}}
+
+	newStorage := func() (*minioStorage, *MockMinioBackend) {
+		mockBackend := &MockMinioBackend{}
+		mockBackend.On("ListObjects", "/home/testuser", false).Return([]*backends.ObjectInfo{
+			mine, untagged, theirs, {Key: "home/testuser/shared/"},
+		}, nil)
+		mockBackend.On("StatObject", "/home/testuser/mine.txt").Return(mine, nil)
+		mockBackend.On("StatObject", "/home/testuser/untagged.txt").Return(untagged, nil)
+		mockBackend.On("StatObject", "/home/testuser/theirs.txt").Return(theirs, nil)
+		return &minioStorage{
+			user:           user,
+			backend:        mockBackend,
+			basePath:       "/home/testuser",
+			currentDir:     "/home/testuser",
+			objectTagKey:   "tenant",
+			objectTagValue: "acme",
+		}, mockBackend
+	}
+
+	t.Run("listing shows only tagged objects", func(t *testing.T) {
+		storage, _ := newStorage()
+		var names []string
+		require.NoError(t, storage.ListDir("", func(info os.FileInfo) error {
+			names = append(names, info.Name())
+			return nil
+		}))
+		assert.ElementsMatch(t, []string{"mine.txt", "shared"}, names)
+	})
+
+	t.Run("tagged object is accessible", func(t *testing.T) {
+		storage, mockBackend := newStorage()
+		mockBackend.On("GetObject", "/home/testuser/mine.txt", int64(0), int64(4)).
+			Return(io.NopCloser(strings.NewReader("data")), nil)
+
+		info, err := storage.Stat("mine.txt")
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), info.Size())
+		size, reader, err := storage.GetFile("mine.txt", 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), size)
+		require.NoError(t, reader.Close())
+	})
+
+	for _, name := range []string{"untagged.txt", "theirs.txt"} {
+		t.Run(name+" is not accessible", func(t *testing.T) {
+			storage, mockBackend := newStorage()
+
+			_, err := storage.Stat(name)
+			assert.ErrorContains(t, err, "file not found")
+			_, _, err = storage.GetFile(name, 0)
+			assert.ErrorContains(t, err, "file not found")
+			assert.ErrorContains(t, storage.DeleteFile(name), "file not found")
+			assert.ErrorContains(t, storage.Rename(name, "renamed.txt"), "file not found")
+			_, err = storage.PutFile(name, strings.NewReader("overwrite"), 0)
+			assert.ErrorIs(t, err, os.ErrPermission)
+
+			mockBackend.AssertNotCalled(t, "GetObject", mock.Anything, mock.Anything, mock.Anything)
+			mockBackend.AssertNotCalled(t, "RemoveObject", mock.Anything)
+			mockBackend.AssertNotCalled(t, "CopyObject", mock.Anything, mock.Anything, mock.Anything)
+			mockBackend.AssertNotCalled(t, "PutObject", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestMinioStorage_PutFile_UploadVerifyRetries(t *testing.T) {
+	user := &ftpv1.User{
+		Spec: ftpv1.UserSpec{
+			Username:      "testuser",
+			HomeDirectory: "/home/testuser",
+			Permissions:   ftpv1.UserPermissions{Write: true},
+		},
+	}
+	mismatch := fmt.Errorf("object size verification failed: %w", backends.ErrUploadVerification)
+	newStorage := func(backend *MockMinioBackend) *minioStorage {
+		return &minioStorage{
+			user:                user,
+			backend:             backend,
+			basePath:            "/home/testuser",
+			currentDir:          "/home/testuser",
+			backendName:         "flaky",
+			uploadVerifyRetries: 2,
+		}
+	}
+
+	t.Run("retry succeeds", func(t *testing.T) {
+		retries := metrics.UploadVerifyRetriesTotal.WithLabelValues("flaky")
+		before := testutil.ToFloat64(retries)
+		mockBackend := &MockMinioBackend{}
+		// The spooled upload is resent with its size known
+		mockBackend.On("PutObject", "/home/testuser/scan.pdf", mock.Anything, int64(13)).Return(mismatch).Once()
+		mockBackend.On("PutObject", "/home/testuser/scan.pdf", mock.Anything, int64(13)).Return(nil).Once()
+
+		size, err := newStorage(mockBackend).PutFile("scan.pdf", strings.NewReader("scan contents"), 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(13), size)
+		mockBackend.AssertNumberOfCalls(t, "PutObject", 2)
+		assert.Equal(t, before+1, testutil.ToFloat64(retries))
+	})
+
+	t.Run("all retries fail", func(t *testing.T) {
+		mockBackend := &MockMinioBackend{}
+		mockBackend.On("PutObject", "/home/testuser/scan.pdf", mock.Anything, int64(13)).Return(mismatch)
+
+		_, err := newStorage(mockBackend).PutFile("scan.pdf", strings.NewReader("scan contents"), 0)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, backends.ErrUploadVerification)
+		mockBackend.AssertNumberOfCalls(t, "PutObject", 3)
+	})
+
+	t.Run("other errors are not retried", func(t *testing.T) {
+		mockBackend := &MockMinioBackend{}
+		mockBackend.On("PutObject", "/home/testuser/scan.pdf", mock.Anything, int64(13)).Return(errors.New("access denied"))
+
+		_, err := newStorage(mockBackend).PutFile("scan.pdf", strings.NewReader("scan contents"), 0)
+		require.Error(t, err)
+		mockBackend.AssertNumberOfCalls(t, "PutObject", 1)
+	})
+
+	t.Run("uploads over the spool limit are streamed once", func(t *testing.T) {
+		previous := uploadVerifySpoolLimit
+		uploadVerifySpoolLimit = 4
+		t.Cleanup(func() { uploadVerifySpoolLimit = previous })
+
+		mockBackend := &MockMinioBackend{}
+		mockBackend.On("PutObject", "/home/testuser/scan.pdf", mock.Anything, int64(-1)).Return(nil).Once()
+
+		// The spooled start and the rest of the stream are both sent
+		size, err := newStorage(mockBackend).PutFile("scan.pdf", strings.NewReader("scan contents"), 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(13), size)
+
+		// Without a spool a failed verification can't be resent
+		mockBackend = &MockMinioBackend{}
+		mockBackend.On("PutObject", "/home/testuser/scan.pdf", mock.Anything, int64(-1)).Return(mismatch)
+		_, err = newStorage(mockBackend).PutFile("scan.pdf", strings.NewReader("scan contents"), 0)
+		assert.ErrorIs(t, err, backends.ErrUploadVerification)
+		mockBackend.AssertNumberOfCalls(t, "PutObject", 1)
+	})
+}
@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+const (
+	// mirrorQueueSize bounds the uploads waiting to be copied to mirrors.
+	// Uploads finishing while the queue is full are not mirrored.
+	mirrorQueueSize = 256
+	// mirrorWorkers is the number of copies to mirrors running at once
+	mirrorWorkers = 4
+)
+
+// mirrorRetryDelays are the waits before each retry of a failed mirror copy
+var mirrorRetryDelays = []time.Duration{time.Second, 10 * time.Second, time.Minute}
+
+// mirrorSpoolLimit bounds the bytes spooled to temporary files for mirroring,
+// across all users, so a slow or unreachable mirror cannot fill the pod's
+// disk. An upload that would exceed it is not mirrored.
+var mirrorSpoolLimit int64 = 1 << 30
+
+// mirrorSpoolBytes counts the bytes currently spooled for mirroring
+var mirrorSpoolBytes atomic.Int64
+
+// errMirrorSpoolFull stops spooling an upload once mirrorSpoolLimit is reached
+var errMirrorSpoolFull = errors.New("mirror spool limit reached")
+
+var (
+	mirrorQueueOnce sync.Once
+	mirrorQueue     chan *mirrorJob
+)
+
+// withMirror copies uploads to s to the user's MirrorBackend in the
+// background. If the mirror backend cannot be set up the error is logged and
+// s is returned unchanged, so a broken mirror never blocks logins.
+func withMirror(ctx context.Context, s Storage, user *ftpv1.User, kubeClient client.Client) Storage {
+	ref := *user.Spec.MirrorBackend
+	mirrorUser := user.DeepCopy()
+	mirrorUser.Spec.Backend = ref
+	mirrorUser.Spec.MirrorBackend = nil
+
+	mirror, err := newBackendStorage(ctx, mirrorUser, kubeClient)
+	if err != nil {
+		ctrl.Log.WithName("mirror").Error(err, "Failed to create mirror backend, uploads will not be mirrored",
+			"username", user.Spec.Username, "backend_kind", ref.Kind, "backend_name", ref.Name)
+		metrics.RecordBackendError(ref.Kind, ref.Name, "mirror")
+		return s
+	}
+	return newMirrorStorage(s, mirror, ref)
+}
+
+func newMirrorStorage(s, mirror Storage, ref ftpv1.BackendReference) *mirrorStorage {
+	mirrorQueueOnce.Do(func() {
+		mirrorQueue = make(chan *mirrorJob, mirrorQueueSize)
+		for i := 0; i < mirrorWorkers; i++ {
+			go runMirrorWorker()
+		}
+	})
+	return &mirrorStorage{Storage: s, mirror: mirror, ref: ref}
+}
+
+// mirrorStorage spools each whole-file upload to a temporary file while it is
+// written to the primary storage, then queues a copy to the mirror. Resumed
+// uploads, deletes, renames and new directories are not mirrored.
+type mirrorStorage struct {
+	Storage
+	mirror Storage
+	ref    ftpv1.BackendReference
+	// pending counts queued copies, which keep the mirror open after Close
+	pending sync.WaitGroup
+}
+
+// mirrorJob is an upload waiting to be copied to a mirror
+type mirrorJob struct {
+	storage *mirrorStorage
+	path    string
+	spool   *spoolWriter
+}
+
+// spoolWriter copies an upload to a temporary file, counting its bytes
+// against mirrorSpoolLimit. A failed write is kept rather than returned so
+// that it cannot fail the primary upload.
+type spoolWriter struct {
+	file *os.File
+	// reserved is the part of mirrorSpoolBytes held by this spool
+	reserved int64
+	err      error
+}
+
+func (w *spoolWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return len(p), nil
+	}
+	n := int64(len(p))
+	if mirrorSpoolBytes.Add(n) > mirrorSpoolLimit {
+		mirrorSpoolBytes.Add(-n)
+		w.err = errMirrorSpoolFull
+		// Give back the disk space right away rather than after the upload
+		_ = w.file.Truncate(0)
+		w.release()
+		return len(p), nil
+	}
+	w.reserved += n
+	_, w.err = w.file.Write(p)
+	return len(p), nil
+}
+
+// release gives the spool's bytes back to mirrorSpoolLimit
+func (w *spoolWriter) release() {
+	mirrorSpoolBytes.Add(-w.reserved)
+	w.reserved = 0
+}
+
+// remove deletes the spool file and releases its bytes
+func (w *spoolWriter) remove() {
+	_ = os.Remove(w.file.Name())
+	w.release()
+}
+
+func (s *mirrorStorage) PutFile(filePath string, reader io.Reader, offset int64) (int64, error) {
+	if offset != 0 {
+		return s.Storage.PutFile(filePath, reader, offset)
+	}
+
+	file, err := os.CreateTemp("", "kubeftpd-mirror-*")
+	if err != nil {
+		s.logger().Error(err, "Failed to spool upload for mirroring", "path", filePath)
+		s.record("failed")
+		return s.Storage.PutFile(filePath, reader, offset)
+	}
+	spool := &spoolWriter{file: file}
+	written, err := s.Storage.PutFile(filePath, io.TeeReader(reader, spool), offset)
+	closeErr := file.Close()
+	if err != nil {
+		spool.remove()
+		return written, err
+	}
+	if spool.err == nil {
+		spool.err = closeErr
+	}
+	if errors.Is(spool.err, errMirrorSpoolFull) {
+		spool.remove()
+		s.logger().Info("Mirror spool full, upload not mirrored", "path", filePath, "spool_limit_bytes", mirrorSpoolLimit)
+		s.record("dropped")
+		return written, nil
+	}
+	if spool.err != nil {
+		spool.remove()
+		s.logger().Error(spool.err, "Failed to spool upload for mirroring", "path", filePath)
+		s.record("failed")
+		return written, nil
+	}
+
+	s.pending.Add(1)
+	select {
+	case mirrorQueue <- &mirrorJob{storage: s, path: filePath, spool: spool}:
+	default:
+		s.pending.Done()
+		spool.remove()
+		s.logger().Info("Mirror queue full, upload not mirrored", "path", filePath)
+		s.record("dropped")
+	}
+	return written, nil
+}
+
+// Close closes the primary storage now and the mirror once its queued copies finish
+func (s *mirrorStorage) Close() error {
+	go func() {
+		s.pending.Wait()
+		_ = s.mirror.Close()
+	}()
+	return s.Storage.Close()
+}
+
+func (s *mirrorStorage) logger() logr.Logger {
+	return ctrl.Log.WithName("mirror").WithValues("backend_kind", s.ref.Kind, "backend_name", s.ref.Name)
+}
+
+func (s *mirrorStorage) record(result string) {
+	metrics.RecordMirrorUpload(s.ref.Kind, s.ref.Name, result)
+}
+
+// runMirrorWorker copies queued uploads to their mirrors until the process exits
+func runMirrorWorker() {
+	for job := range mirrorQueue {
+		job.run()
+	}
+}
+
+// run copies the spooled upload, retrying failures after mirrorRetryDelays
+func (job *mirrorJob) run() {
+	s := job.storage
+	defer s.pending.Done()
+	defer job.spool.remove()
+
+	for attempt := 0; ; attempt++ {
+		err := job.copy()
+		if err == nil {
+			s.record("success")
+			return
+		}
+		if attempt == len(mirrorRetryDelays) {
+			s.logger().Error(err, "Failed to mirror upload, giving up", "path", job.path, "attempts", attempt+1)
+			s.record("failed")
+			return
+		}
+		s.logger().Info("Failed to mirror upload, retrying", "path", job.path, "attempt", attempt+1, "error", err.Error())
+		time.Sleep(mirrorRetryDelays[attempt])
+	}
+}
+
+func (job *mirrorJob) copy() error {
+	file, err := os.Open(job.spool.file.Name())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	mirror := job.storage.mirror
+	if mirror.Capabilities().RequireParents {
+		if err := makeParentDirs(mirror, path.Dir(job.path)); err != nil {
+			return err
+		}
+	}
+	_, err = mirror.PutFile(job.path, file, 0)
+	return err
+}
+
+// makeParentDirs creates dir and any missing ancestors in s, which the
+// primary storage may have had already
+func makeParentDirs(s Storage, dir string) error {
+	if dir == "/" || dir == "." {
+		return nil
+	}
+	if info, err := s.Stat(dir); err == nil && info.IsDir() {
+		return nil
+	}
+	if err := makeParentDirs(s, path.Dir(dir)); err != nil {
+		return err
+	}
+	if err := s.MakeDir(dir); err != nil && !errors.Is(err, ErrDirExists) {
+		return err
+	}
+	return nil
+}
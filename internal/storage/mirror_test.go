@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/metrics"
+)
+
+// recordingStorage keeps uploaded files in memory, failing the first
+// failures uploads
+type recordingStorage struct {
+	Storage
+	mu       sync.Mutex
+	files    map[string]string
+	attempts int
+	failures int
+	closed   bool
+}
+
+func newRecordingStorage(failures int) *recordingStorage {
+	return &recordingStorage{files: map[string]string{}, failures: failures}
+}
+
+func (s *recordingStorage) PutFile(path string, reader io.Reader, offset int64) (int64, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts++
+	if s.attempts <= s.failures {
+		return 0, errors.New("backend unavailable")
+	}
+	s.files[path] = string(data)
+	return int64(len(data)), nil
+}
+
+func (s *recordingStorage) Capabilities() Capabilities {
+	return Capabilities{}
+}
+
+func (s *recordingStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *recordingStorage) file(path string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.files[path]
+	return data, ok
+}
+
+func (s *recordingStorage) attemptCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts
+}
+
+func (s *recordingStorage) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+func shortMirrorRetries(t *testing.T) {
+	t.Helper()
+	previous := mirrorRetryDelays
+	mirrorRetryDelays = []time.Duration{10 * time.Millisecond, 10 * time.Millisecond}
+	t.Cleanup(func() { mirrorRetryDelays = previous })
+}
+
+func TestMirrorStorage_CopiesUpload(t *testing.T) {
+	shortMirrorRetries(t)
+	ref := ftpv1.BackendReference{Kind: "MinioBackend", Name: "mirror-copies"}
+	success := metrics.MirrorUploadsTotal.WithLabelValues(ref.Kind, ref.Name, "success")
+	before := testutil.ToFloat64(success)
+
+	primary, mirror := newRecordingStorage(0), newRecordingStorage(0)
+	s := newMirrorStorage(primary, mirror, ref)
+
+	written, err := s.PutFile("/home/scanner/scan.pdf", strings.NewReader("scan contents"), 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(13), written)
+	data, ok := primary.file("/home/scanner/scan.pdf")
+	require.True(t, ok, "upload should reach the primary before PutFile returns")
+	assert.Equal(t, "scan contents", data)
+
+	require.Eventually(t, func() bool {
+		data, ok := mirror.file("/home/scanner/scan.pdf")
+		return ok && data == "scan contents"
+	}, 5*time.Second, 10*time.Millisecond, "upload should be copied to the mirror")
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(success) == before+1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, s.Close())
+	assert.True(t, primary.isClosed())
+	require.Eventually(t, mirror.isClosed, 5*time.Second, 10*time.Millisecond,
+		"mirror should close once its copies finish")
+}
+
+func TestMirrorStorage_RetriesFailures(t *testing.T) {
+	shortMirrorRetries(t)
+	ref := ftpv1.BackendReference{Kind: "MinioBackend", Name: "mirror-retries"}
+	primary, mirror := newRecordingStorage(0), newRecordingStorage(2)
+	s := newMirrorStorage(primary, mirror, ref)
+
+	written, err := s.PutFile("/home/scanner/scan.pdf", strings.NewReader("scan contents"), 0)
+	require.NoError(t, err, "mirror failures must not fail the upload")
+	assert.Equal(t, int64(13), written)
+
+	require.Eventually(t, func() bool {
+		_, ok := mirror.file("/home/scanner/scan.pdf")
+		return ok
+	}, 5*time.Second, 10*time.Millisecond, "copy should succeed on the third attempt")
+	assert.Equal(t, 3, mirror.attemptCount())
+}
+
+func TestMirrorStorage_GivesUpAfterRetries(t *testing.T) {
+	shortMirrorRetries(t)
+	ref := ftpv1.BackendReference{Kind: "MinioBackend", Name: "mirror-gives-up"}
+	failed := metrics.MirrorUploadsTotal.WithLabelValues(ref.Kind, ref.Name, "failed")
+	before := testutil.ToFloat64(failed)
+
+	primary, mirror := newRecordingStorage(0), newRecordingStorage(100)
+	s := newMirrorStorage(primary, mirror, ref)
+
+	_, err := s.PutFile("/home/scanner/scan.pdf", strings.NewReader("scan contents"), 0)
+	require.NoError(t, err)
+	_, ok := primary.file("/home/scanner/scan.pdf")
+	assert.True(t, ok)
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(failed) == before+1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, len(mirrorRetryDelays)+1, mirror.attemptCount())
+}
+
+func TestMirrorStorage_PrimaryFailureNotMirrored(t *testing.T) {
+	primary, mirror := newRecordingStorage(1), newRecordingStorage(0)
+	s := newMirrorStorage(primary, mirror, ftpv1.BackendReference{Kind: "MinioBackend", Name: "mirror-primary-fails"})
+
+	_, err := s.PutFile("/home/scanner/scan.pdf", strings.NewReader("scan contents"), 0)
+	require.Error(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0, mirror.attemptCount(), "failed uploads should not be queued")
+}
+
+func TestMirrorStorage_SpoolLimit(t *testing.T) {
+	shortMirrorRetries(t)
+	previous := mirrorSpoolLimit
+	mirrorSpoolLimit = 8
+	t.Cleanup(func() { mirrorSpoolLimit = previous })
+
+	ref := ftpv1.BackendReference{Kind: "MinioBackend", Name: "mirror-spool-limit"}
+	dropped := metrics.MirrorUploadsTotal.WithLabelValues(ref.Kind, ref.Name, "dropped")
+	before := testutil.ToFloat64(dropped)
+	primary, mirror := newRecordingStorage(0), newRecordingStorage(0)
+	s := newMirrorStorage(primary, mirror, ref)
+
+	// An upload larger than the spool limit still reaches the primary
+	written, err := s.PutFile("/home/scanner/scan.pdf", strings.NewReader("scan contents"), 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(13), written)
+	data, ok := primary.file("/home/scanner/scan.pdf")
+	require.True(t, ok)
+	assert.Equal(t, "scan contents", data)
+	assert.Equal(t, before+1, testutil.ToFloat64(dropped))
+	assert.Zero(t, mirrorSpoolBytes.Load(), "a dropped upload releases its spool bytes")
+
+	// Uploads within the limit are still mirrored and release the spool once copied
+	_, err = s.PutFile("/home/scanner/note.txt", strings.NewReader("note"), 0)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		_, ok := mirror.file("/home/scanner/note.txt")
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		return mirrorSpoolBytes.Load() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	_, ok = mirror.file("/home/scanner/scan.pdf")
+	assert.False(t, ok)
+}
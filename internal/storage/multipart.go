@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/rossigee/kubeftpd/internal/backends"
+)
+
+// defaultMultipartPartSize is the S3 minimum size for all but the last part
+const defaultMultipartPartSize = 5 * 1024 * 1024
+
+// pendingUploadTTL is how long an interrupted upload waits for a resume before
+// it is aborted, so abandoned parts are neither kept in memory nor billed
+var pendingUploadTTL = 24 * time.Hour
+
+// pendingUploadSweepInterval is how often interrupted uploads are checked
+// against pendingUploadTTL
+var pendingUploadSweepInterval = 10 * time.Minute
+
+var pendingUploadSweepOnce sync.Once
+
+// pendingUpload is an interrupted multipart upload awaiting a resume
+type pendingUpload struct {
+	uploadID string
+	parts    []backends.UploadedPart
+	// active is set while a session is writing to the upload
+	active bool
+	// backend and path let the sweep abort an upload no session resumes
+	backend backends.MinioBackend
+	path    string
+	// updated is when a session last wrote to the upload
+	updated time.Time
+}
+
+// uploadedSize returns the number of bytes stored in completed parts
+func (u *pendingUpload) uploadedSize() int64 {
+	var size int64
+	for _, part := range u.parts {
+		size += part.Size
+	}
+	return size
+}
+
+// pendingUploads tracks interrupted multipart uploads by backend and object
+// path. Storage is created per session, so the registry is package-level to
+// let a reconnecting client resume what an earlier session started. It lives
+// in this process only: a client reconnecting to another replica starts over.
+var pendingUploads = struct {
+	sync.Mutex
+	uploads map[string]*pendingUpload
+}{uploads: make(map[string]*pendingUpload)}
+
+// startPendingUploadSweep starts the background sweep of interrupted uploads
+// the first time a resumable upload is made
+func startPendingUploadSweep() {
+	pendingUploadSweepOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(pendingUploadSweepInterval)
+			defer ticker.Stop()
+			for now := range ticker.C {
+				sweepPendingUploads(now)
+			}
+		}()
+	})
+}
+
+// sweepPendingUploads aborts the interrupted uploads no session has written
+// to for pendingUploadTTL and forgets them
+func sweepPendingUploads(now time.Time) {
+	var stale []*pendingUpload
+	pendingUploads.Lock()
+	for key, upload := range pendingUploads.uploads {
+		if !upload.active && now.Sub(upload.updated) > pendingUploadTTL {
+			stale = append(stale, upload)
+			delete(pendingUploads.uploads, key)
+		}
+	}
+	pendingUploads.Unlock()
+
+	for _, upload := range stale {
+		logger := ctrl.Log.WithName("multipart").WithValues("path", upload.path, "upload_id", upload.uploadID)
+		if err := upload.backend.AbortMultipartUpload(upload.path, upload.uploadID); err != nil {
+			logger.Error(err, "Failed to abort expired interrupted upload")
+			continue
+		}
+		logger.Info("Aborted interrupted upload that was not resumed", "uploaded_bytes", upload.uploadedSize())
+	}
+}
+
+// pendingUploadSize returns the bytes stored for an interrupted upload of
+// fullPath, the offset a client resumes it from
+func (s *minioStorage) pendingUploadSize(fullPath string) (int64, time.Time, bool) {
+	pendingUploads.Lock()
+	defer pendingUploads.Unlock()
+	upload, ok := pendingUploads.uploads[s.uploadScope+":"+fullPath]
+	if !ok {
+		return 0, time.Time{}, false
+	}
+	return upload.uploadedSize(), upload.updated, true
+}
+
+// putFileResumable uploads a file as a multipart upload. With offset 0 any
+// interrupted upload of the same path is discarded and a new one started. With
+// a positive offset the interrupted upload is continued: its completed parts
+// are kept, and the bytes the client resends below the uploaded size are skipped.
+// If the transfer fails, the completed parts are kept for a later resume.
+func (s *minioStorage) putFileResumable(fullPath string, reader io.Reader, offset int64) (int64, error) {
+	key := s.uploadScope + ":" + fullPath
+	startPendingUploadSweep()
+
+	upload, err := s.claimUpload(key, fullPath, offset)
+	if err != nil {
+		return 0, err
+	}
+
+	countingReader := &countingReader{reader: reader}
+	written, err := s.uploadParts(fullPath, upload, countingReader, offset)
+
+	pendingUploads.Lock()
+	defer pendingUploads.Unlock()
+	upload.active = false
+	upload.updated = time.Now()
+	if err != nil {
+		// Leave the upload registered so the client can resume it
+		return written, err
+	}
+	delete(pendingUploads.uploads, key)
+	return written, nil
+}
+
+// claimUpload returns the upload to write to, marking it active
+func (s *minioStorage) claimUpload(key, fullPath string, offset int64) (*pendingUpload, error) {
+	pendingUploads.Lock()
+	defer pendingUploads.Unlock()
+
+	existing := pendingUploads.uploads[key]
+	if existing != nil && existing.active {
+		return nil, fmt.Errorf("upload of %s already in progress", fullPath)
+	}
+
+	if offset > 0 {
+		if existing == nil {
+			return nil, fmt.Errorf("no interrupted upload to resume for %s", fullPath)
+		}
+		if uploaded := existing.uploadedSize(); offset > uploaded {
+			return nil, fmt.Errorf("resume offset %d is beyond the %d bytes uploaded for %s", offset, uploaded, fullPath)
+		}
+		existing.active = true
+		existing.updated = time.Now()
+		return existing, nil
+	}
+
+	if existing != nil {
+		_ = s.backend.AbortMultipartUpload(fullPath, existing.uploadID)
+		delete(pendingUploads.uploads, key)
+	}
+	uploadID, err := s.backend.NewMultipartUpload(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	upload := &pendingUpload{uploadID: uploadID, active: true, backend: s.backend, path: fullPath, updated: time.Now()}
+	pendingUploads.uploads[key] = upload
+	return upload, nil
+}
+
+// uploadParts streams the reader into parts after those already uploaded and
+// completes the upload at EOF. It returns the number of bytes read from reader.
+func (s *minioStorage) uploadParts(fullPath string, upload *pendingUpload, reader *countingReader, offset int64) (int64, error) {
+	// Parts are only appended by the session holding the upload, so reading
+	// them without the registry lock is safe here.
+	if skip := upload.uploadedSize() - offset; skip > 0 {
+		if _, err := io.CopyN(io.Discard, reader, skip); err != nil {
+			return reader.bytesRead, fmt.Errorf("failed to skip already uploaded data: %w", err)
+		}
+	}
+
+	partSize := s.partSize
+	if partSize <= 0 {
+		partSize = defaultMultipartPartSize
+	}
+	buf := make([]byte, partSize)
+	for {
+		n, readErr := io.ReadFull(reader, buf)
+		eof := errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF)
+		if readErr != nil && !eof {
+			// Drop the incomplete part; the client resends it when resuming
+			return reader.bytesRead, fmt.Errorf("upload interrupted after %d bytes: %w", upload.uploadedSize(), readErr)
+		}
+		if n > 0 {
+			partNumber := len(upload.parts) + 1
+			part, err := s.backend.UploadPart(fullPath, upload.uploadID, partNumber, bytes.NewReader(buf[:n]), int64(n))
+			if err != nil {
+				return reader.bytesRead, err
+			}
+			pendingUploads.Lock()
+			upload.parts = append(upload.parts, part)
+			upload.updated = time.Now()
+			pendingUploads.Unlock()
+		}
+		if eof {
+			break
+		}
+	}
+
+	if len(upload.parts) == 0 {
+		// S3 rejects completing an upload without parts, so store an empty object
+		_ = s.backend.AbortMultipartUpload(fullPath, upload.uploadID)
+		if err := s.backend.PutObject(fullPath, bytes.NewReader(nil), 0); err != nil {
+			return reader.bytesRead, fmt.Errorf("failed to put file: %w", err)
+		}
+		return reader.bytesRead, nil
+	}
+	if err := s.backend.CompleteMultipartUpload(fullPath, upload.uploadID, upload.parts); err != nil {
+		return reader.bytesRead, err
+	}
+	return reader.bytesRead, nil
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+	"github.com/rossigee/kubeftpd/internal/backends"
+)
+
+// interruptedReader returns its data and then fails, like a dropped data connection
+func interruptedReader(data string) io.Reader {
+	return io.MultiReader(strings.NewReader(data), &failingReader{})
+}
+
+type failingReader struct{}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("connection reset by peer")
+}
+
+func newResumableStorage(backend backends.MinioBackend, scope string) *minioStorage {
+	return &minioStorage{
+		user: &ftpv1.User{
+			ObjectMeta: metav1.ObjectMeta{Name: "testuser"},
+			Spec: ftpv1.UserSpec{
+				Username:      "testuser",
+				HomeDirectory: "/home/testuser",
+				Permissions:   ftpv1.UserPermissions{Write: true},
+			},
+		},
+		backend:          backend,
+		basePath:         "/home/testuser",
+		currentDir:       "/home/testuser",
+		resumableUploads: true,
+		uploadScope:      scope,
+		partSize:         4,
+	}
+}
+
+func TestMinioStorage_PutFile_ResumesMultipartUpload(t *testing.T) {
+	const objectPath = "/home/testuser/big.bin"
+	mockBackend := &MockMinioBackend{}
+
+	// Parts are matched on content, so each is expected exactly once
+	part := func(n int, data string) backends.UploadedPart {
+		return backends.UploadedPart{PartNumber: n, ETag: "etag-" + data, Size: int64(len(data))}
+	}
+	mockBackend.On("NewMultipartUpload", objectPath).Return("upload-1", nil).Once()
+	mockBackend.On("UploadPart", objectPath, "upload-1", 1, []byte("abcd"), int64(4)).Return(part(1, "abcd"), nil).Once()
+	mockBackend.On("UploadPart", objectPath, "upload-1", 2, []byte("efgh"), int64(4)).Return(part(2, "efgh"), nil).Once()
+	mockBackend.On("UploadPart", objectPath, "upload-1", 3, []byte("ijkl"), int64(4)).Return(part(3, "ijkl"), nil).Once()
+	mockBackend.On("UploadPart", objectPath, "upload-1", 4, []byte("mn"), int64(2)).Return(part(4, "mn"), nil).Once()
+	mockBackend.On("CompleteMultipartUpload", objectPath, "upload-1",
+		[]backends.UploadedPart{part(1, "abcd"), part(2, "efgh"), part(3, "ijkl"), part(4, "mn")}).Return(nil).Once()
+
+	storage := newResumableStorage(mockBackend, "default/resume")
+	assert.True(t, storage.Capabilities().Append)
+
+	// First session: the connection drops after 10 bytes; the partial third part is not uploaded
+	_, err := storage.PutFile("big.bin", interruptedReader("abcdefghij"), 0)
+	require.Error(t, err)
+
+	// Second session resumes from 6 bytes; "gh" is already stored and skipped
+	n, err := storage.PutFile("big.bin", strings.NewReader("ghijklmn"), 6)
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), n)
+
+	mockBackend.AssertExpectations(t)
+	mockBackend.AssertNotCalled(t, "AbortMultipartUpload", mock.Anything, mock.Anything)
+
+	// The upload is finished, so there is nothing left to resume
+	_, err = storage.PutFile("big.bin", strings.NewReader("mn"), 14)
+	assert.ErrorContains(t, err, "no interrupted upload")
+}
+
+func TestMinioStorage_PutFile_ResumeErrors(t *testing.T) {
+	const objectPath = "/home/testuser/data.bin"
+	mockBackend := &MockMinioBackend{}
+	mockBackend.On("NewMultipartUpload", objectPath).Return("upload-1", nil).Once()
+	mockBackend.On("UploadPart", objectPath, "upload-1", 1, []byte("abcd"), int64(4)).
+		Return(backends.UploadedPart{PartNumber: 1, ETag: "etag-1", Size: 4}, nil).Once()
+
+	storage := newResumableStorage(mockBackend, "default/errors")
+
+	_, err := storage.PutFile("data.bin", strings.NewReader("abcd"), 2)
+	assert.ErrorContains(t, err, "no interrupted upload")
+
+	_, err = storage.PutFile("data.bin", interruptedReader("abcdef"), 0)
+	require.Error(t, err)
+
+	// Only 4 bytes were stored, so the client can't skip ahead to 6
+	_, err = storage.PutFile("data.bin", strings.NewReader("gh"), 6)
+	assert.ErrorContains(t, err, "beyond the 4 bytes uploaded")
+
+	// A fresh upload discards the interrupted one
+	mockBackend.On("AbortMultipartUpload", objectPath, "upload-1").Return(nil).Once()
+	mockBackend.On("NewMultipartUpload", objectPath).Return("upload-2", nil).Once()
+	mockBackend.On("UploadPart", objectPath, "upload-2", 1, []byte("xy"), int64(2)).
+		Return(backends.UploadedPart{PartNumber: 1, ETag: "etag-xy", Size: 2}, nil).Once()
+	mockBackend.On("CompleteMultipartUpload", objectPath, "upload-2",
+		[]backends.UploadedPart{{PartNumber: 1, ETag: "etag-xy", Size: 2}}).Return(nil).Once()
+
+	n, err := storage.PutFile("data.bin", strings.NewReader("xy"), 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	mockBackend.AssertExpectations(t)
+}
+
+func TestMinioStorage_InterruptedUploadExpires(t *testing.T) {
+	// Start from an empty registry so uploads left by other tests are not swept
+	pendingUploads.Lock()
+	saved := pendingUploads.uploads
+	pendingUploads.uploads = make(map[string]*pendingUpload)
+	pendingUploads.Unlock()
+	t.Cleanup(func() {
+		pendingUploads.Lock()
+		pendingUploads.uploads = saved
+		pendingUploads.Unlock()
+	})
+
+	const objectPath = "/home/testuser/stale.bin"
+	mockBackend := &MockMinioBackend{}
+	mockBackend.On("NewMultipartUpload", objectPath).Return("upload-1", nil).Once()
+	mockBackend.On("UploadPart", objectPath, "upload-1", 1, []byte("abcd"), int64(4)).
+		Return(backends.UploadedPart{PartNumber: 1, ETag: "etag-1", Size: 4}, nil).Once()
+	mockBackend.On("StatObject", objectPath).Return((*backends.ObjectInfo)(nil), errors.New("object not found"))
+
+	storage := newResumableStorage(mockBackend, "default/expiry")
+	_, err := storage.PutFile("stale.bin", interruptedReader("abcdef"), 0)
+	require.Error(t, err)
+
+	// SIZE reports the committed bytes, the offset to resume from
+	info, err := storage.Stat("stale.bin")
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), info.Size())
+
+	// A recent upload survives the sweep
+	sweepPendingUploads(time.Now())
+	mockBackend.AssertNotCalled(t, "AbortMultipartUpload", mock.Anything, mock.Anything)
+
+	// Once it has waited longer than the TTL it is aborted and forgotten
+	mockBackend.On("AbortMultipartUpload", objectPath, "upload-1").Return(nil).Once()
+	sweepPendingUploads(time.Now().Add(pendingUploadTTL + time.Minute))
+	mockBackend.AssertExpectations(t)
+
+	_, err = storage.Stat("stale.bin")
+	assert.Error(t, err)
+	_, err = storage.PutFile("stale.bin", strings.NewReader("ef"), 4)
+	assert.ErrorContains(t, err, "no interrupted upload")
+}
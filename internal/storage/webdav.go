@@ -125,6 +125,9 @@ func (s *webdavStorage) MakeDir(dirPath string) error {
 	}
 
 	fullPath := s.resolvePath(dirPath)
+	if info, err := s.backend.Stat(fullPath); err == nil && info.IsDir {
+		return ErrDirExists
+	}
 	return s.backend.Mkdir(fullPath)
 }
 
@@ -206,6 +209,12 @@ func (fi *webdavFileInfo) Owner() string      { return "" }
 func (fi *webdavFileInfo) Group() string      { return "" }
 func (fi *webdavFileInfo) Sys() interface{}   { return nil }
 
+// Capabilities reports no optional features: downloads always start at the
+// beginning of the file and uploads cannot be appended to
+func (s *webdavStorage) Capabilities() Capabilities {
+	return Capabilities{}
+}
+
 // Close cleans up resources
 func (s *webdavStorage) Close() error {
 	// WebDAV client does not require explicit closing
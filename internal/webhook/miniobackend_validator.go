@@ -0,0 +1,38 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+// MinioBackendValidator validates MinioBackend resources against the server's
+// backend security policy
+type MinioBackendValidator struct {
+	// RequireHTTPS rejects backends whose endpoint is not https://
+	RequireHTTPS bool
+	decoder      *admission.Decoder
+}
+
+// Handle validates MinioBackend resources
+func (v *MinioBackendValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	backend := &ftpv1.MinioBackend{}
+	if err := (*v.decoder).Decode(req, backend); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if err := ftpv1.ValidateMinioEndpoint(backend.Spec.Endpoint, v.RequireHTTPS); err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	return admission.Allowed("")
+}
+
+// InjectDecoder injects the decoder
+func (v *MinioBackendValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	ftpv1 "github.com/rossigee/kubeftpd/api/v1"
+)
+
+func TestMinioBackendValidator_Handle(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := ftpv1.AddToScheme(scheme)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		endpoint     string
+		requireHTTPS bool
+		wantDeny     bool
+	}{
+		{
+			name:         "http endpoint rejected under policy",
+			endpoint:     "http://minio.storage.svc:9000",
+			requireHTTPS: true,
+			wantDeny:     true,
+		},
+		{
+			name:         "https endpoint accepted under policy",
+			endpoint:     "https://minio.example.com",
+			requireHTTPS: true,
+			wantDeny:     false,
+		},
+		{
+			name:         "http endpoint accepted without policy",
+			endpoint:     "http://minio.storage.svc:9000",
+			requireHTTPS: false,
+			wantDeny:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &ftpv1.MinioBackend{
+				ObjectMeta: metav1.ObjectMeta{Name: "archive", Namespace: "default"},
+				Spec:       ftpv1.MinioBackendSpec{Endpoint: tt.endpoint, Bucket: "archive"},
+			}
+			backendJSON, err := json.Marshal(backend)
+			assert.NoError(t, err)
+
+			validator := &MinioBackendValidator{RequireHTTPS: tt.requireHTTPS}
+			decoder := admission.NewDecoder(scheme)
+			assert.NoError(t, validator.InjectDecoder(&decoder))
+
+			resp := validator.Handle(context.Background(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Object:    runtime.RawExtension{Raw: backendJSON},
+					Namespace: backend.Namespace,
+				},
+			})
+
+			if tt.wantDeny {
+				assert.False(t, resp.Allowed, "Expected admission to be denied")
+				assert.Contains(t, resp.Result.Message, "must use https://")
+			} else {
+				assert.True(t, resp.Allowed, "Expected admission to be allowed")
+			}
+		})
+	}
+}
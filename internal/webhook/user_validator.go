@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"regexp"
+	"slices"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
@@ -17,8 +17,11 @@ import (
 
 // UserValidator validates User resources for security compliance
 type UserValidator struct {
-	Client  client.Client
-	decoder *admission.Decoder
+	Client client.Client
+	// RequireUniqueUsernames denies an enabled User whose username is already
+	// used by another enabled User in any namespace
+	RequireUniqueUsernames bool
+	decoder                *admission.Decoder
 }
 
 // Handle validates User resources
@@ -34,6 +37,17 @@ func (v *UserValidator) Handle(ctx context.Context, req admission.Request) admis
 		return admission.Denied(err.Error())
 	}
 
+	// Validate home directory is absolute and normalized
+	if err := ftpv1.ValidateHomeDirectory(user.Spec.HomeDirectory); err != nil {
+		return admission.Denied(err.Error())
+	}
+	if err := ftpv1.ValidateHomeByCIDR(user.Spec.HomeByCIDR); err != nil {
+		return admission.Denied(err.Error())
+	}
+	if err := ftpv1.ValidateTimezone(user.Spec.Timezone); err != nil {
+		return admission.Denied(err.Error())
+	}
+
 	// Validate password strength if plaintext
 	if user.Spec.Password != "" {
 		if err := v.validatePasswordStrength(user.Spec.Password); err != nil {
@@ -53,88 +67,92 @@ func (v *UserValidator) Handle(ctx context.Context, req admission.Request) admis
 		return admission.Denied(err.Error())
 	}
 
-	return admission.Allowed("")
-}
-
-// validatePasswordConfig ensures proper password configuration
-func (v *UserValidator) validatePasswordConfig(ctx context.Context, user *ftpv1.User) error {
-	hasPassword := user.Spec.Password != ""
-	hasSecret := user.Spec.PasswordSecret != nil
-
-	if !hasPassword && !hasSecret {
-		return fmt.Errorf("either password or passwordSecret must be specified")
+	if v.RequireUniqueUsernames {
+		if err := v.validateUniqueUsername(ctx, user); err != nil {
+			return admission.Denied(err.Error())
+		}
 	}
 
-	if hasPassword && hasSecret {
-		return fmt.Errorf("cannot specify both password and passwordSecret")
+	if err := v.validateLoginAliases(ctx, user); err != nil {
+		return admission.Denied(err.Error())
 	}
 
-	return nil
+	return admission.Allowed("")
 }
 
-// validatePasswordStrength checks plaintext password strength
-func (v *UserValidator) validatePasswordStrength(password string) error {
-	// Minimum length check
-	if len(password) < 8 {
-		return fmt.Errorf("password must be at least 8 characters long")
+// validateUniqueUsername ensures no other enabled User in any namespace has the
+// same username, since the FTP server resolves logins by username alone
+func (v *UserValidator) validateUniqueUsername(ctx context.Context, user *ftpv1.User) error {
+	if !user.Spec.Enabled {
+		return nil
 	}
 
-	// Check for common weak patterns
-	weakPatterns := []string{
-		"password", "123456", "qwerty", "admin", "test", "user",
-		"welcome", "login", "pass", "secret", "default",
+	userList := &ftpv1.UserList{}
+	if err := v.Client.List(ctx, userList); err != nil {
+		return fmt.Errorf("failed to list users: %w", err)
 	}
 
-	lowercasePassword := strings.ToLower(password)
-	for _, weak := range weakPatterns {
-		if strings.Contains(lowercasePassword, weak) {
-			return fmt.Errorf("password contains weak pattern: %s", weak)
+	for _, existing := range userList.Items {
+		if existing.Namespace == user.Namespace && existing.Name == user.Name {
+			continue
+		}
+		if existing.Spec.Enabled && existing.Spec.Username == user.Spec.Username {
+			return fmt.Errorf("username %q is already used by User %s/%s", user.Spec.Username, existing.Namespace, existing.Name)
 		}
 	}
 
-	// Complexity requirements
-	var hasUpper, hasLower, hasDigit, hasSpecial bool
+	return nil
+}
 
-	for _, char := range password {
-		switch {
-		case char >= 'A' && char <= 'Z':
-			hasUpper = true
-		case char >= 'a' && char <= 'z':
-			hasLower = true
-		case char >= '0' && char <= '9':
-			hasDigit = true
-		case strings.ContainsRune("!@#$%^&*()_+-=[]{}|;:,.<>?", char):
-			hasSpecial = true
-		}
+// validateLoginAliases ensures the user's login aliases don't name another
+// User, and that no other User uses the user's username or aliases as an
+// alias, so that an alias can never take over someone else's login
+func (v *UserValidator) validateLoginAliases(ctx context.Context, user *ftpv1.User) error {
+	userList := &ftpv1.UserList{}
+	if err := v.Client.List(ctx, userList); err != nil {
+		return fmt.Errorf("failed to list users: %w", err)
 	}
 
-	missing := []string{}
-	if !hasUpper {
-		missing = append(missing, "uppercase letter")
-	}
-	if !hasLower {
-		missing = append(missing, "lowercase letter")
-	}
-	if !hasDigit {
-		missing = append(missing, "digit")
-	}
-	if !hasSpecial {
-		missing = append(missing, "special character")
+	for _, existing := range userList.Items {
+		if existing.Namespace == user.Namespace && existing.Name == user.Name {
+			continue
+		}
+		for _, alias := range user.Spec.LoginAliases {
+			if alias == existing.Spec.Username {
+				return fmt.Errorf("login alias %q is the username of User %s/%s", alias, existing.Namespace, existing.Name)
+			}
+		}
+		for _, alias := range existing.Spec.LoginAliases {
+			if alias == user.Spec.Username || slices.Contains(user.Spec.LoginAliases, alias) {
+				return fmt.Errorf("login name %q is already a login alias of User %s/%s", alias, existing.Namespace, existing.Name)
+			}
+		}
 	}
 
-	if len(missing) > 0 {
-		return fmt.Errorf("password must contain at least one: %s", strings.Join(missing, ", "))
+	return nil
+}
+
+// validatePasswordConfig ensures proper password configuration
+func (v *UserValidator) validatePasswordConfig(ctx context.Context, user *ftpv1.User) error {
+	hasPassword := user.Spec.Password != ""
+	hasSecret := user.Spec.PasswordSecret != nil
+
+	if !hasPassword && !hasSecret {
+		return fmt.Errorf("either password or passwordSecret must be specified")
 	}
 
-	// Check for sequential characters
-	sequential := regexp.MustCompile(`(012|123|234|345|456|567|678|789|890|abc|bcd|cde|def|efg|fgh|ghi|hij|ijk|jkl|klm|lmn|mno|nop|opq|pqr|qrs|rst|stu|tuv|uvw|vwx|wxy|xyz)`)
-	if sequential.MatchString(strings.ToLower(password)) {
-		return fmt.Errorf("password cannot contain sequential characters")
+	if hasPassword && hasSecret {
+		return fmt.Errorf("cannot specify both password and passwordSecret")
 	}
 
 	return nil
 }
 
+// validatePasswordStrength checks plaintext password strength
+func (v *UserValidator) validatePasswordStrength(password string) error {
+	return ftpv1.ValidatePasswordStrength(password)
+}
+
 // validateSecretReference checks if secret exists and is accessible
 func (v *UserValidator) validateSecretReference(ctx context.Context, user *ftpv1.User) error {
 	secretRef := user.Spec.PasswordSecret
@@ -246,6 +246,107 @@ func TestUserValidator_Handle(t *testing.T) {
 			wantDeny: true,
 			wantMsg:  "password secret default/nonexistent-secret not found",
 		},
+		{
+			name: "invalid - relative home directory",
+			user: &ftpv1.User{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testuser",
+					Namespace: "default",
+				},
+				Spec: ftpv1.UserSpec{
+					Username: "testuser",
+					Password: "MyStrong97@",
+					Backend: ftpv1.BackendReference{
+						Kind: "MinioBackend",
+						Name: "test-backend",
+					},
+					HomeDirectory: "home/testuser",
+				},
+			},
+			wantDeny: true,
+			wantMsg:  "must be an absolute path",
+		},
+		{
+			name: "invalid - home directory with traversal",
+			user: &ftpv1.User{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testuser",
+					Namespace: "default",
+				},
+				Spec: ftpv1.UserSpec{
+					Username: "testuser",
+					Password: "MyStrong97@",
+					Backend: ftpv1.BackendReference{
+						Kind: "MinioBackend",
+						Name: "test-backend",
+					},
+					HomeDirectory: "/home/../etc",
+				},
+			},
+			wantDeny: true,
+			wantMsg:  "must not contain '..'",
+		},
+		{
+			name: "invalid - home directory not normalized",
+			user: &ftpv1.User{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testuser",
+					Namespace: "default",
+				},
+				Spec: ftpv1.UserSpec{
+					Username: "testuser",
+					Password: "MyStrong97@",
+					Backend: ftpv1.BackendReference{
+						Kind: "MinioBackend",
+						Name: "test-backend",
+					},
+					HomeDirectory: "/home//testuser/",
+				},
+			},
+			wantDeny: true,
+			wantMsg:  "is not normalized",
+		},
+		{
+			name: "valid user with timezone",
+			user: &ftpv1.User{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testuser",
+					Namespace: "default",
+				},
+				Spec: ftpv1.UserSpec{
+					Username: "testuser",
+					Password: "MyStrong97@",
+					Backend: ftpv1.BackendReference{
+						Kind: "MinioBackend",
+						Name: "test-backend",
+					},
+					HomeDirectory: "/home/testuser",
+					Timezone:      "Asia/Tokyo",
+				},
+			},
+			wantDeny: false,
+		},
+		{
+			name: "invalid - unknown timezone",
+			user: &ftpv1.User{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testuser",
+					Namespace: "default",
+				},
+				Spec: ftpv1.UserSpec{
+					Username: "testuser",
+					Password: "MyStrong97@",
+					Backend: ftpv1.BackendReference{
+						Kind: "MinioBackend",
+						Name: "test-backend",
+					},
+					HomeDirectory: "/home/testuser",
+					Timezone:      "Mars/Olympus_Mons",
+				},
+			},
+			wantDeny: true,
+			wantMsg:  "is not a known IANA time zone",
+		},
 	}
 
 	for _, tt := range tests {
@@ -364,6 +465,75 @@ func TestUserValidator_validatePasswordStrength(t *testing.T) {
 	}
 }
 
+func TestUserValidator_validatePasswordStrength_CustomPolicy(t *testing.T) {
+	validator := &UserValidator{}
+	t.Cleanup(func() { ftpv1.SetPasswordPolicy(ftpv1.DefaultPasswordPolicy()) })
+
+	tests := []struct {
+		name     string
+		policy   ftpv1.PasswordPolicy
+		password string
+		wantErr  bool
+		errMsg   string
+	}{
+		{
+			name:     "longer minimum rejects a default-strong password",
+			policy:   ftpv1.PasswordPolicy{MinLength: 16},
+			password: "MyStrong97@",
+			wantErr:  true,
+			errMsg:   "password must be at least 16 characters long",
+		},
+		{
+			name:     "no required classes accepts a lowercase passphrase",
+			policy:   ftpv1.PasswordPolicy{MinLength: 8},
+			password: "correct horse battery staple",
+			wantErr:  false,
+		},
+		{
+			name:     "custom banned substring",
+			policy:   ftpv1.PasswordPolicy{MinLength: 8, BannedSubstrings: []string{"Acme"}},
+			password: "MyACME97@x",
+			wantErr:  true,
+			errMsg:   "password contains weak pattern: Acme",
+		},
+		{
+			name:     "default banned substrings no longer apply",
+			policy:   ftpv1.PasswordPolicy{MinLength: 8, RequiredClasses: []string{ftpv1.PasswordClassDigit}},
+			password: "mypassword97",
+			wantErr:  false,
+		},
+		{
+			name:     "sequential characters allowed when not disallowed",
+			policy:   ftpv1.PasswordPolicy{MinLength: 8, RequiredClasses: ftpv1.PasswordClasses},
+			password: "MyStrong123@",
+			wantErr:  false,
+		},
+		{
+			name:     "only the required classes are reported missing",
+			policy:   ftpv1.PasswordPolicy{MinLength: 8, RequiredClasses: []string{ftpv1.PasswordClassDigit}},
+			password: "nodigitshere",
+			wantErr:  true,
+			errMsg:   "password must contain at least one: digit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ftpv1.SetPasswordPolicy(tt.policy)
+			err := validator.validatePasswordStrength(tt.password)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errMsg != "" {
+					assert.Contains(t, err.Error(), tt.errMsg)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestUserValidator_validateProductionRestrictions(t *testing.T) {
 	scheme := runtime.NewScheme()
 	err := ftpv1.AddToScheme(scheme)
@@ -506,3 +676,167 @@ func TestUserValidator_validateProductionRestrictions(t *testing.T) {
 		})
 	}
 }
+
+func TestUserValidator_validateUniqueUsername(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := ftpv1.AddToScheme(scheme)
+	assert.NoError(t, err)
+
+	newUser := func(namespace, name, username string, enabled bool) *ftpv1.User {
+		return &ftpv1.User{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: ftpv1.UserSpec{
+				Username: username,
+				Enabled:  enabled,
+				Backend: ftpv1.BackendReference{
+					Kind: "MinioBackend",
+					Name: "test-backend",
+				},
+				HomeDirectory: "/home/" + username,
+			},
+		}
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			newUser("team-a", "alice", "alice", true),
+			newUser("team-a", "old-bob", "bob", false),
+		).
+		Build()
+
+	validator := &UserValidator{
+		Client:                 fakeClient,
+		RequireUniqueUsernames: true,
+	}
+
+	tests := []struct {
+		name    string
+		user    *ftpv1.User
+		wantErr bool
+	}{
+		{
+			name:    "duplicate username in another namespace",
+			user:    newUser("team-b", "alice", "alice", true),
+			wantErr: true,
+		},
+		{
+			name:    "duplicate username under another name in the same namespace",
+			user:    newUser("team-a", "alice-2", "alice", true),
+			wantErr: true,
+		},
+		{
+			name:    "update of the existing user",
+			user:    newUser("team-a", "alice", "alice", true),
+			wantErr: false,
+		},
+		{
+			name:    "disabled duplicate",
+			user:    newUser("team-b", "alice", "alice", false),
+			wantErr: false,
+		},
+		{
+			name:    "username only used by a disabled user",
+			user:    newUser("team-b", "bob", "bob", true),
+			wantErr: false,
+		},
+		{
+			name:    "new username",
+			user:    newUser("team-b", "carol", "carol", true),
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.validateUniqueUsername(context.Background(), tt.user)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "is already used by User team-a/alice")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUserValidator_validateLoginAliases(t *testing.T) {
+	scheme := runtime.NewScheme()
+	err := ftpv1.AddToScheme(scheme)
+	assert.NoError(t, err)
+
+	newUser := func(namespace, name, username string, aliases ...string) *ftpv1.User {
+		return &ftpv1.User{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: ftpv1.UserSpec{
+				Username:     username,
+				LoginAliases: aliases,
+				Enabled:      true,
+				Backend: ftpv1.BackendReference{
+					Kind: "MinioBackend",
+					Name: "test-backend",
+				},
+				HomeDirectory: "/home/" + username,
+			},
+		}
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			newUser("team-a", "alice", "alice"),
+			newUser("team-a", "scanners", "scanner", "scanner-lobby"),
+		).
+		Build()
+
+	validator := &UserValidator{Client: fakeClient}
+
+	tests := []struct {
+		name    string
+		user    *ftpv1.User
+		wantMsg string
+	}{
+		{
+			name:    "alias naming another user",
+			user:    newUser("team-b", "mallory", "mallory", "alice"),
+			wantMsg: `login alias "alice" is the username of User team-a/alice`,
+		},
+		{
+			name:    "alias claimed by another user",
+			user:    newUser("team-b", "mallory", "mallory", "scanner-lobby"),
+			wantMsg: `login name "scanner-lobby" is already a login alias of User team-a/scanners`,
+		},
+		{
+			name:    "username claimed as another user's alias",
+			user:    newUser("team-b", "lobby", "scanner-lobby"),
+			wantMsg: `login name "scanner-lobby" is already a login alias of User team-a/scanners`,
+		},
+		{
+			name: "update of the user owning the alias",
+			user: newUser("team-a", "scanners", "scanner", "scanner-lobby", "scanner-office"),
+		},
+		{
+			name: "unclaimed aliases",
+			user: newUser("team-b", "printers", "printer", "printer-lobby"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.validateLoginAliases(context.Background(), tt.user)
+
+			if tt.wantMsg != "" {
+				assert.EqualError(t, err, tt.wantMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}